@@ -0,0 +1,40 @@
+package mask
+
+import "strings"
+
+// MaskArgs masks the values of command line flags, e.g. of os.Args, so launchers and audit
+// logs can record invocations without leaking credentials. Flags are recognized in the forms
+//
+//	-name=value  --name=value  -name value  --name value
+//
+// and masked like environment variables by MaskEnviron: flags named like secrets, see
+// WithSecretNames, as well as those matched by opts, e.g. mask.WithDenyFields("p") for -p.
+// A flag followed by a value is assumed to take it as its argument. Arguments following
+// "--" are kept.
+func MaskArgs(args []string, opts ...Option) []string {
+	opts = append([]Option{WithSecretNames()}, opts...)
+	out := make([]string, len(args))
+	copy(out, args)
+	for i := 0; i < len(out); i++ {
+		arg := out[i]
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == "" {
+			continue
+		}
+		if name, value, ok := strings.Cut(name, "="); ok {
+			out[i] = arg[:len(arg)-len(value)] + maskNamed(name, value, opts)
+			continue
+		}
+		if i+1 < len(out) && !strings.HasPrefix(out[i+1], "-") {
+			out[i+1] = maskNamed(name, out[i+1], opts)
+			i++
+		}
+	}
+	return out
+}
@@ -0,0 +1,36 @@
+package mask
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaskArgs(t *testing.T) {
+	args := []string{
+		"deploy", "-v",
+		"--password=hunter2",
+		"-api-key", "sk_live",
+		"--db-token", "--verbose",
+		"-p", "s3cret",
+		"-user", "ada",
+		"-client_secret=",
+		"--", "--password=kept",
+	}
+	out := MaskArgs(args, WithDenyFields("p"))
+	expect := []string{
+		"deploy", "-v",
+		"--password=MASKED",
+		"-api-key", "MASKED",
+		"--db-token", "--verbose",
+		"-p", "MASKED",
+		"-user", "ada",
+		"-client_secret=MASKED",
+		"--", "--password=kept",
+	}
+	if !reflect.DeepEqual(out, expect) {
+		t.Errorf("expect %q == %q", out, expect)
+	}
+	if args[2] != "--password=hunter2" {
+		t.Errorf("expect %v to be unchanged", args)
+	}
+}
@@ -0,0 +1,94 @@
+package bench
+
+import (
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+)
+
+var fixtures = []struct {
+	name string
+	val  interface{}
+	// json is set if the fixture can be encoded as JSON.
+	json bool
+	// allocs is the maximum number of allocations of Mask, guarded by TestAllocations.
+	allocs float64
+}{
+	{"small", Small(), true, 44},
+	{"flat", FlatValue(), true, 1},
+	{"deep", Deep(7), true, 5101},
+	{"slice", Slice(1000), true, 44004},
+	{"cyclic", Cyclic(7), false, 1320},
+	{"maps", Maps(200), true, 9420},
+}
+
+func BenchmarkMask(b *testing.B) {
+	for _, f := range fixtures {
+		b.Run(f.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := mask.Mask(f.val); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkJSON(b *testing.B) {
+	for _, f := range fixtures {
+		if !f.json {
+			continue
+		}
+		b.Run(f.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := mask.JSON(f.val).MarshalJSON(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	for _, f := range fixtures {
+		b.Run(f.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := mask.Scan(f.val); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkClone(b *testing.B) {
+	for _, f := range fixtures {
+		b.Run(f.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := mask.Clone(f.val); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestAllocations fails if masking any fixture allocates more than its baseline,
+// allowing for 10% due to map growth varying with the order of insertion.
+// Lower the baselines when improving allocations.
+func TestAllocations(t *testing.T) {
+	for _, f := range fixtures {
+		allocs := testing.AllocsPerRun(10, func() {
+			if _, err := mask.Mask(f.val); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if allocs > f.allocs*1.1 {
+			t.Errorf("%s: expect %v <= %v allocations", f.name, allocs, f.allocs)
+		}
+	}
+}
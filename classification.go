@@ -0,0 +1,90 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// classTagName is the struct tag classifying fields, e.g. `class:"restricted"`.
+const classTagName = "class"
+
+// Classification labels how sensitive the data of a field is, see WithClearance.
+type Classification int
+
+const (
+	// Public data may be disclosed to anyone.
+	Public Classification = iota
+	// Internal data may be disclosed within the organization.
+	Internal
+	// Confidential data may be disclosed to those needing it, e.g. support.
+	Confidential
+	// Restricted data, e.g. health records or credentials, must not leave its system.
+	Restricted
+)
+
+var classifications = []string{"public", "internal", "confidential", "restricted"}
+
+func (c Classification) String() string {
+	if c < Public || c > Restricted {
+		return fmt.Sprintf("Classification(%d)", int(c))
+	}
+	return classifications[c]
+}
+
+// ParseClassification parses the name of a classification, e.g. "restricted".
+func ParseClassification(name string) (Classification, error) {
+	for i, c := range classifications {
+		if strings.EqualFold(name, c) {
+			return Classification(i), nil
+		}
+	}
+	return Public, fmt.Errorf("%w: unknown classification %q", ErrInvalidTag, name)
+}
+
+// WithClearance enforces the classification of struct fields tagged with class,
+// e.g. `class:"restricted"`: masking fails with ErrClassification if a value
+// holds fields classified above c, unless they are omitted:
+//
+//	type Patient struct {
+//		Name      string `class:"confidential" mask:"partial=1:0"`
+//		Diagnosis string `class:"restricted"`
+//	}
+//
+//	mask.Mask(patient, mask.WithClearance(mask.Confidential)) // fails
+//
+// Masking a field by a strategy other than omit still outputs it, so it needs
+// to be cleared as well. Untagged fields are public.
+func WithClearance(c Classification) Option {
+	return func(o *options) {
+		o.clearance = &c
+	}
+}
+
+// cleared checks the classification of the struct field f, holding v, against the
+// clearance of WithClearance. tag is the strategy of the struct tag of f, if any.
+func (s *state) cleared(f reflect.StructField, tag Strategy, v reflect.Value) error {
+	clearance := s.opts.clearance
+	if clearance == nil {
+		return nil
+	}
+	name, ok := f.Tag.Lookup(classTagName)
+	if !ok {
+		return nil
+	}
+	c, err := ParseClassification(name)
+	if err != nil {
+		return err
+	}
+	if c <= *clearance {
+		return nil
+	}
+	omitted := isOmit(tag)
+	if tag == nil {
+		omitted = isOmit(s.pathStrategy()) || isOmit(s.typeStrategy(v)) || isOmit(s.fieldStrategy())
+	}
+	if omitted {
+		return nil
+	}
+	return fmt.Errorf("%w: %v field with clearance %v", ErrClassification, c, *clearance)
+}
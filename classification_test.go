@@ -0,0 +1,103 @@
+package mask
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type classifiedPatient struct {
+	ID        int
+	Name      string `class:"confidential" mask:"partial=1:0"`
+	Diagnosis string `class:"restricted"`
+	Notes     string `class:"restricted" mask:"omit"`
+}
+
+func TestWithClearance(t *testing.T) {
+	val := classifiedPatient{ID: 1, Name: "Ada", Diagnosis: "flu", Notes: "call back"}
+
+	masked, err := Mask(val, WithClearance(Restricted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (classifiedPatient{ID: 1, Name: "A**", Diagnosis: "flu"}); masked != expect {
+		t.Errorf("expect %+v == %+v", masked, expect)
+	}
+
+	_, err = Mask(val, WithClearance(Confidential))
+	if !errors.Is(err, ErrClassification) {
+		t.Errorf("expect %v to be %v", err, ErrClassification)
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "classifiedPatient.Diagnosis" {
+		t.Errorf("expect %v to locate Diagnosis", err)
+	}
+
+	// omitted fields are not output
+	masked, err = Mask(val, WithClearance(Confidential), WithPathStrategy("Diagnosis", Omit()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (classifiedPatient{ID: 1, Name: "A**"}); masked != expect {
+		t.Errorf("expect %+v == %+v", masked, expect)
+	}
+
+	// nested values are checked as well
+	if _, err := Mask([]*classifiedPatient{&val}, WithClearance(Internal)); !errors.Is(err, ErrClassification) {
+		t.Errorf("expect %v to be %v", err, ErrClassification)
+	}
+	if _, err := json.Marshal(JSON(val, WithClearance(Internal))); !errors.Is(err, ErrClassification) {
+		t.Errorf("expect %v to be %v", err, ErrClassification)
+	}
+
+	// classifications are not enforced without clearance
+	if _, err := Mask(val); err != nil {
+		t.Error(err)
+	}
+
+	type invalid struct {
+		Name string `class:"secret"`
+	}
+	if _, err := Mask(invalid{}, WithClearance(Restricted)); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expect %v to be %v", err, ErrInvalidTag)
+	}
+}
+
+func TestPolicyClearances(t *testing.T) {
+	p, err := LoadPolicy(strings.NewReader(`
+clearances:
+  analytics: internal
+  support: restricted
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	val := classifiedPatient{Name: "Ada", Diagnosis: "flu"}
+	for audience, expect := range map[string]bool{"analytics": true, "support": false, "": false} {
+		opts, err := p.Options(audience)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Mask(val, opts...)
+		if failed := errors.Is(err, ErrClassification); failed != expect {
+			t.Errorf("expect %s to fail: %v, got: %v", audience, expect, err)
+		}
+	}
+
+	_, err = LoadPolicy(strings.NewReader("clearances:\n  analytics: secret\n"))
+	if !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("expect %v to be %v", err, ErrInvalidPolicy)
+	}
+}
+
+func TestClassificationString(t *testing.T) {
+	for c, expect := range map[Classification]string{Public: "public", Restricted: "restricted", 7: "Classification(7)"} {
+		if out := c.String(); out != expect {
+			t.Errorf("expect %v == %v", out, expect)
+		}
+	}
+	if c, err := ParseClassification("Confidential"); err != nil || c != Confidential {
+		t.Errorf("expect %v == %v (%v)", c, Confidential, err)
+	}
+}
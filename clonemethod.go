@@ -0,0 +1,71 @@
+package mask
+
+import (
+	"reflect"
+	"sync"
+)
+
+// WithCloneMethods copies structs by their Clone or DeepCopy method, if any, before masking
+// them. This is correct for types with unexported or non-copyable internals, which copying
+// field by field drops, like the API types of Kubernetes. Methods are used if they are
+//
+//	func (t T) Clone() T      // or DeepCopy
+//	func (t *T) Clone() *T    // or DeepCopy
+//
+// The exported fields of the clone are then copied and masked as usual, while its unexported
+// fields are kept as the method set them.
+func WithCloneMethods() Option {
+	return func(o *options) {
+		o.cloneMethods = true
+	}
+}
+
+// cloneMethod is the Clone or DeepCopy method of a struct type.
+type cloneMethod struct {
+	fn    reflect.Value
+	onPtr bool
+}
+
+// cloneMethods caches the clone method by struct type, the zero cloneMethod if there is none.
+var cloneMethods sync.Map
+
+func cloneMethodOf(t reflect.Type) cloneMethod {
+	if m, ok := cloneMethods.Load(t); ok {
+		return m.(cloneMethod)
+	}
+	var cm cloneMethod
+	p := reflect.PointerTo(t)
+	for _, name := range []string{"Clone", "DeepCopy"} {
+		if m, ok := t.MethodByName(name); ok && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 && m.Type.Out(0) == t {
+			cm = cloneMethod{fn: m.Func}
+			break
+		}
+		if m, ok := p.MethodByName(name); ok && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 && m.Type.Out(0) == p {
+			cm = cloneMethod{fn: m.Func, onPtr: true}
+			break
+		}
+	}
+	cloneMethods.Store(t, cm)
+	return cm
+}
+
+// cloned returns the clone of the struct v made by its clone method, if any, using WithCloneMethods.
+func (s *state) cloned(v reflect.Value) (reflect.Value, bool) {
+	if !s.opts.cloneMethods {
+		return reflect.Value{}, false
+	}
+	cm := cloneMethodOf(v.Type())
+	if !cm.fn.IsValid() {
+		return reflect.Value{}, false
+	}
+	if !cm.onPtr {
+		return cm.fn.Call([]reflect.Value{v})[0], true
+	}
+	recv := reflect.New(v.Type())
+	recv.Elem().Set(v)
+	clone := cm.fn.Call([]reflect.Value{recv})[0]
+	if clone.IsNil() {
+		return reflect.Zero(v.Type()), true
+	}
+	return clone.Elem(), true
+}
@@ -0,0 +1,55 @@
+package mask
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testObject is copied by DeepCopy like the API types of Kubernetes.
+type testObject struct {
+	Name   string
+	Token  string `mask:"redact"`
+	Labels map[string]string
+	// resourceVersion is only kept by DeepCopy
+	resourceVersion string
+}
+
+func (o *testObject) DeepCopy() *testObject {
+	c := *o
+	c.Labels = make(map[string]string, len(o.Labels))
+	for k, v := range o.Labels {
+		c.Labels[k] = v
+	}
+	return &c
+}
+
+// testCounter is copied by Clone, which resets its unexported state.
+type testCounter struct {
+	Name  string
+	count int
+}
+
+func (c testCounter) Clone() testCounter {
+	return testCounter{Name: c.Name, count: c.count + 1}
+}
+
+func TestWithCloneMethods(t *testing.T) {
+	val := &testObject{Name: "pod", Token: "secret", Labels: map[string]string{"app": "api"}, resourceVersion: "42"}
+
+	if out := Must(val); out.resourceVersion != "" {
+		t.Errorf("expect unexported fields to be dropped by default, got %+v", out)
+	}
+	out := Must(val, WithCloneMethods())
+	expect := &testObject{Name: "pod", Token: "MASKED", Labels: map[string]string{"app": "api"}, resourceVersion: "42"}
+	if !reflect.DeepEqual(out, expect) {
+		t.Errorf("expect %+v == %+v", out, expect)
+	}
+	if out.Labels["app"] = "web"; val.Labels["app"] != "api" {
+		t.Errorf("expect %v to be unchanged", val.Labels)
+	}
+
+	counters := Must([]testCounter{{Name: "a", count: 1}}, WithCloneMethods())
+	if counters[0].count != 2 || counters[0].Name != "a" {
+		t.Errorf("expect %+v to be cloned", counters[0])
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/doejon/go-mask/internal/jsonnumber"
+	"gopkg.in/yaml.v3"
+)
+
+// codec decodes all documents read from r, masks them using fn and writes them to w.
+type codec func(r io.Reader, w io.Writer, fn func(interface{}) (interface{}, error)) error
+
+var codecs = map[string]codec{
+	"json": jsonCodec,
+	"yaml": yamlCodec,
+	"csv":  csvCodec,
+}
+
+// jsonCodec handles single JSON documents as well as JSON lines. Numbers are
+// decoded as json.Number, so large IDs are written unchanged.
+func jsonCodec(r io.Reader, w io.Writer, fn func(interface{}) (interface{}, error)) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	enc := json.NewEncoder(w)
+	for {
+		var doc interface{}
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		masked, err := fn(doc)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(jsonnumber.Masked(masked)); err != nil {
+			return err
+		}
+	}
+}
+
+func yamlCodec(r io.Reader, w io.Writer, fn func(interface{}) (interface{}, error)) error {
+	dec := yaml.NewDecoder(r)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	for {
+		var doc interface{}
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			return enc.Close()
+		} else if err != nil {
+			return err
+		}
+		masked, err := fn(doc)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(masked); err != nil {
+			return err
+		}
+	}
+}
+
+// csvCodec masks CSV files with a header row. Each record is masked as a map
+// from column names to values, i.e. columns are matched by paths like [*].email.
+// Columns omitted by the policy are written empty.
+func csvCodec(r io.Reader, w io.Writer, fn func(interface{}) (interface{}, error)) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	header := records[0]
+	rows := make([]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, col := range header {
+			row[col] = record[i]
+		}
+		rows = append(rows, row)
+	}
+
+	masked, err := fn(rows)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range masked.([]interface{}) {
+		m := row.(map[string]interface{})
+		record := make([]string, len(header))
+		for i, col := range header {
+			if v, ok := m[col]; ok && v != nil {
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
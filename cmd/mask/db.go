@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskdb"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+// dialects maps the database/sql drivers supported by -db to the SQL dialect of their dumps.
+var dialects = map[string]maskdb.Dialect{
+	"postgres": maskdb.Postgres,
+	"mysql":    maskdb.MySQL,
+}
+
+// dumpDatabase writes the masked rows of the comma separated tables of the database
+// at dsn to w, see maskdb.Dumper.
+func dumpDatabase(driver, dsn, tables string, copyData bool, w io.Writer, opts []mask.Option) error {
+	dialect, ok := dialects[driver]
+	if !ok {
+		return fmt.Errorf("unsupported database %q", driver)
+	}
+	if tables == "" {
+		return fmt.Errorf("-dsn requires -tables")
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	dopts := []maskdb.Option{maskdb.WithMaskOptions(opts...)}
+	if copyData {
+		dopts = append(dopts, maskdb.WithCopy())
+	}
+	var selected []maskdb.Table
+	for _, name := range strings.Split(tables, ",") {
+		selected = append(selected, maskdb.Table{Name: strings.TrimSpace(name)})
+	}
+	return maskdb.New(db, dialect, dopts...).Dump(context.Background(), w, selected...)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/doejon/go-mask/internal/sqltest"
+	"github.com/doejon/go-mask/maskdb"
+)
+
+func init() {
+	sqltest.Register("masktest", map[string]sqltest.Table{
+		"users": {
+			Columns: []string{"id", "email"},
+			Types:   []string{"INT8", "TEXT"},
+			Rows: [][]driver.Value{
+				{int64(1), []byte("ada@example.com")},
+				{int64(2), []byte("bob@example.com")},
+			},
+		},
+	})
+	dialects["masktest"] = maskdb.Postgres
+}
+
+func TestRunDatabase(t *testing.T) {
+	policy := writeFile(t, "policy.yaml", testPolicy)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-policy", policy, "-db", "masktest", "-dsn", "test", "-tables", "users"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expect exit code %d == 0: %s", code, stderr.String())
+	}
+	expect := `INSERT INTO "users" ("id", "email") VALUES
+  (1, 'a**************'),
+  (2, 'b**************');
+`
+	if stdout.String() != expect {
+		t.Errorf("expect %s == %s", stdout.String(), expect)
+	}
+
+	stderr.Reset()
+	if code := run([]string{"-policy", policy, "-db", "sqlite", "-dsn", "test", "-tables", "users"}, nil, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "unsupported database") {
+		t.Errorf("expect exit code %d == 1: %s", code, stderr.String())
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/doejon/go-mask/cmd/mask
+
+go 1.22.2
+
+require github.com/doejon/go-mask v0.0.0
+
+require (
+	github.com/go-sql-driver/mysql v1.8.1
+	github.com/lib/pq v1.10.9
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require filippo.io/edwards25519 v1.1.0 // indirect
+
+replace github.com/doejon/go-mask => ../..
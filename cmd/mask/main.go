@@ -0,0 +1,160 @@
+// Command mask masks JSON, YAML and CSV documents using the rules of a policy file,
+// e.g. to scrub data dumps and support bundles with the rules the services use.
+//
+//	mask -policy policy.yaml dump.json > masked.json
+//	kubectl get configmap -o yaml | mask -policy policy.yaml -format yaml
+//
+// Binary blobs like heap dumps or support bundles are scrubbed using -format binary:
+// the matches of the detectors of the policy and the secrets listed in the file given
+// by -secrets are overwritten, keeping the size of the blob.
+//
+//	mask -policy policy.yaml -secrets tokens.txt -format binary -w heap.dump
+//
+// Database tables are dumped as SQL using -dsn, masking their rows by the policy. Rows are
+// masked as objects holding the columns by the table name, so rules locate columns by paths
+// like "users.email". Equal values are masked alike across tables, preserving join keys:
+//
+//	mask -policy policy.yaml -db postgres -dsn "$DATABASE_URL" -tables users,orders > staging.sql
+//
+// Changes between two versions of a policy are listed using -diff, e.g. to review
+// them in CI, which fails if the new policy masks fewer values than the old one:
+//
+//	mask -policy policy.yaml -diff released-policy.yaml
+//
+// Documents are read from the given files or stdin and written to stdout,
+// or back to the files using -w.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mask "github.com/doejon/go-mask"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mask", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	policyFile := fs.String("policy", "", "policy `file` (JSON or YAML) defining the values to mask")
+	format := fs.String("format", "", "input `format`: json, yaml, csv or binary; defaults to the file extension, json for stdin")
+	audience := fs.String("audience", "", "apply the rules of the policy for `audience`")
+	write := fs.Bool("w", false, "write the result to the files instead of stdout")
+	secretsFile := fs.String("secrets", "", "`file` listing known secrets, one per line, masked wherever they occur")
+	dbDriver := fs.String("db", "postgres", "`database` dumped using -dsn: postgres or mysql")
+	dsn := fs.String("dsn", "", "dump the tables listed by -tables of the database at `dsn` as SQL instead of masking files")
+	tables := fs.String("tables", "", "comma separated `tables` to dump using -dsn")
+	copyData := fs.Bool("copy", false, "dump PostgreSQL tables as COPY data instead of INSERT statements")
+	diffFile := fs.String("diff", "", "list the changes from the policy `file` to -policy instead of masking; fails if they reduce coverage")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: mask -policy file [flags] [file ...]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *policyFile == "" {
+		fs.Usage()
+		return 2
+	}
+	if *diffFile != "" {
+		return diffPolicies(*diffFile, *policyFile, stdout, stderr)
+	}
+	if *write && fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "mask: -w requires files")
+		return 2
+	}
+
+	opts, err := loadPolicy(*policyFile, *audience)
+	if err != nil {
+		fmt.Fprintf(stderr, "mask: %v\n", err)
+		return 1
+	}
+	if *secretsFile != "" {
+		secrets, err := loadSecrets(*secretsFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "mask: %v\n", err)
+			return 1
+		}
+		opts = append(opts, secrets)
+	}
+	if *dsn != "" {
+		if err := dumpDatabase(*dbDriver, *dsn, *tables, *copyData, stdout, opts); err != nil {
+			fmt.Fprintf(stderr, "mask: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if fs.NArg() == 0 {
+		f := *format
+		if f == "" {
+			f = "json"
+		}
+		if err := maskStream(f, stdin, stdout, opts); err != nil {
+			fmt.Fprintf(stderr, "mask: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	status := 0
+	for _, name := range fs.Args() {
+		if err := maskFile(name, *format, *write, stdout, opts); err != nil {
+			fmt.Fprintf(stderr, "mask: %s: %v\n", name, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+var formatAliases = map[string]string{
+	"yml":   "yaml",
+	"jsonl": "json",
+}
+
+func maskFile(name, format string, write bool, stdout io.Writer, opts []mask.Option) error {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+		if alias, ok := formatAliases[format]; ok {
+			format = alias
+		}
+	}
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if !write {
+		return maskStream(format, in, stdout, opts)
+	}
+	// masked documents are buffered so a failure leaves the file untouched
+	var out bytes.Buffer
+	if err := maskStream(format, in, &out, opts); err != nil {
+		return err
+	}
+	return os.WriteFile(name, out.Bytes(), 0o644)
+}
+
+// maskStream masks all documents read from r and writes them to w.
+func maskStream(format string, r io.Reader, w io.Writer, opts []mask.Option) error {
+	if format == "binary" {
+		return scrubStream(r, w, opts)
+	}
+	c, ok := codecs[format]
+	if !ok {
+		return fmt.Errorf("unsupported format %q", format)
+	}
+	return c(r, w, func(doc interface{}) (interface{}, error) {
+		return mask.Mask(doc, opts...)
+	})
+}
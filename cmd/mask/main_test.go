@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `
+rules:
+  - path: "**.email"
+    strategy: partial=1:0
+  - path: "[*].password"
+    strategy: redact
+  - path: "[*].name"
+    strategy: redact
+    audiences: [analytics]
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	name = filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestRun(t *testing.T) {
+	policy := writeFile(t, "policy.yaml", testPolicy)
+
+	for _, c := range []struct {
+		format, in, expect string
+	}{
+		{
+			format: "json",
+			in:     `{"user":{"email":"ada@example.com","age":36}}` + "\n" + `{"email":"bob@example.com"}`,
+			expect: `{"user":{"age":36,"email":"a**************"}}` + "\n" + `{"email":"b**************"}` + "\n",
+		},
+		{
+			format: "yaml",
+			in:     "user:\n  email: ada@example.com\n  age: 36\n",
+			expect: "user:\n  age: 36\n  email: a**************\n",
+		},
+		{
+			format: "csv",
+			in:     "name,email,password\nAda,ada@example.com,secret\n",
+			expect: "name,email,password\nAda,a**************,MASKED\n",
+		},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run([]string{"-policy", policy, "-format", c.format}, strings.NewReader(c.in), &stdout, &stderr); code != 0 {
+			t.Fatalf("%s: expect exit code %d == 0: %s", c.format, code, stderr.String())
+		}
+		if stdout.String() != c.expect {
+			t.Errorf("%s: expect %q == %q", c.format, stdout.String(), c.expect)
+		}
+	}
+}
+
+func TestRunNumbers(t *testing.T) {
+	policy := writeFile(t, "policy.yaml", "rules: [{path: \"**.account\", strategy: redact}, {path: \"**.card\", strategy: partial=0:4}]")
+	in := `{"id":12345678901234567890,"ratio":1.50,"account":98765432109876543210,"card":4111111111111111,"items":[{"id":-1e400}]}`
+	expect := `{"account":"MASKED","card":"************1111","id":12345678901234567890,"items":[{"id":-1e400}],"ratio":1.50}` + "\n"
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-policy", policy, "-format", "json"}, strings.NewReader(in), &stdout, &stderr); code != 0 {
+		t.Fatalf("expect exit code %d == 0: %s", code, stderr.String())
+	}
+	if stdout.String() != expect {
+		t.Errorf("expect %q == %q", stdout.String(), expect)
+	}
+}
+
+func TestRunOmitCSV(t *testing.T) {
+	policy := writeFile(t, "policy.yaml", "rules: [{path: \"[*].password\", strategy: omit}, {path: \"[*].token\", strategy: \"-\"}]")
+	in := "name,password,token\nAda,secret,tok_123\n"
+	expect := "name,password,token\nAda,,\n"
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-policy", policy, "-format", "csv"}, strings.NewReader(in), &stdout, &stderr); code != 0 {
+		t.Fatalf("expect exit code %d == 0: %s", code, stderr.String())
+	}
+	if stdout.String() != expect {
+		t.Errorf("expect %q == %q", stdout.String(), expect)
+	}
+}
+
+func TestRunAudience(t *testing.T) {
+	policy := writeFile(t, "policy.yaml", testPolicy)
+
+	var stdout, stderr bytes.Buffer
+	in := strings.NewReader("name,email\nAda,ada@example.com\n")
+	if code := run([]string{"-policy", policy, "-format", "csv", "-audience", "analytics"}, in, &stdout, &stderr); code != 0 {
+		t.Fatalf("expect exit code %d == 0: %s", code, stderr.String())
+	}
+	if expect := "name,email\nMASKED,a**************\n"; stdout.String() != expect {
+		t.Errorf("expect %q == %q", stdout.String(), expect)
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	policy := writeFile(t, "policy.json", `{"rules": [{"path": "email", "strategy": "redact"}]}`)
+	file := writeFile(t, "dump.yml", "email: ada@example.com\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-policy", policy, "-w", file}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expect exit code %d == 0: %s", code, stderr.String())
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "email: MASKED\n" || stdout.Len() != 0 {
+		t.Errorf("expect %q to be masked in place", b)
+	}
+
+	invalid := writeFile(t, "invalid.yaml", "rules:\n  - path: email\n    strategy: does-not-exist\n")
+	if code := run([]string{"-policy", invalid, file}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("expect exit code %d == 1 for an invalid policy", code)
+	}
+	if code := run([]string{file}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("expect exit code %d == 2 without a policy", code)
+	}
+}
+
+func TestRunBinary(t *testing.T) {
+	policy := writeFile(t, "policy.yaml", `
+detectors:
+  - name: token
+    pattern: 'tok_[0-9a-f]{6}'
+    strategy: redact
+`)
+	secrets := writeFile(t, "secrets.txt", "hunter22\n\n")
+	file := writeFile(t, "heap.dump", "\x00\x01tok_4f9a8c\x00hunter22\x00\xff")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-policy", policy, "-secrets", secrets, "-format", "binary", "-w", file}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expect exit code %d == 0: %s", code, stderr.String())
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "\x00\x01**********\x00********\x00\xff"; string(b) != expect {
+		t.Errorf("expect %q == %q", b, expect)
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	released := writeFile(t, "released.yaml", "version: \"1\"\n"+testPolicy)
+	proposed := writeFile(t, "proposed.yaml", `
+version: "2"
+rules:
+  - path: "**.email"
+    strategy: partial=1:0
+  - path: "[*].password"
+    strategy: redact
+`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-policy", proposed, "-diff", released}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("expect exit code %d == 1: %s", code, stderr.String())
+	}
+	if expect := "- rule \"[*].name\" for analytics: redact\n"; stdout.String() != expect {
+		t.Errorf("expect %q == %q", stdout.String(), expect)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"-policy", released, "-diff", proposed}, nil, &stdout, &stderr); code != 0 {
+		t.Errorf("expect exit code %d == 0: %s", code, stderr.String())
+	}
+	if expect := "+ rule \"[*].name\" for analytics: redact\n"; stdout.String() != expect {
+		t.Errorf("expect %q == %q", stdout.String(), expect)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	mask "github.com/doejon/go-mask"
+)
+
+// readPolicy reads the policy file name, see mask.LoadPolicy.
+func readPolicy(name string) (*mask.Policy, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := mask.LoadPolicy(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return p, nil
+}
+
+// loadPolicy reads the policy file name and returns the options applying it for audience.
+func loadPolicy(name, audience string) ([]mask.Option, error) {
+	p, err := readPolicy(name)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := p.Options(audience)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return append(opts, mask.WithSortedMaps()), nil
+}
+
+// diffPolicies lists the changes from the policy file from to the policy file to,
+// failing if they reduce coverage, see mask.DiffPolicies.
+func diffPolicies(from, to string, stdout, stderr io.Writer) int {
+	a, err := readPolicy(from)
+	if err != nil {
+		fmt.Fprintf(stderr, "mask: %v\n", err)
+		return 1
+	}
+	b, err := readPolicy(to)
+	if err != nil {
+		fmt.Fprintf(stderr, "mask: %v\n", err)
+		return 1
+	}
+	d := mask.DiffPolicies(a, b)
+	if d.Empty() {
+		return 0
+	}
+	fmt.Fprintln(stdout, d)
+	if d.ReducesCoverage() {
+		fmt.Fprintf(stderr, "mask: %s reduces masking coverage of %s\n", to, from)
+		return 1
+	}
+	return 0
+}
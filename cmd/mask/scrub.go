@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/detect"
+)
+
+// scrubStream overwrites the sensitive data found in the binary blob read from r,
+// see mask.Scrub, and writes it to w.
+func scrubStream(r io.Reader, w io.Writer, opts []mask.Option) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if _, err := mask.Scrub(data, opts...); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// loadSecrets reads the file name listing known secrets, one per line, and returns
+// the option masking them wherever they occur.
+func loadSecrets(name string) (mask.Option, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var secrets []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			secrets = append(secrets, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return mask.WithDetector(detect.Dictionary("secrets", secrets...), nil), nil
+}
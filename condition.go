@@ -0,0 +1,119 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Condition decides whether a field is masked, given the struct holding it.
+// Conditions are referenced by name in struct tags, see RegisterCondition.
+type Condition func(parent interface{}) bool
+
+var conditions registry[string, Condition]
+
+// RegisterCondition makes a condition available to struct tags under the given name.
+// Fields are only masked if the condition referenced by their tag holds:
+//
+//	mask.RegisterCondition("eu", func(parent interface{}) bool {
+//	  return isEU(parent.(Customer).Country)
+//	})
+//
+//	type Customer struct {
+//	  Country string
+//	  TaxID   string `mask:"redact,if=eu"`
+//	}
+//
+// Simple conditions on sibling fields need no registration: `mask:"partial=0:4,if=Country==US"`
+// masks TaxID of US customers only, `if=Country!=US|CA` of customers outside the US and Canada.
+// Sibling fields are compared by their formatted value, nil pointers format to "".
+// It is safe to register conditions while masking.
+func RegisterCondition(name string, c Condition) {
+	conditions.store(name, c)
+}
+
+// tagCondition is a parsed `if=` condition of a tag.
+type tagCondition struct {
+	// name references a registered condition, if set.
+	name string
+	// field is compared to values, the condition holds if it equals any of them, or none if negate is set.
+	field  string
+	values []string
+	negate bool
+}
+
+// tagConditions caches parsed conditions by their source.
+var tagConditions sync.Map
+
+func parseCondition(src string) (tagCondition, error) {
+	if c, ok := tagConditions.Load(src); ok {
+		return c.(tagCondition), nil
+	}
+	var c tagCondition
+	if field, values, ok := strings.Cut(src, "!="); ok {
+		c = tagCondition{field: field, values: strings.Split(values, "|"), negate: true}
+	} else if field, values, ok := strings.Cut(src, "=="); ok {
+		c = tagCondition{field: field, values: strings.Split(values, "|")}
+	} else {
+		c = tagCondition{name: src}
+	}
+	if c.name == "" && c.field == "" {
+		return c, fmt.Errorf("%w: invalid condition %q, expected <field>==<value> or a registered condition", ErrInvalidTag, src)
+	}
+	tagConditions.Store(src, c)
+	return c, nil
+}
+
+// holds reports whether the condition holds for the struct parent.
+func (c tagCondition) holds(parent reflect.Value) (bool, error) {
+	if c.name != "" {
+		cond, ok := conditions.load(c.name)
+		if !ok {
+			return false, fmt.Errorf("%w: unknown condition %q", ErrInvalidTag, c.name)
+		}
+		if !parent.CanInterface() {
+			return false, fmt.Errorf("%w: condition %q on a field of an unexported struct", ErrInvalidTag, c.name)
+		}
+		return cond(parent.Interface()), nil
+	}
+	f := parent.FieldByName(c.field)
+	if !f.IsValid() {
+		return false, fmt.Errorf("%w: unknown field %q in condition", ErrInvalidTag, c.field)
+	}
+	for f.Kind() == reflect.Ptr || f.Kind() == reflect.Interface {
+		if f.IsNil() {
+			break
+		}
+		f = f.Elem()
+	}
+	value := ""
+	if f.Kind() != reflect.Ptr && f.Kind() != reflect.Interface {
+		value = fmt.Sprint(f)
+	}
+	for _, v := range c.values {
+		if v == value {
+			return !c.negate, nil
+		}
+	}
+	return c.negate, nil
+}
+
+// tagStrategy resolves the strategy referenced by the tag of the struct field f
+// of parent. A nil strategy is returned if f has no tag or its condition does not hold.
+func (s *state) tagStrategy(f reflect.StructField, parent reflect.Value) (Strategy, error) {
+	tag, cond, conditional := strings.Cut(s.opts.tag(f), ",if=")
+	strategy, err := s.opts.ruleSets.fromTag(tag)
+	if err != nil || strategy == nil || !conditional {
+		return strategy, err
+	}
+	c, err := parseCondition(cond)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := c.holds(parent)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return strategy, nil
+}
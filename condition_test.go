@@ -0,0 +1,78 @@
+package mask
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type taxRecord struct {
+	Country  string
+	Verified *bool
+	TaxID    string `mask:"partial=0:2,if=Country==US"`
+	Phone    string `mask:"redact,if=Country!=US|CA"`
+	Notes    string `mask:"redact,if=unverified"`
+}
+
+func init() {
+	RegisterCondition("unverified", func(parent interface{}) bool {
+		r := parent.(taxRecord)
+		return r.Verified == nil || !*r.Verified
+	})
+}
+
+func TestConditionalTags(t *testing.T) {
+	verified := true
+	records := []taxRecord{
+		{Country: "US", TaxID: "123-45-6789", Phone: "555-0100", Notes: "n1", Verified: &verified},
+		{Country: "DE", TaxID: "DE123456789", Phone: "030-1234", Notes: "n2"},
+	}
+	masked, err := Mask(records)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []taxRecord{
+		{Country: "US", TaxID: "*********89", Phone: "555-0100", Notes: "n1", Verified: &verified},
+		{Country: "DE", TaxID: "DE123456789", Phone: "MASKED", Notes: "MASKED"},
+	}
+	if !reflect.DeepEqual(masked, expected) {
+		t.Errorf("expect %v == %v", masked, expected)
+	}
+
+	b, err := json.Marshal(JSON(records[1]))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expectedJSON := `{"Country":"DE","Verified":null,"TaxID":"DE123456789","Phone":"MASKED","Notes":"MASKED"}`
+	if string(b) != expectedJSON {
+		t.Errorf("expect %s == %s", b, expectedJSON)
+	}
+
+	findings, err := Scan(records[0])
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(findings) != 1 || findings[0].Path != "taxRecord.TaxID" {
+		t.Errorf("expect a single finding for TaxID, got %v", findings)
+	}
+}
+
+func TestConditionalTagsInvalid(t *testing.T) {
+	for _, val := range []interface{}{
+		struct {
+			A string `mask:"redact,if=Missing==x"`
+		}{},
+		struct {
+			A string `mask:"redact,if=unregistered"`
+		}{},
+		struct {
+			A string `mask:"redact,if="`
+		}{},
+	} {
+		_, err := Mask(val)
+		if !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("expect %v == %v", err, ErrInvalidTag)
+		}
+	}
+}
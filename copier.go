@@ -0,0 +1,129 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// CopierFunc copies values of the type it is registered for as a whole, e.g. by
+// calling their Clone method, instead of field by field. deep copies and masks
+// values held by x, like elements of a collection; it must not be called with x itself.
+type CopierFunc func(x interface{}, deep func(interface{}) (interface{}, error)) (interface{}, error)
+
+// Copier holds copiers for types and options shared by calls to Mask, JSON,
+// Scan, Walk, Transform and Clone using it, see WithCopier. Copiers are independent
+// of each other, so parts of a program can register different copiers for the same
+// type. The package level functions use a default Copier.
+//
+// A Copier is safe for concurrent use, including Register.
+type Copier struct {
+	types registry[reflect.Type, copier]
+	kinds registry[reflect.Kind, copier]
+	opts  []Option
+	// jsonCopied caches whether values of types without registered copier
+	// are encoded from their masked copy.
+	jsonCopied sync.Map
+}
+
+// defaultCopier is used unless WithCopier is given.
+var defaultCopier = &Copier{}
+
+// NewCopier creates a Copier applying opts to all calls using it.
+// Options given to a call are applied after them.
+func NewCopier(opts ...Option) *Copier {
+	return &Copier{opts: opts}
+}
+
+// Register copies values of the type of typ using fn, overriding built-in copiers.
+// typ is a value of the type or its reflect.Type:
+//
+//	c.Register(pgtype.Text{}, func(x interface{}, deep func(interface{}) (interface{}, error)) (interface{}, error) {
+//	  return x, nil // immutable
+//	})
+//
+// typ may also be a reflect.Kind, copying all values of the kind without a copier
+// registered for their type using fn instead of the built-in copier of the kind.
+//
+// Values copied by fn are still masked by their MaskXXX method, path or type strategies.
+func (c *Copier) Register(typ interface{}, fn CopierFunc) {
+	if k, ok := typ.(reflect.Kind); ok {
+		c.kinds.store(k, copierOf(fn, nil))
+		return
+	}
+	t, ok := typ.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(typ)
+	}
+	c.types.store(t, copierOf(fn, t))
+}
+
+// RegisterCopier registers fn on the default Copier used by the package level functions,
+// see Copier.Register. This overrides how specific types or kinds are copied, e.g. by
+// calling their Clone method, for the whole program:
+//
+//	mask.RegisterCopier(reflect.TypeOf((*Graph)(nil)), func(x interface{}, _ func(interface{}) (interface{}, error)) (interface{}, error) {
+//	  return x.(*Graph).Clone(), nil
+//	})
+//
+// It is safe to register copiers while masking.
+func RegisterCopier(typ interface{}, fn CopierFunc) {
+	defaultCopier.Register(typ, fn)
+}
+
+// copierOf adapts fn to copy values of type t, or of the type of the value copied if t is nil.
+func copierOf(fn CopierFunc, t reflect.Type) copier {
+	return func(x interface{}, s *state) (interface{}, error) {
+		out, err := fn(x, func(v interface{}) (interface{}, error) {
+			return _anything(v, s)
+		})
+		if err != nil {
+			return nil, err
+		}
+		t := t
+		if t == nil {
+			t = reflect.TypeOf(x)
+		}
+		if out == nil {
+			return reflect.Zero(t).Interface(), nil
+		}
+		if reflect.TypeOf(out) != t {
+			return nil, fmt.Errorf("%w: copier for %v returned %T", ErrKindMismatch, t, out)
+		}
+		return out, nil
+	}
+}
+
+// WithCopier uses c and its options instead of the default Copier.
+func WithCopier(c *Copier) Option {
+	return func(o *options) {
+		for _, opt := range c.opts {
+			opt(o)
+		}
+		o.copier = c
+	}
+}
+
+// copier returns the Copier used by the current call.
+func (s *state) copier() *Copier {
+	if s.opts.copier != nil {
+		return s.opts.copier
+	}
+	return defaultCopier
+}
+
+// typeCopier returns the copier registered for t.
+// Instances of the generic atomic.Pointer are handled as well.
+func (c *Copier) typeCopier(t reflect.Type) (copier, bool) {
+	if c, ok := c.types.load(t); ok {
+		return c, true
+	}
+	if c, ok := typeCopiers[t]; ok {
+		return c, true
+	}
+	if t.Kind() == reflect.Struct && t.PkgPath() == "sync/atomic" && strings.HasPrefix(t.Name(), "Pointer[") {
+		return _atomic, true
+	}
+	return nil, false
+}
@@ -0,0 +1,126 @@
+package mask
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type cloneable struct {
+	ID    string
+	Items []TestString
+	cache map[string]string
+}
+
+func (c cloneable) Clone() cloneable {
+	cache := make(map[string]string, len(c.cache))
+	for k, v := range c.cache {
+		cache[k] = v
+	}
+	return cloneable{ID: c.ID, Items: c.Items, cache: cache}
+}
+
+func TestCopier(t *testing.T) {
+	cloning := NewCopier(WithSortedMaps())
+	cloning.Register(cloneable{}, func(x interface{}, deep func(interface{}) (interface{}, error)) (interface{}, error) {
+		c := x.(cloneable).Clone()
+		items, err := deep(c.Items)
+		if err != nil {
+			return nil, err
+		}
+		c.Items = items.([]TestString)
+		return c, nil
+	})
+	val := cloneable{ID: "1", Items: []TestString{"secret"}, cache: map[string]string{"a": "b"}}
+
+	masked, err := Mask(val, WithCopier(cloning))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := cloneable{ID: "1", Items: []TestString{"MASKED"}, cache: map[string]string{"a": "b"}}
+	if !reflect.DeepEqual(masked, expected) {
+		t.Errorf("expect %v == %v", masked, expected)
+	}
+
+	masked, err = Mask(val)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if masked.cache != nil {
+		t.Errorf("expect the default copier to be unaffected, got %v", masked)
+	}
+
+	b, err := json.Marshal(JSON(val, WithCopier(cloning)))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(b) != `{"ID":"1","Items":["MASKED"]}` {
+		t.Errorf("expect %s == %s", b, `{"ID":"1","Items":["MASKED"]}`)
+	}
+}
+
+func TestCopierWrongType(t *testing.T) {
+	c := NewCopier()
+	c.Register(reflect.TypeOf(cloneable{}), func(x interface{}, deep func(interface{}) (interface{}, error)) (interface{}, error) {
+		return "nope", nil
+	})
+	_, err := Mask(cloneable{}, WithCopier(c))
+	if !errors.Is(err, ErrKindMismatch) {
+		t.Errorf("expect %v == %v", err, ErrKindMismatch)
+	}
+}
+
+type testGraph struct {
+	Nodes []string
+	index map[string]int
+}
+
+func (g *testGraph) Clone() *testGraph {
+	c := &testGraph{Nodes: append([]string(nil), g.Nodes...), index: make(map[string]int, len(g.index))}
+	for k, v := range g.index {
+		c.index[k] = v
+	}
+	return c
+}
+
+func TestRegisterCopier(t *testing.T) {
+	defer func(c *Copier) { defaultCopier = c }(defaultCopier)
+	defaultCopier = &Copier{}
+
+	RegisterCopier(reflect.TypeOf((*testGraph)(nil)), func(x interface{}, _ func(interface{}) (interface{}, error)) (interface{}, error) {
+		return x.(*testGraph).Clone(), nil
+	})
+	val := &testGraph{Nodes: []string{"a"}, index: map[string]int{"a": 0}}
+	out := Must(val)
+	if out == val || !reflect.DeepEqual(out, val) {
+		t.Errorf("expect %v to be a clone of %v", out, val)
+	}
+}
+
+func TestCopierKind(t *testing.T) {
+	c := NewCopier()
+	c.Register(reflect.String, func(x interface{}, _ func(interface{}) (interface{}, error)) (interface{}, error) {
+		v := reflect.ValueOf(x)
+		return reflect.ValueOf("copied " + v.String()).Convert(v.Type()).Interface(), nil
+	})
+	type flat struct {
+		Name  string
+		Token TestString
+	}
+	out, err := Mask(flat{"ada", "t"}, WithCopier(c))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// MaskXXX still applies to copies
+	if expect := (flat{"copied ada", "MASKED"}); out != expect {
+		t.Errorf("expect %v == %v", out, expect)
+	}
+
+	c.Register(reflect.Int, func(x interface{}, _ func(interface{}) (interface{}, error)) (interface{}, error) {
+		return "nope", nil
+	})
+	if _, err := Mask(1, WithCopier(c)); !errors.Is(err, ErrKindMismatch) {
+		t.Errorf("expect %v == %v", err, ErrKindMismatch)
+	}
+}
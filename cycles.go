@@ -0,0 +1,38 @@
+package mask
+
+import "reflect"
+
+// refKey identifies the data referenced by a map or slice.
+type refKey struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+func newRefKey(v reflect.Value) refKey {
+	return refKey{ptr: v.Pointer(), len: v.Len(), typ: v.Type()}
+}
+
+// enter marks the map or slice identified by k as being copied to dc.
+// If k is already being copied, the value references itself and the
+// copy in progress is returned instead, which keeps the cycle intact.
+func (s *state) enter(k refKey, dc reflect.Value) (interface{}, bool) {
+	if cp, ok := s.visiting[k]; ok {
+		return cp, true
+	}
+	if s.visiting == nil {
+		s.visiting = make(map[refKey]interface{})
+	}
+	s.visiting[k] = dc.Interface()
+	return nil, false
+}
+
+// leave marks the map or slice identified by k as copied.
+// Using WithPreserveAliasing, the copy is remembered and
+// shared with all other references to the same data.
+func (s *state) leave(k refKey) {
+	if s.opts.preserveAliasing {
+		return
+	}
+	delete(s.visiting, k)
+}
@@ -0,0 +1,90 @@
+package mask
+
+import (
+	"testing"
+)
+
+func TestSelfReferencingMap(t *testing.T) {
+	m := map[string]interface{}{"name": TestString("secret")}
+	m["self"] = m
+
+	masked := Must(m)
+	if masked["name"] != TestString("MASKED") {
+		t.Errorf("expect %v == MASKED", masked["name"])
+	}
+	self, ok := masked["self"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expect %v to be a map", masked["self"])
+	}
+	self["probe"] = true
+	if _, ok := masked["probe"]; !ok {
+		t.Errorf("expect the copy to reference itself")
+	}
+	if _, ok := m["probe"]; ok {
+		t.Errorf("expect the original to stay untouched")
+	}
+}
+
+func TestSelfReferencingSlice(t *testing.T) {
+	s := []interface{}{TestString("secret"), nil}
+	s[1] = s
+
+	masked := Must(s)
+	if masked[0] != TestString("MASKED") {
+		t.Errorf("expect %v == MASKED", masked[0])
+	}
+	self, ok := masked[1].([]interface{})
+	if !ok {
+		t.Fatalf("expect %v to be a slice", masked[1])
+	}
+	if &self[0] != &masked[0] {
+		t.Errorf("expect the copy to reference itself")
+	}
+	if &self[0] == &s[0] {
+		t.Errorf("expect the copy not to reference the original")
+	}
+}
+
+func TestSiblingMapsAreCopiedIndependently(t *testing.T) {
+	shared := map[string]int{"a": 1}
+	val := struct {
+		A map[string]int
+		B map[string]int
+	}{shared, shared}
+
+	masked := Must(val)
+	masked.A["b"] = 2
+	if _, ok := masked.B["b"]; ok {
+		t.Errorf("expect sibling maps to be independent copies")
+	}
+}
+
+func TestPreserveAliasing(t *testing.T) {
+	sharedMap := map[string]TestString{"a": "secret"}
+	sharedSlice := []TestString{"secret"}
+	val := struct {
+		A  map[string]TestString
+		B  map[string]TestString
+		S1 []TestString
+		S2 []TestString
+		S3 []TestString
+	}{sharedMap, sharedMap, sharedSlice, sharedSlice, sharedSlice[:0]}
+
+	masked := Must(val, WithPreserveAliasing())
+	if masked.A["a"] != "MASKED" {
+		t.Errorf("expect %v == MASKED", masked.A["a"])
+	}
+	masked.A["b"] = "added"
+	if _, ok := masked.B["b"]; !ok {
+		t.Errorf("expect aliased maps to share their copy")
+	}
+	if _, ok := sharedMap["b"]; ok {
+		t.Errorf("expect the original to stay untouched")
+	}
+	if &masked.S1[0] != &masked.S2[0] {
+		t.Errorf("expect aliased slices to share their copy")
+	}
+	if &masked.S1[0] == &sharedSlice[0] {
+		t.Errorf("expect the copy not to reference the original")
+	}
+}
@@ -0,0 +1,46 @@
+package mask
+
+import (
+	"context"
+	"reflect"
+)
+
+// MaskCtx masks x like Mask, aborting once ctx is done, so masking huge or adversarial
+// payloads never stalls a request beyond its deadline. The error returned is a *FieldError
+// wrapping ctx.Err() and locating the value visited when ctx was done.
+//
+// Using WithTruncateLimits, the partial result is returned instead: values not visited
+// before ctx was done are replaced by their zero value.
+func MaskCtx[T any](ctx context.Context, x T, opts ...Option) (T, error) {
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		o.ctx = ctx
+	})
+	return Mask(x, opts...)
+}
+
+// ctxCheckInterval is the number of values visited between checks of the context of MaskCtx.
+const ctxCheckInterval = 256
+
+// ctxDone reports whether the context of MaskCtx is done. Once it is, it stays done
+// for the rest of the call.
+func (s *state) ctxDone() bool {
+	if s.opts.ctx == nil {
+		return false
+	}
+	if s.ctxErr == nil {
+		s.ctxChecks++
+		if s.ctxChecks%ctxCheckInterval != 1 {
+			return false
+		}
+		s.ctxErr = s.opts.ctx.Err()
+	}
+	return s.ctxErr != nil
+}
+
+// ctxExceeded handles a value of type t visited after the context of MaskCtx is done.
+func (s *state) ctxExceeded(t reflect.Type) (interface{}, error) {
+	if s.opts.truncateLimits {
+		return reflect.Zero(t).Interface(), nil
+	}
+	return s.fail(t, s.ctxErr)
+}
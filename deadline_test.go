@@ -0,0 +1,69 @@
+package mask
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testCancel is called by testCanceling when being masked.
+var testCancel context.CancelFunc
+
+type testCanceling struct {
+	Value  string
+	Cancel bool
+}
+
+func (c testCanceling) MaskXXX() testCanceling {
+	if c.Cancel {
+		testCancel()
+	}
+	return testCanceling{Value: "MASKED"}
+}
+
+func TestMaskCtx(t *testing.T) {
+	val := make([]testCanceling, 1000)
+	val[10].Cancel = true
+	// maskCtx masks val canceling its context while masking val[10]
+	maskCtx := func(opts ...Option) ([]testCanceling, error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		testCancel = cancel
+		return MaskCtx(ctx, val, opts...)
+	}
+
+	_, err := maskCtx()
+	var fieldErr *FieldError
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &fieldErr) || fieldErr.Path != "[255]" {
+		t.Errorf("expect %v to abort at [255]", err)
+	}
+
+	_, err = maskCtx(WithCollectErrors())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v to abort", err)
+	}
+
+	out, err := maskCtx(WithTruncateLimits())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(out) != 1000 || out[254].Value != "MASKED" || out[255].Value != "" || out[999].Value != "" {
+		t.Errorf("expect %v to be masked up to [254]", out[250:260])
+	}
+}
+
+func TestMaskCtxDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	out, err := MaskCtx(ctx, []TestString{"a", "b"})
+	if err != nil || out[0] != "MASKED" || out[1] != "MASKED" {
+		t.Errorf("expect %v to be masked, got %v", out, err)
+	}
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	if _, err := MaskCtx(ctx, []TestString{"a"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect %v == %v", err, context.DeadlineExceeded)
+	}
+}
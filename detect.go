@@ -0,0 +1,134 @@
+package mask
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/doejon/go-mask/detect"
+)
+
+// Detector finds sensitive data in strings by their content; see package detect for implementations.
+type Detector = detect.Detector
+
+type detectorRule struct {
+	detector Detector
+	strategy Strategy
+	// whole masks the whole string rather than only the matches, see PolicyDetector.
+	whole bool
+}
+
+// WithDetector masks the sensitive data found by d in strings anywhere in the value,
+// even in untagged fields and untyped data, using strategy. The rest of the string is kept:
+//
+//	mask.WithDetector(detect.CardNumber(), maskers.Partial(0, 4, maskers.Format{}))
+//
+// masks "paid with 4111 1111 1111 1111" as "paid with ***************1111".
+// A nil strategy replaces matches by the placeholder for strings.
+// Struct tags and path strategies take precedence over detectors.
+func WithDetector(d Detector, strategy Strategy) Option {
+	if strategy == nil {
+		strategy, _ = redactStrategy("")
+	}
+	return func(o *options) {
+		o.detectors = append(o.detectors, detectorRule{detector: d, strategy: strategy})
+	}
+}
+
+// Detect walks x like Scan and reports all strings in which any of detectors
+// finds sensitive data, which is not masked by tags, paths or MaskXXX methods already.
+// Use it to discover personal data in payloads, logs or test fixtures.
+func Detect(x interface{}, detectors ...Detector) ([]Finding, error) {
+	opts := make([]Option, len(detectors))
+	for i, d := range detectors {
+		opts[i] = WithDetector(d, nil)
+	}
+	findings, err := Scan(x, opts...)
+	if err != nil {
+		return nil, err
+	}
+	detected := findings[:0]
+	for _, f := range findings {
+		if f.Source == SourceDetector {
+			detected = append(detected, f)
+		}
+	}
+	return detected, nil
+}
+
+// detect returns the strategy masking v because of its content and the names
+// of the detectors which found sensitive data in it, if any.
+// The matches of all detectors are masked; of overlapping matches, the first one wins.
+func (s *state) detect(v reflect.Value) (Strategy, []string) {
+	if s.inKey || v.Kind() != reflect.String || len(s.opts.detectors) == 0 {
+		return nil, nil
+	}
+	str := v.String()
+	var found []ruleMatch
+	var names []string
+	for _, rule := range s.opts.detectors {
+		matches := rule.detector.Find(str)
+		if len(matches) == 0 {
+			continue
+		}
+		if rule.whole {
+			return rule.strategy, []string{rule.detector.Name()}
+		}
+		names = append(names, rule.detector.Name())
+		for _, m := range matches {
+			found = append(found, ruleMatch{rule: rule, Match: m})
+		}
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	matches := found[:1]
+	for _, m := range found[1:] {
+		if m.Start >= matches[len(matches)-1].End {
+			matches = append(matches, m)
+		}
+	}
+	return matchStrategy{matches: matches, s: s}, names
+}
+
+type ruleMatch struct {
+	rule detectorRule
+	detect.Match
+}
+
+// matchStrategy masks the matches of detectors within a string.
+type matchStrategy struct {
+	matches []ruleMatch
+	s       *state
+}
+
+// Name identifies the detectors along with their strategies, so sessions
+// do not confuse masking matches with masking whole values.
+func (m matchStrategy) Name() string {
+	var names []string
+	for _, match := range m.matches {
+		name := match.rule.detector.Name() + ":" + match.rule.strategy.Name()
+		if len(names) == 0 || names[len(names)-1] != name {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "+")
+}
+
+func (m matchStrategy) Mask(v reflect.Value) (reflect.Value, error) {
+	str := v.String()
+	var b strings.Builder
+	last := 0
+	for _, match := range m.matches {
+		b.WriteString(str[last:match.Start])
+		masked, err := m.s.applyStrategy(match.rule.strategy, reflect.ValueOf(str[match.Start:match.End]))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		b.WriteString(masked.String())
+		last = match.End
+	}
+	b.WriteString(str[last:])
+	return reflect.ValueOf(b.String()).Convert(v.Type()), nil
+}
@@ -0,0 +1,173 @@
+// Package detect contains detectors finding sensitive data in strings by their
+// content, e.g. card numbers in free text or API keys in untyped metadata.
+//
+// Detectors are used by the mask package to report or mask values regardless
+// of their type or location, see mask.WithDetector and mask.Detect.
+package detect
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Match locates sensitive data s[Start:End] within a string s.
+type Match struct {
+	Start, End int
+}
+
+// Detector finds sensitive data in strings.
+type Detector interface {
+	// Name identifies the detector, e.g. in findings.
+	Name() string
+	// Find returns the non-overlapping matches of sensitive data in s, in order.
+	Find(s string) []Match
+}
+
+type detectorFunc struct {
+	name string
+	find func(s string) []Match
+}
+
+func (d *detectorFunc) Name() string {
+	return d.name
+}
+
+func (d *detectorFunc) Find(s string) []Match {
+	return d.find(s)
+}
+
+// Func creates a named detector from find.
+func Func(name string, find func(s string) []Match) Detector {
+	return &detectorFunc{name: name, find: find}
+}
+
+// Regexp returns a detector reporting all matches of re.
+func Regexp(name string, re *regexp.Regexp) Detector {
+	return Func(name, func(s string) []Match {
+		return matches(re, s, nil)
+	})
+}
+
+// matches returns the matches of re in s accepted by valid, if given.
+func matches(re *regexp.Regexp, s string, valid func(string) bool) []Match {
+	var out []Match
+	for _, loc := range re.FindAllStringIndex(s, -1) {
+		if valid == nil || valid(s[loc[0]:loc[1]]) {
+			out = append(out, Match{Start: loc[0], End: loc[1]})
+		}
+	}
+	return out
+}
+
+var emailRe = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// Email returns a detector for email addresses.
+func Email() Detector {
+	return Regexp("email", emailRe)
+}
+
+var cardRe = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+
+// CardNumber returns a detector for payment card numbers of 13 to 19 digits,
+// optionally grouped by spaces or dashes, which pass the Luhn checksum.
+func CardNumber() Detector {
+	return Func("card-number", func(s string) []Match {
+		return matches(cardRe, s, func(m string) bool {
+			return luhn(digits(m))
+		})
+	})
+}
+
+var ibanRe = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`)
+
+// IBAN returns a detector for international bank account numbers,
+// optionally grouped by spaces, which pass the mod 97 checksum.
+func IBAN() Detector {
+	return Func("iban", func(s string) []Match {
+		return matches(ibanRe, s, func(m string) bool {
+			return ibanValid(strings.ReplaceAll(m, " ", ""))
+		})
+	})
+}
+
+// Dictionary returns a detector reporting the given words, e.g. names of
+// customers or internal project code names. Words are matched case-insensitively
+// and only as a whole, i.e. not as part of other words.
+func Dictionary(name string, words ...string) Detector {
+	quoted := make([]string, 0, len(words))
+	for _, w := range words {
+		if w != "" {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+	}
+	if len(quoted) == 0 {
+		return Func(name, func(string) []Match { return nil })
+	}
+	// Prefer longer words, e.g. "Ada Lovelace" over "Ada".
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	re := regexp.MustCompile(`(?i)(?:` + strings.Join(quoted, "|") + `)`)
+	return Func(name, func(s string) []Match {
+		var out []Match
+		for _, loc := range re.FindAllStringIndex(s, -1) {
+			if wordBoundary(s, loc[0]-1) && wordBoundary(s, loc[1]) {
+				out = append(out, Match{Start: loc[0], End: loc[1]})
+			}
+		}
+		return out
+	})
+}
+
+// wordBoundary reports whether the byte at i of s does not belong to a word.
+func wordBoundary(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return true
+	}
+	r := rune(s[i])
+	return r < 0x80 && !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// luhn reports whether the digits d pass the Luhn checksum.
+func luhn(d string) bool {
+	sum := 0
+	double := false
+	for i := len(d) - 1; i >= 0; i-- {
+		n := int(d[i] - '0')
+		if double {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ibanValid reports whether iban passes the mod 97 checksum of ISO 13616.
+func ibanValid(iban string) bool {
+	rearranged := iban[4:] + iban[:4]
+	rem := 0
+	for _, r := range rearranged {
+		switch {
+		case r >= '0' && r <= '9':
+			rem = (rem*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			rem = (rem*100 + int(r-'A'+10)) % 97
+		default:
+			return false
+		}
+	}
+	return rem == 1
+}
@@ -0,0 +1,36 @@
+package detect
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestDetectors(t *testing.T) {
+	tests := []struct {
+		detector Detector
+		in       string
+		expected []string
+	}{
+		{Email(), "mail ada@example.com or bob@example.org.", []string{"ada@example.com", "bob@example.org"}},
+		{Email(), "no address @ here", nil},
+		{CardNumber(), "paid with 4111 1111 1111 1111 on 2024-03-01", []string{"4111 1111 1111 1111"}},
+		{CardNumber(), "card 4111-1111-1111-1112 fails luhn", nil},
+		{CardNumber(), "order 1234567890123", nil},
+		{IBAN(), "to DE89 3704 0044 0532 0130 00 please", []string{"DE89 3704 0044 0532 0130 00"}},
+		{IBAN(), "GB82WEST12345698765432", []string{"GB82WEST12345698765432"}},
+		{IBAN(), "DE00370400440532013000", nil},
+		{Dictionary("names", "Ada", "Ada Lovelace"), "ada lovelace met Adam and ADA", []string{"ada lovelace", "ADA"}},
+		{Dictionary("empty"), "anything", nil},
+		{Regexp("key", regexp.MustCompile(`sk_[a-z0-9]+`)), "key=sk_abc1", []string{"sk_abc1"}},
+	}
+	for _, test := range tests {
+		var found []string
+		for _, m := range test.detector.Find(test.in) {
+			found = append(found, test.in[m.Start:m.End])
+		}
+		if !reflect.DeepEqual(found, test.expected) {
+			t.Errorf("%s: expect %q == %q", test.detector.Name(), found, test.expected)
+		}
+	}
+}
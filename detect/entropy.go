@@ -0,0 +1,156 @@
+package detect
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Default thresholds of Entropy, in bits per character.
+const (
+	DefaultEntropyThreshold    = 4.5
+	DefaultHexEntropyThreshold = 3.0
+	DefaultEntropyMinLength    = 20
+)
+
+type entropy struct {
+	threshold    float64
+	hexThreshold float64
+	minLength    int
+	allow        map[string]bool
+	allowRes     []*regexp.Regexp
+}
+
+// EntropyOption configures the Entropy detector.
+type EntropyOption func(*entropy)
+
+// WithThreshold sets the Shannon entropy in bits per character above which tokens
+// are reported. Hexadecimal tokens use hex, as they cannot exceed 4 bits per character.
+func WithThreshold(threshold, hex float64) EntropyOption {
+	return func(e *entropy) {
+		e.threshold = threshold
+		e.hexThreshold = hex
+	}
+}
+
+// WithMinLength ignores tokens shorter than n characters; short tokens rarely are
+// secrets and their entropy says little.
+func WithMinLength(n int) EntropyOption {
+	return func(e *entropy) {
+		e.minLength = n
+	}
+}
+
+// WithAllow never reports the given tokens, e.g. well-known public keys or build hashes.
+func WithAllow(tokens ...string) EntropyOption {
+	return func(e *entropy) {
+		for _, t := range tokens {
+			e.allow[t] = true
+		}
+	}
+}
+
+// WithAllowPattern never reports tokens matching re completely, e.g. commit hashes
+// or object ids: WithAllowPattern(regexp.MustCompile(`[0-9a-f]{40}`)).
+func WithAllowPattern(re *regexp.Regexp) EntropyOption {
+	return func(e *entropy) {
+		e.allowRes = append(e.allowRes, regexp.MustCompile(`^(?:`+re.String()+`)$`))
+	}
+}
+
+// tokenRe matches candidate tokens, i.e. runs of characters used by base64,
+// base64url, hex and most API key formats, padding included.
+var tokenRe = regexp.MustCompile(`[A-Za-z0-9+/_\-.~]+=*`)
+
+var privateKeyRe = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)
+
+// Entropy returns a detector for random looking tokens like API keys, access tokens
+// and private keys, which cannot be told apart by their format.
+// PEM encoded private keys are reported as a whole.
+//
+// Tokens are reported when their Shannon entropy exceeds a threshold, see WithThreshold,
+// so some random but harmless values like hashes may be reported, see WithAllow.
+func Entropy(opts ...EntropyOption) Detector {
+	e := &entropy{
+		threshold:    DefaultEntropyThreshold,
+		hexThreshold: DefaultHexEntropyThreshold,
+		minLength:    DefaultEntropyMinLength,
+		allow:        make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return Func("entropy", e.find)
+}
+
+func (e *entropy) find(s string) []Match {
+	keys := matches(privateKeyRe, s, nil)
+	var out []Match
+	for _, loc := range tokenRe.FindAllStringIndex(s, -1) {
+		m := Match{Start: loc[0], End: loc[1]}
+		// trailing dots end sentences rather than tokens
+		for m.End > m.Start && s[m.End-1] == '.' {
+			m.End--
+		}
+		if !overlaps(keys, m) && e.secret(s[m.Start:m.End]) {
+			out = append(out, m)
+		}
+	}
+	out = append(out, keys...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	return out
+}
+
+// secret reports whether token is random enough to be a secret.
+func (e *entropy) secret(token string) bool {
+	if len(token) < e.minLength || e.allowed(token) {
+		return false
+	}
+	threshold := e.threshold
+	if strings.Trim(token, "0123456789abcdefABCDEF") == "" {
+		threshold = e.hexThreshold
+	}
+	return Shannon(token) > threshold
+}
+
+func (e *entropy) allowed(token string) bool {
+	if e.allow[token] {
+		return true
+	}
+	for _, re := range e.allowRes {
+		if re.MatchString(token) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(ms []Match, m Match) bool {
+	for _, o := range ms {
+		if m.Start < o.End && o.Start < m.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Shannon returns the Shannon entropy of s in bits per byte.
+func Shannon(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	var h float64
+	n := float64(len(s))
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / n
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
@@ -0,0 +1,84 @@
+package mask
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/doejon/go-mask/detect"
+	"github.com/doejon/go-mask/maskers"
+)
+
+func TestWithDetector(t *testing.T) {
+	type payment struct {
+		Note  string
+		Email string `mask:"partial=1:0"`
+		Meta  map[string]interface{}
+	}
+	val := payment{
+		Note:  "paid with 4111 1111 1111 1111, receipt to ada@example.com",
+		Email: "ada@example.com",
+		Meta:  map[string]interface{}{"ada@example.com": []string{"card 4111111111111111"}},
+	}
+	masked, err := Mask(val,
+		WithDetector(detect.CardNumber(), maskers.Partial(0, 4, maskers.Format{})),
+		WithDetector(detect.Email(), nil),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := payment{
+		Note:  "paid with ***************1111, receipt to MASKED",
+		Email: "a**************",
+		Meta:  map[string]interface{}{"ada@example.com": []string{"card ************1111"}},
+	}
+	if !reflect.DeepEqual(masked, expected) {
+		t.Errorf("expect %v == %v", masked, expected)
+	}
+	if val.Note != "paid with 4111 1111 1111 1111, receipt to ada@example.com" {
+		t.Errorf("expect original to be kept, got %v", val.Note)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	type user struct {
+		Name  string `mask:"name"`
+		Bio   string
+		Notes []string
+	}
+	val := user{
+		Name:  "Ada Lovelace",
+		Bio:   "contact Ada Lovelace at ada@example.com",
+		Notes: []string{"IBAN GB82 WEST 1234 5698 7654 32", "nothing to see"},
+	}
+	findings, err := Detect(val, detect.Email(), detect.IBAN(), detect.Dictionary("customers", "Ada Lovelace"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var found []string
+	for _, f := range findings {
+		found = append(found, f.Path+":"+strings.Join(f.Detectors, ","))
+	}
+	expected := []string{"user.Bio:email,customers", "user.Notes[0]:iban"}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expect %v == %v", found, expected)
+	}
+}
+
+func TestWithDetectorEntropy(t *testing.T) {
+	metadata := map[string]string{
+		"region":  "eu-west-1",
+		"webhook": "https://hooks.example.com/T0001?token=xoxb4eC39HqLyjWDarjtT1zdp7dc",
+	}
+	masked, err := Mask(metadata, WithDetector(detect.Entropy(), nil))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := map[string]string{
+		"region":  "eu-west-1",
+		"webhook": "https://hooks.example.com/T0001?token=MASKED",
+	}
+	if !reflect.DeepEqual(masked, expected) {
+		t.Errorf("expect %v == %v", masked, expected)
+	}
+}
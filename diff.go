@@ -0,0 +1,148 @@
+package mask
+
+import (
+	"math"
+	"reflect"
+	"sort"
+)
+
+// ChangeKind describes how a value differs between original and masked value.
+type ChangeKind string
+
+const (
+	// Modified values exist in both, but differ.
+	Modified ChangeKind = "modified"
+	// Added values only exist in the masked value, e.g. additional slice elements or map keys.
+	Added ChangeKind = "added"
+	// Removed values only exist in the original value.
+	Removed ChangeKind = "removed"
+)
+
+// FieldChange describes a single value differing between original and masked value.
+// It never contains any of the values themselves.
+type FieldChange struct {
+	// Path locates the value, e.g. Order.Items[3].Card.Number.
+	Path string
+	// Type is the type of the value.
+	Type reflect.Type
+	Kind ChangeKind
+}
+
+// Diff lists the paths of all values differing between original and masked,
+// e.g. to assert masking coverage in tests. Values are compared the way Mask copies
+// them: unexported struct fields are ignored, types copied as a whole (like time.Time)
+// are compared as a whole. Map keys are visited in sorted order.
+func Diff[T any](original, masked T) []FieldChange {
+	d := &differ{
+		s:       &state{root: rootName(original)},
+		visited: make(map[[2]uintptr]bool),
+	}
+	d.diff(reflect.ValueOf(&original).Elem(), reflect.ValueOf(&masked).Elem())
+	return d.changes
+}
+
+type differ struct {
+	s       *state
+	visited map[[2]uintptr]bool
+	changes []FieldChange
+}
+
+func (d *differ) change(t reflect.Type, kind ChangeKind) {
+	d.changes = append(d.changes, FieldChange{Path: d.s.currentPath(), Type: t, Kind: kind})
+}
+
+func (d *differ) diff(a, b reflect.Value) {
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return
+	case !a.IsValid():
+		d.change(b.Type(), Added)
+		return
+	case !b.IsValid():
+		d.change(a.Type(), Removed)
+		return
+	case a.Type() != b.Type():
+		d.change(a.Type(), Modified)
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.change(a.Type(), Modified)
+			}
+			return
+		}
+		if a.Kind() == reflect.Ptr {
+			k := [2]uintptr{a.Pointer(), b.Pointer()}
+			if d.visited[k] {
+				return
+			}
+			d.visited[k] = true
+		}
+		d.diff(a.Elem(), b.Elem())
+	case reflect.Struct:
+		if _, ok := d.s.copier().typeCopier(a.Type()); ok {
+			if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+				d.change(a.Type(), Modified)
+			}
+			return
+		}
+		for i := 0; i < a.NumField(); i++ {
+			f := a.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			d.s.pushField(f.Name)
+			d.diff(a.Field(i), b.Field(i))
+			d.s.pop()
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() {
+			d.change(a.Type(), Modified)
+			return
+		}
+		for i := 0; i < max(a.Len(), b.Len()); i++ {
+			d.s.pushIndex(i)
+			switch {
+			case i >= b.Len():
+				d.change(a.Type().Elem(), Removed)
+			case i >= a.Len():
+				d.change(a.Type().Elem(), Added)
+			default:
+				d.diff(a.Index(i), b.Index(i))
+			}
+			d.s.pop()
+		}
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() {
+			d.change(a.Type(), Modified)
+			return
+		}
+		keys := append(a.MapKeys(), b.MapKeys()...)
+		sort.SliceStable(keys, func(i, j int) bool {
+			return compareKeys(keys[i], keys[j]) < 0
+		})
+		for i, k := range keys {
+			if i > 0 && compareKeys(keys[i-1], k) == 0 {
+				continue
+			}
+			d.s.pushKey(k.Interface())
+			d.diff(a.MapIndex(k), b.MapIndex(k))
+			d.s.pop()
+		}
+	case reflect.Float32, reflect.Float64:
+		if fa, fb := a.Float(), b.Float(); fa != fb && !(math.IsNaN(fa) && math.IsNaN(fb)) {
+			d.change(a.Type(), Modified)
+		}
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if a.Pointer() != b.Pointer() {
+			d.change(a.Type(), Modified)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			d.change(a.Type(), Modified)
+		}
+	}
+}
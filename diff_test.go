@@ -0,0 +1,57 @@
+package mask
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	val := newTestStruct()
+	masked := Must(val)
+
+	var paths []string
+	for _, c := range Diff(val, masked) {
+		paths = append(paths, c.Path+":"+string(c.Kind))
+	}
+	expected := []string{
+		"testStruct.S1:modified",
+		"testStruct.S2:modified",
+		"testStruct.I1:modified",
+		"testStruct.I2:modified",
+		`testStruct.Mp["testKey"]:removed`,
+		"testStruct.Sl:modified",
+		"testStruct.Value:modified",
+		"testStruct.Strct1.N:modified",
+		"testStruct.Strct2.N:modified",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expect %v == %v", paths, expected)
+	}
+
+	if changes := Diff(val, newTestStruct()); len(changes) != 0 {
+		t.Errorf("expect no changes, got %v", changes)
+	}
+}
+
+func TestDiffCollections(t *testing.T) {
+	a := map[string][]int{"a": {1, 2}, "b": {1}}
+	b := map[string][]int{"a": {1, 3, 4}, "c": nil}
+
+	changes := Diff(a, b)
+	expected := []FieldChange{
+		{`["a"][1]`, reflect.TypeOf(0), Modified},
+		{`["a"][2]`, reflect.TypeOf(0), Added},
+		{`["b"]`, reflect.TypeOf([]int{}), Removed},
+		{`["c"]`, reflect.TypeOf([]int{}), Added},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expect %v == %v", changes, expected)
+	}
+
+	x := &Foo{Bar: 1}
+	x.Foo = x
+	y := Must(x)
+	if changes := Diff(x, y); len(changes) != 0 {
+		t.Errorf("expect no changes for cyclic values, got %v", changes)
+	}
+}
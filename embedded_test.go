@@ -0,0 +1,184 @@
+package mask
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type TestEmbeddedInPlace struct {
+	Secret string
+}
+
+func (e *TestEmbeddedInPlace) MaskXXX() {
+	e.Secret += "*"
+}
+
+type TestEmbeddedReturning struct {
+	Secret string
+}
+
+func (e TestEmbeddedReturning) MaskXXX() TestEmbeddedReturning {
+	e.Secret += "*"
+	return e
+}
+
+type testEmbedding struct {
+	TestEmbeddedInPlace
+	Name string `mask:"redact"`
+}
+
+type testEmbeddingReturning struct {
+	TestEmbeddedReturning
+	Other string
+}
+
+type testEmbeddingPointer struct {
+	*TestEmbeddedInPlace
+	Other string
+}
+
+type testEmbeddingShadowing struct {
+	TestEmbeddedInPlace
+	Other string
+}
+
+func (e *testEmbeddingShadowing) MaskXXX() {
+	e.Other = "shadowed"
+}
+
+type testEmbeddingTagged struct {
+	Tagged struct {
+		Card string `mask:"redact"`
+	}
+}
+
+type TestEmbeddedTagged struct {
+	Card string `mask:"redact"`
+}
+
+func (e TestEmbeddedTagged) MaskXXX() TestEmbeddedTagged {
+	return e
+}
+
+func TestMaskEmbedded(t *testing.T) {
+	masked, err := Mask(testEmbedding{TestEmbeddedInPlace{"s"}, "Ada Lovelace"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expect := testEmbedding{TestEmbeddedInPlace{"s*"}, "MASKED"}
+	if masked != expect {
+		t.Errorf("expect %v == %v", masked, expect)
+	}
+
+	returning, err := Mask(testEmbeddingReturning{TestEmbeddedReturning{"s"}, "o"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expect := (testEmbeddingReturning{TestEmbeddedReturning{"s*"}, "o"}); returning != expect {
+		t.Errorf("expect %v == %v", returning, expect)
+	}
+
+	src := testEmbeddingPointer{&TestEmbeddedInPlace{"s"}, "o"}
+	pointer, err := Mask(src)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pointer.Secret != "s*" || src.Secret != "s" {
+		t.Errorf("expect %v == s* and %v == s", pointer.Secret, src.Secret)
+	}
+	if _, err := Mask(testEmbeddingPointer{}); err != nil {
+		t.Errorf("expected no error for a nil embedded pointer, got %v", err)
+	}
+
+	shadowing, err := Mask(testEmbeddingShadowing{TestEmbeddedInPlace{"s"}, "o"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expect := (testEmbeddingShadowing{TestEmbeddedInPlace{"s*"}, "shadowed"}); shadowing != expect {
+		t.Errorf("expect %v == %v", shadowing, expect)
+	}
+}
+
+func TestWithSkipEmbeddedMaskers(t *testing.T) {
+	masked, err := Mask(testEmbedding{TestEmbeddedInPlace{"s"}, "Ada Lovelace"}, WithSkipEmbeddedMaskers())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expect := testEmbedding{TestEmbeddedInPlace{"s"}, "MASKED"}
+	if masked != expect {
+		t.Errorf("expect %v == %v", masked, expect)
+	}
+
+	pointer, err := Mask(testEmbeddingPointer{&TestEmbeddedInPlace{"s"}, "o"}, WithSkipEmbeddedMaskers())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pointer.Secret != "s" {
+		t.Errorf("expect %v == s", pointer.Secret)
+	}
+
+	// fields of embedded structs are still masked by their tags
+	tagged, err := Mask(struct{ TestEmbeddedTagged }{TestEmbeddedTagged{"4111"}}, WithSkipEmbeddedMaskers())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tagged.Card != "MASKED" {
+		t.Errorf("expect %v == MASKED", tagged.Card)
+	}
+
+	// values of embedded types which are not embedded are masked
+	standalone, err := Mask(struct{ E TestEmbeddedInPlace }{TestEmbeddedInPlace{"s"}}, WithSkipEmbeddedMaskers())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if standalone.E.Secret != "s*" {
+		t.Errorf("expect %v == s*", standalone.E.Secret)
+	}
+}
+
+func TestJSONEmbedded(t *testing.T) {
+	for _, tc := range []struct {
+		opts   []Option
+		expect string
+	}{
+		{nil, `{"Secret":"s*","Other":"o"}`},
+		{[]Option{WithSkipEmbeddedMaskers()}, `{"Secret":"s","Other":"o"}`},
+	} {
+		for _, val := range []interface{}{
+			testEmbeddingReturning{TestEmbeddedReturning{"s"}, "o"},
+			testEmbeddingPointer{&TestEmbeddedInPlace{"s"}, "o"},
+		} {
+			b, err := json.Marshal(JSON(val, tc.opts...))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if string(b) != tc.expect {
+				t.Errorf("expect %s == %s", b, tc.expect)
+			}
+		}
+	}
+}
+
+func TestScanEmbedded(t *testing.T) {
+	val := testEmbedding{TestEmbeddedInPlace{"s"}, "Ada Lovelace"}
+	for _, tc := range []struct {
+		opts   []Option
+		expect []string
+	}{
+		{nil, []string{"testEmbedding.TestEmbeddedInPlace:MaskXXX", "testEmbedding.Name:redact"}},
+		{[]Option{WithSkipEmbeddedMaskers()}, []string{"testEmbedding.Name:redact"}},
+	} {
+		findings, err := Scan(val, tc.opts...)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var paths []string
+		for _, f := range findings {
+			paths = append(paths, f.Path+":"+f.Strategy)
+		}
+		if !reflect.DeepEqual(paths, tc.expect) {
+			t.Errorf("expect %v == %v", paths, tc.expect)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package mask
+
+import "strings"
+
+// secretNames are the patterns of the names of environment variables and configuration
+// keys commonly holding secrets, see WithSecretNames.
+var secretNames = []string{
+	"*password", "*passwd", "*secret", "*_key", "*token", "*credential", "*credentials",
+	"*dsn", "*connection_string",
+}
+
+// WithSecretNames masks the values of struct fields, map entries, environment variables
+// and configuration keys named like secrets, e.g. DB_PASSWORD, AWS_SECRET_ACCESS_KEY,
+// API_KEY or github_token, using the redact strategy; see WithDenyFields.
+func WithSecretNames() Option {
+	return WithDenyFields(secretNames...)
+}
+
+// MaskEnviron masks environment variables formatted as "NAME=value", e.g. those of
+// os.Environ(), before logging them. Variables named like secrets are masked, see
+// WithSecretNames; opts mask further variables, e.g. mask.WithPathStrategy("DATABASE_URL", s)
+// or mask.WithDenyFields("*_url"). Variables failing to be masked are redacted.
+func MaskEnviron(env []string, opts ...Option) []string {
+	opts = append([]Option{WithSecretNames()}, opts...)
+	out := make([]string, len(env))
+	for i, kv := range env {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			out[i] = kv
+			continue
+		}
+		out[i] = name + "=" + maskNamed(name, value, opts)
+	}
+	return out
+}
+
+// maskNamed masks value as entry name of a map, redacting it if masking fails.
+func maskNamed(name, value string, opts []Option) string {
+	masked, err := Mask(map[string]string{name: value}, opts...)
+	if err != nil {
+		return Placeholder[string]()
+	}
+	return masked[name]
+}
+
+// MaskConfig masks the settings of a configuration before printing the effective
+// configuration, e.g. at startup. cfg is a map of nested maps like the one of viper's
+// AllSettings, or a map of flattened keys like the one of koanf's All, e.g. "db.password".
+// Settings named like secrets are masked, see WithSecretNames, as well as those matched by opts.
+func MaskConfig(cfg map[string]interface{}, opts ...Option) (map[string]interface{}, error) {
+	return Mask(cfg, append([]Option{WithSecretNames()}, opts...)...)
+}
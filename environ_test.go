@@ -0,0 +1,58 @@
+package mask
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaskEnviron(t *testing.T) {
+	env := []string{
+		"HOME=/home/ada",
+		"DB_PASSWORD=hunter2",
+		"AWS_SECRET_ACCESS_KEY=abc",
+		"STRIPE_API_KEY=sk_live",
+		"GITHUB_TOKEN=ghp_123",
+		"DATABASE_URL=postgres://ada:hunter2@db",
+		"KEYBOARD=de",
+		"EMPTY=",
+		"INVALID",
+	}
+	out := MaskEnviron(env, WithPathStrategy("DATABASE_URL", mustParseStrategy(t, "redact")))
+	expect := []string{
+		"HOME=/home/ada",
+		"DB_PASSWORD=MASKED",
+		"AWS_SECRET_ACCESS_KEY=MASKED",
+		"STRIPE_API_KEY=MASKED",
+		"GITHUB_TOKEN=MASKED",
+		"DATABASE_URL=MASKED",
+		"KEYBOARD=de",
+		"EMPTY=",
+		"INVALID",
+	}
+	if !reflect.DeepEqual(out, expect) {
+		t.Errorf("expect %v == %v", out, expect)
+	}
+}
+
+func TestMaskConfig(t *testing.T) {
+	// nested like viper, flattened like koanf
+	cfg := map[string]interface{}{
+		"server":              map[string]interface{}{"port": 8080, "tls": map[string]interface{}{"private_key": "-----BEGIN"}},
+		"db":                  map[string]interface{}{"password": "hunter2", "host": "db"},
+		"oauth.client_secret": "s3cret",
+		"oauth.client_id":     "app",
+	}
+	out, err := MaskConfig(cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expect := map[string]interface{}{
+		"server":              map[string]interface{}{"port": 8080, "tls": map[string]interface{}{"private_key": "MASKED"}},
+		"db":                  map[string]interface{}{"password": "MASKED", "host": "db"},
+		"oauth.client_secret": "MASKED",
+		"oauth.client_id":     "app",
+	}
+	if !reflect.DeepEqual(out, expect) {
+		t.Errorf("expect %v == %v", out, expect)
+	}
+}
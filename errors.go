@@ -0,0 +1,106 @@
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedKind is returned for values which cannot be copied,
+	// i.e. channels, functions and unsafe pointers.
+	ErrUnsupportedKind = errors.New("unsupported kind")
+	// ErrBadMaskSignature is returned for MaskXXX methods with an invalid signature.
+	ErrBadMaskSignature = errors.New("bad MaskXXX signature")
+	// ErrUnknownStrategy is returned for tags referencing an unregistered strategy.
+	ErrUnknownStrategy = errors.New("unknown mask strategy")
+	// ErrInvalidTag is returned for tags with invalid strategy arguments.
+	ErrInvalidTag = errors.New("invalid mask tag")
+	// ErrIncompatibleStrategy is returned if a strategy's result cannot be
+	// converted to the type of the masked value.
+	ErrIncompatibleStrategy = errors.New("incompatible strategy result")
+	// ErrMaxDepth is returned for values nested deeper than allowed by WithMaxDepth.
+	ErrMaxDepth = errors.New("max depth exceeded")
+	// ErrMaxElements is returned if more elements are visited than allowed by WithMaxElements.
+	ErrMaxElements = errors.New("max elements exceeded")
+	// ErrKeyCollision is returned if distinct map keys are equal after masking; see WithKeyCollision.
+	ErrKeyCollision = errors.New("masked map keys collide")
+	// ErrKindMismatch is returned if a copier is called with a value of the wrong kind.
+	ErrKindMismatch = errors.New("kind mismatch")
+	// ErrInvalidPath is returned for invalid path patterns; see WithPathStrategy.
+	ErrInvalidPath = errors.New("invalid path pattern")
+	// ErrMutatingMasker is returned for MaskXXX methods masking in place; see WithPureMaskers.
+	ErrMutatingMasker = errors.New("MaskXXX masks in place")
+	// ErrClassification is returned for fields classified above the clearance of WithClearance.
+	ErrClassification = errors.New("classification exceeds clearance")
+	// ErrIrreversible is returned by Unmask for values masked irreversibly.
+	ErrIrreversible = errors.New("irreversible strategy")
+)
+
+// FieldError describes a value which could not be masked.
+// Use errors.Is on it to check for the cause, e.g. ErrUnsupportedKind.
+type FieldError struct {
+	// Path locates the value, e.g. Order.Items[3].Card.Number.
+	Path string
+	// Type is the type of the value.
+	Type reflect.Type
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	var b strings.Builder
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+	if e.Type != nil {
+		fmt.Fprintf(&b, " (type %v)", e.Type)
+	}
+	return b.String()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// MaskError is returned by Mask using WithCollectErrors and
+// lists every value which could not be masked.
+type MaskError struct {
+	Errors []*FieldError
+}
+
+func (e *MaskError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("failed to mask %d value(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap allows errors.Is and errors.As to inspect every collected error.
+func (e *MaskError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// fail handles err raised while copying a value of type t.
+// err is wrapped in a *FieldError locating the value. By default it aborts
+// masking. Using WithCollectErrors, it is recorded and the zero value of t
+// is used instead, so traversal can continue.
+func (s *state) fail(t reflect.Type, err error) (interface{}, error) {
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		fieldErr = &FieldError{Path: s.currentPath(), Type: t, Err: err}
+	}
+	// once the context of MaskCtx is done, there is no use in continuing
+	if !s.opts.collectErrors || s.ctxErr != nil {
+		return nil, fieldErr
+	}
+	s.errs = append(s.errs, fieldErr)
+	return reflect.Zero(t).Interface(), nil
+}
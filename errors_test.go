@@ -0,0 +1,100 @@
+package mask
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testOrderItem struct {
+	Name     TestString
+	Callback interface{}
+}
+
+type testOrder struct {
+	Items    []testOrderItem
+	Meta     map[string]interface{}
+	Customer string `mask:"does-not-exist"`
+	Name     TestString
+}
+
+func TestCollectErrors(t *testing.T) {
+	val := &testOrder{
+		Items: []testOrderItem{
+			{Name: "first"},
+			{Name: "second", Callback: func() {}},
+		},
+		Meta:     map[string]interface{}{"hook": func() {}},
+		Customer: "customer",
+		Name:     "name",
+	}
+
+	_, err := Mask(val)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	var maskErr *MaskError
+	if errors.As(err, &maskErr) {
+		t.Errorf("expected a single error in strict mode, got %v", err)
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("expected a *FieldError, got %v", err)
+	}
+	if fieldErr.Path != "testOrder.Items[1].Callback" || fieldErr.Type != reflect.TypeOf(func() {}) {
+		t.Errorf("expected the error to locate the callback, got %v", fieldErr)
+	}
+	if !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expected %v to be ErrUnsupportedKind", err)
+	}
+
+	masked, err := Mask(val, WithCollectErrors())
+	if masked != nil {
+		t.Errorf("expected no result, got %v", masked)
+	}
+	if !errors.As(err, &maskErr) {
+		t.Fatalf("expected a *MaskError, got %v", err)
+	}
+	paths := make([]string, len(maskErr.Errors))
+	for i, e := range maskErr.Errors {
+		paths[i] = e.Path
+	}
+	expected := []string{
+		"testOrder.Items[1].Callback",
+		`testOrder.Meta["hook"]`,
+		"testOrder.Customer",
+	}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("expect %v == %v", paths, expected)
+	}
+	if !errors.As(err, &fieldErr) || fieldErr.Path != expected[0] {
+		t.Errorf("expect errors.As to find the first field error, got %v", fieldErr)
+	}
+	if !strings.Contains(err.Error(), `testOrder.Customer: unknown mask strategy "does-not-exist" (type string)`) {
+		t.Errorf("expect %v to contain the failing path", err)
+	}
+	if !errors.Is(err, ErrUnknownStrategy) || !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expect %v to contain all causes", err)
+	}
+}
+
+type testBadSignature string
+
+func (t testBadSignature) MaskXXX() string {
+	return "MASKED"
+}
+
+func TestBadMaskSignature(t *testing.T) {
+	type S struct {
+		Values []testBadSignature
+	}
+	_, err := Mask(S{Values: []testBadSignature{"a"}})
+	if !errors.Is(err, ErrBadMaskSignature) {
+		t.Fatalf("expected %v to be ErrBadMaskSignature", err)
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "S.Values[0]" || fieldErr.Type != reflect.TypeOf(testBadSignature("")) {
+		t.Errorf("expected the error to locate the value, got %v", err)
+	}
+}
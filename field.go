@@ -0,0 +1,88 @@
+package mask
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// FieldMasker applies a strategy to values of type T, checking at compile time
+// what struct tags only check at runtime. Create it using Field.
+type FieldMasker[T any] struct {
+	strategy Strategy
+}
+
+// Field returns a masker applying strategy to values of type T:
+//
+//	var maskEmail = mask.Field[string](maskers.Partial(1, 0, maskers.Format{}))
+//
+//	masked, err := maskEmail.Mask("ada@example.com")
+func Field[T any](strategy Strategy) FieldMasker[T] {
+	return FieldMasker[T]{strategy: strategy}
+}
+
+// Mask returns the masked replacement of v.
+func (f FieldMasker[T]) Mask(v T) (T, error) {
+	var out T
+	masked, err := applyStrategy(f.strategy, reflect.ValueOf(&v).Elem())
+	if err != nil {
+		return out, err
+	}
+	out, _ = masked.Interface().(T)
+	return out, nil
+}
+
+// Must returns the masked replacement of v and panics on any errors.
+func (f FieldMasker[T]) Must(v T) T {
+	out, err := f.Mask(v)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// PII holds personal data. It is masked like a value tagged `mask:"redact"`,
+// i.e. replaced by the placeholder for T, by Mask as well as when printed
+// or encoded to JSON, so it cannot leak by accident:
+//
+//	type Customer struct {
+//	  Email mask.PII[string]
+//	}
+//
+//	c := Customer{Email: mask.NewPII("ada@example.com")}
+//	fmt.Println(c.Email) // MASKED
+//
+// Use Value to access the personal data.
+type PII[T any] struct {
+	value T
+}
+
+// NewPII wraps v.
+func NewPII[T any](v T) PII[T] {
+	return PII[T]{value: v}
+}
+
+// Value returns the personal data.
+func (p PII[T]) Value() T {
+	return p.value
+}
+
+// MaskXXX replaces the personal data by the placeholder for T.
+func (p PII[T]) MaskXXX() PII[T] {
+	return PII[T]{value: Placeholder[T]()}
+}
+
+// String formats the placeholder for T.
+func (p PII[T]) String() string {
+	return fmt.Sprint(Placeholder[T]())
+}
+
+// MarshalJSON encodes the placeholder for T.
+func (p PII[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Placeholder[T]())
+}
+
+// UnmarshalJSON decodes the personal data, e.g. from a request.
+func (p *PII[T]) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &p.value)
+}
@@ -0,0 +1,58 @@
+package mask
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+func TestField(t *testing.T) {
+	email := Field[string](maskers.Partial(1, 0, maskers.Format{}))
+	if masked := email.Must("ada@example.com"); masked != "a**************" {
+		t.Errorf("expect %v == a**************", masked)
+	}
+	if _, err := Field[int](maskers.Partial(1, 0, maskers.Format{})).Mask(42); err == nil {
+		t.Errorf("expect partial to fail on ints")
+	}
+	if masked := Field[*TestString](maskers.Partial(1, 0, maskers.Format{})).Must(nil); masked != nil {
+		t.Errorf("expect nil pointers to stay nil, got %v", masked)
+	}
+}
+
+type testPIICustomer struct {
+	Email PII[string]
+	Age   PII[int]
+}
+
+func TestPII(t *testing.T) {
+	val := testPIICustomer{Email: NewPII("ada@example.com"), Age: NewPII(36)}
+
+	masked := Must(val)
+	if masked.Email.Value() != "MASKED" || masked.Age.Value() != 0 {
+		t.Errorf("expect %v, %v to be masked", masked.Email.Value(), masked.Age.Value())
+	}
+	if val.Email.Value() != "ada@example.com" {
+		t.Errorf("expect the original to stay untouched")
+	}
+
+	if s := fmt.Sprintf("%v %s", val.Email, val.Email); s != "MASKED MASKED" {
+		t.Errorf("expect %q == MASKED MASKED", s)
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"Email":"MASKED","Age":0}` {
+		t.Errorf("expect %s to be masked", b)
+	}
+
+	var decoded testPIICustomer
+	if err := json.Unmarshal([]byte(`{"Email":"bob@example.com","Age":42}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Email.Value() != "bob@example.com" || decoded.Age.Value() != 42 {
+		t.Errorf("expect %v, %v to be decoded", decoded.Email.Value(), decoded.Age.Value())
+	}
+}
@@ -0,0 +1,104 @@
+package mask
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// fieldRules mask values by the name of their struct field or map key.
+type fieldRules struct {
+	deny, allow []string
+	strategy    Strategy
+}
+
+// WithDenyFields masks all values of struct fields and map entries whose name
+// matches any of the patterns, anywhere in the value, using the redact strategy.
+// This masks obvious secrets even in untagged structs of third-party packages:
+//
+//	mask.WithDenyFields("password", "secret*", "*_token")
+//
+// Patterns may contain the wildcards * and ?. Names are matched case-insensitively
+// and ignoring underscores and dashes, so "*_token" matches the field AccessToken
+// as well as the keys "access_token" and "Refresh-Token".
+//
+// Struct tags, path and type strategies take precedence over field names.
+// An invalid pattern fails Mask with ErrInvalidPath.
+func WithDenyFields(patterns ...string) Option {
+	return withFieldPatterns(patterns, func(r *fieldRules, p []string) {
+		r.deny = append(r.deny, p...)
+	})
+}
+
+// WithAllowFields exempts struct fields and map entries whose name matches any of
+// the patterns from WithDenyFields, e.g. to keep "csrf_token" while denying "*_token".
+// Patterns are matched like the ones of WithDenyFields.
+func WithAllowFields(patterns ...string) Option {
+	return withFieldPatterns(patterns, func(r *fieldRules, p []string) {
+		r.allow = append(r.allow, p...)
+	})
+}
+
+func withFieldPatterns(patterns []string, add func(*fieldRules, []string)) Option {
+	normalized := make([]string, len(patterns))
+	var err error
+	for i, p := range patterns {
+		normalized[i] = normalizeFieldName(p)
+		if _, matchErr := path.Match(normalized[i], ""); matchErr != nil {
+			err = fmt.Errorf("%w %q: %v", ErrInvalidPath, p, matchErr)
+		}
+	}
+	return func(o *options) {
+		if err != nil {
+			o.err = err
+			return
+		}
+		if o.fields.strategy == nil {
+			o.fields.strategy, _ = redactStrategy("")
+		}
+		add(&o.fields, normalized)
+	}
+}
+
+// normalizeFieldName lowercases name and removes underscores and dashes.
+func normalizeFieldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+func matchFieldName(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldStrategy returns the strategy masking the current value because of
+// the name of its struct field or map key, if any.
+func (s *state) fieldStrategy() Strategy {
+	if s.inKey || len(s.path) == 0 || len(s.opts.fields.deny) == 0 {
+		return nil
+	}
+	last := s.path[len(s.path)-1]
+	var name string
+	switch {
+	case last.field != "":
+		name = last.field
+	case last.key != nil && reflect.TypeOf(last.key).Kind() == reflect.String:
+		name = reflect.ValueOf(last.key).String()
+	default:
+		return nil
+	}
+	name = normalizeFieldName(name)
+	if !matchFieldName(s.opts.fields.deny, name) || matchFieldName(s.opts.fields.allow, name) {
+		return nil
+	}
+	return s.opts.fields.strategy
+}
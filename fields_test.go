@@ -0,0 +1,76 @@
+package mask
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type thirdPartyConfig struct {
+	User        string
+	Password    string
+	AccessToken string
+	CSRFToken   string
+	Retries     int
+	Extra       map[string]interface{}
+}
+
+func TestWithDenyFields(t *testing.T) {
+	val := thirdPartyConfig{
+		User:        "ada",
+		Password:    "s3cr3t",
+		AccessToken: "tok",
+		CSRFToken:   "csrf",
+		Retries:     3,
+		Extra: map[string]interface{}{
+			"refresh-token": "tok",
+			"client_secret": 42,
+			"region":        "eu",
+		},
+	}
+	opts := []Option{WithDenyFields("password", "*_token", "*secret"), WithAllowFields("csrf_token")}
+	masked, err := Mask(val, opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := thirdPartyConfig{
+		User:        "ada",
+		Password:    "MASKED",
+		AccessToken: "MASKED",
+		CSRFToken:   "csrf",
+		Retries:     3,
+		Extra: map[string]interface{}{
+			"refresh-token": "MASKED",
+			"client_secret": 0,
+			"region":        "eu",
+		},
+	}
+	if !reflect.DeepEqual(masked, expected) {
+		t.Errorf("expect %v == %v", masked, expected)
+	}
+
+	b, err := json.Marshal(JSON(val, opts...))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expectedJSON := `{"User":"ada","Password":"MASKED","AccessToken":"MASKED","CSRFToken":"csrf","Retries":3,"Extra":{"client_secret":0,"refresh-token":"MASKED","region":"eu"}}`
+	if string(b) != expectedJSON {
+		t.Errorf("expect %s == %s", b, expectedJSON)
+	}
+
+	findings, err := Scan(val, opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(findings) != 4 || findings[0].Path != "thirdPartyConfig.Password" || findings[0].Source != SourceField {
+		t.Errorf("expect 4 field findings, got %v", findings)
+	}
+}
+
+func TestWithDenyFieldsInvalidPattern(t *testing.T) {
+	_, err := Mask(thirdPartyConfig{}, WithDenyFields("[pass"))
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expect %v == %v", err, ErrInvalidPath)
+	}
+}
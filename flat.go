@@ -0,0 +1,72 @@
+package mask
+
+import (
+	"reflect"
+	"sync"
+)
+
+// flatType describes whether a struct type is flat: all of its fields are exported,
+// untagged booleans, numbers, strings or flat structs, or arrays of them, and none
+// of them has a MaskXXX method. Copying a flat struct field by field yields the
+// struct itself, unless options inspect its fields.
+type flatType struct {
+	flat bool
+	// arrays is set if the struct holds arrays, whose elements count towards WithMaxElements.
+	arrays bool
+}
+
+// flatTypes caches flatType by struct type.
+var flatTypes sync.Map
+
+func flatTypeOf(t reflect.Type) flatType {
+	if ft, ok := flatTypes.Load(t); ok {
+		return ft.(flatType)
+	}
+	ft := flatType{flat: true}
+	for i := 0; i < t.NumField() && ft.flat; i++ {
+		f := t.Field(i)
+		_, tagged := f.Tag.Lookup(tagName)
+		ft.flat = f.PkgPath == "" && !tagged && flatField(f.Type, &ft)
+	}
+	flatTypes.Store(t, ft)
+	return ft
+}
+
+// flatField reports whether values of type t keep struct fields of type t flat.
+func flatField(t reflect.Type, ft *flatType) bool {
+	if _, ok, err := maskMethod(t); ok || err != nil {
+		return false
+	}
+	if _, ok := typeCopiers[t]; ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		return true
+	case reflect.Array:
+		ft.arrays = true
+		return flatField(t.Elem(), ft)
+	case reflect.Struct:
+		inner := flatTypeOf(t)
+		ft.arrays = ft.arrays || inner.arrays
+		return inner.flat
+	}
+	return false
+}
+
+// flat reports whether the struct type t is flat and none of the options inspect
+// its fields, so values of t are copied by a single assignment.
+func (s *state) flat(t reflect.Type) bool {
+	o := &s.opts
+	if len(o.pathStrategies) > 0 || len(o.typeStrategies) > 0 || len(o.interfaceStrategies) > 0 ||
+		len(o.detectors) > 0 || len(o.fields.deny) > 0 || len(o.hooks) > 0 || o.transform != nil ||
+		o.maxDepth > 0 || o.maxStringLen > 0 || len(o.tagKeys) > 0 || len(o.tagAliases) > 0 ||
+		o.clearance != nil ||
+		!s.copier().types.empty() || !s.copier().kinds.empty() {
+		return false
+	}
+	ft := flatTypeOf(t)
+	return ft.flat && (!ft.arrays || o.maxElements <= 0)
+}
@@ -0,0 +1,101 @@
+package mask
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testFlatAddress struct {
+	Street string
+	Zip    [5]byte
+}
+
+type testFlatDTO struct {
+	ID      int64
+	Name    string
+	Score   float64
+	Active  bool
+	Address testFlatAddress
+}
+
+type testNotFlat struct {
+	ID    int64
+	Email string `mask:"redact"`
+}
+
+type testNotFlatMasker struct {
+	ID   int64
+	Name TestString
+}
+
+type testNotFlatUnexported struct {
+	ID   int64
+	name string
+}
+
+func TestFlatTypes(t *testing.T) {
+	for _, tc := range []struct {
+		val  interface{}
+		flat bool
+	}{
+		{testFlatDTO{}, true},
+		{testFlatAddress{}, true},
+		{testNotFlat{}, false},
+		{testNotFlatMasker{}, false},
+		{testNotFlatUnexported{}, false},
+		{struct{ P *int }{}, false},
+		{struct{ S []string }{}, false},
+	} {
+		if flat := flatTypeOf(reflect.TypeOf(tc.val)).flat; flat != tc.flat {
+			t.Errorf("expect %T flat %v == %v", tc.val, flat, tc.flat)
+		}
+	}
+}
+
+func TestMaskFlat(t *testing.T) {
+	val := testFlatDTO{ID: 1, Name: "Ada", Score: 1.5, Active: true, Address: testFlatAddress{"Main St", [5]byte{'1', '2'}}}
+	masked, err := Mask(val)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if masked != val {
+		t.Errorf("expect %v == %v", masked, val)
+	}
+	var x interface{} = val
+	s := newState(x, nil)
+	defer s.release()
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := _struct(x, s); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expect %v == 0 allocations", allocs)
+	}
+
+	// options inspecting fields disable the fast path
+	masked, err = Mask(val, WithDenyFields("name"), WithPathStrategy("Address.Street", mustParseStrategy(t, "redact")))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if masked.Name != "MASKED" || masked.Address.Street != "MASKED" {
+		t.Errorf("expect %v and %v == MASKED", masked.Name, masked.Address.Street)
+	}
+	_, err = Mask(val, WithMaxElements(2))
+	if err == nil {
+		t.Errorf("expected an error for the array exceeding the limit")
+	}
+}
+
+func BenchmarkMaskFlat(b *testing.B) {
+	val := make([]testFlatDTO, 100)
+	for i := range val {
+		val[i] = testFlatDTO{ID: int64(i), Name: "Ada", Score: 1.5, Active: true, Address: testFlatAddress{Street: "Main St"}}
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Mask(val); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package mask
+
+import "fmt"
+
+type formatter struct {
+	x    interface{}
+	opts []Option
+}
+
+// Fmt returns a wrapper printing the masked form of x using the verbs and flags
+// of package fmt. Masking is deferred until x is actually formatted, so suppressed
+// debug logs do not pay for the deep copy:
+//
+//	log.Printf("order: %+v", mask.Fmt(order))
+//
+// If x cannot be masked, the error is printed instead of x.
+func Fmt(x interface{}, opts ...Option) fmt.Formatter {
+	return formatter{x: x, opts: opts}
+}
+
+func (f formatter) Format(s fmt.State, verb rune) {
+	masked, err := Mask(f.x, f.opts...)
+	if err != nil {
+		fmt.Fprintf(s, "%%!%c(mask error: %v)", verb, err)
+		return
+	}
+	fmt.Fprintf(s, fmt.FormatString(s, verb), masked)
+}
+
+type stringer formatter
+
+// Stringer returns a fmt.Stringer whose String method masks x and formats it compactly
+// like %+v, for APIs accepting a fmt.Stringer, e.g. error messages or slog attributes.
+// Like for Fmt, masking is deferred until String is called and errors are printed instead of x.
+func Stringer(x interface{}, opts ...Option) fmt.Stringer {
+	return stringer{x: x, opts: opts}
+}
+
+func (s stringer) String() string {
+	return fmt.Sprintf("%+v", formatter(s))
+}
@@ -0,0 +1,43 @@
+package mask
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFmt(t *testing.T) {
+	val := &testPerson{Name: "Ada Lovelace", Email: "ada@example.com"}
+	masked := Must(val)
+
+	for _, format := range []string{"%v", "%+v", "%s", "%20v"} {
+		expect := fmt.Sprintf(format, masked)
+		if s := fmt.Sprintf(format, Fmt(val)); s != expect {
+			t.Errorf("expect %q == %q", s, expect)
+		}
+	}
+	if s := fmt.Sprint(Fmt(val)); strings.Contains(s, "Ada Lovelace") {
+		t.Errorf("expect %q to be masked", s)
+	}
+
+	order := testOrderItem{Name: "first", Callback: func() {}}
+	if s := fmt.Sprintf("%v", Fmt(order)); !strings.HasPrefix(s, "%!v(mask error: ") {
+		t.Errorf("expect %q to print the error", s)
+	}
+	if s := fmt.Sprintf("%v", Fmt(order, WithSkipUnsupported())); strings.HasPrefix(s, "%!v") {
+		t.Errorf("expect options to be applied, got %q", s)
+	}
+}
+
+func TestStringer(t *testing.T) {
+	val := &testPerson{Name: "Ada Lovelace", Email: "ada@example.com"}
+	if s, expect := Stringer(val).String(), fmt.Sprintf("%+v", Must(val)); s != expect {
+		t.Errorf("expect %q == %q", s, expect)
+	}
+	if s := fmt.Sprintf("failed for %s", Stringer(val)); strings.Contains(s, "Ada Lovelace") {
+		t.Errorf("expect %q to be masked", s)
+	}
+	if s := Stringer(testOrderItem{Callback: func() {}}).String(); !strings.HasPrefix(s, "%!v(mask error: ") {
+		t.Errorf("expect %q to print the error", s)
+	}
+}
@@ -0,0 +1,164 @@
+package mask
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fuzzNode is built by fuzz targets into arbitrary graphs, covering nil and
+// non-nil pointers and interfaces, cycles, shared references, unexported
+// fields, tags and MaskXXX methods.
+type fuzzNode struct {
+	Name     string `mask:"redact"`
+	Secret   TestString
+	Next     *fuzzNode
+	Any      interface{}
+	Children []*fuzzNode
+	Attrs    map[string]interface{}
+	Masker   *testPointerMasker
+	Fixed    [2]*fuzzNode
+	hidden   *fuzzNode
+}
+
+// buildFuzzGraph interprets data as instructions building a graph of fuzzNodes.
+// The same data always builds the same graph.
+func buildFuzzGraph(data []byte) *fuzzNode {
+	nodes := []*fuzzNode{{Name: "root"}}
+	pick := func(b byte) *fuzzNode {
+		return nodes[int(b)%len(nodes)]
+	}
+	for i := 0; i+1 < len(data); i += 2 {
+		op, arg := data[i], data[i+1]
+		cur := nodes[len(nodes)-1]
+		switch op % 12 {
+		case 0:
+			nodes = append(nodes, &fuzzNode{Name: string(rune('a' + arg%26)), Secret: TestString([]byte{arg})})
+		case 1:
+			cur.Next = pick(arg)
+		case 2:
+			cur.Any = pick(arg)
+		case 3:
+			cur.Any = *pick(arg)
+		case 4:
+			cur.Children = append(cur.Children, pick(arg), nil)
+		case 5:
+			if cur.Attrs == nil {
+				cur.Attrs = make(map[string]interface{})
+			}
+			cur.Attrs[string(rune('a'+arg%26))] = pick(arg)
+		case 6:
+			if cur.Attrs == nil {
+				cur.Attrs = make(map[string]interface{})
+			}
+			cur.Attrs["self"] = cur.Attrs
+			cur.Attrs["list"] = []interface{}{int(arg), nil, TestString("s"), cur.Children}
+		case 7:
+			cur.Masker = &testPointerMasker{string(rune(arg))}
+		case 8:
+			cur.Fixed[arg%2] = pick(arg)
+		case 9:
+			cur.hidden = pick(arg)
+		case 10:
+			var nilPtr *fuzzNode
+			cur.Any = nilPtr
+		case 11:
+			cur.Children = pick(arg).Children
+		}
+	}
+	return nodes[0]
+}
+
+// within fails the test if f does not return within a reasonable time, e.g. because
+// a cycle is followed forever.
+func within(t *testing.T, f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("did not return")
+	}
+}
+
+func FuzzMaskGraph(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 1, 1, 0, 2, 0})
+	f.Add([]byte{0, 1, 0, 2, 4, 0, 4, 1, 5, 2, 6, 0, 11, 1})
+	f.Add([]byte{0, 3, 3, 0, 8, 0, 9, 0, 7, 'x', 10, 0})
+	f.Add([]byte{0, 1, 2, 1, 1, 0, 6, 1, 5, 0, 3, 1, 8, 1})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		val := buildFuzzGraph(data)
+		var errs []error
+		within(t, func() {
+			for _, opts := range [][]Option{nil, {WithPreserveAliasing(), WithSortedMaps()}, {WithMaxDepth(4), WithTruncateLimits()}} {
+				_, err := Mask(val, opts...)
+				errs = append(errs, err)
+			}
+			_, err := Scan(val)
+			errs = append(errs, err)
+			_, err = Clone(val)
+			errs = append(errs, err)
+		})
+		for _, err := range errs {
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if !reflect.DeepEqual(val, buildFuzzGraph(data)) {
+			t.Fatalf("expect the original to stay untouched")
+		}
+	})
+}
+
+func FuzzMaskJSON(f *testing.F) {
+	f.Add(`{}`)
+	f.Add(`null`)
+	f.Add(`[1, "two", null, {"a": [true, 2.5]}]`)
+	f.Add(`{"Name": "Ada", "Secret": "s", "Next": {"Name": "Bob", "Any": {"x": [1, {}]}}, "Attrs": {"k": null}}`)
+	f.Add(`{"Children": [null, {"Children": [{}]}], "Fixed": [{"Name": "a"}, null], "Masker": {"N": "m"}}`)
+	f.Fuzz(func(t *testing.T, data string) {
+		var generic interface{}
+		if json.Unmarshal([]byte(data), &generic) == nil {
+			var err error
+			within(t, func() {
+				_, err = Mask(generic)
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			var again interface{}
+			_ = json.Unmarshal([]byte(data), &again)
+			if !reflect.DeepEqual(generic, again) {
+				t.Fatalf("expect the original to stay untouched")
+			}
+			if _, err := json.Marshal(JSON(generic)); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		var node fuzzNode
+		if json.Unmarshal([]byte(data), &node) != nil {
+			return
+		}
+		var err error
+		within(t, func() {
+			_, err = Mask(&node)
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var again fuzzNode
+		_ = json.Unmarshal([]byte(data), &again)
+		if !reflect.DeepEqual(node, again) {
+			t.Fatalf("expect the original to stay untouched")
+		}
+		if _, err := json.Marshal(JSON(node)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package mask
+
+import (
+	"reflect"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+// HookAction tells how a value is handled after a hook has seen it.
+type HookAction int
+
+const (
+	// HookContinue masks the value as usual.
+	HookContinue HookAction = iota
+	// HookKeep vetoes masking the value itself: its tag, path, type, field and
+	// detector strategies and its MaskXXX method are not applied.
+	// Its fields and elements are still visited and masked.
+	HookKeep
+	// HookReplace replaces the value by HookResult.Value.
+	HookReplace
+	// HookMask masks the value using HookResult.Strategy.
+	HookMask
+)
+
+// HookResult is the decision of a hook about a value.
+type HookResult struct {
+	Action HookAction
+	// Value replaces the value for HookReplace. It must be convertible to the type of the value;
+	// the zero value is used if Value is invalid.
+	Value reflect.Value
+	// Strategy masks the value for HookMask.
+	Strategy Strategy
+}
+
+// Hook is called for every visited value but map keys, pointers and interfaces,
+// which are followed to their elements. path locates the value like in findings,
+// e.g. Order.Items[3].Card. Hooks must not modify v.
+type Hook func(path string, v reflect.Value) HookResult
+
+// WithHook calls h for every visited value, allowing to observe, veto or override masking
+// decisions, e.g. for rules tags cannot express. Hooks are called in the order they are
+// given; the first one returning an action other than HookContinue decides.
+// Hooks take precedence over all other means of masking.
+func WithHook(h Hook) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, h)
+	}
+}
+
+// hook returns the decision of the hooks about v at the current path. Pointers and
+// interfaces are decided on by their elements, which take over the decision.
+func (s *state) hook(v reflect.Value) HookResult {
+	switch {
+	case !s.opts.hooking():
+		return HookResult{}
+	case s.opts.clone:
+		return HookResult{Action: HookKeep}
+	case s.inKey:
+		// map keys are located by their value, so they are not seen by hooks
+		return s.undecided()
+	}
+	indirect := v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface
+	if s.decided != nil && s.decidedAt == s.visits {
+		r := *s.decided
+		if !indirect {
+			s.decided = nil
+		}
+		return r
+	}
+	elem := v
+	for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
+		if elem.IsNil() {
+			return s.undecided()
+		}
+		elem = elem.Elem()
+	}
+	r := s.undecided()
+	if s.opts.transform != nil {
+		r = s.transformed(elem)
+	} else {
+		path := s.currentPath()
+		for _, h := range s.opts.hooks {
+			if r = h(path, elem); r.Action != HookContinue {
+				break
+			}
+		}
+	}
+	if indirect {
+		s.decided, s.decidedAt = &r, s.visits
+	}
+	return r
+}
+
+// undecided is the decision about values not seen by hooks: Transform keeps them.
+func (s *state) undecided() HookResult {
+	if s.opts.transform != nil {
+		return HookResult{Action: HookKeep}
+	}
+	return HookResult{}
+}
+
+// hooking reports whether values are decided on by hooks, Transform or Clone.
+func (o *options) hooking() bool {
+	return len(o.hooks) > 0 || o.transform != nil || o.clone
+}
+
+// decide passes the decision r about the current value to hook while calling f,
+// so values are not seen twice by hooks, e.g. tagged fields.
+func (s *state) decide(r HookResult, f func()) {
+	s.decided, s.decidedAt = &r, s.visits
+	f()
+	s.decided = nil
+}
+
+// hooked masks v as decided by a hook using HookReplace or HookMask.
+func (s *state) hooked(r HookResult, v reflect.Value) (interface{}, error) {
+	if r.Action == HookMask {
+		return _path(v.Interface(), r.Strategy, s)
+	}
+	replaced, err := applyStrategy(maskers.Func("hook", func(v reflect.Value) (reflect.Value, error) {
+		return r.Value, nil
+	}), v)
+	if err != nil {
+		return s.fail(v.Type(), err)
+	}
+	s.masked("hook")
+	return replaced.Interface(), nil
+}
+
+// strategyName names the strategy decided by a hook in findings.
+func (r HookResult) strategyName() string {
+	if r.Action == HookMask {
+		return r.Strategy.Name()
+	}
+	return "hook"
+}
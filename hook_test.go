@@ -0,0 +1,124 @@
+package mask
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type hookedAccount struct {
+	Owner   string  `mask:"redact"`
+	Alias   *string `mask:"redact"`
+	Email   string
+	Comment TestString
+	Balance int
+}
+
+func TestWithHook(t *testing.T) {
+	alias := "ada"
+	val := hookedAccount{Owner: "Ada Lovelace", Alias: &alias, Email: "ada@example.com", Comment: "vip", Balance: 42}
+	var visited []string
+	opts := []Option{
+		WithHook(func(path string, v reflect.Value) HookResult {
+			visited = append(visited, path)
+			return HookResult{}
+		}),
+		WithHook(func(path string, v reflect.Value) HookResult {
+			switch {
+			case strings.HasSuffix(path, ".Alias"), strings.HasSuffix(path, ".Comment"):
+				return HookResult{Action: HookKeep}
+			case strings.HasSuffix(path, ".Email"):
+				return HookResult{Action: HookMask, Strategy: mustParseStrategy(t, "partial=1:0")}
+			case v.Kind() == reflect.Int:
+				return HookResult{Action: HookReplace, Value: reflect.ValueOf(-1)}
+			}
+			return HookResult{}
+		}),
+	}
+	masked, err := Mask(val, opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := hookedAccount{Owner: "MASKED", Alias: &alias, Email: "a**************", Comment: "vip", Balance: -1}
+	if !reflect.DeepEqual(masked, expected) {
+		t.Errorf("expect %v == %v", masked, expected)
+	}
+	if masked.Alias == val.Alias {
+		t.Errorf("expect kept pointers to be copied")
+	}
+	expectedVisited := []string{
+		"hookedAccount",
+		"hookedAccount.Owner",
+		"hookedAccount.Alias",
+		"hookedAccount.Email",
+		"hookedAccount.Comment",
+		"hookedAccount.Balance",
+	}
+	if !reflect.DeepEqual(visited, expectedVisited) {
+		t.Errorf("expect %v == %v", visited, expectedVisited)
+	}
+
+	visited = nil
+	b, err := json.Marshal(JSON(val, opts...))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expectedJSON := `{"Owner":"MASKED","Alias":"ada","Email":"a**************","Comment":"vip","Balance":-1}`
+	if string(b) != expectedJSON {
+		t.Errorf("expect %s == %s", b, expectedJSON)
+	}
+	if !reflect.DeepEqual(visited, expectedVisited) {
+		t.Errorf("expect %v == %v", visited, expectedVisited)
+	}
+
+	visited = nil
+	findings, err := Scan(val, opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var sources []string
+	for _, f := range findings {
+		sources = append(sources, f.Path+":"+f.Strategy+":"+string(f.Source))
+	}
+	expectedSources := []string{
+		"hookedAccount.Owner:redact:tag",
+		"hookedAccount.Email:partial:hook",
+		"hookedAccount.Balance:hook:hook",
+	}
+	if !reflect.DeepEqual(sources, expectedSources) {
+		t.Errorf("expect %v == %v", sources, expectedSources)
+	}
+	if !reflect.DeepEqual(visited, expectedVisited) {
+		t.Errorf("expect %v == %v", visited, expectedVisited)
+	}
+}
+
+func mustParseStrategy(t *testing.T, tag string) Strategy {
+	t.Helper()
+	s, err := ParseStrategy(tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestWithHookVetoesPointers(t *testing.T) {
+	email := "ada@example.com"
+	val := struct{ Email *string }{&email}
+	var calls int
+	masked, err := Mask(val, WithPathStrategy("Email", mustParseStrategy(t, "redact")),
+		WithHook(func(path string, v reflect.Value) HookResult {
+			calls++
+			return HookResult{Action: HookKeep}
+		}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *masked.Email != email || masked.Email == val.Email {
+		t.Errorf("expect %v == %v", *masked.Email, email)
+	}
+	if calls != 2 {
+		t.Errorf("expect hook to be called for the struct and the string, got %d calls", calls)
+	}
+}
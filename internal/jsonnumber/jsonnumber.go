@@ -0,0 +1,26 @@
+// Package jsonnumber keeps JSON documents decoded using json.Decoder.UseNumber
+// encodable after masking.
+package jsonnumber
+
+import "encoding/json"
+
+// Masked replaces the numbers of the decoded JSON document v which were masked by
+// strategies yielding no number, e.g. redact or partial, by strings, as encoding/json
+// refuses to encode them. Maps and slices are replaced in place.
+func Masked(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if s := string(v); s == "" || s[0] != '-' && (s[0] < '0' || s[0] > '9') || !json.Valid([]byte(s)) {
+			return s
+		}
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = Masked(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = Masked(e)
+		}
+	}
+	return v
+}
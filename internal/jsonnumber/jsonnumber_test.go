@@ -0,0 +1,24 @@
+package jsonnumber
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMasked(t *testing.T) {
+	doc := map[string]interface{}{
+		"age":   json.Number("42"),
+		"pin":   json.Number("****"),
+		"cvv":   json.Number("1**"),
+		"name":  "ada",
+		"codes": []interface{}{json.Number("-1.5e3"), json.Number("[REDACTED]")},
+	}
+	b, err := json.Marshal(Masked(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"age":42,"codes":[-1.5e3,"[REDACTED]"],"cvv":"1**","name":"ada","pin":"****"}`
+	if string(b) != expect {
+		t.Errorf("expect %s == %s", b, expect)
+	}
+}
@@ -0,0 +1,109 @@
+// Package sqltest provides a database/sql driver serving fixed tables, so packages
+// dumping tables can be tested without a database.
+package sqltest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Table is a table served by the driver.
+type Table struct {
+	Columns []string
+	// Types are the database type names of the columns, e.g. "TEXT".
+	Types []string
+	Rows  [][]driver.Value
+}
+
+// Register registers a driver serving tables under name. The driver answers queries
+// like SELECT * FROM "name"; queries with a WHERE clause select the first row only.
+func Register(name string, tables map[string]Table) {
+	sql.Register(name, testDriver{tables: tables})
+}
+
+type testDriver struct {
+	tables map[string]Table
+}
+
+func (d testDriver) Open(string) (driver.Conn, error) {
+	return testConn(d), nil
+}
+
+type testConn struct {
+	tables map[string]Table
+}
+
+func (c testConn) Prepare(query string) (driver.Stmt, error) {
+	return testStmt{tables: c.tables, query: query}, nil
+}
+
+func (testConn) Close() error {
+	return nil
+}
+
+func (testConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type testStmt struct {
+	tables map[string]Table
+	query  string
+}
+
+func (testStmt) Close() error {
+	return nil
+}
+
+func (testStmt) NumInput() int {
+	return -1
+}
+
+func (testStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s testStmt) Query([]driver.Value) (driver.Rows, error) {
+	_, name, _ := strings.Cut(s.query, "FROM ")
+	name, where, _ := strings.Cut(name, " WHERE ")
+	t, ok := s.tables[strings.Trim(name, "\"`")]
+	if !ok {
+		return nil, errors.New("no such table")
+	}
+	rows := t.Rows
+	if where != "" {
+		rows = rows[:1]
+	}
+	return &testRows{t: t, rows: rows}, nil
+}
+
+type testRows struct {
+	t    Table
+	rows [][]driver.Value
+}
+
+func (r *testRows) Columns() []string {
+	return r.t.Columns
+}
+
+func (r *testRows) ColumnTypeDatabaseTypeName(i int) string {
+	if i < len(r.t.Types) {
+		return r.t.Types[i]
+	}
+	return ""
+}
+
+func (r *testRows) Close() error {
+	return nil
+}
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
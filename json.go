@@ -0,0 +1,875 @@
+package mask
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type jsonMarshaler struct {
+	x    interface{}
+	opts []Option
+	// fields encodes the fields of a root struct, even if it encodes itself, see MarshalJSON.
+	fields bool
+}
+
+// JSON returns a json.Marshaler encoding the masked form of x. Unlike encoding the
+// result of Mask, values are masked while they are encoded, without building a deep
+// copy of x first; only values masked by MaskXXX methods and values encoding themselves
+// (implementing json.Marshaler or encoding.TextMarshaler) are copied.
+//
+//	json.NewEncoder(w).Encode(mask.JSON(response))
+//
+// Struct fields are encoded like encoding/json does, honoring json tags.
+func JSON(x interface{}, opts ...Option) json.Marshaler {
+	return jsonMarshaler{x: x, opts: opts}
+}
+
+// MarshalJSON returns the JSON encoding of the masked form of x, like JSON. If x
+// is a struct, or a pointer to one, its fields are encoded even if it implements
+// json.Marshaler, so types can derive their encoding from their mask tags:
+//
+//	type User struct {
+//		Name  string `json:"name"`
+//		Email string `json:"email" mask:"partial=1:0"`
+//	}
+//
+//	func (u User) MarshalJSON() ([]byte, error) {
+//		return mask.MarshalJSON(u)
+//	}
+//
+// API responses and logs encoding a User by encoding/json are masked alike. Register
+// such types using RegisterMarshalJSON, so JSON and MarshalJSON encode the fields of
+// nested values directly rather than masking them twice. Values masked by Mask already
+// are masked again when encoded.
+func MarshalJSON(x interface{}, opts ...Option) ([]byte, error) {
+	return jsonMarshaler{x: x, opts: opts, fields: true}.MarshalJSON()
+}
+
+// derivedJSON holds the struct types registered by RegisterMarshalJSON.
+var derivedJSON = newRegistry(map[reflect.Type]bool{})
+
+// RegisterMarshalJSON declares that the MarshalJSON method of T, a struct type or a
+// pointer to one, derives its encoding by calling MarshalJSON. JSON and MarshalJSON
+// encode the fields of nested values of T directly, so they are masked once, with the
+// options of the call; values of types not registered are masked by Mask and masked
+// again by their MarshalJSON method. Register types before encoding them:
+//
+//	func init() {
+//		mask.RegisterMarshalJSON[User]()
+//	}
+func RegisterMarshalJSON[T any]() {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	derivedJSON.store(t, true)
+}
+
+// derivesJSON reports whether the struct type t, or the struct t points to, has been
+// registered by RegisterMarshalJSON.
+func derivesJSON(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	derived, _ := derivedJSON.load(t)
+	return derived
+}
+
+func (j jsonMarshaler) MarshalJSON() (_ []byte, err error) {
+	s := newState(j.x, j.opts)
+	defer s.release()
+	if s.opts.metrics != nil {
+		defer s.observe("json", time.Now(), &err)
+	}
+	if s.opts.tracer != nil {
+		defer s.endSpan(s.startSpan("json", j.x), &err)
+	}
+	if s.opts.err != nil {
+		return nil, s.opts.err
+	}
+	w := newBufWriter()
+	defer w.release()
+	if err := j.encode(s, w); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(w.buf.Bytes()), nil
+}
+
+// encode writes the masked form of j.x to w.
+func (j jsonMarshaler) encode(s *state, w jsonWriter) error {
+	e := &jsonEncoder{s: s, w: w}
+	v := reflect.ValueOf(j.x)
+	if j.fields {
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+	}
+	var err error
+	if j.fields && v.Kind() == reflect.Struct {
+		// calling e.encode would call x's MarshalJSON, which calls MarshalJSON again
+		err = e.encodeStruct(v)
+	} else {
+		err = e.encode(v)
+	}
+	if err != nil {
+		return err
+	}
+	if len(s.errs) > 0 && !s.opts.partial {
+		return &MaskError{Errors: s.errs}
+	}
+	return nil
+}
+
+var jsonNull = []byte("null")
+
+// jsonWriter receives the encoded tokens and values.
+type jsonWriter interface {
+	// delim writes one of { } [ ].
+	delim(c byte) error
+	// str writes a string value or an object member name.
+	str(s string) error
+	// raw writes an encoded value.
+	raw(b []byte) error
+	// value encodes x, which has been masked already.
+	value(x interface{}) error
+	// null writes a nil map, slice, pointer or interface of kind k.
+	null(k reflect.Kind) error
+}
+
+// bufWriter writes compact JSON to a buffer.
+type bufWriter struct {
+	buf bytes.Buffer
+	// objects tracks for every open object or array whether it is an object,
+	// counts the number of names and values written to it.
+	objects []bool
+	counts  []int
+}
+
+// sep writes the separator needed before the next name or value.
+func (w *bufWriter) sep() {
+	n := len(w.counts) - 1
+	if n < 0 {
+		return
+	}
+	switch {
+	case w.objects[n] && w.counts[n]%2 == 1:
+		w.buf.WriteByte(':')
+	case w.counts[n] > 0:
+		w.buf.WriteByte(',')
+	}
+	w.counts[n]++
+}
+
+func (w *bufWriter) delim(c byte) error {
+	switch c {
+	case '{', '[':
+		w.sep()
+		w.objects = append(w.objects, c == '{')
+		w.counts = append(w.counts, 0)
+	default:
+		w.objects = w.objects[:len(w.objects)-1]
+		w.counts = w.counts[:len(w.counts)-1]
+	}
+	w.buf.WriteByte(c)
+	return nil
+}
+
+func (w *bufWriter) str(s string) error {
+	w.sep()
+	appendJSONString(&w.buf, s)
+	return nil
+}
+
+func (w *bufWriter) raw(b []byte) error {
+	w.sep()
+	w.buf.Write(b)
+	return nil
+}
+
+func (w *bufWriter) null(reflect.Kind) error {
+	return w.raw(jsonNull)
+}
+
+func (w *bufWriter) value(x interface{}) error {
+	b, err := json.Marshal(x)
+	if err != nil {
+		return err
+	}
+	return w.raw(b)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type jsonEncoder struct {
+	s *state
+	w jsonWriter
+	// scratch is used to format numbers.
+	scratch [64]byte
+	// encoding holds the pointers currently encoded to detect cycles.
+	encoding map[uintptr]bool
+}
+
+// marshal appends the encoding of x, or of the zero value of t if err is nil but x is.
+func (e *jsonEncoder) marshal(x interface{}, err error) error {
+	if err != nil {
+		return err
+	}
+	return e.w.value(x)
+}
+
+// copied appends the encoding of the masked deep copy of v.
+func (e *jsonEncoder) copied(v reflect.Value) error {
+	return e.marshal(_anything(v.Interface(), e.s))
+}
+
+func (e *jsonEncoder) encode(v reflect.Value) error {
+	s := e.s
+	if !v.IsValid() {
+		return e.w.raw(jsonNull)
+	}
+	t := v.Type()
+	if s.exceedsDepth() {
+		return e.marshal(s.depthExceeded(t))
+	}
+	hook := s.hook(v)
+	switch hook.Action {
+	case HookReplace, HookMask:
+		return e.marshal(s.hooked(hook, v))
+	case HookKeep:
+		if s.copiedForJSON(t) {
+			var err error
+			s.decide(hook, func() {
+				err = e.copied(v)
+			})
+			return err
+		}
+	}
+	if v.Kind() != reflect.Interface && hook.Action == HookContinue {
+		if strategy := s.pathStrategy(); strategy != nil {
+			return e.marshal(_path(v.Interface(), strategy, s))
+		}
+		if strategy := s.typeStrategy(v); strategy != nil {
+			return e.marshal(_path(v.Interface(), strategy, s))
+		}
+		if strategy := s.fieldStrategy(); strategy != nil {
+			return e.marshal(_path(v.Interface(), strategy, s))
+		}
+		if strategy, _ := s.detect(v); strategy != nil {
+			return e.marshal(_path(v.Interface(), strategy, s))
+		}
+		if s.copiedForJSON(t) {
+			return e.copied(v)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return e.w.raw(jsonNull)
+		}
+		if e.encoding[v.Pointer()] {
+			if _, err := s.fail(t, fmt.Errorf("%w: unable to encode cycles", ErrUnsupportedKind)); err != nil {
+				return err
+			}
+			return e.w.raw(jsonNull)
+		}
+		if e.encoding == nil {
+			e.encoding = make(map[uintptr]bool)
+		}
+		e.encoding[v.Pointer()] = true
+		defer delete(e.encoding, v.Pointer())
+		return e.encode(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return e.w.raw(jsonNull)
+		}
+		return e.encode(v.Elem())
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return e.w.null(reflect.Map)
+		}
+		return e.encodeMap(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return e.w.null(reflect.Slice)
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			if s.truncates() {
+				v = s.truncated(v)
+			}
+			return e.marshal(v.Interface(), nil)
+		}
+		return e.encodeArray(v)
+	case reflect.Array:
+		return e.encodeArray(v)
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		if _, err := s.fail(t, fmt.Errorf("%w: %v", ErrUnsupportedKind, v.Kind())); err != nil {
+			return err
+		}
+		if s.opts.partial {
+			return e.w.str(UnsupportedValue{Kind: v.Kind()}.String())
+		}
+		return e.w.raw(jsonNull)
+	}
+	return e.primitive(v)
+}
+
+// primitive appends the encoding of the bool, number or string v.
+func (e *jsonEncoder) primitive(v reflect.Value) error {
+	scratch := e.scratch[:0]
+	switch v.Kind() {
+	case reflect.Bool:
+		return e.w.raw(strconv.AppendBool(scratch, v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.w.raw(strconv.AppendInt(scratch, v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.w.raw(strconv.AppendUint(scratch, v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		bits := v.Type().Bits()
+		f := v.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return &json.UnsupportedValueError{Value: v, Str: strconv.FormatFloat(f, 'g', -1, bits)}
+		}
+		// format floats like encoding/json does
+		format := byte('f')
+		if abs := math.Abs(f); abs != 0 {
+			if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+				format = 'e'
+			}
+		}
+		b := strconv.AppendFloat(scratch, f, format, -1, bits)
+		if format == 'e' {
+			// clean up e-09 to e-9
+			if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+				b[n-2] = b[n-1]
+				b = b[:n-1]
+			}
+		}
+		return e.w.raw(b)
+	case reflect.String:
+		if e.s.truncates() {
+			v = e.s.truncated(v)
+		}
+		return e.w.str(v.String())
+	}
+	return e.marshal(v.Interface(), nil)
+}
+
+// appendJSONString appends s as a JSON string to buf, escaped like encoding/json does including HTML characters.
+func appendJSONString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch b {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[b>>4])
+				buf.WriteByte(hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+func (e *jsonEncoder) encodeArray(v reflect.Value) error {
+	if err := e.w.delim('['); err != nil {
+		return err
+	}
+	n := v.Len()
+	if v.Kind() == reflect.Slice {
+		n = e.s.sliceLen(v)
+	}
+	for i := 0; i < n; i++ {
+		if e.s.countElement() {
+			if _, err := e.s.elementsExceeded(v.Type(), v); err != nil {
+				return err
+			}
+			break
+		}
+		e.s.pushIndex(i)
+		err := e.encode(v.Index(i))
+		e.s.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return e.w.delim(']')
+}
+
+func (e *jsonEncoder) encodeMap(v reflect.Value) error {
+	s := e.s
+	t := v.Type()
+	type entry struct {
+		key   string
+		value reflect.Value
+		raw   reflect.Value
+	}
+	entries := make(map[string]entry, v.Len())
+	exists := func(key string) bool {
+		_, ok := entries[key]
+		return ok
+	}
+	for _, me := range mapEntries(v, s.opts.sortMaps) {
+		if s.countElement() {
+			if _, err := s.elementsExceeded(t, v); err != nil {
+				return err
+			}
+			break
+		}
+		s.pushKey(me.key.Interface())
+		// like Mask, omitted entries collide with no other key
+		if s.omits(nil, me.value) {
+			s.pop()
+			continue
+		}
+		key, ok, err := e.mapKey(me.key, t, exists)
+		s.pop()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		entries[key] = entry{key: key, value: me.value, raw: me.key}
+	}
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if err := e.w.delim('{'); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := e.w.str(k); err != nil {
+			return err
+		}
+		s.pushKey(entries[k].raw.Interface())
+		err := e.encode(entries[k].value)
+		s.pop()
+		if err != nil {
+			return err
+		}
+	}
+	if err := e.encodeWatermark(t); err != nil {
+		return err
+	}
+	return e.w.delim('}')
+}
+
+// mapKey masks key like Mask does and formats it like encoding/json does. Keys equal
+// to a key encoded before, as reported by exists, are handled like Mask does, see
+// WithKeyCollision. The entry is dropped, i.e. false returned, if the key failed to be
+// masked and the error was collected.
+func (e *jsonEncoder) mapKey(key reflect.Value, t reflect.Type, exists func(string) bool) (string, bool, error) {
+	s := e.s
+	s.inKey = true
+	k, err := _anything(key.Interface(), s)
+	s.inKey = false
+	if err != nil {
+		return "", false, err
+	}
+	kv := reflect.ValueOf(k)
+	if !kv.IsValid() {
+		kv = reflect.Zero(t.Key())
+	}
+	if strategy, ok := s.opts.keyStrategies[t.Key()]; ok {
+		if kv, err = s.applyStrategy(strategy, kv); err != nil {
+			_, err = s.fail(t.Key(), err)
+			return "", false, err
+		}
+		s.masked(strategy.Name())
+	}
+	name, ok, err := e.keyName(kv, t)
+	if !ok || !exists(name) {
+		return name, ok, err
+	}
+	// only keys of kind string are renamed, which are encoded as they are
+	kv, err = s.collidingKey(kv, t, func(k reflect.Value) bool {
+		return exists(k.String())
+	})
+	if err != nil || !kv.IsValid() {
+		return "", false, err
+	}
+	return e.keyName(kv, t)
+}
+
+// keyName formats the masked key kv of a map of type t like encoding/json does.
+// False is returned if kv cannot be formatted and the error was collected.
+func (e *jsonEncoder) keyName(kv reflect.Value, t reflect.Type) (string, bool, error) {
+	if kv.Kind() == reflect.String {
+		return kv.String(), true, nil
+	}
+	if tm, ok := kv.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err == nil, err
+	}
+	switch kv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(kv.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(kv.Uint(), 10), true, nil
+	}
+	_, err := e.s.fail(t.Key(), fmt.Errorf("%w: unable to encode map keys of type %v", ErrUnsupportedKind, t.Key()))
+	return "", false, err
+}
+
+func (e *jsonEncoder) encodeStruct(v reflect.Value) error {
+	if err := e.w.delim('{'); err != nil {
+		return err
+	}
+	if err := e.encodeFields(v); err != nil {
+		return err
+	}
+	if err := e.encodeWatermark(v.Type()); err != nil {
+		return err
+	}
+	return e.w.delim('}')
+}
+
+// jsonField describes how a struct field is encoded.
+type jsonField struct {
+	// index is the index sequence of the field, fields of embedded structs
+	// are encoded in place of them.
+	index []int
+	field reflect.StructField
+	name  string
+	// tagged is set if the name is given by the json tag.
+	tagged    bool
+	omitEmpty bool
+	omitZero  bool
+	quoted    bool
+}
+
+// jsonFields caches the fields of all struct types encoded so far.
+var jsonFields sync.Map
+
+// jsonFieldsOf returns the fields of the struct type t encoded by encoding/json in
+// the order of their index sequences. Like encoding/json, fields of embedded structs
+// are promoted unless their name is dominated by another field: the shallowest field
+// wins, a tagged field beats an untagged one at the same depth, ambiguous names are
+// dropped.
+func jsonFieldsOf(t reflect.Type) []jsonField {
+	if fields, ok := jsonFields.Load(t); ok {
+		return fields.([]jsonField)
+	}
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+	var fields []jsonField
+	// walk the embedded structs breadth first, counting the embeddings of the same
+	// type at each depth, which makes their fields ambiguous
+	var current []embedded
+	next := []embedded{{t: t}}
+	var count, nextCount map[reflect.Type]int
+	visited := map[reflect.Type]bool{}
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+		for _, e := range current {
+			if visited[e.t] {
+				continue
+			}
+			visited[e.t] = true
+			for i := 0; i < e.t.NumField(); i++ {
+				f := e.t.Field(i)
+				ft := f.Type
+				if f.Anonymous {
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					// embedded pointers to unexported structs cannot be set, so they are ignored
+					if !f.IsExported() && (ft.Kind() != reflect.Struct || f.Type.Kind() == reflect.Ptr) {
+						continue
+					}
+				} else if !f.IsExported() {
+					continue
+				}
+				tag := f.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(append([]int(nil), e.index...), i)
+				if name == "" && f.Anonymous && ft.Kind() == reflect.Struct {
+					nextCount[ft]++
+					if nextCount[ft] == 1 {
+						next = append(next, embedded{t: ft, index: index})
+					}
+					continue
+				}
+				jf := jsonField{index: index, field: f, name: name, tagged: name != ""}
+				if jf.name == "" {
+					jf.name = f.Name
+				}
+				for opts != "" {
+					var opt string
+					opt, opts, _ = strings.Cut(opts, ",")
+					jf.omitEmpty = jf.omitEmpty || opt == "omitempty"
+					jf.omitZero = jf.omitZero || opt == "omitzero"
+					jf.quoted = jf.quoted || opt == "string"
+				}
+				fields = append(fields, jf)
+				if count[e.t] > 1 {
+					// the struct is embedded more than once at this depth, so its fields annihilate each other
+					fields = append(fields, jf)
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if len(a.index) != len(b.index) {
+			return len(a.index) < len(b.index)
+		}
+		if a.tagged != b.tagged {
+			return a.tagged
+		}
+		return indexLess(a.index, b.index)
+	})
+	dominant := fields[:0]
+	for i := 0; i < len(fields); {
+		n := 1
+		for i+n < len(fields) && fields[i+n].name == fields[i].name {
+			n++
+		}
+		// fields are sorted by depth and tags, so the first one dominates unless the second one is alike
+		if n == 1 || len(fields[i].index) != len(fields[i+1].index) || fields[i].tagged != fields[i+1].tagged {
+			dominant = append(dominant, fields[i])
+		}
+		i += n
+	}
+	fields = dominant
+	sort.Slice(fields, func(i, j int) bool {
+		return indexLess(fields[i].index, fields[j].index)
+	})
+	jsonFields.Store(t, fields)
+	return fields
+}
+
+// indexLess orders index sequences of struct fields by their position in the struct.
+func indexLess(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// copiedForJSON reports whether values of type t are encoded from their masked copy
+// rather than field by field: values masked by MaskXXX, values of types copied by a
+// type copier and values encoding themselves, unless registered by RegisterMarshalJSON.
+func (s *state) copiedForJSON(t reflect.Type) bool {
+	c := s.copier()
+	if _, ok := c.types.load(t); ok {
+		return true
+	}
+	if copied, ok := c.jsonCopied.Load(t); ok {
+		return copied.(bool)
+	}
+	_, copied, err := maskMethod(t)
+	if _, ok := c.typeCopier(t); ok || err != nil {
+		copied = true
+	}
+	pt := reflect.PointerTo(t)
+	if t.Implements(jsonMarshalerType) || pt.Implements(jsonMarshalerType) {
+		copied = copied || !derivesJSON(t)
+	} else {
+		copied = copied || t.Implements(textMarshalerType) || pt.Implements(textMarshalerType)
+	}
+	c.jsonCopied.Store(t, copied)
+	return copied
+}
+
+// encodeFields encodes the fields of the struct v, promoting fields of embedded structs
+// like encoding/json. Embedded structs masked by MaskXXX are encoded from their masked copy.
+func (e *jsonEncoder) encodeFields(v reflect.Value) error {
+	s := e.s
+	// copies holds the masked copies of embedded structs by their index sequence
+	var copies map[string]reflect.Value
+fields:
+	for _, f := range jsonFieldsOf(v.Type()) {
+		parent := v
+		depth := len(f.index) - 1
+		for j, i := range f.index[:depth] {
+			ef := parent.Type().Field(i)
+			parent = parent.Field(i)
+			if parent.Kind() == reflect.Ptr {
+				if parent.IsNil() {
+					s.popN(j)
+					continue fields
+				}
+				parent = parent.Elem()
+			}
+			s.pushStructField(ef)
+			if s.copiedForJSON(parent.Type()) {
+				key := fmt.Sprint(f.index[:j+1])
+				copied, ok := copies[key]
+				if !ok {
+					c, err := _anything(parent.Interface(), s)
+					if err != nil {
+						s.popN(j + 1)
+						return err
+					}
+					if copied = reflect.Indirect(reflect.ValueOf(c)); copied.Kind() != reflect.Struct {
+						copied = reflect.Value{}
+					}
+					if copies == nil {
+						copies = make(map[string]reflect.Value)
+					}
+					copies[key] = copied
+				}
+				if !copied.IsValid() {
+					s.popN(j + 1)
+					continue fields
+				}
+				parent = copied
+			}
+		}
+		err := e.encodeFieldOf(f, parent)
+		s.popN(depth)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeFieldOf encodes the name and value of the field f of the struct v unless it is omitted.
+func (e *jsonEncoder) encodeFieldOf(f jsonField, v reflect.Value) error {
+	s := e.s
+	fv := v.Field(f.index[len(f.index)-1])
+	if f.omitEmpty && isEmptyValue(fv) || f.omitZero && fv.IsZero() {
+		return nil
+	}
+	s.pushStructField(f.field)
+	defer s.pop()
+	if tag, _ := s.tagStrategy(f.field, v); s.omits(tag, fv) {
+		return nil
+	}
+	if err := e.w.str(f.name); err != nil {
+		return err
+	}
+	return e.encodeField(f.field, v, fv, f.quoted)
+}
+
+func (e *jsonEncoder) encodeField(f reflect.StructField, parent, v reflect.Value, quoted bool) error {
+	if quoted {
+		switch f.Type.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64, reflect.String:
+			// encode the value on its own and write its encoding as string
+			w := e.w
+			tmp := &bufWriter{}
+			e.w = tmp
+			err := e.encodeField(f, parent, v, false)
+			e.w = w
+			if err != nil {
+				return err
+			}
+			return e.w.str(tmp.buf.String())
+		}
+	}
+
+	s := e.s
+	strategy, err := s.tagStrategy(f, parent)
+	if err == nil {
+		err = s.cleared(f, strategy, v)
+	}
+	if err != nil {
+		return e.marshal(s.fail(f.Type, err))
+	}
+	if strategy == nil {
+		return e.encode(v)
+	}
+	switch hook := s.hook(v); hook.Action {
+	case HookReplace, HookMask:
+		return e.marshal(s.hooked(hook, v))
+	case HookKeep:
+		s.decide(hook, func() {
+			err = e.encode(v)
+		})
+		return err
+	}
+	masked, err := s.applyStrategy(strategy, v)
+	if err != nil {
+		return e.marshal(s.fail(f.Type, err))
+	}
+	s.masked(strategy.Name())
+	return e.marshal(masked.Interface(), nil)
+}
+
+// isEmptyValue reports whether v is omitted by encoding/json using omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
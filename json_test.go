@@ -0,0 +1,356 @@
+package mask
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// JSONBase is exported as Mask skips unexported embedded structs.
+type JSONBase struct {
+	ID int `json:"id"`
+}
+
+type testJSON struct {
+	JSONBase
+	Person   testPerson         `json:"person"`
+	People   []*testPerson      `json:"people,omitempty"`
+	Empty    []string           `json:"empty,omitempty"`
+	Emails   map[testEmail]int  `json:"emails"`
+	Counts   map[int]TestString `json:"counts"`
+	Secret   Secret[string]     `json:"secret"`
+	Created  time.Time          `json:"created"`
+	Count    int                `json:"count,string"`
+	Ignored  string             `json:"-"`
+	Raw      []byte
+	Any      interface{}
+	internal string
+}
+
+func newTestJSON() *testJSON {
+	return &testJSON{
+		JSONBase: JSONBase{ID: 7},
+		Person:   testPerson{Name: "Ada Lovelace", Email: "ada@example.com"},
+		People:   []*testPerson{{Name: "Charles Babbage"}, nil},
+		Emails:   map[testEmail]int{"ada@example.com": 1, "bob@example.com": 2},
+		Counts:   map[int]TestString{2: "two", 1: "one"},
+		Secret:   NewSecret("hunter2"),
+		Created:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Count:    42,
+		Ignored:  "ignored",
+		Raw:      []byte("raw"),
+		Any:      map[string]interface{}{"nested": []interface{}{TestString("secret"), 1.5}},
+		internal: "internal",
+	}
+}
+
+func TestJSON(t *testing.T) {
+	val := newTestJSON()
+	for _, opts := range [][]Option{
+		nil,
+		{WithPathStrategy("Person.Email", redactTestStrategy(t)), WithMapKeyMasking[testEmail](redactTestStrategy(t)), WithSortedMaps()},
+	} {
+		expect, err := json.Marshal(Must(val, opts...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(JSON(val, opts...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, expect) {
+			t.Errorf("expect %s == %s", got, expect)
+		}
+	}
+	if val.Person.Name != "Ada Lovelace" {
+		t.Errorf("expect the original to stay untouched")
+	}
+}
+
+func TestJSONKeyCollision(t *testing.T) {
+	val := map[TestString]int{"a": 1, "b": 2, "c": 3}
+	for _, tc := range []struct {
+		collision KeyCollision
+		expect    string
+	}{
+		{KeyCollisionOverwrite, `{"MASKED":3}`},
+		{KeyCollisionSuffix, `{"MASKED":1,"MASKED#2":2,"MASKED#3":3}`},
+		{KeyCollisionError, ""},
+	} {
+		opts := []Option{WithSortedMaps(), WithKeyCollision(tc.collision)}
+		got, err := MarshalJSON(val, opts...)
+		if tc.expect == "" {
+			if !errors.Is(err, ErrKeyCollision) {
+				t.Errorf("expect %v to be %v", err, ErrKeyCollision)
+			}
+			if _, err := Mask(val, opts...); !errors.Is(err, ErrKeyCollision) {
+				t.Errorf("expect %v to be %v", err, ErrKeyCollision)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect, err := json.Marshal(Must(val, opts...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.expect || !bytes.Equal(got, expect) {
+			t.Errorf("expect %s == %s == %s", got, expect, tc.expect)
+		}
+	}
+}
+
+func redactTestStrategy(t *testing.T) Strategy {
+	t.Helper()
+	s, err := ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestJSONErrors(t *testing.T) {
+	_, err := json.Marshal(JSON(testOrderItem{Callback: func() {}}))
+	if !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expect %v to be ErrUnsupportedKind", err)
+	}
+	b, err := json.Marshal(JSON(testOrderItem{Name: "x", Callback: func() {}}, WithCollectErrors()))
+	var maskErr *MaskError
+	if !errors.As(err, &maskErr) || b != nil {
+		t.Errorf("expect %v to be a MaskError", err)
+	}
+
+	node := &testNode{}
+	node.Next = node
+	if _, err := json.Marshal(JSON(node)); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expect cycles to fail, got %v", err)
+	}
+}
+
+type JSONShadowA struct {
+	ID    int
+	Name  string
+	Email string `mask:"redact"`
+	Phone string
+}
+
+type JSONShadowB struct {
+	Name  string
+	Email string `json:"Email"`
+	Phone string `json:"Phone"`
+}
+
+type JSONShadowC struct {
+	Phone string `json:"Phone"`
+}
+
+type testJSONShadowed struct {
+	JSONShadowA
+	*JSONShadowB
+	*JSONShadowC
+	ID int `mask:"redact"`
+}
+
+func TestJSONShadowed(t *testing.T) {
+	for _, val := range []testJSONShadowed{
+		{JSONShadowA: JSONShadowA{ID: 1, Name: "Ada", Email: "ada@example.com", Phone: "1"}, ID: 2},
+		{JSONShadowA: JSONShadowA{ID: 1, Name: "Ada"}, JSONShadowB: &JSONShadowB{Name: "Bob", Email: "bob@example.com", Phone: "2"}, ID: 2},
+		{JSONShadowB: &JSONShadowB{Phone: "2"}, JSONShadowC: &JSONShadowC{Phone: "3"}},
+	} {
+		expect, err := json.Marshal(Must(val))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(JSON(val))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, expect) {
+			t.Errorf("expect %s == %s", got, expect)
+		}
+
+		expect, err = json.Marshal(val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err = json.Marshal(JSON(val, WithTagKeys("unmasked")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, expect) {
+			t.Errorf("expect %s == %s", got, expect)
+		}
+	}
+}
+
+type testJSONRecord struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	Email  string   `json:"email" mask:"redact"`
+	Tags   []string `json:"tags"`
+	Score  float64  `json:"score"`
+	Active bool     `json:"active"`
+}
+
+func init() {
+	RegisterMarshalJSON[testJSONTagged]()
+	RegisterMarshalJSON[*testJSONTaggedPtr]()
+	RegisterMarshalJSON[testJSONHashed]()
+	RegisterMarshalJSON[testJSONHashedPtr]()
+}
+
+type testJSONTagged struct {
+	Name    string            `json:"name"`
+	Email   string            `json:"email" mask:"partial=1:0"`
+	Friends []*testJSONTagged `json:"friends,omitempty"`
+}
+
+func (t testJSONTagged) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+type testJSONTaggedPtr struct {
+	Token string `json:"token" mask:"redact"`
+}
+
+func (t *testJSONTaggedPtr) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+type testJSONHashed struct {
+	Email   string           `json:"email" mask:"hash"`
+	Friends []testJSONHashed `json:"friends,omitempty"`
+}
+
+func (t testJSONHashed) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+type testJSONHashedPtr struct {
+	Email string `json:"email" mask:"hash"`
+}
+
+func (t *testJSONHashedPtr) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+func TestMarshalJSONNested(t *testing.T) {
+	val := testJSONHashed{Email: "ada@example.com", Friends: []testJSONHashed{{Email: "ada@example.com"}}}
+	var got struct {
+		Email   string `json:"email"`
+		Friends []struct {
+			Email string `json:"email"`
+		} `json:"friends"`
+	}
+	for _, encode := range []func() ([]byte, error){
+		func() ([]byte, error) { return json.Marshal(val) },
+		func() ([]byte, error) { return MarshalJSON(val) },
+		func() ([]byte, error) { return JSON(val).MarshalJSON() },
+		func() ([]byte, error) { return JSON([]testJSONHashed{val}).MarshalJSON() },
+	} {
+		b, err := encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b[0] == '[' {
+			b = b[1 : len(b)-1]
+		}
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Email == val.Email || len(got.Friends) != 1 || got.Friends[0].Email != got.Email {
+			t.Errorf("expect the email to be masked once alike, got %s", b)
+		}
+	}
+
+	ptr := map[string]*testJSONHashedPtr{"a": {Email: "ada@example.com"}}
+	a, err := JSON(ptr).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(ptr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(a) != string(b) {
+		t.Errorf("expect %s == %s", a, b)
+	}
+}
+
+type testJSONCounted struct {
+	Email string `json:"email" mask:"partial=1:0"`
+}
+
+// testJSONCountedCalls counts the calls of testJSONCounted.MarshalJSON.
+var testJSONCountedCalls int
+
+func (t testJSONCounted) MarshalJSON() ([]byte, error) {
+	testJSONCountedCalls++
+	return json.Marshal(map[string]string{"email": t.Email})
+}
+
+func TestMarshalJSONUnregistered(t *testing.T) {
+	testJSONCountedCalls = 0
+	got, err := JSON([]testJSONCounted{{Email: "ada@example.com"}}).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// unregistered types encode their masked copy, without being called otherwise
+	if expect := `[{"email":"a**************"}]`; string(got) != expect || testJSONCountedCalls != 1 {
+		t.Errorf("expect %s == %s encoded by %d == 1 call", got, expect, testJSONCountedCalls)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	val := testJSONTagged{Name: "Ada", Email: "ada@example.com", Friends: []*testJSONTagged{{Name: "Bob", Email: "bob@example.com"}}}
+	got, err := json.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"name":"Ada","email":"a**************","friends":[{"name":"Bob","email":"b**************"}]}`
+	if string(got) != expect {
+		t.Errorf("expect %s == %s", got, expect)
+	}
+
+	got, err = json.Marshal(map[string]*testJSONTaggedPtr{"a": {Token: "tok_123"}, "b": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := `{"a":{"token":"MASKED"},"b":null}`; string(got) != expect {
+		t.Errorf("expect %s == %s", got, expect)
+	}
+
+	got, err = MarshalJSON([]string{"ada@example.com"}, WithPathStrategy("[*]", redactTestStrategy(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := `["MASKED"]`; string(got) != expect {
+		t.Errorf("expect %s == %s", got, expect)
+	}
+}
+
+func BenchmarkJSON(b *testing.B) {
+	val := make([]testJSONRecord, 100)
+	for i := range val {
+		val[i] = testJSONRecord{ID: i, Name: "Ada", Email: "ada@example.com", Tags: []string{"a", "b"}, Score: 1.5, Active: true}
+	}
+	b.Run("Mask", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(Must(val)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("JSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(JSON(val)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
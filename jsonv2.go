@@ -0,0 +1,82 @@
+//go:build go1.27
+
+package mask
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"reflect"
+	"time"
+)
+
+// MarshalJSONTo streams the masked form of x to enc, masking values as their tokens
+// are written. It is used by encoding/json/v2 in favor of MarshalJSON, so large
+// payloads are neither copied nor buffered:
+//
+//	json.MarshalWrite(w, mask.JSON(response))
+func (j jsonMarshaler) MarshalJSONTo(enc *jsontext.Encoder) (err error) {
+	s := newState(j.x, j.opts)
+	defer s.release()
+	if s.opts.metrics != nil {
+		defer s.observe("json", time.Now(), &err)
+	}
+	if s.opts.tracer != nil {
+		defer s.endSpan(s.startSpan("json", j.x), &err)
+	}
+	if s.opts.err != nil {
+		return s.opts.err
+	}
+	return j.encode(s, &jsontextWriter{enc: enc})
+}
+
+// jsontextWriter writes tokens to a jsontext.Encoder.
+type jsontextWriter struct {
+	enc *jsontext.Encoder
+}
+
+func (w *jsontextWriter) delim(c byte) error {
+	var t jsontext.Token
+	switch c {
+	case '{':
+		t = jsontext.BeginObject
+	case '}':
+		t = jsontext.EndObject
+	case '[':
+		t = jsontext.BeginArray
+	default:
+		t = jsontext.EndArray
+	}
+	return w.enc.WriteToken(t)
+}
+
+func (w *jsontextWriter) str(s string) error {
+	return w.enc.WriteToken(jsontext.String(s))
+}
+
+func (w *jsontextWriter) raw(b []byte) error {
+	return w.enc.WriteValue(jsontext.Value(b))
+}
+
+// null writes nil maps and slices as {} and [] unless the encoder is configured
+// to write them as null, like encoding/json/v2 does.
+func (w *jsontextWriter) null(k reflect.Kind) error {
+	switch {
+	case k == reflect.Map && !jsonOption(w.enc, jsonv2.FormatNilMapAsNull):
+		return w.raw([]byte("{}"))
+	case k == reflect.Slice && !jsonOption(w.enc, jsonv2.FormatNilSliceAsNull):
+		return w.raw([]byte("[]"))
+	}
+	return w.enc.WriteToken(jsontext.Null)
+}
+
+func jsonOption(enc *jsontext.Encoder, option func(bool) jsonv2.Options) bool {
+	v, _ := jsonv2.GetOption(enc.Options(), option)
+	return v
+}
+
+func (w *jsontextWriter) value(x interface{}) error {
+	if x == nil {
+		return w.enc.WriteToken(jsontext.Null)
+	}
+	return jsonv2.MarshalEncode(w.enc, x)
+}
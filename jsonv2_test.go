@@ -0,0 +1,35 @@
+//go:build go1.27
+
+package mask
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"testing"
+)
+
+func TestJSONv2(t *testing.T) {
+	val := newTestJSON()
+	val.Empty = nil
+	val.Emails = nil
+	for _, opts := range []jsonv2.Options{
+		nil,
+		jsonv2.JoinOptions(jsonv2.FormatNilMapAsNull(true), jsonv2.FormatNilSliceAsNull(true)),
+		jsontext.Multiline(true),
+	} {
+		// maps are always encoded sorted by their keys
+		opts = jsonv2.JoinOptions(opts, jsonv2.Deterministic(true))
+		expect, err := jsonv2.Marshal(Must(val), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got bytes.Buffer
+		if err := jsonv2.MarshalWrite(&got, JSON(val), opts); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Bytes(), expect) {
+			t.Errorf("expect %s == %s", got.Bytes(), expect)
+		}
+	}
+}
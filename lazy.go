@@ -0,0 +1,42 @@
+package mask
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Lazy returns a function masking x on its first call, caching the result.
+// If x cannot be masked, the zero value of T is returned so nothing leaks.
+func Lazy[T any](x T, opts ...Option) func() T {
+	return sync.OnceValue(func() T {
+		masked, err := Mask(x, opts...)
+		if err != nil {
+			var zero T
+			return zero
+		}
+		return masked
+	})
+}
+
+type logValuer struct {
+	x    interface{}
+	opts []Option
+}
+
+// LogValue returns a slog.LogValuer masking x when a handler resolves it, which
+// happens only if the record is actually logged:
+//
+//	slog.Debug("order placed", "order", mask.LogValue(order))
+//
+// If x cannot be masked, the error is logged instead of x.
+func LogValue(x interface{}, opts ...Option) slog.LogValuer {
+	return logValuer{x: x, opts: opts}
+}
+
+func (l logValuer) LogValue() slog.Value {
+	masked, err := Mask(l.x, l.opts...)
+	if err != nil {
+		return slog.StringValue("!MASK ERROR: " + err.Error())
+	}
+	return slog.AnyValue(masked)
+}
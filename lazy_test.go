@@ -0,0 +1,61 @@
+package mask
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type testCountingPerson struct {
+	Name TestString
+}
+
+var testMaskCalls int
+
+func (p testCountingPerson) MaskXXX() testCountingPerson {
+	testMaskCalls++
+	return testCountingPerson{Name: "MASKED"}
+}
+
+func TestLazy(t *testing.T) {
+	testMaskCalls = 0
+	masked := Lazy(testCountingPerson{Name: "Ada"})
+	if testMaskCalls != 0 {
+		t.Errorf("expect masking to be deferred")
+	}
+	if p := masked(); p.Name != "MASKED" {
+		t.Errorf("expect %v == MASKED", p.Name)
+	}
+	masked()
+	if testMaskCalls != 1 {
+		t.Errorf("expect %d == 1 calls", testMaskCalls)
+	}
+
+	if item := Lazy(testOrderItem{Name: "first", Callback: func() {}})(); item.Name != "" {
+		t.Errorf("expect the zero value on errors, got %v", item)
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	testMaskCalls = 0
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	logger.Debug("suppressed", "person", LogValue(testCountingPerson{Name: "Ada"}))
+	if testMaskCalls != 0 || buf.Len() != 0 {
+		t.Errorf("expect suppressed records not to be masked")
+	}
+
+	logger.Info("logged", "person", LogValue(testCountingPerson{Name: "Ada"}))
+	if testMaskCalls != 1 || !strings.Contains(buf.String(), "person={Name:MASKED}") {
+		t.Errorf("expect %q to contain the masked person", buf.String())
+	}
+
+	buf.Reset()
+	logger.Log(context.Background(), slog.LevelInfo, "failed", "item", LogValue(testOrderItem{Callback: func() {}}))
+	if !strings.Contains(buf.String(), "!MASK ERROR") {
+		t.Errorf("expect %q to contain the error", buf.String())
+	}
+}
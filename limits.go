@@ -0,0 +1,68 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// exceedsDepth reports whether the value currently visited is nested deeper than allowed.
+func (s *state) exceedsDepth() bool {
+	return s.opts.maxDepth > 0 && len(s.path) > s.opts.maxDepth
+}
+
+// countElement counts a single slice, array or map element towards
+// WithMaxElements and reports whether the limit has been exceeded.
+func (s *state) countElement() bool {
+	if s.opts.maxElements <= 0 {
+		return false
+	}
+	s.elements++
+	return s.elements > s.opts.maxElements
+}
+
+// depthExceeded handles a value of type t nested too deeply.
+func (s *state) depthExceeded(t reflect.Type) (interface{}, error) {
+	if s.opts.truncateLimits {
+		return reflect.Zero(t).Interface(), nil
+	}
+	return s.fail(t, fmt.Errorf("%w: limit is %d", ErrMaxDepth, s.opts.maxDepth))
+}
+
+// elementsExceeded handles a collection of type t exceeding WithMaxElements.
+// truncated is the collection holding all elements copied so far.
+func (s *state) elementsExceeded(t reflect.Type, truncated reflect.Value) (interface{}, error) {
+	if s.opts.truncateLimits {
+		return truncated.Interface(), nil
+	}
+	return s.fail(t, fmt.Errorf("%w: limit is %d", ErrMaxElements, s.opts.maxElements))
+}
+
+// truncates reports whether strings are truncated, see WithMaxStringLen.
+func (s *state) truncates() bool {
+	return s.opts.maxStringLen > 0 && !s.inKey
+}
+
+// truncated truncates the string or byte slice v, see WithMaxStringLen.
+func (s *state) truncated(v reflect.Value) reflect.Value {
+	if v.Len() <= s.opts.maxStringLen {
+		return v
+	}
+	out, err := s.opts.truncate.Mask(v)
+	if err != nil {
+		return v
+	}
+	return out.Convert(v.Type())
+}
+
+// sliceLen returns the number of elements of the slice v kept in the masked copy.
+func (s *state) sliceLen(v reflect.Value) int {
+	n := v.Len()
+	max := s.opts.maxSliceLen
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		max = s.opts.maxStringLen
+	}
+	if max > 0 && n > max {
+		return max
+	}
+	return n
+}
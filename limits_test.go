@@ -0,0 +1,77 @@
+package mask
+
+import (
+	"errors"
+	"testing"
+)
+
+type testNode struct {
+	Name TestString
+	Next *testNode
+}
+
+func newTestList(n int) *testNode {
+	var head *testNode
+	for i := 0; i < n; i++ {
+		head = &testNode{Name: "node", Next: head}
+	}
+	return head
+}
+
+func TestMaxDepth(t *testing.T) {
+	list := newTestList(10)
+
+	if _, err := Mask(list, WithMaxDepth(20)); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	_, err := Mask(list, WithMaxDepth(5))
+	if !errors.Is(err, ErrMaxDepth) {
+		t.Fatalf("expected %v to be ErrMaxDepth", err)
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "testNode.Next.Next.Next.Next.Next.Name" {
+		t.Errorf("expected the error to locate the value, got %v", err)
+	}
+
+	masked := Must(list, WithMaxDepth(5), WithTruncateLimits())
+	depth := 0
+	for n := masked; n != nil; n = n.Next {
+		depth++
+		if depth > 10 {
+			t.Fatalf("expected list to be truncated")
+		}
+	}
+	// the 6th node is at depth 5, its fields are beyond the limit
+	if depth != 6 {
+		t.Errorf("expect %v == 6", depth)
+	}
+	if masked.Name != "MASKED" {
+		t.Errorf("expect %v == MASKED", masked.Name)
+	}
+}
+
+func TestMaxElements(t *testing.T) {
+	val := struct {
+		A []TestString
+		B map[string]int
+	}{
+		A: []TestString{"1", "2", "3"},
+		B: map[string]int{"a": 1, "b": 2},
+	}
+
+	if _, err := Mask(val, WithMaxElements(5)); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, err := Mask(val, WithMaxElements(4)); !errors.Is(err, ErrMaxElements) {
+		t.Errorf("expected %v to be ErrMaxElements", err)
+	}
+
+	masked := Must(val, WithMaxElements(2), WithTruncateLimits())
+	if len(masked.A) != 2 || masked.A[1] != "MASKED" {
+		t.Errorf("expect %v to be truncated to 2 masked elements", masked.A)
+	}
+	if len(masked.B) != 0 {
+		t.Errorf("expect %v to be empty", masked.B)
+	}
+}
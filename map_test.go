@@ -0,0 +1,36 @@
+package mask
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNilMap(t *testing.T) {
+	val := struct {
+		Nil   map[string]string
+		Empty map[string]string
+	}{nil, map[string]string{}}
+
+	masked := Must(val)
+	if masked.Nil != nil {
+		t.Errorf("expect %v to be nil", masked.Nil)
+	}
+	if masked.Empty == nil {
+		t.Errorf("expect %v not to be nil", masked.Empty)
+	}
+
+	a, _ := json.Marshal(val)
+	b, _ := json.Marshal(masked)
+	if string(a) != string(b) {
+		t.Errorf("expect %s == %s", b, a)
+	}
+}
+
+func TestMapNilInterfaceValue(t *testing.T) {
+	val := map[string]interface{}{"a": nil}
+
+	masked := Must(val)
+	if v, ok := masked["a"]; !ok || v != nil {
+		t.Errorf("expect key a to be kept with a nil value, got %v", masked)
+	}
+}
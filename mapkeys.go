@@ -0,0 +1,93 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// KeyCollision defines how distinct map keys are handled which are equal after masking,
+// e.g. because their MaskXXX method returns a constant.
+type KeyCollision int
+
+const (
+	// KeyCollisionOverwrite keeps whichever entry is copied last.
+	KeyCollisionOverwrite KeyCollision = iota
+	// KeyCollisionError fails with ErrKeyCollision.
+	KeyCollisionError
+	// KeyCollisionSuffix renames colliding keys by appending #2, #3, ... to them.
+	// It is only supported for keys of kind string and fails with ErrKeyCollision otherwise.
+	KeyCollisionSuffix
+)
+
+// WithMapKeyMasking masks the keys of all maps with keys of type K using strategy,
+// e.g. to mask maps indexed by email addresses. Keys are masked after their
+// MaskXXX method, if any, has been applied.
+func WithMapKeyMasking[K comparable](strategy Strategy) Option {
+	t := reflect.TypeOf((*K)(nil)).Elem()
+	return func(o *options) {
+		if o.keyStrategies == nil {
+			o.keyStrategies = make(map[reflect.Type]Strategy)
+		}
+		o.keyStrategies[t] = strategy
+	}
+}
+
+// WithKeyCollision defines how masked map keys colliding with each other are handled.
+// Defaults to KeyCollisionOverwrite.
+func WithKeyCollision(c KeyCollision) Option {
+	return func(o *options) {
+		o.keyCollision = c
+	}
+}
+
+// _key copies and masks the key of a map of type t which is copied to dc.
+// An invalid value is returned if the key failed to be masked and the error
+// was collected; the entry is dropped in that case.
+func _key(key reflect.Value, t reflect.Type, dc reflect.Value, s *state) (reflect.Value, error) {
+	s.inKey = true
+	k, err := _anything(key.Interface(), s)
+	s.inKey = false
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	kv := held(k, t.Key())
+	if !kv.IsValid() {
+		kv = reflect.Zero(t.Key())
+	}
+	if strategy, ok := s.opts.keyStrategies[t.Key()]; ok {
+		if kv, err = s.applyStrategy(strategy, kv); err != nil {
+			_, err = s.fail(t.Key(), err)
+			return reflect.Value{}, err
+		}
+		s.masked(strategy.Name())
+	}
+	if !dc.MapIndex(kv).IsValid() {
+		return kv, nil
+	}
+	return s.collidingKey(kv, t, func(k reflect.Value) bool {
+		return dc.MapIndex(k).IsValid()
+	})
+}
+
+// collidingKey handles the masked key kv of a map of type t which equals a key copied
+// before, see WithKeyCollision. exists reports whether a key was copied before.
+func (s *state) collidingKey(kv reflect.Value, t reflect.Type, exists func(reflect.Value) bool) (reflect.Value, error) {
+	var err error
+	switch s.opts.keyCollision {
+	case KeyCollisionError:
+		_, err = s.fail(t.Key(), fmt.Errorf("%w: %v", ErrKeyCollision, kv))
+		return reflect.Value{}, err
+	case KeyCollisionSuffix:
+		if kv.Kind() != reflect.String {
+			_, err = s.fail(t.Key(), fmt.Errorf("%w: unable to rename %v (a %v)", ErrKeyCollision, kv, kv.Kind()))
+			return reflect.Value{}, err
+		}
+		for i := 2; ; i++ {
+			renamed := reflect.ValueOf(fmt.Sprintf("%s#%d", kv.String(), i)).Convert(kv.Type())
+			if !exists(renamed) {
+				return renamed, nil
+			}
+		}
+	}
+	return kv, nil
+}
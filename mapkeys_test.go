@@ -0,0 +1,64 @@
+package mask
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+type testEmail string
+
+func TestMapKeyMasking(t *testing.T) {
+	val := map[testEmail]int{
+		"ada@example.com":     1,
+		"charles@example.com": 2,
+	}
+	plain := map[string]int{"ada@example.com": 1}
+
+	opt := WithMapKeyMasking[testEmail](maskers.Partial(1, 12, maskers.Format{}))
+	masked := Must(val, opt)
+	if masked["a**@example.com"] != 1 || masked["c******@example.com"] != 2 {
+		t.Errorf("expect keys to be masked, got %v", masked)
+	}
+	if _, ok := val["ada@example.com"]; !ok {
+		t.Errorf("expect the original to stay untouched")
+	}
+	if m := Must(plain, opt); m["ada@example.com"] != 1 {
+		t.Errorf("expect keys of other types to stay untouched, got %v", m)
+	}
+}
+
+func TestMapKeyCollision(t *testing.T) {
+	val := map[TestString]int{"a": 1, "b": 2, "c": 3}
+
+	if masked := Must(val); len(masked) != 1 {
+		t.Errorf("expect colliding keys to be overwritten by default, got %v", masked)
+	}
+
+	_, err := Mask(val, WithKeyCollision(KeyCollisionError))
+	if !errors.Is(err, ErrKeyCollision) {
+		t.Errorf("expect %v to be ErrKeyCollision", err)
+	}
+
+	masked := Must(val, WithKeyCollision(KeyCollisionSuffix))
+	if len(masked) != 3 {
+		t.Fatalf("expect all entries to be kept, got %v", masked)
+	}
+	sum := 0
+	for k, v := range masked {
+		if !strings.HasPrefix(string(k), "MASKED") {
+			t.Errorf("expect %v to start with MASKED", k)
+		}
+		sum += v
+	}
+	if _, ok := masked["MASKED#3"]; !ok || sum != 6 {
+		t.Errorf("expect keys to be renamed, got %v", masked)
+	}
+
+	ints := map[testInt]int{1: 1, 2: 2}
+	if _, err := Mask(ints, WithKeyCollision(KeyCollisionSuffix)); !errors.Is(err, ErrKeyCollision) {
+		t.Errorf("expect %v to be ErrKeyCollision", err)
+	}
+}
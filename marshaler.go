@@ -0,0 +1,96 @@
+package mask
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// WithMarshalerCopies copies structs holding unexported fields by a round trip through
+// their encoding.BinaryMarshaler and BinaryUnmarshaler, or TextMarshaler and TextUnmarshaler
+// methods, instead of field by field. Copying field by field drops unexported fields, which
+// breaks types keeping invariants in them, like decimal.Decimal; a round trip keeps them
+// without sharing any memory with the original.
+//
+// Copiers registered using Copier.Register and the built-in ones, e.g. for time.Time,
+// take precedence. Copies are still masked by their MaskXXX method.
+func WithMarshalerCopies() Option {
+	return func(o *options) {
+		o.marshalerCopies = true
+	}
+}
+
+var (
+	binaryMarshalerType   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// marshalerCopiers caches the copier of WithMarshalerCopies by type, nil if there is none.
+var marshalerCopiers sync.Map
+
+// marshalerCopier returns the copier of WithMarshalerCopies for values of type t, if any.
+func (s *state) marshalerCopier(t reflect.Type) (copier, bool) {
+	if !s.opts.marshalerCopies || t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	if c, ok := marshalerCopiers.Load(t); ok {
+		c := c.(copier)
+		return c, c != nil
+	}
+	var c copier
+	if hasUnexportedField(t) {
+		p := reflect.PointerTo(t)
+		switch {
+		case p.Implements(binaryMarshalerType) && p.Implements(binaryUnmarshalerType):
+			c = _binaryMarshaled
+		case p.Implements(textMarshalerType) && p.Implements(textUnmarshalerType):
+			c = _textMarshaled
+		}
+	}
+	marshalerCopiers.Store(t, c)
+	return c, c != nil
+}
+
+func hasUnexportedField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func _binaryMarshaled(x interface{}, s *state) (interface{}, error) {
+	in, out := marshalerPair(x)
+	b, err := in.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+	if err == nil {
+		err = out.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("copy %T by marshaling: %w", x, err)
+	}
+	return out.Elem().Interface(), nil
+}
+
+func _textMarshaled(x interface{}, s *state) (interface{}, error) {
+	in, out := marshalerPair(x)
+	b, err := in.Interface().(encoding.TextMarshaler).MarshalText()
+	if err == nil {
+		err = out.Interface().(encoding.TextUnmarshaler).UnmarshalText(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("copy %T by marshaling: %w", x, err)
+	}
+	return out.Elem().Interface(), nil
+}
+
+// marshalerPair returns a pointer to a copy of x, to call marshal methods with pointer
+// receivers on, and a pointer to a new value to unmarshal into.
+func marshalerPair(x interface{}) (in, out reflect.Value) {
+	v := reflect.ValueOf(x)
+	in = reflect.New(v.Type())
+	in.Elem().Set(v)
+	return in, reflect.New(v.Type())
+}
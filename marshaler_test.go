@@ -0,0 +1,72 @@
+package mask
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// testDecimal keeps its invariants in unexported fields like decimal.Decimal.
+type testDecimal struct {
+	value *big.Int
+	exp   int32
+}
+
+func (d testDecimal) MarshalText() ([]byte, error) {
+	return []byte(d.value.String() + "e" + big.NewInt(int64(d.exp)).String()), nil
+}
+
+func (d *testDecimal) UnmarshalText(b []byte) error {
+	value, exp, ok := strings.Cut(string(b), "e")
+	v, ok1 := new(big.Int).SetString(value, 10)
+	e, ok2 := new(big.Int).SetString(exp, 10)
+	if !ok || !ok1 || !ok2 {
+		return errors.New("invalid decimal")
+	}
+	d.value, d.exp = v, int32(e.Int64())
+	return nil
+}
+
+// testBinaryID prefers its binary encoding.
+type testBinaryID struct {
+	id []byte
+}
+
+func (i testBinaryID) MarshalBinary() ([]byte, error) {
+	return i.id, nil
+}
+
+func (i *testBinaryID) UnmarshalBinary(b []byte) error {
+	i.id = append([]byte(nil), b...)
+	return nil
+}
+
+func (i testBinaryID) MarshalText() ([]byte, error) {
+	return nil, errors.New("not used")
+}
+
+func (i *testBinaryID) UnmarshalText([]byte) error {
+	return errors.New("not used")
+}
+
+func TestWithMarshalerCopies(t *testing.T) {
+	type payment struct {
+		Amount testDecimal
+		ID     *testBinaryID
+	}
+	val := payment{Amount: testDecimal{big.NewInt(1995), -2}, ID: &testBinaryID{[]byte{1, 2}}}
+
+	out := Must(val)
+	if out.Amount.value != nil || out.ID.id != nil {
+		t.Errorf("expect unexported fields to be dropped by default, got %+v", out)
+	}
+
+	out = Must(val, WithMarshalerCopies())
+	if out.Amount.value.Cmp(val.Amount.value) != 0 || out.Amount.exp != -2 || out.Amount.value == val.Amount.value {
+		t.Errorf("expect %+v to be a copy of %+v", out.Amount, val.Amount)
+	}
+	if string(out.ID.id) != "\x01\x02" || &out.ID.id[0] == &val.ID.id[0] {
+		t.Errorf("expect %v to be a copy of %v", out.ID.id, val.ID.id)
+	}
+}
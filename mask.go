@@ -3,9 +3,45 @@ package mask
 import (
 	"fmt"
 	"reflect"
+	"runtime"
+	"sync"
+	"time"
 )
 
-type copier func(interface{}, map[uintptr]interface{}) (interface{}, error)
+type copier func(interface{}, *state) (interface{}, error)
+
+// state is shared by all copiers during a single call to Mask.
+type state struct {
+	// ptrs maps the addresses of already copied pointers to their copies.
+	ptrs map[uintptr]interface{}
+	opts options
+	// root and path locate the value currently copied.
+	root string
+	path []segment
+	// errs collects errors when using WithCollectErrors.
+	errs []*FieldError
+	// elements counts the slice, array and map elements visited.
+	elements int
+	// visiting maps the maps and slices currently copied to their copies;
+	// using WithPreserveAliasing all maps and slices copied so far.
+	visiting map[refKey]interface{}
+	// inKey is set while copying map keys, which are not matched by path strategies.
+	inKey bool
+	// decided is the decision of hooks about the value visited, valid as long as
+	// visits, which counts the values visited, equals decidedAt; see hook.
+	decided   *HookResult
+	decidedAt int
+	visits    int
+	// embeddedAt equals visits while visiting an embedded field.
+	embeddedAt int
+	// maskedBy counts the values masked by strategy when using WithMetrics.
+	maskedBy map[string]int
+	// ctxChecks counts the values visited by MaskCtx, ctxErr is the error of its context once done.
+	ctxChecks int
+	ctxErr    error
+	// omittedAt equals visits while visiting a value omitted by Omit.
+	omittedAt int
+}
 
 var copiers map[reflect.Kind]copier
 
@@ -36,25 +72,24 @@ func init() {
 	}
 }
 
-// Masker needs to be implemented by a type in need to mask pointer values.
-// In case your element will not be handled as a pointer, implement
-// the Masker with the returned type;
-// Example:
+// Masker is implemented by types masking themselves in place.
+// Alternatively, a type can implement a method returning its masked value:
 //
-//	type MyString string;
+//	type MyString string
 //
-//	 func(s MyString) MaskXXX()MyString{
+//	func (s MyString) MaskXXX() MyString {
 //	  return MyString("MASKED")
-//	 }
+//	}
+//
+// Either form is applied regardless of whether the value is held directly or
+// by pointer, and regardless of whether it is defined on a value or a pointer receiver.
 type Masker interface {
 	MaskXXX()
 }
 
-var maskerTpPtr = reflect.TypeOf((*Masker)(nil)).Elem()
-
 // Must masks values and panics on any errors.
-func Must[T any](x T) T {
-	dc, err := Mask(x)
+func Must[T any](x T, opts ...Option) T {
+	dc, err := Mask(x, opts...)
 	if err != nil {
 		panic(err)
 	}
@@ -63,7 +98,7 @@ func Must[T any](x T) T {
 
 // Primitive makes a copy of a primitive type...which just means it returns the input value.
 // This is wholly uninteresting, but I included it for consistency's sake.
-func _primitive(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error) {
+func _primitive(x interface{}, s *state) (interface{}, error) {
 	kind := reflect.ValueOf(x).Kind()
 	if kind == reflect.Array ||
 		kind == reflect.Chan ||
@@ -74,103 +109,285 @@ func _primitive(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error
 		kind == reflect.Slice ||
 		kind == reflect.Struct ||
 		kind == reflect.UnsafePointer {
-		return nil, fmt.Errorf("unable to copy %v (a %v) as a primitive", x, kind)
+		return nil, fmt.Errorf("%w: unable to copy %v (a %v) as a primitive", ErrKindMismatch, x, kind)
+	}
+	if kind == reflect.String && s.truncates() {
+		return s.truncated(reflect.ValueOf(x)).Interface(), nil
 	}
 	return x, nil
 }
 
 // Mask masks the handled object
 // Mask makes a deep copy of whatever gets passed in. It handles pretty much all known go types
-// (with the exception of channels, unsafe pointers, and functions). Note that this is a truly deep
+// (with the exception of channels, unsafe pointers, and functions; see WithSkipUnsupported). Note that this is a truly deep
 // copy that will work it's way all the way to the leaves of the types--any pointer will be copied,
 // any values in any slice or map will be deep copied, etc.
 // Note: in order to avoid an infinite loop, we keep track of any pointers that we've run across.
 // If we run into that pointer again, we don't make another deep copy of it; we just replace it with
 // the copy we've already made. This also ensures that the cloned result is functionally equivalent
 // to the original value.
-func Mask[T any](x T) (T, error) {
-	ptrs := make(map[uintptr]interface{})
-	out, err := _anything(x, ptrs)
-	if err != nil || out == nil {
+//
+// Mask is safe for concurrent use by multiple goroutines, also while strategies, placeholders,
+// conditions or copiers are registered. MaskXXX methods, strategies and hooks may thus be
+// called concurrently and need to be safe for concurrent use themselves.
+func Mask[T any](x T, opts ...Option) (_ T, err error) {
+	s := newState(x, opts)
+	defer s.release()
+	if s.opts.metrics != nil {
+		defer s.observe("mask", time.Now(), &err)
+	}
+	if s.opts.tracer != nil {
+		defer s.endSpan(s.startSpan("mask", x), &err)
+	}
+	if s.opts.err != nil {
+		var out T
+		return out, s.opts.err
+	}
+	out, err := _anything(x, s)
+	partial := false
+	if err == nil && len(s.errs) > 0 {
+		err = &MaskError{Errors: s.errs}
+		partial = s.opts.partial
+	}
+	if (err != nil && !partial) || out == nil {
 		var out T
 		return out, err
 	}
-
-	return out.(T), err
+	// an UnsupportedValue replacing x is no T
+	masked, _ := out.(T)
+	return masked, err
 }
 
-func _anything(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error) {
+func _anything(x interface{}, s *state) (interface{}, error) {
 	v := reflect.ValueOf(x)
 	if !v.IsValid() {
 		return x, nil
 	}
-	if c, ok := copiers[v.Kind()]; ok {
-		out, err := c(x, ptrs)
+	if s.exceedsDepth() {
+		return s.depthExceeded(v.Type())
+	}
+	if s.ctxDone() {
+		return s.ctxExceeded(v.Type())
+	}
+	promotes := s.promotes()
+	hook := s.hook(v)
+	switch hook.Action {
+	case HookReplace, HookMask:
+		return s.hooked(hook, v)
+	case HookContinue:
+		if strategy := s.pathStrategy(); strategy != nil {
+			return _path(x, strategy, s)
+		}
+		if strategy := s.typeStrategy(v); strategy != nil {
+			return _path(x, strategy, s)
+		}
+		if strategy := s.fieldStrategy(); strategy != nil {
+			return _path(x, strategy, s)
+		}
+		if strategy, _ := s.detect(v); strategy != nil {
+			return _path(x, strategy, s)
+		}
+	}
+	c, ok := s.copier().typeCopier(v.Type())
+	if !ok {
+		c, ok = s.marshalerCopier(v.Type())
+	}
+	if !ok {
+		c, ok = s.copier().kinds.load(v.Kind())
+	}
+	if !ok {
+		c, ok = copiers[v.Kind()]
+	}
+	if ok {
+		copied, err := c(x, s)
 		if err != nil {
-			return nil, err
+			return s.fail(v.Type(), err)
+		}
+		if hook.Action == HookKeep || !promotes {
+			return copied, nil
 		}
-		out, err = _mask(out)
+		if s.opts.pureMaskers && masksInPlace(v.Type()) {
+			return s.fail(v.Type(), ErrMutatingMasker)
+		}
+		out, masked, err := _mask(copied)
 		if err != nil {
-			return nil, err
+			return s.fail(v.Type(), err)
+		}
+		if masked {
+			if s.opts.reveal != nil {
+				return s.fail(v.Type(), fmt.Errorf("%w: %s", ErrIrreversible, maskFnName))
+			}
+			s.masked(maskFnName)
 		}
 		return out, nil
 	}
-	t := reflect.TypeOf(x)
-	return nil, fmt.Errorf("unable to make a deep copy of %v (type: %v) - kind %v is not supported", x, t, v.Kind())
+	if isUnsupported(v.Kind()) {
+		switch s.opts.unsupported {
+		case keepUnsupported:
+			return x, nil
+		case zeroUnsupported:
+			return reflect.Zero(v.Type()).Interface(), nil
+		}
+	}
+	return s.unsupported(v, fmt.Errorf("%w: %v", ErrUnsupportedKind, v.Kind()))
 }
 
 const maskFnName = "MaskXXX"
 
-func _mask(x interface{}) (interface{}, error) {
+// _mask applies the MaskXXX method of x, if any, and reports whether it did. Both forms of MaskXXX
+// are supported on value as well as on pointer receivers:
+//
+//	func (t T) MaskXXX() T   // or (t *T), returning the masked value
+//	func (t *T) MaskXXX()    // the Masker interface, masking in place
+//
+// x is always the copy of the original value, never the original itself: pointers are masked
+// by their element, which has been masked while copying it already, and a pointer receiver
+// is called on another copy of the value, so MaskXXX can never modify the original.
+func _mask(x interface{}) (interface{}, bool, error) {
 	tp := reflect.TypeOf(x)
-	if tp.Kind() == reflect.Ptr {
+	onPtr, ok, err := maskMethod(tp)
+	if err != nil || !ok {
+		return x, false, err
+	}
 
-		vof := reflect.ValueOf(x)
-		if vof.IsNil() {
-			return x, nil
-		}
-		if !tp.Implements(maskerTpPtr) {
-			return x, nil
-		}
-		vof.MethodByName(maskFnName).Call(nil)
-		return x, nil
+	recv := reflect.ValueOf(x)
+	if onPtr {
+		p := reflect.New(tp)
+		p.Elem().Set(recv)
+		recv = p
 	}
+	res := recv.MethodByName(maskFnName).Call(nil)
+	if len(res) == 0 {
+		return recv.Elem().Interface(), true, nil
+	}
+	return res[0].Interface(), true, nil
+}
+
+// promotes reports whether the value visited is masked by its MaskXXX method,
+// which is not the case for embedded fields using WithSkipEmbeddedMaskers.
+// It must be called before visiting the children of the value.
+func (s *state) promotes() bool {
+	return !s.opts.skipEmbedded || s.embeddedAt == 0 || s.embeddedAt != s.visits
+}
+
+// pushStructField pushes the struct field f, keeping track of embedded fields.
+func (s *state) pushStructField(f reflect.StructField) {
+	s.pushField(f.Name)
+	if f.Anonymous {
+		s.embeddedAt = s.visits
+	}
+}
+
+// masksInPlace reports whether values of type tp implement Masker.
+func masksInPlace(tp reflect.Type) bool {
+	method, ok := reflect.PointerTo(tp).MethodByName(maskFnName)
+	return ok && method.Type.NumOut() == 0
+}
 
-	// mask value
+// maskMethod reports whether values of type tp are masked by a MaskXXX method
+// and whether it needs to be called on a pointer receiver.
+//
+// MaskXXX methods promoted from embedded fields are ignored: the embedded field is masked
+// by the method of its type while copying the struct, just as its other fields are masked.
+// Calling the promoted method on the struct would mask the embedded field twice and
+// leave structs embedding a MaskXXX returning the embedded type unusable.
+func maskMethod(tp reflect.Type) (onPtr bool, ok bool, err error) {
+	if tp.Kind() == reflect.Ptr {
+		return false, false, nil
+	}
 	method, ok := tp.MethodByName(maskFnName)
 	if !ok {
-		return x, nil
+		if method, ok = reflect.PointerTo(tp).MethodByName(maskFnName); !ok {
+			return false, false, nil
+		}
+		onPtr = true
+	}
+	if promoted(tp, method) {
+		return false, false, nil
 	}
-	if method.Type.NumOut() != 1 {
-		return nil, fmt.Errorf("MaskXXX needs to return exactly 1 value, got: %d", method.Type.NumOut())
+	if method.Type.NumIn() != 1 {
+		return false, false, fmt.Errorf("%w: MaskXXX must not take any arguments, got: %d", ErrBadMaskSignature, method.Type.NumIn()-1)
 	}
-	outName := method.Type.Out(0).Name()
-	if outName != tp.Name() {
-		return nil, fmt.Errorf("MaskXXX needs to return the same type as its target type (%s), got: %s", tp.Name(), outName)
+	switch method.Type.NumOut() {
+	case 0:
+		// a value receiver cannot mask in place
+		return onPtr, onPtr, nil
+	case 1:
+		if out := method.Type.Out(0); out != tp {
+			return false, false, fmt.Errorf("%w: MaskXXX needs to return the same type as its target type (%v), got: %v", ErrBadMaskSignature, tp, out)
+		}
+		return onPtr, true, nil
 	}
+	return false, false, fmt.Errorf("%w: MaskXXX needs to return exactly 1 value, got: %d", ErrBadMaskSignature, method.Type.NumOut())
+}
 
-	vof := reflect.ValueOf(x)
+// promotedMethods caches whether the MaskXXX method of a struct type is promoted.
+var promotedMethods sync.Map
 
-	res := vof.MethodByName(maskFnName).Call(nil)
-	itf := res[0].Interface()
-	return itf, nil
+// promoted reports whether method of the struct type tp is promoted from one of its
+// embedded fields rather than declared on tp. The compiler implements promoted
+// methods by generated wrappers, which are told apart from declared methods by
+// their source file; a declared method shadows the methods of embedded fields.
+func promoted(tp reflect.Type, method reflect.Method) bool {
+	if tp.Kind() != reflect.Struct {
+		return false
+	}
+	if p, ok := promotedMethods.Load(tp); ok {
+		return p.(bool)
+	}
+	p := false
+	for i := 0; i < tp.NumField() && !p; i++ {
+		f := tp.Field(i)
+		if !f.Anonymous {
+			continue
+		}
+		_, onValue := f.Type.MethodByName(maskFnName)
+		_, onPtr := reflect.PointerTo(f.Type).MethodByName(maskFnName)
+		p = onValue || onPtr
+	}
+	if p {
+		pc := method.Func.Pointer()
+		file, _ := runtime.FuncForPC(pc).FileLine(pc)
+		p = file == "<autogenerated>"
+	}
+	promotedMethods.Store(tp, p)
+	return p
 }
 
-func _slice(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error) {
+func _slice(x interface{}, s *state) (interface{}, error) {
 	v := reflect.ValueOf(x)
 	if v.Kind() != reflect.Slice {
-		return nil, fmt.Errorf("must pass a value with kind of Slice; got %v", v.Kind())
+		return nil, fmt.Errorf("%w: must pass a value with kind of Slice; got %v", ErrKindMismatch, v.Kind())
 	}
-	// Create a new slice and, for each item in the slice, make a deep copy of it.
-	size := v.Len()
 	t := reflect.TypeOf(x)
-	dc := reflect.MakeSlice(t, size, size)
+	if v.IsNil() {
+		return reflect.Zero(t).Interface(), nil
+	}
+	// Create a new slice and, for each item in the slice, make a deep copy of it.
+	size := s.sliceLen(v)
+	capacity := size
+	if s.opts.preserveCapacity {
+		capacity = v.Cap()
+	}
+	dc := reflect.MakeSlice(t, size, capacity)
+	if size > 0 {
+		k := newRefKey(v)
+		if cp, ok := s.enter(k, dc); ok {
+			return cp, nil
+		}
+		defer s.leave(k)
+	}
 	for i := 0; i < size; i++ {
-		item, err := _anything(v.Index(i).Interface(), ptrs)
+		if s.countElement() {
+			return s.elementsExceeded(t, dc.Slice(0, i))
+		}
+		s.pushIndex(i)
+		item, err := _anything(v.Index(i).Interface(), s)
+		s.pop()
 		if err != nil {
-			return nil, fmt.Errorf("failed to clone slice item at index %v: %v", i, err)
+			return nil, err
 		}
-		iv := reflect.ValueOf(item)
+		iv := held(item, t.Elem())
 		if iv.IsValid() {
 			dc.Index(i).Set(iv)
 		}
@@ -178,32 +395,60 @@ func _slice(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error) {
 	return dc.Interface(), nil
 }
 
-func _map(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error) {
+func _map(x interface{}, s *state) (interface{}, error) {
 	v := reflect.ValueOf(x)
 	if v.Kind() != reflect.Map {
-		return nil, fmt.Errorf("must pass a value with kind of Map; got %v", v.Kind())
+		return nil, fmt.Errorf("%w: must pass a value with kind of Map; got %v", ErrKindMismatch, v.Kind())
 	}
 	t := reflect.TypeOf(x)
+	if v.IsNil() {
+		return reflect.Zero(t).Interface(), nil
+	}
 	dc := reflect.MakeMapWithSize(t, v.Len())
-	iter := v.MapRange()
-	for iter.Next() {
-		item, err := _anything(iter.Value().Interface(), ptrs)
+	if v.Len() > 0 {
+		k := newRefKey(v)
+		if cp, ok := s.enter(k, dc); ok {
+			return cp, nil
+		}
+		defer s.leave(k)
+	}
+	for _, e := range mapEntries(v, s.opts.sortMaps) {
+		if s.countElement() {
+			return s.elementsExceeded(t, dc)
+		}
+		s.pushKey(e.key.Interface())
+		visit := s.visits
+		item, err := _anything(e.value.Interface(), s)
 		if err != nil {
-			return nil, fmt.Errorf("failed to clone map item %v: %v", iter.Key().Interface(), err)
+			s.pop()
+			return nil, err
 		}
-		k, err := _anything(iter.Key().Interface(), ptrs)
+		if s.omittedAt == visit {
+			s.pop()
+			continue
+		}
+		k, err := _key(e.key, t, dc, s)
+		s.pop()
 		if err != nil {
-			return nil, fmt.Errorf("failed to clone the map key %v: %v", k, err)
+			return nil, err
 		}
-		dc.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(item))
+		if !k.IsValid() {
+			continue
+		}
+		iv := held(item, t.Elem())
+		if !iv.IsValid() {
+			// nil interface values; an invalid value would delete the key
+			iv = reflect.Zero(t.Elem())
+		}
+		dc.SetMapIndex(k, iv)
 	}
 	return dc.Interface(), nil
 }
 
-func _pointer(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error) {
+func _pointer(x interface{}, s *state) (interface{}, error) {
 	v := reflect.ValueOf(x)
 	if v.Kind() != reflect.Ptr {
-		return nil, fmt.Errorf("must pass a value with kind of Ptr; got %v", v.Kind())
+		return nil, fmt.Errorf("%w: must pass a value with kind of Ptr; got %v", ErrKindMismatch, v.Kind())
 	}
 
 	if v.IsNil() {
@@ -212,40 +457,51 @@ func _pointer(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error)
 	}
 
 	addr := v.Pointer()
-	if dc, ok := ptrs[addr]; ok {
+	if dc, ok := s.ptrs[addr]; ok {
 		return dc, nil
 	}
 	t := reflect.TypeOf(x)
 	dc := reflect.New(t.Elem())
-	ptrs[addr] = dc.Interface()
+	s.ptrs[addr] = dc.Interface()
 
-	item, err := _anything(v.Elem().Interface(), ptrs)
+	item, err := _anything(v.Elem().Interface(), s)
 	if err != nil {
-		return nil, fmt.Errorf("failed to copy the value under the pointer %v: %v", v, err)
+		return nil, err
 	}
-	iv := reflect.ValueOf(item)
+	iv := held(item, t.Elem())
 	if iv.IsValid() {
-		dc.Elem().Set(reflect.ValueOf(item))
+		dc.Elem().Set(iv)
 	}
 
 	return dc.Interface(), nil
 }
 
-func _struct(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error) {
+func _struct(x interface{}, s *state) (interface{}, error) {
 	v := reflect.ValueOf(x)
 	if v.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("must pass a value with kind of Struct; got %v", v.Kind())
+		return nil, fmt.Errorf("%w: must pass a value with kind of Struct; got %v", ErrKindMismatch, v.Kind())
 	}
 	t := reflect.TypeOf(x)
+	if s.flat(t) {
+		// x holds a copy of the struct already
+		return x, nil
+	}
 	dc := reflect.New(t)
+	if clone, ok := s.cloned(v); ok {
+		// the clone provides the unexported fields, its exported ones are copied below
+		v = clone
+		dc.Elem().Set(clone)
+	}
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
 		if f.PkgPath != "" {
 			continue
 		}
-		item, err := _anything(v.Field(i).Interface(), ptrs)
+		s.pushStructField(f)
+		item, err := _field(f, v, i, s)
+		s.pop()
 		if err != nil {
-			return nil, fmt.Errorf("failed to copy the field %v in the struct %#v: %v", t.Field(i).Name, x, err)
+			return nil, err
 		}
 		vof := reflect.ValueOf(item)
 		fld := dc.Elem().Field(i)
@@ -261,27 +517,64 @@ func _struct(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error) {
 				}
 			}
 		} else {
-			fld.Set(vof)
+			fld.Set(held(item, f.Type))
 		}
 
 	}
 	return dc.Elem().Interface(), nil
 }
 
-func _array(x interface{}, ptrs map[uintptr]interface{}) (interface{}, error) {
+// _field copies the value of the struct field f with index i of parent,
+// masking it using the strategy referenced by the field's tag, if any.
+func _field(f reflect.StructField, parent reflect.Value, i int, s *state) (interface{}, error) {
+	v := parent.Field(i)
+	strategy, err := s.tagStrategy(f, parent)
+	if err == nil {
+		err = s.cleared(f, strategy, v)
+	}
+	if err != nil {
+		return s.fail(f.Type, err)
+	}
+	if strategy == nil {
+		return _anything(v.Interface(), s)
+	}
+	switch hook := s.hook(v); hook.Action {
+	case HookReplace, HookMask:
+		return s.hooked(hook, v)
+	case HookKeep:
+		var out interface{}
+		s.decide(hook, func() {
+			out, err = _anything(v.Interface(), s)
+		})
+		return out, err
+	}
+	masked, err := s.applyStrategy(strategy, v)
+	if err != nil {
+		return s.fail(f.Type, err)
+	}
+	s.masked(strategy.Name())
+	return masked.Interface(), nil
+}
+
+func _array(x interface{}, s *state) (interface{}, error) {
 	v := reflect.ValueOf(x)
 	if v.Kind() != reflect.Array {
-		return nil, fmt.Errorf("must pass a value with kind of Array; got %v", v.Kind())
+		return nil, fmt.Errorf("%w: must pass a value with kind of Array; got %v", ErrKindMismatch, v.Kind())
 	}
 	t := reflect.TypeOf(x)
 	size := t.Len()
 	dc := reflect.New(reflect.ArrayOf(size, t.Elem())).Elem()
 	for i := 0; i < size; i++ {
-		item, err := _anything(v.Index(i).Interface(), ptrs)
+		if s.countElement() {
+			return s.elementsExceeded(t, dc)
+		}
+		s.pushIndex(i)
+		item, err := _anything(v.Index(i).Interface(), s)
+		s.pop()
 		if err != nil {
-			return nil, fmt.Errorf("failed to clone array item at index %v: %v", i, err)
+			return nil, err
 		}
-		dc.Index(i).Set(reflect.ValueOf(item))
+		dc.Index(i).Set(held(item, t.Elem()))
 	}
 	return dc.Interface(), nil
 }
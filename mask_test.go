@@ -1,6 +1,7 @@
 package mask
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -57,10 +58,10 @@ func ExampleMap() {
 	}
 	// Output:
 	// x["foo"] = y["foo"]: false
-	// x["foo"].Foo = y["foo"].Foo: false
+	// x["foo"].Foo = y["foo"].Foo: true
 	// x["foo"].Bar = y["foo"].Bar: true
 	// x["bar"] = y["bar"]: false
-	// x["bar"].Foo = y["bar"].Foo: false
+	// x["bar"].Foo = y["bar"].Foo: true
 	// x["bar"].Bar = y["bar"].Bar: true
 }
 
@@ -77,7 +78,7 @@ func TestInterface(t *testing.T) {
 	}
 }
 
-func ExampleAvoidInfiniteLoops() {
+func Example_avoidInfiniteLoops() {
 	x := &Foo{
 		Bar: 4,
 	}
@@ -384,3 +385,121 @@ func TestStructInterfaceKey(t *testing.T) {
 	}
 
 }
+
+type testValueMasker struct {
+	N string
+}
+
+func (t testValueMasker) MaskXXX() testValueMasker {
+	return testValueMasker{N: "MASKED"}
+}
+
+type testPointerMasker struct {
+	N string
+}
+
+func (t *testPointerMasker) MaskXXX() {
+	t.N = "MASKED"
+}
+
+type testPointerReturningMasker struct {
+	N string
+}
+
+func (t *testPointerReturningMasker) MaskXXX() testPointerReturningMasker {
+	return testPointerReturningMasker{N: "MASKED"}
+}
+
+func TestMaskReceivers(t *testing.T) {
+	type S struct {
+		Value        testValueMasker
+		ValuePtr     *testValueMasker
+		Pointer      testPointerMasker
+		PointerPtr   *testPointerMasker
+		Returning    testPointerReturningMasker
+		ReturningPtr *testPointerReturningMasker
+		Interface    interface{}
+	}
+	val := &S{
+		Value:        testValueMasker{"v"},
+		ValuePtr:     &testValueMasker{"v"},
+		Pointer:      testPointerMasker{"p"},
+		PointerPtr:   &testPointerMasker{"p"},
+		Returning:    testPointerReturningMasker{"r"},
+		ReturningPtr: &testPointerReturningMasker{"r"},
+		Interface:    testPointerMasker{"i"},
+	}
+	masked := Must(val)
+
+	for name, n := range map[string]string{
+		"Value":        masked.Value.N,
+		"ValuePtr":     masked.ValuePtr.N,
+		"Pointer":      masked.Pointer.N,
+		"PointerPtr":   masked.PointerPtr.N,
+		"Returning":    masked.Returning.N,
+		"ReturningPtr": masked.ReturningPtr.N,
+		"Interface":    masked.Interface.(testPointerMasker).N,
+	} {
+		if n != "MASKED" {
+			t.Errorf("expect %v: %v == MASKED", name, n)
+		}
+	}
+	if val.Pointer.N != "p" || val.PointerPtr.N != "p" || val.Interface.(testPointerMasker).N != "i" {
+		t.Errorf("expect the original to stay untouched, got %v", val)
+	}
+
+	if top := Must(testPointerMasker{"p"}); top.N != "MASKED" {
+		t.Errorf("expect %v == MASKED", top.N)
+	}
+}
+
+type testSharingMasker struct {
+	Tags  map[string]string
+	Items []string
+	Owner *string
+}
+
+func (t *testSharingMasker) MaskXXX() {
+	t.Tags["owner"] = "MASKED"
+	t.Items[0] = "MASKED"
+	*t.Owner = "MASKED"
+}
+
+func TestMaskDoesNotMutateSource(t *testing.T) {
+	newVal := func() *testSharingMasker {
+		owner := "ada"
+		return &testSharingMasker{Tags: map[string]string{"owner": "ada"}, Items: []string{"ada"}, Owner: &owner}
+	}
+	for name, val := range map[string]func(*testSharingMasker) interface{}{
+		"pointer":   func(v *testSharingMasker) interface{} { return v },
+		"value":     func(v *testSharingMasker) interface{} { return *v },
+		"slice":     func(v *testSharingMasker) interface{} { return []interface{}{v, *v} },
+		"map":       func(v *testSharingMasker) interface{} { return map[string]interface{}{"p": v} },
+		"interface": func(v *testSharingMasker) interface{} { return struct{ X interface{} }{v} },
+	} {
+		src := newVal()
+		if _, err := Mask(val(src)); err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if src.Tags["owner"] != "ada" || src.Items[0] != "ada" || *src.Owner != "ada" {
+			t.Errorf("%s: expect the original to stay untouched, got %v, %v, %v", name, src.Tags, src.Items, *src.Owner)
+		}
+	}
+}
+
+func TestWithPureMaskers(t *testing.T) {
+	if _, err := Mask(testValueMasker{"v"}, WithPureMaskers()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, err := Mask(testPointerReturningMasker{"r"}, WithPureMaskers()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	_, err := Mask(struct{ P *testPointerMasker }{&testPointerMasker{"p"}}, WithPureMaskers())
+	if !errors.Is(err, ErrMutatingMasker) {
+		t.Errorf("expect %v == %v", err, ErrMutatingMasker)
+	}
+	_, err = Scan(testPointerMasker{"p"}, WithPureMaskers())
+	if !errors.Is(err, ErrMutatingMasker) {
+		t.Errorf("expect %v == %v", err, ErrMutatingMasker)
+	}
+}
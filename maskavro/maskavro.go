@@ -0,0 +1,277 @@
+// Package maskavro masks Avro data based on its schema rather than Go types,
+// so pipelines can redact records using the schemas of their registry.
+//
+// Fields are masked by their properties:
+//
+//	{"name": "email", "type": "string", "sensitivity": "pii"}
+//	{"name": "phone", "type": ["null", "string"], "mask": "partial=0:4"}
+//
+// A mask property holds a strategy like a struct tag. Sensitivity levels are
+// mapped to strategies using WithSensitivity; levels without strategy are redacted.
+// Values of logical types, e.g. all dates, are masked using WithLogicalType.
+package maskavro
+
+import (
+	"fmt"
+	"reflect"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/hamba/avro/v2"
+)
+
+const (
+	// SensitivityProp is the field property holding the sensitivity level of a field.
+	SensitivityProp = "sensitivity"
+	// MaskProp is the field property holding the strategy of a field, e.g. "redact".
+	MaskProp = "mask"
+)
+
+// Option configures a Masker.
+type Option func(*Masker)
+
+// WithSensitivity masks fields with the given sensitivity level using strategy.
+func WithSensitivity(level string, strategy mask.Strategy) Option {
+	return func(m *Masker) {
+		m.levels[level] = strategy
+	}
+}
+
+// WithLogicalType masks all values of the logical type lt using strategy,
+// unless their field has a strategy of its own.
+func WithLogicalType(lt avro.LogicalType, strategy mask.Strategy) Option {
+	return func(m *Masker) {
+		m.logical[lt] = strategy
+	}
+}
+
+// Masker masks data of a single schema.
+type Masker struct {
+	schema  avro.Schema
+	levels  map[string]mask.Strategy
+	logical map[avro.LogicalType]mask.Strategy
+	fields  map[*avro.Field]mask.Strategy
+}
+
+// New creates a Masker for data of schema. It fails if a mask property
+// does not hold a valid strategy.
+func New(schema avro.Schema, opts ...Option) (*Masker, error) {
+	m := &Masker{
+		schema:  schema,
+		levels:  make(map[string]mask.Strategy),
+		logical: make(map[avro.LogicalType]mask.Strategy),
+		fields:  make(map[*avro.Field]mask.Strategy),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if err := m.collect(schema, make(map[avro.Schema]bool)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// collect resolves the strategies of all fields of the records in schema.
+func (m *Masker) collect(schema avro.Schema, seen map[avro.Schema]bool) error {
+	if seen[schema] {
+		return nil
+	}
+	seen[schema] = true
+	switch s := schema.(type) {
+	case *avro.RefSchema:
+		return m.collect(s.Schema(), seen)
+	case *avro.RecordSchema:
+		for _, f := range s.Fields() {
+			strategy, err := m.fieldStrategy(f)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", s.FullName(), f.Name(), err)
+			}
+			if strategy != nil {
+				m.fields[f] = strategy
+			}
+			if err := m.collect(f.Type(), seen); err != nil {
+				return err
+			}
+		}
+	case *avro.ArraySchema:
+		return m.collect(s.Items(), seen)
+	case *avro.MapSchema:
+		return m.collect(s.Values(), seen)
+	case *avro.UnionSchema:
+		for _, t := range s.Types() {
+			if err := m.collect(t, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Masker) fieldStrategy(f *avro.Field) (mask.Strategy, error) {
+	if tag, ok := f.Prop(MaskProp).(string); ok {
+		return mask.ParseStrategy(tag)
+	}
+	level, ok := f.Prop(SensitivityProp).(string)
+	if !ok {
+		return nil, nil
+	}
+	if strategy, ok := m.levels[level]; ok {
+		return strategy, nil
+	}
+	return mask.ParseStrategy("redact")
+}
+
+// Mask masks the binary encoded datum b.
+func (m *Masker) Mask(b []byte) ([]byte, error) {
+	var v interface{}
+	if err := avro.Unmarshal(m.schema, b, &v); err != nil {
+		return nil, err
+	}
+	masked, err := m.MaskValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return avro.Marshal(m.schema, masked)
+}
+
+// MaskValue masks a generic datum as decoded by avro.Unmarshal into an interface{}:
+// records and maps are map[string]interface{}, unions a map from the name of
+// their type to their value or, if nullable, the value itself. v is not modified.
+func (m *Masker) MaskValue(v interface{}) (interface{}, error) {
+	return m.mask(m.schema, v, nil, "")
+}
+
+func (m *Masker) mask(schema avro.Schema, v interface{}, strategy mask.Strategy, path string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch s := schema.(type) {
+	case *avro.RefSchema:
+		return m.mask(s.Schema(), v, strategy, path)
+	case *avro.UnionSchema:
+		return m.maskUnion(s, v, strategy, path)
+	case *avro.RecordSchema:
+		record, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: expected record, got %T", path, v)
+		}
+		out := make(map[string]interface{}, len(record))
+		for k, fv := range record {
+			out[k] = fv
+		}
+		for _, f := range s.Fields() {
+			fv, ok := record[f.Name()]
+			if !ok {
+				continue
+			}
+			fs := strategy
+			if fieldStrategy, ok := m.fields[f]; ok {
+				fs = fieldStrategy
+			}
+			masked, err := m.mask(f.Type(), fv, fs, join(path, f.Name()))
+			if err != nil {
+				return nil, err
+			}
+			out[f.Name()] = masked
+		}
+		return out, nil
+	case *avro.ArraySchema:
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: expected array, got %T", path, v)
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			masked, err := m.mask(s.Items(), item, strategy, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = masked
+		}
+		return out, nil
+	case *avro.MapSchema:
+		values, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: expected map, got %T", path, v)
+		}
+		out := make(map[string]interface{}, len(values))
+		for k, value := range values {
+			masked, err := m.mask(s.Values(), value, strategy, fmt.Sprintf("%s[%q]", path, k))
+			if err != nil {
+				return nil, err
+			}
+			out[k] = masked
+		}
+		return out, nil
+	}
+	if strategy == nil {
+		if ls, ok := schema.(avro.LogicalTypeSchema); ok && ls.Logical() != nil {
+			strategy = m.logical[ls.Logical().Type()]
+		}
+	}
+	if strategy == nil {
+		return v, nil
+	}
+	masked, err := strategy.Mask(reflect.ValueOf(v))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if t := reflect.TypeOf(v); masked.Type() != t {
+		if !masked.Type().ConvertibleTo(t) {
+			return nil, fmt.Errorf("%s: %w: %v is not convertible to %v", path, mask.ErrIncompatibleStrategy, masked.Type(), t)
+		}
+		masked = masked.Convert(t)
+	}
+	return masked.Interface(), nil
+}
+
+// maskUnion masks v of a union: either a map from the name of its type to its value
+// or, for unions of null and a single type, the value itself.
+func (m *Masker) maskUnion(s *avro.UnionSchema, v interface{}, strategy mask.Strategy, path string) (interface{}, error) {
+	if wrapped, ok := v.(map[string]interface{}); ok && len(wrapped) == 1 {
+		for name, value := range wrapped {
+			if t := unionType(s, name); t != nil {
+				masked, err := m.mask(t, value, strategy, join(path, name))
+				if err != nil {
+					return nil, err
+				}
+				return map[string]interface{}{name: masked}, nil
+			}
+		}
+	}
+	if s.Nullable() {
+		_, typ := s.Indices()
+		return m.mask(s.Types()[typ], v, strategy, path)
+	}
+	return nil, fmt.Errorf("%s: unexpected union value %T", path, v)
+}
+
+// unionType returns the type of s named name, as named by avro.Unmarshal.
+func unionType(s *avro.UnionSchema, name string) avro.Schema {
+	for _, t := range s.Types() {
+		if typeName(t) == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func typeName(schema avro.Schema) string {
+	if ref, ok := schema.(*avro.RefSchema); ok {
+		schema = ref.Schema()
+	}
+	if n, ok := schema.(avro.NamedSchema); ok {
+		return n.FullName()
+	}
+	name := string(schema.Type())
+	if ls, ok := schema.(avro.LogicalTypeSchema); ok && ls.Logical() != nil {
+		name += "." + string(ls.Logical().Type())
+	}
+	return name
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
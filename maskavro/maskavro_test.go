@@ -0,0 +1,99 @@
+package maskavro
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskers"
+	"github.com/hamba/avro/v2"
+)
+
+var testSchema = avro.MustParse(`{
+	"type": "record",
+	"name": "User",
+	"namespace": "test",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "email", "type": "string", "sensitivity": "pii"},
+		{"name": "token", "type": "string", "sensitivity": "secret"},
+		{"name": "phone", "type": ["null", "string"], "mask": "partial=0:2"},
+		{"name": "birthday", "type": {"type": "int", "logicalType": "date"}},
+		{"name": "aliases", "type": {"type": "array", "items": "string"}, "sensitivity": "pii"},
+		{"name": "friend", "type": ["null", "User"]}
+	]
+}`)
+
+func TestMasker(t *testing.T) {
+	m, err := New(testSchema,
+		WithSensitivity("pii", maskers.Partial(1, 0, maskers.Format{})),
+		WithLogicalType(avro.Date, maskers.Date(maskers.Year)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	birthday := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	user := map[string]interface{}{
+		"id":       int64(1),
+		"email":    "alice@example.com",
+		"token":    "s3cr3t",
+		"phone":    map[string]interface{}{"string": "555123"},
+		"birthday": birthday,
+		"aliases":  []interface{}{"al"},
+		"friend": map[string]interface{}{"test.User": map[string]interface{}{
+			"id":       int64(2),
+			"email":    "bob@example.com",
+			"token":    "t0k3n",
+			"phone":    nil,
+			"birthday": birthday,
+			"aliases":  []interface{}{},
+			"friend":   nil,
+		}},
+	}
+	b, err := avro.Marshal(testSchema, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := m.Mask(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := avro.Unmarshal(testSchema, out, &got); err != nil {
+		t.Fatal(err)
+	}
+	year := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	expect := map[string]interface{}{
+		"id":       int64(1),
+		"email":    "a****************",
+		"token":    mask.DefaultPlaceholder,
+		"phone":    "****23",
+		"birthday": year,
+		"aliases":  []interface{}{"a*"},
+		"friend": map[string]interface{}{"test.User": map[string]interface{}{
+			"id":       int64(2),
+			"email":    "b**************",
+			"token":    mask.DefaultPlaceholder,
+			"phone":    nil,
+			"birthday": year,
+			"aliases":  []interface{}{},
+			"friend":   nil,
+		}},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect %v == %v", got, expect)
+	}
+	if user["email"] != "alice@example.com" {
+		t.Errorf("expect the value not to be modified")
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	schema := avro.MustParse(`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "string", "mask": "nope"}]}`)
+	if _, err := New(schema); !errors.Is(err, mask.ErrUnknownStrategy) {
+		t.Errorf("expect %v == %v", err, mask.ErrUnknownStrategy)
+	}
+}
@@ -0,0 +1,171 @@
+// Package maskaws masks the requests and responses logged by the AWS SDK for Go v2,
+// so client log modes can be enabled in production:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx,
+//	  config.WithClientLogMode(aws.LogRequestWithBody|aws.LogResponseWithBody),
+//	  config.WithAPIOptions([]func(*middleware.Stack) error{maskaws.Middleware()}))
+//
+// The middleware wraps the logger of every operation. Before a message reaches it,
+// credentials in headers, like Authorization and X-Amz-Security-Token, the signatures
+// and credentials of presigned URLs and secret fields of JSON, XML and form bodies,
+// like SecretAccessKey, SessionToken, Plaintext or SecretString, are redacted.
+package maskaws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+	mask "github.com/doejon/go-mask"
+)
+
+// sensitiveHeaders are the lower-cased names of HTTP headers holding credentials or keys.
+var sensitiveHeaders = map[string]bool{
+	"authorization":        true,
+	"proxy-authorization":  true,
+	"cookie":               true,
+	"set-cookie":           true,
+	"x-amz-security-token": true,
+	"x-amz-server-side-encryption-customer-key":             true,
+	"x-amz-copy-source-server-side-encryption-customer-key": true,
+}
+
+// sensitiveParams are the query parameters of presigned URLs holding credentials or signatures.
+var sensitiveParams = map[string]bool{
+	"x-amz-signature":      true,
+	"x-amz-credential":     true,
+	"x-amz-security-token": true,
+	"signature":            true,
+	"awsaccesskeyid":       true,
+}
+
+// sensitiveFields are the fields of inputs and outputs of AWS APIs holding secrets,
+// e.g. the credentials returned by STS and SSO, the plaintext of KMS data keys or
+// the values of Secrets Manager.
+var sensitiveFields = []string{
+	"SecretAccessKey", "SessionToken", "Plaintext", "SecretString", "SecretBinary",
+	"Password", "MasterUserPassword", "NewPassword", "OldPassword", "PrivateKey",
+	"KeyMaterial", "AuthToken", "AccessToken", "RefreshToken", "ClientSecret",
+	"SAMLAssertion", "WebIdentityToken",
+}
+
+var (
+	fieldNames = "(?i)(" + strings.Join(sensitiveFields, "|") + ")"
+	jsonFields = regexp.MustCompile(`"` + fieldNames + `"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	xmlFields  = regexp.MustCompile(`<` + fieldNames + `>[^<]*<`)
+	formFields = regexp.MustCompile(`(?m)(^|&)` + fieldNames + `=[^&\s]*`)
+)
+
+// Options returns options masking the secret fields of inputs and outputs of AWS APIs,
+// e.g. before logging the output of sts.AssumeRole:
+//
+//	log.Printf("assumed role: %+v", mask.Must(out, maskaws.Options()...))
+func Options() []mask.Option {
+	return []mask.Option{mask.WithDenyFields(sensitiveFields...)}
+}
+
+// Middleware returns an API option adding a middleware which masks the messages logged
+// while sending a request, see MaskDump. Options, e.g. detectors, mask the messages
+// further.
+func Middleware(opts ...mask.Option) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		// after the SDK's middleware setting the logger of the client
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("MaskLogger",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+				middleware.InitializeOutput, middleware.Metadata, error,
+			) {
+				logger := &Logger{Logger: middleware.GetLogger(ctx), opts: opts}
+				return next.HandleInitialize(middleware.SetLogger(ctx, logger), in)
+			}), middleware.After)
+	}
+}
+
+// Logger is a logging.Logger masking messages before passing them to Logger.
+type Logger struct {
+	Logger logging.Logger
+	opts   []mask.Option
+}
+
+// NewLogger returns a Logger passing masked messages to l.
+func NewLogger(l logging.Logger, opts ...mask.Option) *Logger {
+	return &Logger{Logger: l, opts: opts}
+}
+
+// Logf implements logging.Logger.
+func (l *Logger) Logf(classification logging.Classification, format string, v ...interface{}) {
+	l.Logger.Logf(classification, "%s", MaskDump(fmt.Sprintf(format, v...), l.opts...))
+}
+
+// WithContext implements logging.ContextLogger.
+func (l *Logger) WithContext(ctx context.Context) logging.Logger {
+	return &Logger{Logger: logging.WithContext(ctx, l.Logger), opts: l.opts}
+}
+
+// MaskDump masks the dump of an HTTP request or response, as logged by the SDK:
+// sensitive headers, the credentials of presigned URLs in the request line and the
+// secret fields of the body are redacted. Options, e.g. detectors, mask the dump further.
+func MaskDump(dump string, opts ...mask.Option) string {
+	lines := strings.Split(dump, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, "\r")
+		if trimmed == "" {
+			// the body follows the headers
+			break
+		}
+		name, _, ok := strings.Cut(trimmed, ":")
+		switch {
+		case ok && sensitiveHeaders[strings.ToLower(strings.TrimSpace(name))]:
+			lines[i] = name + ": " + mask.Placeholder[string]() + line[len(trimmed):]
+		case strings.Contains(trimmed, "?"):
+			lines[i] = MaskURL(line)
+		}
+	}
+	masked := maskBody(strings.Join(lines, "\n"))
+	if len(opts) == 0 {
+		return masked
+	}
+	masked, err := mask.Mask(masked, opts...)
+	if err != nil {
+		return mask.Placeholder[string]()
+	}
+	return masked
+}
+
+// maskBody redacts the secret fields of JSON, XML and form bodies.
+func maskBody(body string) string {
+	redacted := mask.Placeholder[string]()
+	body = jsonFields.ReplaceAllString(body, `"$1"$2"`+redacted+`"`)
+	body = xmlFields.ReplaceAllString(body, `<$1>`+redacted+`<`)
+	return formFields.ReplaceAllString(body, `$1$2=`+redacted)
+}
+
+// MaskURL masks the signature and credentials of a presigned URL, keeping the
+// other parameters, e.g. X-Amz-Expires. u may be a line of text containing the URL.
+func MaskURL(u string) string {
+	start := strings.IndexByte(u, '?')
+	if start < 0 {
+		return u
+	}
+	end := strings.IndexAny(u[start:], " \t\r\n#\"'")
+	if end < 0 {
+		end = len(u)
+	} else {
+		end += start
+	}
+	params := strings.Split(u[start+1:end], "&")
+	for i, p := range params {
+		name, _, ok := strings.Cut(p, "=")
+		if ok && sensitiveParams[strings.ToLower(name)] {
+			params[i] = name + "=" + mask.Placeholder[string]()
+		}
+	}
+	return u[:start+1] + strings.Join(params, "&") + u[end:]
+}
+
+var (
+	_ logging.Logger        = (*Logger)(nil)
+	_ logging.ContextLogger = (*Logger)(nil)
+)
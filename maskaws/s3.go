@@ -0,0 +1,62 @@
+package maskaws
+
+import (
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/doejon/go-mask/maskobject"
+)
+
+// S3API is the part of *s3.Client used by S3Bucket.
+type S3API interface {
+	s3.ListObjectsV2APIClient
+	manager.UploadAPIClient
+	GetObject(ctx context.Context, in *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+type s3Bucket struct {
+	client   S3API
+	bucket   string
+	uploader *manager.Uploader
+}
+
+// S3Bucket returns the bucket named bucket of client for scrubbing objects, see maskobject.
+// Objects are uploaded in parts, so they need not fit into memory.
+func S3Bucket(client S3API, bucket string) maskobject.Bucket {
+	return &s3Bucket{client: client, bucket: bucket, uploader: manager.NewUploader(client)}
+}
+
+func (b *s3Bucket) List(ctx context.Context, prefix string, fn func(key string) error) error {
+	pages := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, obj := range page.Contents {
+			if err := fn(aws.ToString(obj.Key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *s3Bucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(b.bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+func (b *s3Bucket) Put(ctx context.Context, key string, r io.Reader) error {
+	_, err := b.uploader.Upload(ctx, &s3.PutObjectInput{Bucket: aws.String(b.bucket), Key: aws.String(key), Body: r})
+	return err
+}
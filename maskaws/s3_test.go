@@ -0,0 +1,110 @@
+package maskaws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskobject"
+)
+
+// testS3 keeps the objects of a single bucket in memory, listing one object per page.
+type testS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (c *testS3) ListObjectsV2(_ context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var keys []string
+	for k := range c.objects {
+		if strings.HasPrefix(k, aws.ToString(in.Prefix)) && k > aws.ToString(in.ContinuationToken) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	out := &s3.ListObjectsV2Output{}
+	if len(keys) > 0 {
+		out.Contents = []types.Object{{Key: aws.String(keys[0])}}
+		out.IsTruncated = aws.Bool(len(keys) > 1)
+		out.NextContinuationToken = aws.String(keys[0])
+	}
+	return out, nil
+}
+
+func (c *testS3) GetObject(_ context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.objects[aws.ToString(in.Key)]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(b))}, nil
+}
+
+func (c *testS3) PutObject(_ context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	b, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.objects[aws.ToString(in.Key)] = b
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (c *testS3) UploadPart(context.Context, *s3.UploadPartInput, ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *testS3) CreateMultipartUpload(context.Context, *s3.CreateMultipartUploadInput, ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *testS3) CompleteMultipartUpload(context.Context, *s3.CompleteMultipartUploadInput, ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *testS3) AbortMultipartUpload(context.Context, *s3.AbortMultipartUploadInput, ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestS3Bucket(t *testing.T) {
+	client := &testS3{objects: map[string][]byte{
+		"raw/a.json":   []byte(`{"SecretAccessKey":"wJalr","Region":"eu-west-1"}`),
+		"raw/b.csv":    []byte("user,SessionToken\nada,FwoG\n"),
+		"other/c.json": []byte(`{}`),
+	}}
+	bucket := S3Bucket(client, "test")
+	s := maskobject.New(bucket, bucket, maskobject.WithMaskOptions(Options()...), maskobject.WithRename(func(key string) string {
+		return "archive/" + strings.TrimPrefix(key, "raw/")
+	}))
+	result, err := s.Scrub(context.Background(), "raw/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Scrubbed != 2 {
+		t.Errorf("expect %d == 2 objects scrubbed", result.Scrubbed)
+	}
+	for key, expect := range map[string]string{
+		"archive/a.json": `{"Region":"eu-west-1","SecretAccessKey":"` + mask.Placeholder[string]() + `"}` + "\n",
+		"archive/b.csv":  "user,SessionToken\nada," + mask.Placeholder[string]() + "\n",
+	} {
+		if out := string(client.objects[key]); out != expect {
+			t.Errorf("expect %s: %q == %q", key, out, expect)
+		}
+	}
+	if _, ok := client.objects["archive/c.json"]; ok {
+		t.Error("expect objects outside the prefix to be ignored")
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/doejon/go-mask/maskchi
+
+go 1.23
+
+require github.com/doejon/go-mask v0.0.0
+
+require (
+	github.com/go-chi/chi/v5 v5.3.1
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/doejon/go-mask => ..
@@ -0,0 +1,94 @@
+// Package maskchi plugs masked body capture into the chi request logger:
+//
+//	r := chi.NewRouter()
+//	r.Use(maskchi.RequestLogger(maskchi.NewLogFormatter(nil, false), maskhttp.WithOptions(policyOpts...)))
+//
+// RequestLogger works like middleware.RequestLogger, but passes the masked bodies
+// as Bodies to the extra argument of LogEntry.Write. NewLogFormatter appends them
+// to the lines of the default chi formatter; custom formatters can read them as well.
+package maskchi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/doejon/go-mask/maskhttp"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Bodies holds the masked bodies of a request, see maskhttp.Exchange.
+type Bodies struct {
+	Request, Response []byte
+}
+
+// RequestLogger returns a middleware logging requests using f like
+// middleware.RequestLogger, capturing their bodies masked using opts.
+func RequestLogger(f middleware.LogFormatter, opts ...maskhttp.Option) func(next http.Handler) http.Handler {
+	m := maskhttp.New(opts...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			entry := f.NewLogEntry(r)
+			req := m.CaptureRequest(r)
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			resp := m.NewBody()
+			ww.Tee(resp)
+
+			start := time.Now()
+			defer func() {
+				entry.Write(ww.Status(), ww.BytesWritten(), ww.Header(), time.Since(start), Bodies{
+					Request:  m.Mask(r.Header.Get("Content-Type"), req),
+					Response: m.Mask(ww.Header().Get("Content-Type"), resp),
+				})
+			}()
+
+			next.ServeHTTP(ww, middleware.WithLogEntry(r, entry))
+		})
+	}
+}
+
+// NewLogFormatter returns a formatter writing the lines of middleware.DefaultLogFormatter
+// to logger, followed by the masked bodies. A nil logger writes to stdout.
+func NewLogFormatter(logger middleware.LoggerInterface, noColor bool) middleware.LogFormatter {
+	if logger == nil {
+		logger = log.New(os.Stdout, "", log.LstdFlags)
+	}
+	return &formatter{logger: logger, noColor: noColor}
+}
+
+type formatter struct {
+	logger  middleware.LoggerInterface
+	noColor bool
+}
+
+func (f *formatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	e := &entry{logger: f.logger}
+	e.LogEntry = (&middleware.DefaultLogFormatter{Logger: e, NoColor: f.noColor}).NewLogEntry(r)
+	return e
+}
+
+// entry formats lines using the default entry, which prints them to entry
+// to have the bodies appended.
+type entry struct {
+	middleware.LogEntry
+	logger middleware.LoggerInterface
+	bodies Bodies
+}
+
+func (e *entry) Write(status, bytes int, header http.Header, elapsed time.Duration, extra interface{}) {
+	e.bodies, _ = extra.(Bodies)
+	e.LogEntry.Write(status, bytes, header, elapsed, extra)
+}
+
+func (e *entry) Print(v ...interface{}) {
+	line := fmt.Sprint(v...)
+	if len(e.bodies.Request) > 0 {
+		line += fmt.Sprintf(" request_body=%q", e.bodies.Request)
+	}
+	if len(e.bodies.Response) > 0 {
+		line += fmt.Sprintf(" response_body=%q", e.bodies.Response)
+	}
+	e.logger.Print(line)
+}
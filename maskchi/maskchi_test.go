@@ -0,0 +1,57 @@
+package maskchi
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskhttp"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRequestLogger(t *testing.T) {
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs bytes.Buffer
+	r := chi.NewRouter()
+	r.Use(RequestLogger(NewLogFormatter(log.New(&logs, "", 0), true),
+		maskhttp.WithOptions(mask.WithPathStrategy("**.password", redact))))
+	r.Post("/users", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(body)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"alice","password":"s3cr3t"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), "s3cr3t") {
+		t.Errorf("expect the response not to be modified, got %d %s", rec.Code, rec.Body.String())
+	}
+	line := logs.String()
+	for _, expect := range []string{
+		`"POST http://example.com/users HTTP/1.1" from 192.0.2.1:1234 - 201`,
+		`request_body="{\"name\":\"alice\",\"password\":\"MASKED\"}"`,
+		`response_body="{\"name\":\"alice\",\"password\":\"MASKED\"}"`,
+	} {
+		if !strings.Contains(line, expect) {
+			t.Errorf("expect %q to contain %q", line, expect)
+		}
+	}
+	if strings.Contains(line, "s3cr3t") {
+		t.Errorf("expect %q not to contain the password", line)
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/doejon/go-mask/maskcodec
+
+go 1.22.2
+
+require (
+	github.com/doejon/go-mask v0.0.0
+	github.com/fxamacker/cbor/v2 v2.9.4
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/doejon/go-mask => ..
@@ -0,0 +1,107 @@
+// Package maskcodec masks MessagePack and CBOR encoded payloads, e.g. events
+// which must be scrubbed before archival, without Go types describing them.
+//
+// Payloads are decoded into maps and slices and masked using the given options,
+// usually path strategies:
+//
+//	masked, err := maskcodec.MaskMsgPack(event,
+//		mask.WithPathStrategy("user.email", maskers.Partial(1, 0, maskers.Format{})),
+//		mask.WithPathStrategy("**.password", redact),
+//	)
+//
+// Maps are written with sorted keys, so masking the same payload twice yields the same bytes.
+package maskcodec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MaskMsgPack masks a single MessagePack document.
+// Map keys must be strings.
+func MaskMsgPack(b []byte, opts ...mask.Option) ([]byte, error) {
+	var out bytes.Buffer
+	if err := msgpackDocument(msgpack.NewDecoder(bytes.NewReader(b)), newMsgpackEncoder(&out), opts); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// MsgPack masks all MessagePack documents read from r and writes them to w.
+func MsgPack(r io.Reader, w io.Writer, opts ...mask.Option) error {
+	dec := msgpack.NewDecoder(r)
+	enc := newMsgpackEncoder(w)
+	for {
+		err := msgpackDocument(dec, enc, opts)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func newMsgpackEncoder(w io.Writer) *msgpack.Encoder {
+	enc := msgpack.NewEncoder(w)
+	enc.SetSortMapKeys(true)
+	return enc
+}
+
+func msgpackDocument(dec *msgpack.Decoder, enc *msgpack.Encoder, opts []mask.Option) error {
+	doc, err := dec.DecodeInterface()
+	if err != nil {
+		return err
+	}
+	masked, err := mask.Mask(doc, opts...)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(masked)
+}
+
+var (
+	// cborDecMode decodes maps into map[interface{}]interface{}, allowing keys of any type.
+	// Paths match keys by their formatted value, e.g. [1] the key 1 of COSE structures.
+	cborDecMode, _ = cbor.DecOptions{}.DecMode()
+	cborEncMode, _ = cbor.EncOptions{Sort: cbor.SortCanonical, Time: cbor.TimeRFC3339Nano}.EncMode()
+)
+
+// MaskCBOR masks a single CBOR data item.
+func MaskCBOR(b []byte, opts ...mask.Option) ([]byte, error) {
+	var doc interface{}
+	if err := cborDecMode.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	masked, err := mask.Mask(doc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cborEncMode.Marshal(masked)
+}
+
+// CBOR masks a sequence of CBOR data items read from r and writes them to w.
+func CBOR(r io.Reader, w io.Writer, opts ...mask.Option) error {
+	dec := cborDecMode.NewDecoder(r)
+	enc := cborEncMode.NewEncoder(w)
+	for {
+		var doc interface{}
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		masked, err := mask.Mask(doc, opts...)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(masked); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package maskcodec
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func testOptions(t *testing.T) []mask.Option {
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []mask.Option{
+		mask.WithPathStrategy("user.email", redact),
+		mask.WithPathStrategy("**.password", redact),
+	}
+}
+
+func TestMaskMsgPack(t *testing.T) {
+	in, err := msgpack.Marshal(map[string]interface{}{
+		"id":   42,
+		"user": map[string]interface{}{"name": "Alice", "email": "alice@example.com", "password": "secret"},
+		"tags": []interface{}{"a", "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := MaskMsgPack(in, testOptions(t)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := msgpack.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	user := got["user"].(map[string]interface{})
+	if user["email"] != mask.DefaultPlaceholder || user["password"] != mask.DefaultPlaceholder {
+		t.Errorf("expect email and password to be masked, got %v", user)
+	}
+	if user["name"] != "Alice" || got["tags"].([]interface{})[1] != "b" {
+		t.Errorf("expect other values to be kept, got %v", got)
+	}
+	if again, _ := MaskMsgPack(in, testOptions(t)...); !bytes.Equal(again, out) {
+		t.Errorf("expect masking to be deterministic")
+	}
+	if _, err := MaskMsgPack([]byte{0xc1}); err == nil {
+		t.Errorf("expect invalid payloads to fail")
+	}
+}
+
+func TestMsgPack(t *testing.T) {
+	var in bytes.Buffer
+	enc := msgpack.NewEncoder(&in)
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		if err := enc.Encode(map[string]interface{}{"user": map[string]interface{}{"email": email}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	if err := MsgPack(&in, &out, testOptions(t)...); err != nil {
+		t.Fatal(err)
+	}
+	dec := msgpack.NewDecoder(&out)
+	for i := 0; i < 2; i++ {
+		var got map[string]map[string]string
+		if err := dec.Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got["user"]["email"] != mask.DefaultPlaceholder {
+			t.Errorf("expect %v == %v", got["user"]["email"], mask.DefaultPlaceholder)
+		}
+	}
+}
+
+func TestMaskCBOR(t *testing.T) {
+	in, err := cbor.Marshal(map[interface{}]interface{}{
+		"user": map[string]interface{}{"email": "alice@example.com", "name": "Alice"},
+		1:      "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	redact, _ := mask.ParseStrategy("redact")
+	out, err := MaskCBOR(in, append(testOptions(t), mask.WithPathStrategy("[1]", redact))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[interface{}]interface{}
+	if err := cbor.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[interface{}]interface{}{
+		"user":    map[interface{}]interface{}{"email": mask.DefaultPlaceholder, "name": "Alice"},
+		uint64(1): mask.DefaultPlaceholder,
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect %v == %v", got, expect)
+	}
+}
+
+func TestCBOR(t *testing.T) {
+	var in bytes.Buffer
+	enc := cbor.NewEncoder(&in)
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		if err := enc.Encode(map[string]interface{}{"user": map[string]interface{}{"email": email}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	if err := CBOR(&in, &out, testOptions(t)...); err != nil {
+		t.Fatal(err)
+	}
+	dec := cbor.NewDecoder(&out)
+	for i := 0; i < 2; i++ {
+		var got map[string]map[string]string
+		if err := dec.Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got["user"]["email"] != mask.DefaultPlaceholder {
+			t.Errorf("expect %v == %v", got["user"]["email"], mask.DefaultPlaceholder)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package maskcsv masks columns of CSV files, e.g. data exports, by header name.
+package maskcsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+
+	mask "github.com/doejon/go-mask"
+)
+
+// ErrUnknownColumn is returned if a column rule names a column missing from the header,
+// so that a typo never lets a column pass unmasked.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// Mask copies the CSV file read from r to w, masking the values of the columns
+// named in columnRules using their strategy. The first record is the header.
+// Records are streamed one by one, so files of any size can be masked.
+func Mask(r io.Reader, w io.Writer, columnRules map[string]mask.Strategy) error {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	cw := csv.NewWriter(w)
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// the records read next reuse the slice of the header
+	header = slices.Clone(header)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	columns := make([]mask.Strategy, len(header))
+	for name, strategy := range columnRules {
+		found := false
+		for i, col := range header {
+			if col == name {
+				columns[i] = strategy
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w %q", ErrUnknownColumn, name)
+		}
+	}
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		for i, strategy := range columns {
+			if strategy == nil {
+				continue
+			}
+			masked, err := maskValue(strategy, record[i])
+			if err != nil {
+				return fmt.Errorf("line %d, column %q: %w", line, header[i], err)
+			}
+			record[i] = masked
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// maskValue masks s using strategy. Strategies returning other kinds than
+// strings, e.g. a placeholder, are formatted.
+func maskValue(strategy mask.Strategy, s string) (string, error) {
+	masked, err := strategy.Mask(reflect.ValueOf(s))
+	if err != nil {
+		return "", err
+	}
+	if masked.Kind() == reflect.String {
+		return masked.String(), nil
+	}
+	return fmt.Sprint(masked.Interface()), nil
+}
@@ -0,0 +1,60 @@
+package maskcsv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskers"
+)
+
+func TestMask(t *testing.T) {
+	in := "id,email,name\n1,alice@example.com,Alice\n2,bob@example.com,\"Bob, Jr.\"\n"
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	err = Mask(strings.NewReader(in), &out, map[string]mask.Strategy{
+		"email": redact,
+		"name":  maskers.Partial(1, 0, maskers.Format{}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "id,email,name\n1,MASKED,A****\n2,MASKED,B*******\n"
+	if out.String() != expect {
+		t.Errorf("expect %q == %q", out.String(), expect)
+	}
+}
+
+func TestMaskErrors(t *testing.T) {
+	redact, _ := mask.ParseStrategy("redact")
+	var out strings.Builder
+	err := Mask(strings.NewReader("id,mail\n1,a@b.c\n"), &out, map[string]mask.Strategy{"email": redact})
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expect %v == %v", err, ErrUnknownColumn)
+	}
+
+	out.Reset()
+	err = Mask(strings.NewReader("id,email\n1,a@b.c,x\n"), &out, map[string]mask.Strategy{"email": redact})
+	if err == nil {
+		t.Errorf("expect records with too many fields to fail")
+	}
+
+	out.Reset()
+	round, err := mask.ParseStrategy("round=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Mask(strings.NewReader("id,email\n1,a@b.c\n"), &out, map[string]mask.Strategy{"email": round})
+	if err == nil || !strings.HasPrefix(err.Error(), `line 2, column "email": `) || strings.Contains(err.Error(), "a@b.c") {
+		t.Errorf("expect the error to name the column but not its value, got %v", err)
+	}
+
+	out.Reset()
+	if err := Mask(strings.NewReader(""), &out, nil); err != nil || out.Len() != 0 {
+		t.Errorf("expect empty input to be copied, got %v, %q", err, out.String())
+	}
+}
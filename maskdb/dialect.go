@@ -0,0 +1,196 @@
+package maskdb
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect selects the SQL syntax of dumps.
+type Dialect int
+
+const (
+	Postgres Dialect = iota
+	MySQL
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case Postgres:
+		return "PostgreSQL"
+	case MySQL:
+		return "MySQL"
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+// binaryTypes are the database type names of columns holding bytes rather than text.
+var binaryTypes = map[string]bool{
+	"BYTEA": true, "BLOB": true, "TINYBLOB": true, "MEDIUMBLOB": true, "LONGBLOB": true,
+	"BINARY": true, "VARBINARY": true,
+}
+
+func (d Dialect) quoteIdent(name string) string {
+	if d == MySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// literal formats v, as returned by a driver and masked, as SQL literal.
+func (d Dialect) literal(v interface{}, binary bool) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		switch {
+		case d == MySQL && v:
+			return "1"
+		case d == MySQL:
+			return "0"
+		}
+		return strings.ToUpper(strconv.FormatBool(v))
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return d.quote(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		if d == MySQL {
+			return d.quote(v.Format("2006-01-02 15:04:05.999999"))
+		}
+		return d.quote(v.Format("2006-01-02 15:04:05.999999Z07:00"))
+	case []byte:
+		if !binary {
+			return d.quote(string(v))
+		}
+		if d == MySQL {
+			return "X'" + hex.EncodeToString(v) + "'"
+		}
+		return `'\x` + hex.EncodeToString(v) + "'"
+	case string:
+		return d.quote(v)
+	}
+	return d.quote(fmt.Sprint(v))
+}
+
+func (d Dialect) quote(s string) string {
+	if d == MySQL {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// rowWriter writes the rows of a table.
+type rowWriter interface {
+	row(values []interface{}) error
+	close() error
+}
+
+func (d *Dumper) newWriter(w io.Writer, table string, columns []string, binary []bool) rowWriter {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = d.dialect.quoteIdent(col)
+	}
+	bw := bufio.NewWriter(w)
+	if d.copy {
+		return &copyWriter{w: bw, header: fmt.Sprintf("COPY %s (%s) FROM stdin;\n",
+			d.dialect.quoteIdent(table), strings.Join(quoted, ", ")), binary: binary}
+	}
+	return &insertWriter{w: bw, dialect: d.dialect, batch: d.batch, binary: binary,
+		header: fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", d.dialect.quoteIdent(table), strings.Join(quoted, ", "))}
+}
+
+// insertWriter writes rows as INSERT statements of up to batch rows.
+type insertWriter struct {
+	w       *bufio.Writer
+	dialect Dialect
+	header  string
+	batch   int
+	binary  []bool
+	n       int
+}
+
+func (iw *insertWriter) row(values []interface{}) error {
+	if iw.n == 0 {
+		iw.w.WriteString(iw.header)
+	} else {
+		iw.w.WriteString(",\n")
+	}
+	iw.w.WriteString("  (")
+	for i, v := range values {
+		if i > 0 {
+			iw.w.WriteString(", ")
+		}
+		iw.w.WriteString(iw.dialect.literal(v, iw.binary[i]))
+	}
+	if iw.n++; iw.n == iw.batch {
+		iw.n = 0
+		// write errors are sticky, so the last write reports the first error
+		_, err := iw.w.WriteString(");\n")
+		return err
+	}
+	_, err := iw.w.WriteString(")")
+	return err
+}
+
+func (iw *insertWriter) close() error {
+	if iw.n > 0 {
+		iw.w.WriteString(";\n")
+	}
+	return iw.w.Flush()
+}
+
+// copyWriter writes rows as data of a COPY statement in text format.
+type copyWriter struct {
+	w      *bufio.Writer
+	header string
+	binary []bool
+}
+
+var copyEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func (cw *copyWriter) row(values []interface{}) error {
+	if cw.header != "" {
+		cw.w.WriteString(cw.header)
+		cw.header = ""
+	}
+	for i, v := range values {
+		if i > 0 {
+			cw.w.WriteByte('\t')
+		}
+		switch v := v.(type) {
+		case nil:
+			cw.w.WriteString(`\N`)
+		case bool:
+			cw.w.WriteString(strconv.FormatBool(v)[:1])
+		case time.Time:
+			cw.w.WriteString(v.Format("2006-01-02 15:04:05.999999Z07:00"))
+		case []byte:
+			if cw.binary[i] {
+				cw.w.WriteString(`\\x` + hex.EncodeToString(v))
+			} else {
+				copyEscaper.WriteString(cw.w, string(v))
+			}
+		default:
+			copyEscaper.WriteString(cw.w, fmt.Sprint(v))
+		}
+	}
+	// write errors are sticky, so the last write reports the first error
+	return cw.w.WriteByte('\n')
+}
+
+func (cw *copyWriter) close() error {
+	if cw.header == "" {
+		// rows have been written
+		cw.w.WriteString("\\.\n")
+	}
+	return cw.w.Flush()
+}
@@ -0,0 +1,230 @@
+// Package maskdb dumps database tables as SQL with their sensitive columns masked,
+// e.g. to seed staging databases from production:
+//
+//	d := maskdb.New(db, maskdb.Postgres)
+//	err := d.Dump(ctx, w, maskdb.Table{
+//		Name:    "users",
+//		Columns: map[string]mask.Strategy{"email": maskers.Sequence("user-%d@example.com")},
+//	})
+//
+// Rows are streamed, so memory does not grow with the number of rows. All columns are
+// masked within a single mask.Session, so equal values are masked alike across tables and
+// join keys, e.g. emails referenced by other tables, are preserved. The session holds the
+// surrogate of every distinct value masked, so memory grows with the number of distinct
+// values; WithoutSession dumps huge tables in constant memory using keyed strategies,
+// e.g. maskers.Hash. The dump consists of INSERT statements or, for PostgreSQL, COPY
+// data, see WithCopy.
+//
+// Only database/sql is used; register the driver of the database as usual.
+package maskdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	mask "github.com/doejon/go-mask"
+)
+
+// ErrUnknownColumn is returned if a table lacks a column configured to be masked,
+// so that a typo never lets a column pass unmasked.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// Table selects the rows of a table to dump.
+type Table struct {
+	Name string
+	// Columns masks the values of columns by name; other columns are dumped unchanged.
+	Columns map[string]mask.Strategy
+	// Where restricts the rows dumped, e.g. "created_at > now() - interval '30 days'".
+	Where string
+}
+
+// Dumper writes masked dumps of tables, see New.
+type Dumper struct {
+	db      *sql.DB
+	dialect Dialect
+	opts    []mask.Option
+	session *mask.Session
+	// stateless is set by WithoutSession.
+	stateless bool
+	copy      bool
+	batch     int
+}
+
+// Option configures a Dumper.
+type Option func(*Dumper)
+
+// WithMaskOptions masks the rows of all tables using opts, e.g. the options of a policy.
+// Rows are masked as map[string]interface{} holding the row by the table name, so paths
+// like "users.email" locate the column of a table, and "**.email" those of all tables.
+func WithMaskOptions(opts ...mask.Option) Option {
+	return func(d *Dumper) {
+		d.opts = append(d.opts, opts...)
+	}
+}
+
+// WithSession masks columns within session rather than a session of their own.
+func WithSession(session *mask.Session) Option {
+	return func(d *Dumper) {
+		d.session = session
+		d.stateless = false
+	}
+}
+
+// WithoutSession masks every value on its own rather than within a session, so memory
+// stays constant however many distinct values are masked. Equal values are masked alike
+// by deterministic strategies only, e.g. maskers.Hash with a key, which preserve join keys
+// without holding surrogates:
+//
+//	d := maskdb.New(db, maskdb.Postgres, maskdb.WithoutSession())
+//	err := d.Dump(ctx, w, maskdb.Table{
+//		Name:    "users",
+//		Columns: map[string]mask.Strategy{"email": maskers.Hash(key)},
+//	})
+func WithoutSession() Option {
+	return func(d *Dumper) {
+		d.session = nil
+		d.stateless = true
+	}
+}
+
+// WithCopy writes COPY data rather than INSERT statements, which PostgreSQL loads faster.
+func WithCopy() Option {
+	return func(d *Dumper) {
+		d.copy = true
+	}
+}
+
+// WithBatchSize inserts up to n rows per INSERT statement; it defaults to 100.
+func WithBatchSize(n int) Option {
+	return func(d *Dumper) {
+		if n > 0 {
+			d.batch = n
+		}
+	}
+}
+
+// New returns a Dumper reading from db, writing SQL for dialect.
+func New(db *sql.DB, dialect Dialect, opts ...Option) *Dumper {
+	d := &Dumper{db: db, dialect: dialect, batch: 100}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.session == nil && !d.stateless {
+		d.session = mask.NewSession()
+	}
+	return d
+}
+
+// Dump writes the masked rows of tables to w, in the order given.
+func (d *Dumper) Dump(ctx context.Context, w io.Writer, tables ...Table) error {
+	if d.copy && d.dialect != Postgres {
+		return fmt.Errorf("COPY is not supported by %v", d.dialect)
+	}
+	for _, t := range tables {
+		if err := d.dumpTable(ctx, w, t); err != nil {
+			return fmt.Errorf("table %s: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func (d *Dumper) dumpTable(ctx context.Context, w io.Writer, t Table) error {
+	query := "SELECT * FROM " + d.dialect.quoteIdent(t.Name)
+	if t.Where != "" {
+		query += " WHERE " + t.Where
+	}
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	columns := make([]string, len(types))
+	binary := make([]bool, len(types))
+	strategies := make([]mask.Strategy, len(types))
+	for i, ct := range types {
+		columns[i] = ct.Name()
+		binary[i] = binaryTypes[strings.ToUpper(ct.DatabaseTypeName())]
+		strategies[i] = t.Columns[ct.Name()]
+	}
+	for name := range t.Columns {
+		found := false
+		for _, col := range columns {
+			found = found || col == name
+		}
+		if !found {
+			return fmt.Errorf("%w %q", ErrUnknownColumn, name)
+		}
+	}
+
+	out := d.newWriter(w, t.Name, columns, binary)
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		row, err := d.maskRow(t.Name, columns, binary, strategies, values)
+		if err != nil {
+			return err
+		}
+		if err := out.row(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return out.close()
+}
+
+// maskRow masks the values of a row, returned by the driver, of the table name.
+func (d *Dumper) maskRow(name string, columns []string, binary []bool, strategies []mask.Strategy, values []interface{}) ([]interface{}, error) {
+	row := make([]interface{}, len(values))
+	for i, v := range values {
+		// drivers return text as bytes; strategies expect strings
+		if b, ok := v.([]byte); ok && !binary[i] {
+			v = string(b)
+		}
+		if strategies[i] != nil {
+			masked, err := d.session.Apply(strategies[i], v)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", columns[i], err)
+			}
+			v = masked
+		}
+		row[i] = v
+	}
+	if len(d.opts) == 0 {
+		return row, nil
+	}
+	m := make(map[string]interface{}, len(columns))
+	for i, col := range columns {
+		m[col] = row[i]
+	}
+	opts := d.opts
+	if d.session != nil {
+		opts = append(opts[:len(opts):len(opts)], mask.WithSession(d.session))
+	}
+	masked, err := mask.Mask(map[string]interface{}{name: m}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	m, _ = masked[name].(map[string]interface{})
+	for i, col := range columns {
+		// omitted columns are NULL
+		row[i] = m[col]
+	}
+	return row, nil
+}
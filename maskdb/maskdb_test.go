@@ -0,0 +1,148 @@
+package maskdb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/internal/sqltest"
+	"github.com/doejon/go-mask/maskers"
+)
+
+func init() {
+	sqltest.Register("maskdbtest", map[string]sqltest.Table{
+		"users": {
+			Columns: []string{"id", "email", "name", "active", "avatar", "created"},
+			Types:   []string{"INT8", "TEXT", "TEXT", "BOOL", "BYTEA", "TIMESTAMPTZ"},
+			Rows: [][]driver.Value{
+				{int64(1), []byte("ada@example.com"), []byte("Ada"), true, []byte{0xca, 0xfe}, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+				{int64(2), []byte("bob@example.com"), []byte("Bob's\ttab"), false, nil, time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		"orders": {
+			Columns: []string{"id", "email", "total"},
+			Types:   []string{"INT8", "TEXT", "FLOAT8"},
+			Rows: [][]driver.Value{
+				{int64(7), []byte("bob@example.com"), 9.5},
+			},
+		},
+	})
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("maskdbtest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestDump(t *testing.T) {
+	db := openTestDB(t)
+	email := maskers.Sequence("user%d@example.com")
+	d := New(db, Postgres, WithBatchSize(1))
+	var out bytes.Buffer
+	err := d.Dump(context.Background(), &out,
+		Table{Name: "users", Columns: map[string]mask.Strategy{"email": email, "name": maskers.Partial(1, 0, maskers.Format{})}},
+		Table{Name: "orders", Columns: map[string]mask.Strategy{"email": email}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `INSERT INTO "users" ("id", "email", "name", "active", "avatar", "created") VALUES
+  (1, 'user1@example.com', 'A**', TRUE, '\xcafe', '2024-01-02 03:04:05Z');
+INSERT INTO "users" ("id", "email", "name", "active", "avatar", "created") VALUES
+  (2, 'user2@example.com', 'B********', FALSE, NULL, '2024-01-03 00:00:00Z');
+INSERT INTO "orders" ("id", "email", "total") VALUES
+  (7, 'user2@example.com', 9.5);
+`
+	if out.String() != expect {
+		t.Errorf("expect %s == %s", out.String(), expect)
+	}
+}
+
+func TestDumpWithoutSession(t *testing.T) {
+	db := openTestDB(t)
+	email := maskers.Hash([]byte("key"))
+	session := mask.NewSession()
+	d := New(db, Postgres, WithSession(session), WithoutSession(), WithMaskOptions(mask.WithPathStrategy("**.name", email)))
+	var out bytes.Buffer
+	err := d.Dump(context.Background(), &out,
+		Table{Name: "users", Columns: map[string]mask.Strategy{"email": email}},
+		Table{Name: "orders", Columns: map[string]mask.Strategy{"email": email}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, _ := email.Mask(reflect.ValueOf("bob@example.com"))
+	if n := strings.Count(out.String(), bob.String()); n != 2 {
+		t.Errorf("expect the email of bob to be masked alike across tables, found %d times in %s", n, out.String())
+	}
+	if strings.Contains(out.String(), "@example.com") || strings.Contains(out.String(), "'Ada'") {
+		t.Errorf("expect %s to be masked", out.String())
+	}
+	if session.Len() != 0 {
+		t.Errorf("expect no surrogates to be held, got %d", session.Len())
+	}
+}
+
+func TestDumpMySQL(t *testing.T) {
+	db := openTestDB(t)
+	d := New(db, MySQL, WithMaskOptions(mask.WithPathStrategy("users.name", maskers.Partial(1, 0, maskers.Format{}))))
+	var out bytes.Buffer
+	if err := d.Dump(context.Background(), &out, Table{Name: "users", Where: "id = 1"}); err != nil {
+		t.Fatal(err)
+	}
+	expect := "INSERT INTO `users` (`id`, `email`, `name`, `active`, `avatar`, `created`) VALUES\n" +
+		"  (1, 'ada@example.com', 'A**', 1, X'cafe', '2024-01-02 03:04:05');\n"
+	if out.String() != expect {
+		t.Errorf("expect %s == %s", out.String(), expect)
+	}
+
+	out.Reset()
+	if err := New(db, MySQL).Dump(context.Background(), &out, Table{Name: "users"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `'Bob''s	tab'`) {
+		t.Errorf("expect %s to quote strings", out.String())
+	}
+	if err := New(db, MySQL, WithCopy()).Dump(context.Background(), &out, Table{Name: "users"}); err == nil {
+		t.Error("expect COPY to fail for MySQL")
+	}
+}
+
+func TestDumpCopy(t *testing.T) {
+	db := openTestDB(t)
+	session := mask.NewSession()
+	d := New(db, Postgres, WithCopy(), WithSession(session), WithMaskOptions(mask.WithPathStrategy("**.email", mask.Omit())))
+	var out bytes.Buffer
+	if err := d.Dump(context.Background(), &out, Table{Name: "users"}); err != nil {
+		t.Fatal(err)
+	}
+	expect := `COPY "users" ("id", "email", "name", "active", "avatar", "created") FROM stdin;
+1	\N	Ada	t	\\xcafe	2024-01-02 03:04:05Z
+2	\N	Bob's\ttab	f	\N	2024-01-03 00:00:00Z
+\.
+`
+	if out.String() != expect {
+		t.Errorf("expect %s == %s", out.String(), expect)
+	}
+}
+
+func TestDumpUnknownColumn(t *testing.T) {
+	db := openTestDB(t)
+	err := New(db, Postgres).Dump(context.Background(), io.Discard, Table{Name: "users", Columns: map[string]mask.Strategy{"mail": maskers.Trim()}})
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expect %v to be %v", err, ErrUnknownColumn)
+	}
+}
@@ -0,0 +1,104 @@
+// Package maskecho plugs masked body capture into the Echo request logger:
+//
+//	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+//		LogStatus: true,
+//		LogURI:    true,
+//		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+//			req, resp := maskecho.Bodies(c)
+//			slog.Info("request", "uri", v.URI, "status", v.Status, "request_body", req, "response_body", resp)
+//			return nil
+//		},
+//	}))
+//	e.Use(maskecho.Middleware(maskhttp.WithOptions(policyOpts...)))
+package maskecho
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+
+	"github.com/doejon/go-mask/maskhttp"
+	"github.com/labstack/echo/v4"
+)
+
+// Context keys of the masked bodies.
+const (
+	RequestBodyKey  = "mask.request_body"
+	ResponseBodyKey = "mask.response_body"
+)
+
+// Middleware captures the bodies of requests and responses masked using opts.
+// Register it after the request logger, so the logger sees the bodies once it runs.
+func Middleware(opts ...maskhttp.Option) echo.MiddlewareFunc {
+	m := maskhttp.New(opts...)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := m.CaptureRequest(c.Request())
+			w := &bodyWriter{ResponseWriter: c.Response().Writer, body: m.NewBody()}
+			c.Response().Writer = w
+			err := next(c)
+			c.Response().Writer = w.ResponseWriter
+			c.Set(RequestBodyKey, string(m.Mask(c.Request().Header.Get(echo.HeaderContentType), req)))
+			c.Set(ResponseBodyKey, string(m.Mask(c.Response().Header().Get(echo.HeaderContentType), w.body)))
+			return err
+		}
+	}
+}
+
+// BodyDump returns a middleware passing the masked bodies of each request to
+// handler, like middleware.BodyDump does with the unmasked ones.
+func BodyDump(handler func(c echo.Context, reqBody, resBody []byte), opts ...maskhttp.Option) echo.MiddlewareFunc {
+	capture := Middleware(opts...)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		h := capture(func(c echo.Context) error {
+			err := next(c)
+			if err != nil {
+				// Write the error response before the body is masked.
+				c.Error(err)
+			}
+			return err
+		})
+		return func(c echo.Context) error {
+			err := h(c)
+			req, resp := Bodies(c)
+			handler(c, []byte(req), []byte(resp))
+			return err
+		}
+	}
+}
+
+// Bodies returns the masked bodies captured by Middleware.
+func Bodies(c echo.Context) (request, response string) {
+	request, _ = c.Get(RequestBodyKey).(string)
+	response, _ = c.Get(ResponseBodyKey).(string)
+	return request, response
+}
+
+// bodyWriter captures the body written by handlers.
+type bodyWriter struct {
+	http.ResponseWriter
+	body *maskhttp.Body
+}
+
+func (w *bodyWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.body.Write(p[:n])
+	return n, err
+}
+
+func (w *bodyWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *bodyWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+func (w *bodyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
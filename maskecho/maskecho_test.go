@@ -0,0 +1,84 @@
+package maskecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskhttp"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func testOptions(t *testing.T) maskhttp.Option {
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return maskhttp.WithOptions(mask.WithPathStrategy("**.password", redact))
+}
+
+func echoUser(c echo.Context) error {
+	var body struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, body)
+}
+
+func serve(e *echo.Echo, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	var req, resp string
+	e := echo.New()
+	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogStatus: true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			req, resp = Bodies(c)
+			return nil
+		},
+	}))
+	e.Use(Middleware(testOptions(t)))
+	e.POST("/users", echoUser)
+
+	rec := serve(e, `{"name":"alice","password":"s3cr3t"}`)
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), "s3cr3t") {
+		t.Errorf("expect the response not to be modified, got %d %s", rec.Code, rec.Body.String())
+	}
+	if expect := `{"name":"alice","password":"MASKED"}`; req != expect || resp != expect {
+		t.Errorf("expect %v and %v == %v", req, resp, expect)
+	}
+}
+
+func TestBodyDump(t *testing.T) {
+	var req, resp []byte
+	e := echo.New()
+	e.Use(BodyDump(func(c echo.Context, reqBody, resBody []byte) {
+		req, resp = reqBody, resBody
+	}, testOptions(t)))
+	e.POST("/users", echoUser)
+
+	serve(e, `{"name":"alice","password":"s3cr3t"}`)
+	if expect := `{"name":"alice","password":"MASKED"}`; string(req) != expect || string(resp) != expect {
+		t.Errorf("expect %s and %s == %v", req, resp, expect)
+	}
+
+	rec := serve(e, `{"password":`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expect %v == %v", rec.Code, http.StatusBadRequest)
+	}
+	if string(req) != "[12 bytes omitted]" || !strings.Contains(string(resp), "message") {
+		t.Errorf("expect the error response to be dumped, got %s and %s", req, resp)
+	}
+}
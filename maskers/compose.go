@@ -0,0 +1,34 @@
+package maskers
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Compose returns a strategy applying strategies in order, each masking the result
+// of the previous one, e.g. trimming, hashing and truncating a value:
+//
+//	maskers.Compose(maskers.Trim(), maskers.Hash(key), maskers.Truncate(12))
+//
+// Its name joins the names of strategies by "|", like the tag `mask:"trim|hash|truncate=12"`.
+func Compose(strategies ...Strategy) Strategy {
+	names := make([]string, len(strategies))
+	for i, s := range strategies {
+		names[i] = s.Name()
+	}
+	return Func(strings.Join(names, "|"), func(v reflect.Value) (reflect.Value, error) {
+		t := v.Type()
+		for _, s := range strategies {
+			out, err := s.Mask(v)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			// keep named types, so strategies see the type of the masked value
+			if out.IsValid() && out.Type() != t && out.Type().ConvertibleTo(t) {
+				out = out.Convert(t)
+			}
+			v = out
+		}
+		return v, nil
+	})
+}
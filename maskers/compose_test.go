@@ -0,0 +1,37 @@
+package maskers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompose(t *testing.T) {
+	type email string
+	s := Compose(Trim(), Hash(nil), Truncate(12))
+	if s.Name() != "trim|hash|truncate" {
+		t.Errorf("expect %v == %v", s.Name(), "trim|hash|truncate")
+	}
+	out, err := s.Mask(reflect.ValueOf(email(" ada@example.com\n")))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// sha256("ada@example.com")
+	if out.Interface() != email("b5fc85e55755…") {
+		t.Errorf("expect %v == %v", out.Interface(), email("b5fc85e55755…"))
+	}
+
+	if _, err := Compose(Trim(), Round(10)).Mask(reflect.ValueOf("10")); err == nil {
+		t.Errorf("expect an error of the second strategy")
+	}
+}
+
+func TestHash(t *testing.T) {
+	out, err := Hash([]byte("key")).Mask(reflect.ValueOf("ada"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	other, _ := Hash([]byte("other")).Mask(reflect.ValueOf("ada"))
+	if len(out.String()) != 64 || out.String() == other.String() {
+		t.Errorf("expect %v to depend on the key", out.String())
+	}
+}
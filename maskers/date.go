@@ -0,0 +1,133 @@
+package maskers
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// DatePrecision defines which part of a date is kept by Date.
+type DatePrecision int
+
+const (
+	// Year keeps the year only; dates are moved to January 1st.
+	Year DatePrecision = iota
+	// Month keeps year and month; dates are moved to the first of the month.
+	Month
+)
+
+// ParseDatePrecision parses "year" or "month".
+func ParseDatePrecision(s string) (DatePrecision, error) {
+	switch s {
+	case "", "year":
+		return Year, nil
+	case "month":
+		return Month, nil
+	}
+	return 0, fmt.Errorf("unknown date precision %q", s)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// dateLayouts are tried in order when masking dates stored as strings.
+var dateLayouts = []string{
+	time.DateOnly,
+	time.RFC3339Nano,
+	time.DateTime,
+}
+
+// Date returns a strategy generalizing dates to the given precision.
+// It handles time.Time values as well as strings in one of the formats
+// 2006-01-02, RFC 3339 or 2006-01-02 15:04:05; masked strings keep their format.
+// Time of day is dropped, the location is kept.
+func Date(p DatePrecision) Strategy {
+	return dateFunc("date", func(t time.Time) time.Time {
+		month := time.January
+		if p == Month {
+			month = t.Month()
+		}
+		return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())
+	})
+}
+
+func dateFunc(name string, fn func(t time.Time) time.Time) Strategy {
+	return Func(name, func(v reflect.Value) (reflect.Value, error) {
+		if v.Type().ConvertibleTo(timeType) && v.Kind() == reflect.Struct {
+			t := v.Convert(timeType).Interface().(time.Time)
+			if t.IsZero() {
+				return v, nil
+			}
+			return reflect.ValueOf(fn(t)), nil
+		}
+		if v.Kind() == reflect.String {
+			if v.String() == "" {
+				return v, nil
+			}
+			t, layout, err := parseDate(v.String())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("strategy %s: %v", name, err)
+			}
+			return reflect.ValueOf(fn(t).Format(layout)), nil
+		}
+		return reflect.Value{}, fmt.Errorf("strategy %s: must pass a time.Time or a string; got %v", name, v.Type())
+	})
+}
+
+func parseDate(s string) (time.Time, string, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("unable to parse %q as date", s)
+}
+
+// AgeRange returns a strategy generalizing birth dates and ages into buckets
+// of width years, e.g. 30-39 for a width of 10. Age is computed from the year
+// of birth only, so a bucket is stable for a whole calendar year.
+//
+//   - time.Time values are replaced by January 1st of the earliest year of birth within the bucket
+//   - strings holding a date (see Date) are replaced by the bucket, e.g. "30-39"
+//   - integers are taken as an age and replaced by the lower bound of their bucket
+func AgeRange(width int) Strategy {
+	return ageRange(width, time.Now)
+}
+
+func ageRange(width int, now func() time.Time) Strategy {
+	if width < 1 {
+		width = 1
+	}
+	bucket := func(age int) int {
+		if age < 0 {
+			return 0
+		}
+		return age / width * width
+	}
+	return Func("agerange", func(v reflect.Value) (reflect.Value, error) {
+		switch {
+		case v.Kind() == reflect.Struct && v.Type().ConvertibleTo(timeType):
+			t := v.Convert(timeType).Interface().(time.Time)
+			if t.IsZero() {
+				return v, nil
+			}
+			year := now().Year() - bucket(now().Year()-t.Year()) - width + 1
+			return reflect.ValueOf(time.Date(year, time.January, 1, 0, 0, 0, 0, t.Location())), nil
+		case v.Kind() == reflect.String:
+			if v.String() == "" {
+				return v, nil
+			}
+			t, _, err := parseDate(v.String())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("strategy agerange: %v", err)
+			}
+			lo := bucket(now().Year() - t.Year())
+			return reflect.ValueOf(strconv.Itoa(lo) + "-" + strconv.Itoa(lo+width-1)), nil
+		case v.CanInt():
+			return reflect.ValueOf(int64(bucket(int(v.Int())))), nil
+		case v.CanUint():
+			return reflect.ValueOf(uint64(bucket(int(v.Uint())))), nil
+		}
+		return reflect.Value{}, fmt.Errorf("strategy agerange: must pass a time.Time, a string or an integer; got %v", v.Type())
+	})
+}
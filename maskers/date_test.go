@@ -0,0 +1,68 @@
+package maskers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDate(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	birth := time.Date(1987, time.May, 3, 13, 14, 15, 0, berlin)
+
+	tests := []struct {
+		strategy Strategy
+		in       interface{}
+		expected interface{}
+	}{
+		{Date(Year), birth, time.Date(1987, time.January, 1, 0, 0, 0, 0, berlin)},
+		{Date(Month), birth, time.Date(1987, time.May, 1, 0, 0, 0, 0, berlin)},
+		{Date(Year), time.Time{}, time.Time{}},
+		{Date(Year), "1987-05-03", "1987-01-01"},
+		{Date(Month), "1987-05-03T13:14:15Z", "1987-05-01T00:00:00Z"},
+		{Date(Month), "1987-05-03 13:14:15", "1987-05-01 00:00:00"},
+		{Date(Year), "", ""},
+	}
+	for _, test := range tests {
+		out, err := test.strategy.Mask(reflect.ValueOf(test.in))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !reflect.DeepEqual(out.Interface(), test.expected) {
+			t.Errorf("expect %v == %v", out.Interface(), test.expected)
+		}
+	}
+
+	if _, err := Date(Year).Mask(reflect.ValueOf("yesterday")); err == nil {
+		t.Errorf("expected an error for an unparsable date")
+	}
+	if _, err := Date(Year).Mask(reflect.ValueOf(1987)); err == nil {
+		t.Errorf("expected an error for an integer")
+	}
+}
+
+func TestAgeRange(t *testing.T) {
+	now := func() time.Time { return time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC) }
+	s := ageRange(10, now)
+
+	tests := []struct {
+		in       interface{}
+		expected interface{}
+	}{
+		{"1987-05-03", "30-39"},
+		{"2020-01-01", "0-9"},
+		{time.Date(1987, time.May, 3, 0, 0, 0, 0, time.UTC), time.Date(1987, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(1995, time.May, 3, 0, 0, 0, 0, time.UTC), time.Date(1987, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{37, int64(30)},
+		{uint8(42), uint64(40)},
+	}
+	for _, test := range tests {
+		out, err := s.Mask(reflect.ValueOf(test.in))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !reflect.DeepEqual(out.Interface(), test.expected) {
+			t.Errorf("expect %v == %v", out.Interface(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package maskers
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Reversible is implemented by strategies whose masking can be undone
+// by authorized services holding the required secrets.
+type Reversible interface {
+	Strategy
+	// Unmask returns the original value of the masked value v.
+	Unmask(v reflect.Value) (reflect.Value, error)
+}
+
+// ErrDecrypt is returned for values which cannot be decrypted, e.g. because
+// they were encrypted using another key or have been tampered with.
+var ErrDecrypt = errors.New("unable to decrypt")
+
+type encrypt struct {
+	// current returns the AEAD to encrypt with and the ID of its key, if any.
+	current func() (cipher.AEAD, string, error)
+	// byID returns the AEAD for the key ID a ciphertext is prefixed with.
+	byID func(id string) (cipher.AEAD, error)
+}
+
+// Encrypt returns a reversible strategy replacing strings and byte slices with their
+// base64 encoded ciphertext, using a random nonce for each value. The ciphertext
+// is prefixed with the nonce, so equal values result in different ciphertexts.
+// Use Decrypt or the Unmask method of the strategy to restore the original values.
+func Encrypt(aead cipher.AEAD) Reversible {
+	return &encrypt{
+		current: func() (cipher.AEAD, string, error) { return aead, "", nil },
+		byID:    func(string) (cipher.AEAD, error) { return aead, nil },
+	}
+}
+
+// EncryptWithKeys works like Encrypt using AES-GCM with the current key of keys.
+// The ciphertext is prefixed with the ID of the key, e.g. "k2:<base64>",
+// so values encrypted before a key rotation can still be decrypted.
+func EncryptWithKeys(keys KeyProvider) Reversible {
+	var aeads sync.Map
+	byID := func(id string) (cipher.AEAD, error) {
+		if aead, ok := aeads.Load(id); ok {
+			return aead.(cipher.AEAD), nil
+		}
+		k, err := keys.Key(id)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := aesGCM(k)
+		if err != nil {
+			return nil, err
+		}
+		aeads.Store(id, aead)
+		return aead, nil
+	}
+	return &encrypt{
+		current: func() (cipher.AEAD, string, error) {
+			k, err := keys.Current()
+			if err != nil {
+				return nil, "", err
+			}
+			aead, err := byID(k.ID)
+			return aead, k.ID, err
+		},
+		byID: byID,
+	}
+}
+
+// Decrypt returns a strategy restoring values masked by Encrypt, e.g. to register
+// it for the tag used by Encrypt in services authorized to see the original values.
+func Decrypt(aead cipher.AEAD) Strategy {
+	return Func("decrypt", Encrypt(aead).Unmask)
+}
+
+// DecryptWithKeys returns a strategy restoring values masked by EncryptWithKeys.
+func DecryptWithKeys(keys KeyProvider) Strategy {
+	return Func("decrypt", EncryptWithKeys(keys).Unmask)
+}
+
+func (e *encrypt) Name() string {
+	return "encrypt"
+}
+
+func (e *encrypt) Mask(v reflect.Value) (reflect.Value, error) {
+	plain, err := bytesOf(v)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	aead, id, err := e.current()
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("strategy encrypt: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return reflect.Value{}, fmt.Errorf("strategy encrypt: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, nil))
+	if id != "" {
+		encoded = id + ":" + encoded
+	}
+	return fromBytes(v, []byte(encoded)), nil
+}
+
+func (e *encrypt) Unmask(v reflect.Value) (reflect.Value, error) {
+	encoded, err := bytesOf(v)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	id, data, ok := strings.Cut(string(encoded), ":")
+	if !ok {
+		id, data = "", id
+	}
+	aead, err := e.byID(id)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	n := aead.NonceSize()
+	if len(sealed) < n {
+		return reflect.Value{}, fmt.Errorf("%w: ciphertext too short", ErrDecrypt)
+	}
+	plain, err := aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	return fromBytes(v, plain), nil
+}
+
+// bytesOf returns the content of strings and byte slices.
+func bytesOf(v reflect.Value) ([]byte, error) {
+	switch {
+	case v.Kind() == reflect.String:
+		return []byte(v.String()), nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		return v.Bytes(), nil
+	}
+	return nil, fmt.Errorf("must pass a string or byte slice; got %v", v.Type())
+}
+
+// fromBytes returns b as a value of the kind of v.
+func fromBytes(v reflect.Value, b []byte) reflect.Value {
+	if v.Kind() == reflect.String {
+		return reflect.ValueOf(string(b))
+	}
+	return reflect.ValueOf(b)
+}
@@ -0,0 +1,69 @@
+package maskers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testAEAD(t *testing.T, key string) cipher.AEAD {
+	t.Helper()
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aead
+}
+
+func TestEncrypt(t *testing.T) {
+	aead := testAEAD(t, "0123456789abcdef")
+	e := Encrypt(aead)
+
+	for _, val := range []interface{}{"ada@example.com", []byte("secret")} {
+		v := reflect.ValueOf(val)
+		masked, err := e.Mask(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, _ := e.Mask(v)
+		if reflect.DeepEqual(masked.Interface(), val) || reflect.DeepEqual(masked.Interface(), again.Interface()) {
+			t.Errorf("expect %v to be encrypted using a random nonce", masked)
+		}
+		for _, s := range []interface {
+			Unmask(reflect.Value) (reflect.Value, error)
+		}{e, decryptFunc{Decrypt(aead)}} {
+			plain, err := s.Unmask(masked)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(plain.Interface(), val) {
+				t.Errorf("expect %v == %v", plain, val)
+			}
+		}
+	}
+
+	masked, _ := e.Mask(reflect.ValueOf("secret"))
+	if _, err := Encrypt(testAEAD(t, "fedcba9876543210")).Unmask(masked); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expect %v to be ErrDecrypt using another key", err)
+	}
+	if _, err := e.Unmask(reflect.ValueOf("not base64!")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expect %v to be ErrDecrypt", err)
+	}
+	if _, err := e.Mask(reflect.ValueOf(42)); err == nil {
+		t.Errorf("expect ints to be rejected")
+	}
+}
+
+type decryptFunc struct {
+	Strategy
+}
+
+func (d decryptFunc) Unmask(v reflect.Value) (reflect.Value, error) {
+	return d.Mask(v)
+}
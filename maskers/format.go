@@ -0,0 +1,124 @@
+package maskers
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Format configures how characters are replaced by strategies
+// masking parts of a string. The zero value masks using '*'.
+type Format struct {
+	// Char replaces every masked character; defaults to '*'.
+	Char rune
+	// FullWidth replaces wide characters (e.g. CJK or emoji) with the
+	// full-width form of Char, so masked strings keep their display width.
+	FullWidth bool
+}
+
+func (f Format) char() rune {
+	if f.Char == 0 {
+		return '*'
+	}
+	return f.Char
+}
+
+// maskCluster returns the replacement of a single grapheme cluster.
+func (f Format) maskCluster(cluster string) string {
+	c := f.char()
+	if f.FullWidth && isWide(cluster) && c >= 0x21 && c <= 0x7e {
+		c += 0xfee0
+	}
+	return string(c)
+}
+
+// mask replaces all grapheme clusters of s.
+func (f Format) mask(clusters []string) string {
+	var b strings.Builder
+	for _, c := range clusters {
+		b.WriteString(f.maskCluster(c))
+	}
+	return b.String()
+}
+
+const zwj = 0x200d
+
+// graphemes splits s into user-perceived characters.
+// It approximates extended grapheme clusters (Unicode UAX #29): combining marks,
+// variation selectors, emoji modifiers, zero-width-joiner sequences and regional
+// indicator pairs (flags) stay attached to their base character.
+func graphemes(s string) []string {
+	var out []string
+	start := 0
+	prev := rune(-1)
+	regional := 0
+	for i, r := range s {
+		if i > start && !extendsCluster(prev, r, regional) {
+			out = append(out, s[start:i])
+			start = i
+			regional = 0
+		}
+		if isRegionalIndicator(r) {
+			regional++
+		}
+		prev = r
+	}
+	if start < len(s) {
+		out = append(out, s[start:])
+	}
+	return out
+}
+
+func extendsCluster(prev, r rune, regional int) bool {
+	switch {
+	case prev == '\r' && r == '\n':
+		return true
+	case r == zwj, prev == zwj:
+		return true
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true
+	case r >= 0xfe00 && r <= 0xfe0f: // variation selectors
+		return true
+	case r >= 0x1f3fb && r <= 0x1f3ff: // emoji skin tone modifiers
+		return true
+	case r >= 0xe0020 && r <= 0xe007f: // emoji tag sequences
+		return true
+	case isRegionalIndicator(prev) && isRegionalIndicator(r) && regional%2 == 1:
+		return true
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f},   // Hangul Jamo
+	{0x2e80, 0x303e},   // CJK radicals, punctuation
+	{0x3041, 0x33ff},   // Hiragana, Katakana, CJK compatibility
+	{0x3400, 0x4dbf},   // CJK extension A
+	{0x4e00, 0x9fff},   // CJK unified ideographs
+	{0xa000, 0xa4cf},   // Yi
+	{0xac00, 0xd7a3},   // Hangul syllables
+	{0xf900, 0xfaff},   // CJK compatibility ideographs
+	{0xfe30, 0xfe4f},   // CJK compatibility forms
+	{0xff00, 0xff60},   // fullwidth forms
+	{0xffe0, 0xffe6},   // fullwidth signs
+	{0x1f1e6, 0x1f1ff}, // regional indicators
+	{0x1f300, 0x1f64f}, // pictographs, emoticons
+	{0x1f900, 0x1f9ff}, // supplemental pictographs
+	{0x20000, 0x3fffd}, // CJK extensions
+}
+
+// isWide reports whether cluster is displayed using two columns.
+func isWide(cluster string) bool {
+	for _, r := range cluster {
+		for _, rg := range wideRanges {
+			if r >= rg[0] && r <= rg[1] {
+				return true
+			}
+		}
+		return strings.ContainsRune(cluster, 0xfe0f) // emoji presentation
+	}
+	return false
+}
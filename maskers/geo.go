@@ -0,0 +1,183 @@
+package maskers
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// metersPerDegree is the length of a degree of latitude, and of longitude at the equator.
+// Degrees of longitude are shorter by the cosine of the latitude.
+const metersPerDegree = 111_320
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// latFields and lngFields are the names of the coordinate fields of geo structs.
+var (
+	latFields = []string{"Lat", "Latitude"}
+	lngFields = []string{"Lng", "Lon", "Long", "Longitude"}
+)
+
+// Geo returns a strategy reducing the precision of locations to at least radius meters,
+// e.g. 1000 to keep the neighborhood but not the address. It handles
+//
+//   - floats, taken as latitude or longitude, rounded to the decimals of the finest
+//     step of at least radius meters, e.g. 52.52 for 52.520008 and 1000
+//   - arrays and slices of floats like GeoJSON coordinates, [lng, lat]
+//   - strings holding "lat,lng" pairs, or geohashes, which are shortened to cells
+//     at least radius meters wide and high, e.g. "u33dc" for "u33dc0cppj" and 1000
+//   - structs with float fields named Lat or Latitude and Lng, Lon, Long or Longitude;
+//     their other fields are kept
+//
+// Degrees of longitude shrink towards the poles, so longitudes are rounded to steps
+// scaled by the latitude they are paired with, e.g. 13.4 rather than 13.40 at 52°.
+// Single floats are rounded like latitudes, for longitudes the radius holds at the
+// equator only; mask coordinates as pairs to coarsen them at any latitude.
+func Geo(radius float64) Strategy {
+	if radius <= 0 {
+		radius = 1
+	}
+	snapLat := func(x float64) float64 {
+		return geoRound(x, geoDecimals(radius, metersPerDegree))
+	}
+	snapLng := func(x, lat float64) float64 {
+		return geoRound(x, geoDecimals(radius, metersPerDegree*math.Cos(lat*math.Pi/180)))
+	}
+	return Func("geo", func(v reflect.Value) (reflect.Value, error) {
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			out := reflect.New(v.Type()).Elem()
+			out.SetFloat(snapLat(v.Float()))
+			return out, nil
+		case reflect.Array, reflect.Slice:
+			if k := v.Type().Elem().Kind(); k != reflect.Float32 && k != reflect.Float64 {
+				break
+			}
+			if v.Kind() == reflect.Slice && v.IsNil() {
+				return v, nil
+			}
+			out := reflect.New(v.Type()).Elem()
+			if v.Kind() == reflect.Slice {
+				out = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+			}
+			for i := 0; i < v.Len(); i++ {
+				out.Index(i).SetFloat(snapLat(v.Index(i).Float()))
+			}
+			if v.Len() >= 2 {
+				// [lng, lat, ...]
+				out.Index(0).SetFloat(snapLng(v.Index(0).Float(), out.Index(1).Float()))
+			}
+			return out, nil
+		case reflect.String:
+			s, err := geoString(v.String(), radius, snapLat, snapLng)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(s), nil
+		case reflect.Struct:
+			lat, lng := geoField(v, latFields), geoField(v, lngFields)
+			if lat < 0 || lng < 0 {
+				break
+			}
+			out := reflect.New(v.Type()).Elem()
+			out.Set(v)
+			out.Field(lat).SetFloat(snapLat(v.Field(lat).Float()))
+			out.Field(lng).SetFloat(snapLng(v.Field(lng).Float(), out.Field(lat).Float()))
+			return out, nil
+		}
+		return reflect.Value{}, fmt.Errorf("strategy geo: must pass a coordinate, geohash or geo struct; got %v", v.Type())
+	})
+}
+
+// geoDecimals returns the decimals of the finest step of degrees at least radius meters
+// long, given the length of a degree, e.g. 2 for 1000 meters, as 0.01° are about 1.1 km
+// of latitude. Near the poles, steps are limited to 100°.
+func geoDecimals(radius, metersPerDegree float64) int {
+	return int(math.Max(math.Floor(-math.Log10(radius/math.Max(metersPerDegree, 1e-9))), -2))
+}
+
+// geoRound rounds x to decimals.
+func geoRound(x float64, decimals int) float64 {
+	x = math.Round(x*math.Pow10(decimals)) / math.Pow10(decimals)
+	// drop artifacts like 52.519999999999996
+	x, _ = strconv.ParseFloat(strconv.FormatFloat(x, 'f', max(decimals, 0), 64), 64)
+	return x
+}
+
+// geohashSize returns the width and height in meters of geohash cells of length n
+// at latitude lat.
+func geohashSize(n int, lat float64) (width, height float64) {
+	lngBits, latBits := (5*n+1)/2, 5*n/2
+	width = 360 / math.Ldexp(1, lngBits) * metersPerDegree * math.Cos(lat*math.Pi/180)
+	height = 180 / math.Ldexp(1, latBits) * metersPerDegree
+	return width, height
+}
+
+// geohashLat returns the latitude of the center of the cell of the geohash s.
+func geohashLat(s string) float64 {
+	lo, hi := -90.0, 90.0
+	// bits alternate between longitude and latitude, starting with longitude
+	bit := 0
+	for _, c := range s {
+		n := strings.IndexRune(geohashAlphabet, c)
+		for b := 4; b >= 0; b-- {
+			if bit%2 == 1 {
+				if mid := (lo + hi) / 2; n>>b&1 == 1 {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			bit++
+		}
+	}
+	return (lo + hi) / 2
+}
+
+func geoString(s string, radius float64, snapLat func(float64) float64, snapLng func(float64, float64) float64) (string, error) {
+	if s == "" {
+		return s, nil
+	}
+	if lat, lng, ok := strings.Cut(s, ","); ok {
+		x, err1 := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		y, err2 := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+		if err1 != nil || err2 != nil {
+			return "", fmt.Errorf("strategy geo: invalid coordinates %q", s)
+		}
+		sep := ","
+		if strings.HasPrefix(lng, " ") {
+			sep = ", "
+		}
+		x = snapLat(x)
+		return strconv.FormatFloat(x, 'f', -1, 64) + sep + strconv.FormatFloat(snapLng(y, x), 'f', -1, 64), nil
+	}
+	lower := strings.ToLower(s)
+	for _, c := range lower {
+		if !strings.ContainsRune(geohashAlphabet, c) {
+			return "", fmt.Errorf("strategy geo: invalid geohash %q", s)
+		}
+	}
+	// keep the longest prefix whose cell is at least radius meters wide and high
+	n := 1
+	for ; n < len(s) && n < 12; n++ {
+		if width, height := geohashSize(n+1, geohashLat(lower[:n+1])); width < radius || height < radius {
+			break
+		}
+	}
+	return s[:n], nil
+}
+
+// geoField returns the index of the exported float field of the struct v named
+// like one of names, or -1.
+func geoField(v reflect.Value, names []string) int {
+	for _, name := range names {
+		if f, ok := v.Type().FieldByName(name); ok && len(f.Index) == 1 && f.IsExported() {
+			if k := f.Type.Kind(); k == reflect.Float32 || k == reflect.Float64 {
+				return f.Index[0]
+			}
+		}
+	}
+	return -1
+}
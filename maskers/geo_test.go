@@ -0,0 +1,53 @@
+package maskers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeo(t *testing.T) {
+	type point struct {
+		Lat, Lng float64
+		Name     string
+	}
+	type place struct {
+		Latitude  float32
+		Longitude float32
+	}
+	for _, test := range []struct {
+		in       interface{}
+		radius   float64
+		expected interface{}
+	}{
+		{52.520008, 1000.0, 52.52},
+		{13.404954, 1000.0, 13.4},
+		{13.404954, 100.0, 13.405},
+		{[2]float64{13.404954, 52.520008}, 1000.0, [2]float64{13.4, 52.52}},
+		{[]float64{13.404954, 52.520008}, 10000.0, []float64{13, 52.5}},
+		{"0.123456, 10.123456", 1000.0, "0.12, 10.12"},
+		{"60.123456, 10.123456", 1000.0, "60.12, 10.1"},
+		{"89.99, 10.123456", 1000.0, "89.99, 0"},
+		{point{Lat: 60.123456, Lng: 10.123456}, 1000.0, point{Lat: 60.12, Lng: 10.1}},
+		{"52.520008, 13.404954", 1000.0, "52.52, 13.4"},
+		{"u33dc0cppj", 1000.0, "u33dc"},
+		{"u33dc0cppj", 150000.0, "u3"},
+		{"s00twy01mt", 1000.0, "s00tw"},
+		{"u33", 1000.0, "u33"},
+		{point{Lat: 52.520008, Lng: 13.404954, Name: "Berlin"}, 1000.0, point{Lat: 52.52, Lng: 13.4, Name: "Berlin"}},
+		{place{Latitude: 52.520008, Longitude: 13.404954}, 1000.0, place{Latitude: 52.52, Longitude: 13.4}},
+	} {
+		out, err := Geo(test.radius).Mask(reflect.ValueOf(test.in))
+		if err != nil {
+			t.Fatalf("%v: expected no error, got %v", test.in, err)
+		}
+		if !reflect.DeepEqual(out.Interface(), test.expected) {
+			t.Errorf("%v: expect %v == %v", test.in, out.Interface(), test.expected)
+		}
+	}
+
+	for _, in := range []interface{}{"Berlin", "52.5,x", 42, struct{ Lat float64 }{}} {
+		if _, err := Geo(1000).Mask(reflect.ValueOf(in)); err == nil {
+			t.Errorf("%v: expect an error", in)
+		}
+	}
+}
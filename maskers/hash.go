@@ -0,0 +1,29 @@
+package maskers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Hash returns a strategy replacing strings by the hex encoded HMAC-SHA256 of them
+// using key, so equal values can still be matched. Without a key, the SHA-256 of
+// values is used, which can be reversed for guessable values by trying them.
+func Hash(key []byte) Strategy {
+	return StringFunc("hash", func(s string) string {
+		if key == nil {
+			sum := sha256.Sum256([]byte(s))
+			return hex.EncodeToString(sum[:])
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(s))
+		return hex.EncodeToString(mac.Sum(nil))
+	})
+}
+
+// Trim returns a strategy removing leading and trailing white space from strings,
+// e.g. before hashing them.
+func Trim() Strategy {
+	return StringFunc("trim", strings.TrimSpace)
+}
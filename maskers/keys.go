@@ -0,0 +1,230 @@
+package maskers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// Key is a secret used by keyed strategies, identified by ID.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// KeyProvider manages the keys of keyed strategies like EncryptWithKeys and NameWithKeys,
+// allowing to plug in key management systems like KMS or Vault.
+// Implementations must be safe for concurrent use.
+type KeyProvider interface {
+	// Current returns the key to mask new values with.
+	Current() (Key, error)
+	// Key returns the key with the given ID, e.g. to decrypt values masked before a rotation.
+	Key(id string) (Key, error)
+	// Rotate creates a new key and makes it the current one.
+	// Previous keys must stay available using Key.
+	Rotate() (Key, error)
+}
+
+// ErrUnknownKey is returned by key providers for unknown key IDs.
+var ErrUnknownKey = errors.New("unknown key")
+
+// newKey creates a random 256 bit key.
+func newKey() (Key, error) {
+	id := make([]byte, 8)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return Key{}, err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return Key{}, err
+	}
+	return Key{ID: hex.EncodeToString(id), Secret: secret}, nil
+}
+
+// MemoryKeyProvider keeps keys in memory. It is useful for tests and for
+// keys injected at startup, e.g. from environment variables.
+type MemoryKeyProvider struct {
+	mu      sync.RWMutex
+	keys    map[string]Key
+	current string
+}
+
+// NewMemoryKeyProvider returns a provider holding keys, of which the last one is the current one.
+// A random key is created if none are given.
+func NewMemoryKeyProvider(keys ...Key) (*MemoryKeyProvider, error) {
+	p := &MemoryKeyProvider{keys: make(map[string]Key)}
+	if len(keys) == 0 {
+		_, err := p.Rotate()
+		return p, err
+	}
+	for _, k := range keys {
+		p.keys[k.ID] = k
+		p.current = k.ID
+	}
+	return p, nil
+}
+
+func (p *MemoryKeyProvider) Current() (Key, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.keys[p.current], nil
+}
+
+func (p *MemoryKeyProvider) Key(id string) (Key, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	k, ok := p.keys[id]
+	if !ok {
+		return Key{}, fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	return k, nil
+}
+
+func (p *MemoryKeyProvider) Rotate() (Key, error) {
+	k, err := newKey()
+	if err != nil {
+		return Key{}, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys[k.ID] = k
+	p.current = k.ID
+	return k, nil
+}
+
+// FileKeyProvider keeps keys in a JSON file readable by the service only:
+//
+//	{"current": "k2", "keys": {"k1": "<base64 secret>", "k2": "<base64 secret>"}}
+type FileKeyProvider struct {
+	name string
+	// mu serializes rotations and reloads, so no rotation is lost.
+	mu  sync.Mutex
+	mem atomic.Pointer[MemoryKeyProvider]
+}
+
+type keyFile struct {
+	Current string            `json:"current"`
+	Keys    map[string][]byte `json:"keys"`
+}
+
+// NewFileKeyProvider reads the keys from the file name. A file holding
+// a random key is created if it does not exist.
+func NewFileKeyProvider(name string) (*FileKeyProvider, error) {
+	p := &FileKeyProvider{name: name}
+	err := p.Reload()
+	if errors.Is(err, os.ErrNotExist) {
+		var mem *MemoryKeyProvider
+		if mem, err = NewMemoryKeyProvider(); err == nil {
+			if err = p.write(mem); err == nil {
+				p.mem.Store(mem)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload reads the keys from the file again, e.g. after it has been updated by another process.
+func (p *FileKeyProvider) Reload() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b, err := os.ReadFile(p.name)
+	if err != nil {
+		return err
+	}
+	var f keyFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return fmt.Errorf("%s: %w", p.name, err)
+	}
+	if _, ok := f.Keys[f.Current]; !ok {
+		return fmt.Errorf("%s: %w %q", p.name, ErrUnknownKey, f.Current)
+	}
+	mem := &MemoryKeyProvider{keys: make(map[string]Key), current: f.Current}
+	for id, secret := range f.Keys {
+		mem.keys[id] = Key{ID: id, Secret: secret}
+	}
+	p.mem.Store(mem)
+	return nil
+}
+
+func (p *FileKeyProvider) Current() (Key, error) {
+	return p.mem.Load().Current()
+}
+
+func (p *FileKeyProvider) Key(id string) (Key, error) {
+	return p.mem.Load().Key(id)
+}
+
+// Rotate creates a new key and writes it to the file. The new key becomes the current
+// one once it has been written, i.e. values are never masked using a key which is lost
+// if writing the file fails.
+func (p *FileKeyProvider) Rotate() (Key, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k, err := newKey()
+	if err != nil {
+		return Key{}, err
+	}
+	prev := p.mem.Load()
+	prev.mu.RLock()
+	mem := &MemoryKeyProvider{keys: make(map[string]Key, len(prev.keys)+1), current: k.ID}
+	for id, key := range prev.keys {
+		mem.keys[id] = key
+	}
+	prev.mu.RUnlock()
+	mem.keys[k.ID] = k
+	if err := p.write(mem); err != nil {
+		return Key{}, err
+	}
+	p.mem.Store(mem)
+	return k, nil
+}
+
+// write replaces the file by the keys of mem atomically, so readers never see a
+// partially written file.
+func (p *FileKeyProvider) write(mem *MemoryKeyProvider) error {
+	mem.mu.RLock()
+	f := keyFile{Current: mem.current, Keys: make(map[string][]byte, len(mem.keys))}
+	for id, k := range mem.keys {
+		f.Keys[id] = k.Secret
+	}
+	mem.mu.RUnlock()
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	// CreateTemp creates files readable by the owner only
+	tmp, err := os.CreateTemp(filepath.Dir(p.name), filepath.Base(p.name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p.name)
+}
+
+// aesGCM creates an AES-GCM AEAD from key.
+func aesGCM(key Key) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", key.ID, err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,99 @@
+package maskers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMemoryKeyProvider(t *testing.T) {
+	keys, err := NewMemoryKeyProvider(Key{ID: "k1", Secret: []byte("0123456789abcdef")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := EncryptWithKeys(keys)
+	before, err := e.Mask(reflect.ValueOf("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(before.String(), "k1:") {
+		t.Errorf("expect %v to be prefixed by the key ID", before)
+	}
+
+	rotated, err := keys.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, _ := e.Mask(reflect.ValueOf("secret"))
+	if !strings.HasPrefix(after.String(), rotated.ID+":") {
+		t.Errorf("expect %v to use the rotated key %v", after, rotated.ID)
+	}
+	for _, masked := range []reflect.Value{before, after} {
+		plain, err := DecryptWithKeys(keys).Mask(masked)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plain.String() != "secret" {
+			t.Errorf("expect %v == secret", plain)
+		}
+	}
+
+	if _, err := keys.Key("unknown"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expect %v to be ErrUnknownKey", err)
+	}
+	if _, err := e.Unmask(reflect.ValueOf("unknown:" + strings.SplitN(after.String(), ":", 2)[1])); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expect %v to be ErrDecrypt", err)
+	}
+}
+
+func TestFileKeyProvider(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "keys.json")
+	keys, err := NewFileKeyProvider(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := keys.Current()
+	if len(first.Secret) != 32 {
+		t.Errorf("expect a 256 bit key to be created, got %d bytes", len(first.Secret))
+	}
+	if info, err := os.Stat(name); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expect the key file to be readable by the owner only, got %v", info.Mode())
+	}
+	second, err := keys.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileKeyProvider(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current, _ := reopened.Current(); current.ID != second.ID {
+		t.Errorf("expect %v == %v", current.ID, second.ID)
+	}
+	if k, err := reopened.Key(first.ID); err != nil || !reflect.DeepEqual(k, first) {
+		t.Errorf("expect %v == %v (%v)", k, first, err)
+	}
+
+	// keys which failed to be written are not used
+	if err := os.RemoveAll(filepath.Dir(name)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Rotate(); err == nil {
+		t.Error("expect the rotation to fail")
+	}
+	if current, _ := keys.Current(); current.ID != second.ID {
+		t.Errorf("expect %v == %v", current.ID, second.ID)
+	}
+
+	a := NameWithKeys(keys)
+	b := NameWithKeys(reopened)
+	x, _ := a.Mask(reflect.ValueOf("Ada Lovelace"))
+	y, _ := b.Mask(reflect.ValueOf("Ada Lovelace"))
+	if x.String() != y.String() {
+		t.Errorf("expect %v == %v using the same key", x, y)
+	}
+}
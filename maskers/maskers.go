@@ -0,0 +1,49 @@
+// Package maskers contains reusable masking strategies.
+//
+// A strategy receives the value of a single field and returns its masked
+// replacement. Strategies are applied by the mask package to struct fields
+// tagged with `mask:"<strategy>"`, but they can be used on their own as well.
+package maskers
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Strategy masks a single value.
+type Strategy interface {
+	// Name identifies the strategy, e.g. in struct tags.
+	Name() string
+	// Mask returns the masked replacement of v. Mask must not modify v.
+	// The returned value needs to be convertible to the type of v.
+	Mask(v reflect.Value) (reflect.Value, error)
+}
+
+type strategyFunc struct {
+	name string
+	fn   func(v reflect.Value) (reflect.Value, error)
+}
+
+func (s *strategyFunc) Name() string {
+	return s.name
+}
+
+func (s *strategyFunc) Mask(v reflect.Value) (reflect.Value, error) {
+	return s.fn(v)
+}
+
+// Func creates a named strategy from fn.
+func Func(name string, fn func(v reflect.Value) (reflect.Value, error)) Strategy {
+	return &strategyFunc{name: name, fn: fn}
+}
+
+// StringFunc creates a named strategy masking string values using fn.
+// Values of any other kind are rejected.
+func StringFunc(name string, fn func(s string) string) Strategy {
+	return Func(name, func(v reflect.Value) (reflect.Value, error) {
+		if v.Kind() != reflect.String {
+			return reflect.Value{}, fmt.Errorf("strategy %s: must pass a value with kind of String; got %v", name, v.Kind())
+		}
+		return reflect.ValueOf(fn(v.String())), nil
+	})
+}
@@ -0,0 +1,90 @@
+package maskers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var firstNames = []string{
+	"Aaliyah", "Adam", "Aiko", "Alejandro", "Amara", "Anders", "Anna", "Arjun",
+	"Beatriz", "Ben", "Carmen", "Chen", "Chloe", "Daniel", "Dario", "Elena",
+	"Emeka", "Emma", "Farah", "Felix", "Freya", "Gabriel", "Hana", "Hugo",
+	"Ingrid", "Isaac", "Jamal", "Javier", "Julia", "Kai", "Keiko", "Lars",
+	"Layla", "Leon", "Lucia", "Malik", "Maria", "Mateo", "Mei", "Mila",
+	"Nadia", "Noah", "Nora", "Omar", "Oscar", "Priya", "Rafael", "Rosa",
+	"Ravi", "Sara", "Sofia", "Stefan", "Tariq", "Theo", "Uma", "Victor",
+	"Wei", "Yara", "Yusuf", "Zara", "Zoe", "Lina", "Pablo", "Ines",
+}
+
+var lastNames = []string{
+	"Abe", "Adeyemi", "Andersen", "Bauer", "Becker", "Brown", "Castro", "Chen",
+	"Costa", "Dubois", "Eriksson", "Fischer", "Garcia", "Gomez", "Haddad", "Hansen",
+	"Ito", "Jansen", "Jones", "Kim", "Kowalski", "Kumar", "Larsen", "Lee",
+	"Lopez", "Martin", "Meyer", "Moreau", "Nakamura", "Nguyen", "Novak", "Okafor",
+	"Olsen", "Park", "Patel", "Petrov", "Popescu", "Reyes", "Rossi", "Sato",
+	"Schmidt", "Silva", "Singh", "Smith", "Suzuki", "Tanaka", "Taylor", "Torres",
+	"Usman", "Vargas", "Wagner", "Walker", "Wang", "Weber", "Wilson", "Wong",
+	"Yamamoto", "Yilmaz", "Young", "Zhang", "Zimmer", "Fernandes", "Ivanova", "Mendes",
+}
+
+// Name returns a strategy pseudonymizing personal names.
+// Every name is replaced by a fake name chosen deterministically from the
+// HMAC-SHA256 of the original using key, so equal names (ignoring case and
+// surrounding whitespace) always map to the same fake name.
+// The number of name parts is preserved: all but the last part are replaced
+// by first names, the last part by a family name.
+//
+// Use a secret key; without one, pseudonyms of well known names can be
+// reversed by a simple lookup.
+func Name(key []byte) Strategy {
+	return StringFunc("name", func(s string) string {
+		return pseudonymizeName(key, s)
+	})
+}
+
+// NameWithKeys works like Name using the current key of keys.
+// Pseudonyms change whenever the key is rotated.
+func NameWithKeys(keys KeyProvider) Strategy {
+	return Func("name", func(v reflect.Value) (reflect.Value, error) {
+		if v.Kind() != reflect.String {
+			return reflect.Value{}, fmt.Errorf("strategy name: must pass a value with kind of String; got %v", v.Kind())
+		}
+		k, err := keys.Current()
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("strategy name: %w", err)
+		}
+		return reflect.ValueOf(pseudonymizeName(k.Secret, v.String())), nil
+	})
+}
+
+func pseudonymizeName(key []byte, s string) string {
+	parts := strings.Fields(s)
+	if len(parts) == 0 {
+		return s
+	}
+	normalized := strings.ToLower(strings.Join(parts, " "))
+
+	out := make([]string, len(parts))
+	for i := range parts {
+		names := firstNames
+		if i > 0 && i == len(parts)-1 {
+			names = lastNames
+		}
+		out[i] = names[nameIndex(key, normalized, i, len(names))]
+	}
+	return strings.Join(out, " ")
+}
+
+func nameIndex(key []byte, normalized string, part int, n int) int {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(normalized))
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(part))
+	mac.Write(b[:])
+	sum := mac.Sum(nil)
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(n))
+}
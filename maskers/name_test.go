@@ -0,0 +1,54 @@
+package maskers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestName(t *testing.T) {
+	s := Name([]byte("secret"))
+
+	mask := func(in string) string {
+		out, err := s.Mask(reflect.ValueOf(in))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return out.String()
+	}
+
+	a := mask("Ada Lovelace")
+	if a == "Ada Lovelace" {
+		t.Errorf("expect %v to be pseudonymized", a)
+	}
+	if len(strings.Fields(a)) != 2 {
+		t.Errorf("expect %v to consist of 2 parts", a)
+	}
+	if b := mask("  ada   LOVELACE "); a != b {
+		t.Errorf("expect %v == %v", a, b)
+	}
+	if c := mask("Charles Babbage"); a == c {
+		t.Errorf("expect %v != %v", a, c)
+	}
+	if parts := strings.Fields(mask("Mary Ann Evans")); len(parts) != 3 {
+		t.Errorf("expect %v to consist of 3 parts", parts)
+	}
+	if e := mask(""); e != "" {
+		t.Errorf("expect %q to be empty", e)
+	}
+
+	other, err := Name([]byte("other")).Mask(reflect.ValueOf("Ada Lovelace"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if other.String() == a {
+		// 64 x 64 combinations; a collision for these fixed inputs would be a bug in keying
+		t.Errorf("expect different keys to produce different pseudonyms, got %v twice", a)
+	}
+}
+
+func TestNameRejectsNonStrings(t *testing.T) {
+	if _, err := Name(nil).Mask(reflect.ValueOf(42)); err == nil {
+		t.Errorf("expected an error for non string values")
+	}
+}
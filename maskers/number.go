@@ -0,0 +1,91 @@
+package maskers
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+)
+
+// random returns pseudo-random numbers in [0, 1); it is replaced by tests.
+var random = rand.Float64
+
+// Round returns a strategy rounding numbers to the nearest multiple of bucket,
+// e.g. 1234 to 1200 for a bucket of 100 or 3.14159 to 3.1 for 0.1, so values
+// stay comparable while exact amounts are hidden. A bucket below or equal to 0
+// rounds to integers.
+func Round(bucket float64) Strategy {
+	if bucket <= 0 {
+		bucket = 1
+	}
+	return numberFunc("round", func(x float64) float64 {
+		return math.Round(x/bucket) * bucket
+	})
+}
+
+// Jitter returns a strategy perturbing numbers by a random fraction of their value
+// of up to ±fraction, e.g. 1000 becomes a number between 900 and 1100 for 0.1.
+// Unlike Round, sums and averages over many values are kept, not single values.
+// Integers are rounded after perturbing them.
+func Jitter(fraction float64) Strategy {
+	fraction = math.Abs(fraction)
+	return numberFunc("jitter", func(x float64) float64 {
+		return x * (1 + fraction*(2*random()-1))
+	})
+}
+
+// numberFunc creates a named strategy masking integers, unsigned integers and floats
+// using fn. Results are rounded for integers and clamped to the range of their type.
+func numberFunc(name string, fn func(x float64) float64) Strategy {
+	return Func(name, func(v reflect.Value) (reflect.Value, error) {
+		out := reflect.New(v.Type()).Elem()
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			bits := v.Type().Bits()
+			limit := math.Ldexp(1, bits-1)
+			out.SetInt(int64(clamp(math.Round(fn(float64(v.Int()))), -limit, math.Nextafter(limit, 0))))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			bits := v.Type().Bits()
+			out.SetUint(uint64(clamp(math.Round(fn(float64(v.Uint()))), 0, math.Nextafter(math.Ldexp(1, bits), 0))))
+		case reflect.Float32, reflect.Float64:
+			out.SetFloat(fn(v.Float()))
+		default:
+			return reflect.Value{}, fmt.Errorf("strategy %s: must pass a number; got %v", name, v.Kind())
+		}
+		return out, nil
+	})
+}
+
+func clamp(x, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, x))
+}
+
+// normal returns standard normally distributed numbers; it is replaced by tests.
+var normal = rand.NormFloat64
+
+// Laplace returns a strategy adding noise of the Laplace mechanism of differential
+// privacy to numbers: the result of a query, e.g. a count or sum, changing by at most
+// sensitivity if a single individual is added or removed is made epsilon-differentially
+// private. Smaller epsilons add more noise.
+//
+//	maskers.Laplace(1, 0.5) // a count, epsilon 0.5
+//
+// Integers are rounded after adding the noise, which keeps the guarantee.
+func Laplace(sensitivity, epsilon float64) Strategy {
+	scale := math.Abs(sensitivity) / epsilon
+	return numberFunc("laplace", func(x float64) float64 {
+		u := random() - 0.5
+		return x - scale*math.Copysign(1, u)*math.Log(1-2*math.Abs(u))
+	})
+}
+
+// Gaussian returns a strategy adding noise of the Gaussian mechanism of differential
+// privacy to numbers, making results changing by at most sensitivity per individual
+// (epsilon, delta)-differentially private, for epsilon below 1. delta is the probability
+// of the guarantee not holding and should be well below 1/n for n individuals.
+func Gaussian(sensitivity, epsilon, delta float64) Strategy {
+	sigma := math.Abs(sensitivity) * math.Sqrt(2*math.Log(1.25/delta)) / epsilon
+	return numberFunc("gaussian", func(x float64) float64 {
+		return x + sigma*normal()
+	})
+}
@@ -0,0 +1,81 @@
+package maskers
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRound(t *testing.T) {
+	type amount int32
+	for _, test := range []struct {
+		in       interface{}
+		bucket   float64
+		expected interface{}
+	}{
+		{1234, 100.0, 1200},
+		{-1250, 100.0, -1300},
+		{amount(1260), 100.0, amount(1300)},
+		{uint8(250), 100.0, uint8(255)},
+		{int8(127), 50.0, int8(127)},
+		{3.14159, 0.1, 3.1},
+		{float32(2.6), 0.0, float32(3)},
+		{int64(math.MaxInt64), 10.0, int64(9223372036854774784)},
+	} {
+		out, err := Round(test.bucket).Mask(reflect.ValueOf(test.in))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		got := out.Interface()
+		if f, ok := got.(float64); ok {
+			got = math.Round(f*1e9) / 1e9
+		}
+		if got != test.expected {
+			t.Errorf("%v: expect %v == %v", test.in, got, test.expected)
+		}
+	}
+	if _, err := Round(10).Mask(reflect.ValueOf("10")); err == nil {
+		t.Errorf("expect an error for strings")
+	}
+}
+
+func TestJitter(t *testing.T) {
+	defer func(r func() float64) { random = r }(random)
+	for r, expected := range map[float64]int{0: 900, 0.5: 1000, 0.75: 1050} {
+		random = func() float64 { return r }
+		out, err := Jitter(0.1).Mask(reflect.ValueOf(1000))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if out.Int() != int64(expected) {
+			t.Errorf("expect %v == %v", out.Int(), expected)
+		}
+	}
+}
+
+func TestLaplace(t *testing.T) {
+	defer func(r func() float64) { random = r }(random)
+	for r, expected := range map[float64]float64{0.5: 100, 0.75: 100 - 2*math.Log(0.5), 0.25: 100 + 2*math.Log(0.5)} {
+		random = func() float64 { return r }
+		out, err := Laplace(1, 0.5).Mask(reflect.ValueOf(100.0))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if math.Abs(out.Float()-expected) > 1e-9 {
+			t.Errorf("expect %v == %v", out.Float(), expected)
+		}
+	}
+}
+
+func TestGaussian(t *testing.T) {
+	defer func(n func() float64) { normal = n }(normal)
+	normal = func() float64 { return 1 }
+	out, err := Gaussian(1, 0.5, 1e-5).Mask(reflect.ValueOf(100))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// sigma is sqrt(2 ln(125000)) / 0.5, about 9.69
+	if out.Int() != 110 {
+		t.Errorf("expect %v == %v", out.Int(), 110)
+	}
+}
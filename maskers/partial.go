@@ -0,0 +1,32 @@
+package maskers
+
+import "strings"
+
+// Partial returns a strategy masking all but the first keepStart and the last
+// keepEnd characters of a string using f, e.g. "J**n" for "John" with 1 and 1.
+// Characters are counted as grapheme clusters, so multi-byte characters
+// like emoji or CJK are never cut in half.
+// Strings too short to keep anything are masked entirely.
+func Partial(keepStart, keepEnd int, f Format) Strategy {
+	return StringFunc("partial", func(s string) string {
+		return partial(s, keepStart, keepEnd, f)
+	})
+}
+
+func partial(s string, keepStart, keepEnd int, f Format) string {
+	clusters := graphemes(s)
+	if keepStart < 0 {
+		keepStart = 0
+	}
+	if keepEnd < 0 {
+		keepEnd = 0
+	}
+	if keepStart+keepEnd >= len(clusters) {
+		return f.mask(clusters)
+	}
+	var b strings.Builder
+	b.WriteString(strings.Join(clusters[:keepStart], ""))
+	b.WriteString(f.mask(clusters[keepStart : len(clusters)-keepEnd]))
+	b.WriteString(strings.Join(clusters[len(clusters)-keepEnd:], ""))
+	return b.String()
+}
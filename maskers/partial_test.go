@@ -0,0 +1,41 @@
+package maskers
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPartial(t *testing.T) {
+	tests := []struct {
+		in        string
+		keepStart int
+		keepEnd   int
+		format    Format
+		expected  string
+	}{
+		{"John", 1, 1, Format{}, "J**n"},
+		{"John", 0, 0, Format{}, "****"},
+		{"Jo", 1, 1, Format{}, "**"},
+		{"", 1, 1, Format{}, ""},
+		{"Zoe\u0308", 1, 1, Format{Char: '#'}, "Z#e\u0308"},
+		{"Zoë", 1, 1, Format{}, "Z*ë"},
+		{"山田太郎", 1, 0, Format{}, "山***"},
+		{"山田太郎", 1, 0, Format{Char: '*', FullWidth: true}, "山＊＊＊"},
+		{"a👍🏽b", 1, 1, Format{}, "a*b"},
+		{"🇩🇪🇫🇷🇯🇵", 1, 1, Format{}, "🇩🇪*🇯🇵"},
+		{"👩‍👩‍👧x", 0, 1, Format{FullWidth: true}, "＊x"},
+	}
+	for _, test := range tests {
+		out, err := Partial(test.keepStart, test.keepEnd, test.format).Mask(reflect.ValueOf(test.in))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if out.String() != test.expected {
+			t.Errorf("expect %q == %q", out.String(), test.expected)
+		}
+		if !utf8.ValidString(out.String()) {
+			t.Errorf("expect %q to be valid utf8", out.String())
+		}
+	}
+}
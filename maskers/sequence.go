@@ -0,0 +1,16 @@
+package maskers
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Sequence returns a strategy replacing strings with surrogates numbered in the
+// order they are masked, formatted using format, e.g. "user-%04d@example.com".
+// Combined with a mask.Session, equal values get the same surrogate.
+func Sequence(format string) Strategy {
+	var n atomic.Int64
+	return StringFunc("sequence", func(string) string {
+		return fmt.Sprintf(format, n.Add(1))
+	})
+}
@@ -0,0 +1,185 @@
+package maskers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TokenStore persists the mapping between values and the opaque tokens replacing them,
+// allowing authorized tooling to re-identify specific records later on.
+// Implementations must be safe for concurrent use.
+type TokenStore interface {
+	// Token returns the token of value, creating one if value has not been seen before.
+	// Equal values always result in the same token.
+	Token(ctx context.Context, value string) (string, error)
+	// Value returns the value replaced by token.
+	Value(ctx context.Context, token string) (string, error)
+}
+
+// ErrUnknownToken is returned by token stores for unknown tokens.
+var ErrUnknownToken = errors.New("unknown token")
+
+// newToken creates a random token.
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "tok_" + hex.EncodeToString(b), nil
+}
+
+type tokenize struct {
+	store TokenStore
+}
+
+// Tokenize returns a reversible strategy replacing strings with tokens from store.
+// Unlike encryption, tokens carry no information about the original value at all.
+func Tokenize(store TokenStore) Reversible {
+	return &tokenize{store: store}
+}
+
+func (t *tokenize) Name() string {
+	return "tokenize"
+}
+
+func (t *tokenize) Mask(v reflect.Value) (reflect.Value, error) {
+	if v.Kind() != reflect.String {
+		return reflect.Value{}, fmt.Errorf("strategy tokenize: must pass a value with kind of String; got %v", v.Kind())
+	}
+	token, err := t.store.Token(context.Background(), v.String())
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("strategy tokenize: %w", err)
+	}
+	return reflect.ValueOf(token), nil
+}
+
+func (t *tokenize) Unmask(v reflect.Value) (reflect.Value, error) {
+	if v.Kind() != reflect.String {
+		return reflect.Value{}, fmt.Errorf("strategy tokenize: must pass a value with kind of String; got %v", v.Kind())
+	}
+	value, err := t.store.Value(context.Background(), v.String())
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("strategy tokenize: %w", err)
+	}
+	return reflect.ValueOf(value), nil
+}
+
+// MemoryTokenStore keeps tokens in memory, e.g. for tests or
+// for masking a single export consistently.
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]string
+	values map[string]string
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[string]string),
+		values: make(map[string]string),
+	}
+}
+
+func (s *MemoryTokenStore) Token(ctx context.Context, value string) (string, error) {
+	s.mu.RLock()
+	token, ok := s.tokens[value]
+	s.mu.RUnlock()
+	if ok {
+		return token, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token, ok := s.tokens[value]; ok {
+		return token, nil
+	}
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	s.tokens[value] = token
+	s.values[token] = value
+	return token, nil
+}
+
+func (s *MemoryTokenStore) Value(ctx context.Context, token string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[token]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownToken, token)
+	}
+	return value, nil
+}
+
+// SQLTokenStore keeps tokens in a database table, see CreateTable.
+// Values are looked up by their SHA-256 hash, so they need no index themselves.
+type SQLTokenStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(n int) string
+}
+
+// NewSQLTokenStore returns a store keeping tokens in table.
+// placeholder formats the n-th (starting at 1) query parameter, e.g. "$1" for PostgreSQL;
+// "?" is used if it is nil.
+func NewSQLTokenStore(db *sql.DB, table string, placeholder func(n int) string) *SQLTokenStore {
+	if placeholder == nil {
+		placeholder = func(int) string { return "?" }
+	}
+	return &SQLTokenStore{db: db, table: table, placeholder: placeholder}
+}
+
+// CreateTable creates the table of the store if it does not exist yet.
+func (s *SQLTokenStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (token VARCHAR(36) PRIMARY KEY, value_hash CHAR(64) NOT NULL UNIQUE, value TEXT NOT NULL)",
+		s.table))
+	return err
+}
+
+func (s *SQLTokenStore) Token(ctx context.Context, value string) (string, error) {
+	sum := sha256.Sum256([]byte(value))
+	hash := hex.EncodeToString(sum[:])
+	token, err := s.lookup(ctx, hash)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return token, err
+	}
+
+	if token, err = newToken(); err != nil {
+		return "", err
+	}
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (token, value_hash, value) VALUES (%s, %s, %s)",
+		s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3)), token, hash, value)
+	if err != nil {
+		// another process might have inserted the value concurrently
+		if existing, lookupErr := s.lookup(ctx, hash); lookupErr == nil {
+			return existing, nil
+		}
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *SQLTokenStore) lookup(ctx context.Context, hash string) (string, error) {
+	var token string
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT token FROM %s WHERE value_hash = %s",
+		s.table, s.placeholder(1)), hash).Scan(&token)
+	return token, err
+}
+
+func (s *SQLTokenStore) Value(ctx context.Context, token string) (string, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT value FROM %s WHERE token = %s",
+		s.table, s.placeholder(1)), token).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w %q", ErrUnknownToken, token)
+	}
+	return value, err
+}
@@ -0,0 +1,117 @@
+package maskers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func testTokenStore(t *testing.T, store TokenStore) {
+	t.Helper()
+	s := Tokenize(store)
+	a, err := s.Mask(reflect.ValueOf("ada@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := s.Mask(reflect.ValueOf("ada@example.com"))
+	c, _ := s.Mask(reflect.ValueOf("bob@example.com"))
+	if a.String() != b.String() || a.String() == c.String() || !strings.HasPrefix(a.String(), "tok_") {
+		t.Errorf("expect equal values to share a token, got %v, %v, %v", a, b, c)
+	}
+	plain, err := s.Unmask(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.String() != "ada@example.com" {
+		t.Errorf("expect %v == ada@example.com", plain)
+	}
+	if _, err := store.Value(context.Background(), "tok_unknown"); !errors.Is(err, ErrUnknownToken) {
+		t.Errorf("expect %v to be ErrUnknownToken", err)
+	}
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	testTokenStore(t, NewMemoryTokenStore())
+}
+
+func TestSQLTokenStore(t *testing.T) {
+	db := sql.OpenDB(tokenConnector{&tokenDriver{rows: make(map[string][3]string)}})
+	defer db.Close()
+	store := NewSQLTokenStore(db, "tokens", nil)
+	if err := store.CreateTable(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	testTokenStore(t, store)
+}
+
+// tokenDriver is a minimal database/sql driver understanding the statements of SQLTokenStore.
+type tokenDriver struct {
+	mu sync.Mutex
+	// rows holds token, value_hash and value by token.
+	rows map[string][3]string
+}
+
+func (d *tokenDriver) Open(string) (driver.Conn, error) { return tokenConn{d}, nil }
+
+type tokenConnector struct{ d *tokenDriver }
+
+func (c tokenConnector) Connect(context.Context) (driver.Conn, error) { return tokenConn{c.d}, nil }
+func (c tokenConnector) Driver() driver.Driver                        { return c.d }
+
+type tokenConn struct{ d *tokenDriver }
+
+func (c tokenConn) Prepare(query string) (driver.Stmt, error) { return tokenStmt{c.d, query}, nil }
+func (c tokenConn) Close() error                              { return nil }
+func (c tokenConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type tokenStmt struct {
+	d     *tokenDriver
+	query string
+}
+
+func (s tokenStmt) Close() error  { return nil }
+func (s tokenStmt) NumInput() int { return strings.Count(s.query, "?") }
+
+func (s tokenStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	if strings.HasPrefix(s.query, "INSERT") {
+		s.d.rows[args[0].(string)] = [3]string{args[0].(string), args[1].(string), args[2].(string)}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s tokenStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	// look up tokens by value_hash or values by token
+	col, result := 0, 2
+	if strings.Contains(s.query, "WHERE value_hash") {
+		col, result = 1, 0
+	}
+	var values []string
+	for _, row := range s.d.rows {
+		if row[col] == args[0].(string) {
+			values = append(values, row[result])
+		}
+	}
+	return &tokenRows{values: values}, nil
+}
+
+type tokenRows struct{ values []string }
+
+func (r *tokenRows) Columns() []string { return []string{"result"} }
+func (r *tokenRows) Close() error      { return nil }
+func (r *tokenRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
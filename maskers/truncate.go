@@ -0,0 +1,45 @@
+package maskers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ellipsis marks truncated strings.
+const Ellipsis = "…"
+
+// Truncate returns a strategy cutting strings after n characters, marked by Ellipsis,
+// e.g. "Lorem ipsu…" for "Lorem ipsum dolor" and 10, and byte slices after n bytes.
+// Characters are counted as grapheme clusters, so they are never cut in half.
+// Shorter values are kept as they are.
+func Truncate(n int) Strategy {
+	if n < 0 {
+		n = 0
+	}
+	return Func("truncate", func(v reflect.Value) (reflect.Value, error) {
+		switch {
+		case v.Kind() == reflect.String:
+			return reflect.ValueOf(truncate(v.String(), n)), nil
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			if v.Len() <= n {
+				return v, nil
+			}
+			return reflect.ValueOf(append([]byte(nil), v.Bytes()[:n]...)), nil
+		}
+		return reflect.Value{}, fmt.Errorf("strategy truncate: must pass a value with kind of String or a byte slice; got %v", v.Type())
+	})
+}
+
+func truncate(s string, n int) string {
+	// grapheme clusters consist of at least one rune
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	clusters := graphemes(s)
+	if len(clusters) <= n {
+		return s
+	}
+	return strings.Join(clusters[:n], "") + Ellipsis
+}
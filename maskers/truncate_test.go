@@ -0,0 +1,43 @@
+package maskers
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	for _, test := range []struct {
+		in       string
+		n        int
+		expected string
+	}{
+		{"Lorem ipsum dolor", 10, "Lorem ipsu…"},
+		{"Lorem", 5, "Lorem"},
+		{"Lorem", 0, "…"},
+		{"", 3, ""},
+		{"Zoë Doe", 3, "Zoë…"},
+		{"a👍🏽b", 2, "a👍🏽…"},
+	} {
+		out, err := Truncate(test.n).Mask(reflect.ValueOf(test.in))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if out.String() != test.expected {
+			t.Errorf("expect %q == %q", out.String(), test.expected)
+		}
+	}
+
+	blob := []byte("0123456789")
+	out, err := Truncate(4).Mask(reflect.ValueOf(blob))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), []byte("0123")) || &out.Bytes()[0] == &blob[0] {
+		t.Errorf("expect %q == %q, not sharing the original", out.Bytes(), "0123")
+	}
+
+	if _, err := Truncate(4).Mask(reflect.ValueOf(42)); err == nil {
+		t.Errorf("expect an error for ints")
+	}
+}
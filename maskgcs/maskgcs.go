@@ -0,0 +1,59 @@
+// Package maskgcs scrubs objects of Google Cloud Storage buckets, see maskobject:
+//
+//	client, err := storage.NewClient(ctx)
+//	s := maskobject.New(maskgcs.Bucket(client.Bucket("raw")), maskgcs.Bucket(client.Bucket("archive")),
+//		maskobject.WithMaskOptions(opts...))
+//	result, err := s.Scrub(ctx, "exports/")
+package maskgcs
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"github.com/doejon/go-mask/maskobject"
+	"google.golang.org/api/iterator"
+)
+
+type bucket struct {
+	handle *storage.BucketHandle
+}
+
+// Bucket returns a bucket reading and writing the objects of handle.
+// Objects are uploaded in chunks, so they need not fit into memory.
+func Bucket(handle *storage.BucketHandle) maskobject.Bucket {
+	return bucket{handle: handle}
+}
+
+func (b bucket) List(ctx context.Context, prefix string, fn func(key string) error) error {
+	it := b.handle.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(attrs.Name); err != nil {
+			return err
+		}
+	}
+}
+
+func (b bucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return b.handle.Object(key).NewReader(ctx)
+}
+
+func (b bucket) Put(ctx context.Context, key string, r io.Reader) error {
+	// canceling the context of a writer discards the object
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := b.handle.Object(key).NewWriter(ctx)
+	if _, err := io.Copy(w, r); err != nil {
+		cancel()
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
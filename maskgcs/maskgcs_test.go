@@ -0,0 +1,98 @@
+package maskgcs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskobject"
+)
+
+// testServer serves the JSON API of a storage emulator for the objects of one bucket.
+type testServer struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/storage/v1/b/test/o"):
+		var items []map[string]string
+		for name := range s.objects {
+			if strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+				items = append(items, map[string]string{"name": name, "bucket": "test"})
+			}
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i]["name"] < items[j]["name"] })
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/test/"):
+		content, ok := s.objects[strings.TrimPrefix(r.URL.Path, "/test/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, content)
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/test/o":
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			s.t.Error(err)
+			return
+		}
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		var attrs map[string]interface{}
+		part, _ := mr.NextPart()
+		json.NewDecoder(part).Decode(&attrs)
+		part, _ = mr.NextPart()
+		content, _ := io.ReadAll(part)
+		name, _ := attrs["name"].(string)
+		s.objects[name] = string(content)
+		json.NewEncoder(w).Encode(map[string]string{"name": name, "bucket": "test"})
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		http.Error(w, "not implemented", http.StatusNotImplemented)
+	}
+}
+
+func TestBucket(t *testing.T) {
+	server := &testServer{t: t, objects: map[string]string{
+		"raw/users.json": `{"email":"ada@example.com","plan":"pro"}`,
+		"raw/logo.png":   "\x89PNG",
+	}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	t.Setenv("STORAGE_EMULATOR_HOST", strings.TrimPrefix(ts.URL, "http://"))
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	b := Bucket(client.Bucket("test"))
+	s := maskobject.New(b, b, maskobject.WithMaskOptions(mask.WithDenyFields("email")), maskobject.WithRename(func(key string) string {
+		return "archive/" + strings.TrimPrefix(key, "raw/")
+	}))
+	result, err := s.Scrub(context.Background(), "raw/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Scrubbed != 1 || len(result.Skipped) != 1 {
+		t.Errorf("expect one object scrubbed and one skipped, got %+v", result)
+	}
+	expect := `{"email":"` + mask.Placeholder[string]() + `","plan":"pro"}` + "\n"
+	if out := server.objects["archive/users.json"]; out != expect {
+		t.Errorf("expect %q == %q", out, expect)
+	}
+}
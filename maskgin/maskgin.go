@@ -0,0 +1,89 @@
+// Package maskgin plugs masked body capture into the Gin logger:
+//
+//	r := gin.New()
+//	r.Use(gin.LoggerWithFormatter(maskgin.LogFormatter(nil)))
+//	r.Use(maskgin.Middleware(maskhttp.WithOptions(policyOpts...)))
+//
+// The middleware stores the masked bodies in the context, where they are
+// read by the formatter or by any logger using Bodies.
+package maskgin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doejon/go-mask/maskhttp"
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys of the masked bodies.
+const (
+	RequestBodyKey  = "mask.request_body"
+	ResponseBodyKey = "mask.response_body"
+)
+
+// Middleware captures the bodies of requests and responses masked using opts.
+// Register it after gin.Logger, so the logger sees the bodies once it runs.
+func Middleware(opts ...maskhttp.Option) gin.HandlerFunc {
+	m := maskhttp.New(opts...)
+	return func(c *gin.Context) {
+		req := m.CaptureRequest(c.Request)
+		w := &bodyWriter{ResponseWriter: c.Writer, body: m.NewBody()}
+		c.Writer = w
+		c.Next()
+		c.Set(RequestBodyKey, string(m.Mask(c.Request.Header.Get("Content-Type"), req)))
+		c.Set(ResponseBodyKey, string(m.Mask(w.Header().Get("Content-Type"), w.body)))
+	}
+}
+
+// Bodies returns the masked bodies captured by Middleware.
+func Bodies(c *gin.Context) (request, response string) {
+	return c.GetString(RequestBodyKey), c.GetString(ResponseBodyKey)
+}
+
+// LogFormatter appends the masked bodies to the lines formatted by base,
+// or by a formatter similar to the default one of Gin if base is nil.
+func LogFormatter(base gin.LogFormatter) gin.LogFormatter {
+	if base == nil {
+		base = defaultFormatter
+	}
+	return func(param gin.LogFormatterParams) string {
+		line := strings.TrimSuffix(base(param), "\n")
+		for _, kv := range [...]struct{ name, key string }{{"request_body", RequestBodyKey}, {"response_body", ResponseBodyKey}} {
+			if body, _ := param.Keys[kv.key].(string); body != "" {
+				line += fmt.Sprintf(" %s=%q", kv.name, body)
+			}
+		}
+		return line + "\n"
+	}
+}
+
+func defaultFormatter(param gin.LogFormatterParams) string {
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v%s\n",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		param.Path,
+		param.ErrorMessage,
+	)
+}
+
+// bodyWriter captures the body written by handlers.
+type bodyWriter struct {
+	gin.ResponseWriter
+	body *maskhttp.Body
+}
+
+func (w *bodyWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.body.Write(p[:n])
+	return n, err
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.body.Write([]byte(s[:n]))
+	return n, err
+}
@@ -0,0 +1,55 @@
+package maskgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskhttp"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	r := gin.New()
+	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{Formatter: LogFormatter(nil), Output: &logs}))
+	r.Use(Middleware(maskhttp.WithOptions(mask.WithPathStrategy("**.password", redact))))
+	r.POST("/users", func(c *gin.Context) {
+		var body struct{ Name, Password string }
+		if err := c.BindJSON(&body); err != nil {
+			t.Error(err)
+		}
+		c.JSON(http.StatusCreated, gin.H{"name": body.Name, "password": body.Password})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"alice","password":"s3cr3t"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), "s3cr3t") {
+		t.Errorf("expect the response not to be modified, got %d %s", rec.Code, rec.Body.String())
+	}
+	line := logs.String()
+	for _, expect := range []string{
+		`request_body="{\"name\":\"alice\",\"password\":\"MASKED\"}"`,
+		`response_body="{\"name\":\"alice\",\"password\":\"MASKED\"}"`,
+		"| 201 |",
+	} {
+		if !strings.Contains(line, expect) {
+			t.Errorf("expect %q to contain %q", line, expect)
+		}
+	}
+	if strings.Contains(line, "s3cr3t") {
+		t.Errorf("expect %q not to contain the password", line)
+	}
+}
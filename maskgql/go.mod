@@ -0,0 +1,19 @@
+module github.com/doejon/go-mask/maskgql
+
+go 1.25
+
+require (
+	github.com/99designs/gqlgen v0.17.87
+	github.com/doejon/go-mask v0.0.0
+	github.com/vektah/gqlparser/v2 v2.5.32
+)
+
+require (
+	github.com/agnivade/levenshtein v1.2.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/sosodev/duration v1.3.1 // indirect
+	golang.org/x/sync v0.19.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/doejon/go-mask => ..
@@ -0,0 +1,141 @@
+// Package maskgql masks the results of gqlgen resolvers declared with the
+// @mask directive, bringing struct tag like redaction to GraphQL schemas:
+//
+//	directive @mask(strategy: String) on FIELD_DEFINITION
+//
+//	type User {
+//	  email: String! @mask(strategy: "partial=1:1")
+//	  address: Address @mask
+//	}
+//
+// Fields with a strategy are masked using it, fields without one using Mask,
+// i.e. by the struct tags and MaskXXX methods of their Go types.
+// Install the middleware on the gqlgen server:
+//
+//	srv.AroundFields(maskgql.FieldMiddleware())
+package maskgql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	mask "github.com/doejon/go-mask"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Directive declares the @mask directive; add it to the schema.
+const Directive = `directive @mask(strategy: String) on FIELD_DEFINITION`
+
+// DirectiveName is the name of the directive marking fields to be masked.
+const DirectiveName = "mask"
+
+// FieldMiddleware returns the middleware masking the results of fields
+// declared with the @mask directive. opts apply to fields without strategy.
+func FieldMiddleware(opts ...mask.Option) graphql.FieldMiddleware {
+	var strategies sync.Map // *ast.FieldDefinition -> fieldStrategy
+	return func(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+		fc := graphql.GetFieldContext(ctx)
+		if fc == nil || fc.Field.Field == nil || fc.Field.Definition == nil {
+			return next(ctx)
+		}
+		def := fc.Field.Definition
+		cached, ok := strategies.Load(def)
+		if !ok {
+			cached, _ = strategies.LoadOrStore(def, strategyOf(def))
+		}
+		fs := cached.(fieldStrategy)
+		if !fs.masked {
+			return next(ctx)
+		}
+		if fs.err != nil {
+			return nil, fs.err
+		}
+
+		res, err := next(ctx)
+		if err != nil || res == nil {
+			return res, err
+		}
+		if fs.strategy == nil {
+			masked, err := mask.Mask(res, opts...)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", fc.Object, def.Name, err)
+			}
+			return masked, nil
+		}
+		masked, err := maskValue(fs.strategy, reflect.ValueOf(res))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", fc.Object, def.Name, err)
+		}
+		return masked.Interface(), nil
+	}
+}
+
+type fieldStrategy struct {
+	masked   bool
+	strategy mask.Strategy
+	err      error
+}
+
+func strategyOf(def *ast.FieldDefinition) fieldStrategy {
+	d := def.Directives.ForName(DirectiveName)
+	if d == nil {
+		return fieldStrategy{}
+	}
+	arg := d.Arguments.ForName("strategy")
+	if arg == nil || arg.Value == nil || arg.Value.Kind == ast.NullValue {
+		return fieldStrategy{masked: true}
+	}
+	strategy, err := mask.ParseStrategy(arg.Value.Raw)
+	if err != nil {
+		err = fmt.Errorf("field %s: %w", def.Name, err)
+	}
+	return fieldStrategy{masked: true, strategy: strategy, err: err}
+}
+
+// maskValue applies strategy to v or, for lists, to each of their elements.
+func maskValue(strategy mask.Strategy, v reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		fallthrough
+	case reflect.Array:
+		var out reflect.Value
+		if v.Kind() == reflect.Slice {
+			out = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		} else {
+			out = reflect.New(v.Type()).Elem()
+		}
+		for i := 0; i < v.Len(); i++ {
+			masked, err := maskValue(strategy, v.Index(i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Index(i).Set(masked)
+		}
+		return out, nil
+	case reflect.Ptr:
+		if v.IsNil() || (v.Elem().Kind() != reflect.Slice && v.Elem().Kind() != reflect.Array) {
+			break
+		}
+		masked, err := maskValue(strategy, v.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(masked)
+		return out, nil
+	}
+	masked, err := mask.Field[interface{}](strategy).Mask(v.Interface())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if masked == nil {
+		return reflect.Zero(v.Type()), nil
+	}
+	return reflect.ValueOf(masked), nil
+}
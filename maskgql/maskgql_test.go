@@ -0,0 +1,79 @@
+package maskgql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	mask "github.com/doejon/go-mask"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var testSchema = gqlparser.MustLoadSchema(&ast.Source{Input: Directive + `
+type Query { user: User }
+type User {
+	name: String!
+	email: String! @mask(strategy: "partial=1:1")
+	phone: String @mask(strategy: "redact")
+	aliases: [String!]! @mask(strategy: "partial=1:0")
+	address: Address @mask
+	token: String @mask(strategy: "nope")
+}
+type Address { street: String! }
+`})
+
+type testAddress struct {
+	Street string `mask:"redact"`
+	City   string
+}
+
+func resolve(t *testing.T, field string, res interface{}) (interface{}, error) {
+	t.Helper()
+	def := testSchema.Types["User"].Fields.ForName(field)
+	ctx := graphql.WithFieldContext(context.Background(), &graphql.FieldContext{
+		Object: "User",
+		Field:  graphql.CollectedField{Field: &ast.Field{Name: field, Definition: def}},
+	})
+	return FieldMiddleware()(ctx, func(context.Context) (interface{}, error) {
+		return res, nil
+	})
+}
+
+func TestFieldMiddleware(t *testing.T) {
+	phone := "5551234"
+	for _, tc := range []struct {
+		field  string
+		res    interface{}
+		expect interface{}
+	}{
+		{"name", "Alice", "Alice"},
+		{"email", "alice@example.com", "a***************m"},
+		{"phone", &phone, func() *string { s := mask.DefaultPlaceholder; return &s }()},
+		{"phone", (*string)(nil), (*string)(nil)},
+		{"aliases", []string{"al", "ally"}, []string{"a*", "a***"}},
+		{"address", &testAddress{Street: "Main St 1", City: "Berlin"}, &testAddress{Street: mask.DefaultPlaceholder, City: "Berlin"}},
+	} {
+		got, err := resolve(t, tc.field, tc.res)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.field, err)
+		}
+		if !reflect.DeepEqual(got, tc.expect) {
+			t.Errorf("%s: expect %v == %v", tc.field, got, tc.expect)
+		}
+	}
+	if phone != "5551234" {
+		t.Errorf("expect the result not to be modified")
+	}
+}
+
+func TestFieldMiddlewareErrors(t *testing.T) {
+	if _, err := resolve(t, "token", "secret"); !errors.Is(err, mask.ErrUnknownStrategy) {
+		t.Errorf("expect %v == %v", err, mask.ErrUnknownStrategy)
+	}
+	if got, err := resolve(t, "email", 42); err == nil {
+		t.Errorf("expect incompatible results to fail, got %v", got)
+	}
+}
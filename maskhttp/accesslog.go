@@ -0,0 +1,190 @@
+package maskhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// LogFormat is the format of access log lines.
+type LogFormat int
+
+const (
+	// CommonLog is the Common Log Format of Apache and nginx.
+	CommonLog LogFormat = iota
+	// CombinedLog is CommonLog followed by the referer and user agent.
+	CombinedLog
+	// JSONLog writes a JSON object per request, including the request headers and masked bodies.
+	JSONLog
+)
+
+// SensitiveHeaders are redacted in access logs.
+var SensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// redacted replaces sensitive headers and query strings which cannot be masked.
+const redacted = "[REDACTED]"
+
+// AccessLog returns a middleware writing a line per request to w in the given format.
+// Query strings are masked like forms, i.e. using the options of m, sensitive
+// headers are redacted and the user of basic authentication is never written.
+func (m *Masker) AccessLog(w io.Writer, format LogFormat) func(http.Handler) http.Handler {
+	var mu sync.Mutex
+	return m.Middleware(func(e Exchange) {
+		var line []byte
+		switch format {
+		case JSONLog:
+			line = m.jsonLine(e)
+		default:
+			line = m.commonLine(e, format == CombinedLog)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write(line)
+	})
+}
+
+func (m *Masker) commonLine(e Exchange, combined bool) []byte {
+	r := e.Request
+	size := "-"
+	if e.ResponseSize > 0 {
+		size = fmt.Sprint(e.ResponseSize)
+	}
+	line := fmt.Sprintf("%s - - [%s] %q %d %s",
+		remoteHost(r), e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method+" "+m.MaskURL(r.URL)+" "+r.Proto, e.Status, size)
+	if combined {
+		line += fmt.Sprintf(" %q %q", m.maskReferer(r.Referer()), r.UserAgent())
+	}
+	return []byte(line + "\n")
+}
+
+type jsonLine struct {
+	Time         string              `json:"time"`
+	RemoteAddr   string              `json:"remote_addr"`
+	Method       string              `json:"method"`
+	URI          string              `json:"uri"`
+	Proto        string              `json:"proto"`
+	Status       int                 `json:"status"`
+	Bytes        int64               `json:"bytes"`
+	DurationMS   float64             `json:"duration_ms"`
+	Referer      string              `json:"referer,omitempty"`
+	UserAgent    string              `json:"user_agent,omitempty"`
+	Headers      map[string][]string `json:"headers,omitempty"`
+	RequestBody  json.RawMessage     `json:"request_body,omitempty"`
+	ResponseBody json.RawMessage     `json:"response_body,omitempty"`
+}
+
+func (m *Masker) jsonLine(e Exchange) []byte {
+	r := e.Request
+	headers := MaskHeader(r.Header)
+	if referer := r.Referer(); referer != "" {
+		headers.Set("Referer", m.maskReferer(referer))
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(jsonLine{
+		Time:         e.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		RemoteAddr:   remoteHost(r),
+		Method:       r.Method,
+		URI:          m.MaskURL(r.URL),
+		Proto:        r.Proto,
+		Status:       e.Status,
+		Bytes:        e.ResponseSize,
+		DurationMS:   float64(e.Duration.Microseconds()) / 1000,
+		Referer:      m.maskReferer(r.Referer()),
+		UserAgent:    r.UserAgent(),
+		Headers:      headers,
+		RequestBody:  rawBody(e.RequestBody),
+		ResponseBody: rawBody(e.ResponseBody),
+	})
+	return buf.Bytes()
+}
+
+// rawBody embeds JSON bodies as they are and all other bodies as strings.
+func rawBody(b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return nil
+	}
+	if json.Valid(b) {
+		return b
+	}
+	s, _ := json.Marshal(string(b))
+	return s
+}
+
+func remoteHost(r *http.Request) string {
+	host := r.RemoteAddr
+	if i := strings.LastIndexByte(host, ':'); i >= 0 {
+		host = host[:i]
+	}
+	return strings.Trim(host, "[]")
+}
+
+// MaskURL returns the request URI of u with its query masked like a form.
+// Queries which cannot be parsed are redacted as a whole.
+func (m *Masker) MaskURL(u *url.URL) string {
+	uri := u.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+	if u.RawQuery == "" {
+		return uri
+	}
+	return uri + "?" + m.maskQuery(u.RawQuery)
+}
+
+func (m *Masker) maskQuery(query string) string {
+	values, err := url.ParseQuery(query)
+	if err == nil {
+		values, err = m.maskValues(values)
+	}
+	if err != nil {
+		return redacted
+	}
+	return values.Encode()
+}
+
+// maskReferer masks the query of the referer, dropping credentials and fragments.
+func (m *Masker) maskReferer(referer string) string {
+	if referer == "" {
+		return ""
+	}
+	u, err := url.Parse(referer)
+	if err != nil {
+		return redacted
+	}
+	u.User = nil
+	u.Fragment = ""
+	if u.RawQuery != "" {
+		u.RawQuery = m.maskQuery(u.RawQuery)
+	}
+	return u.String()
+}
+
+// MaskHeader returns a copy of h with the values of SensitiveHeaders redacted.
+func MaskHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range SensitiveHeaders {
+		if vs, ok := out[http.CanonicalHeaderKey(name)]; ok {
+			masked := make([]string, len(vs))
+			for i := range masked {
+				masked[i] = redacted
+			}
+			out[http.CanonicalHeaderKey(name)] = masked
+		}
+	}
+	return out
+}
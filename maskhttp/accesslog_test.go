@@ -0,0 +1,65 @@
+package maskhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessLog(t *testing.T) {
+	defer func(orig func() time.Time) { now = orig }(now)
+	start := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	calls := 0
+	now = func() time.Time {
+		calls++
+		return start.Add(time.Duration(calls-1) * 1500 * time.Microsecond)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"token":"tok_123"}`)
+	})
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/login?user=alice&password=s3cr3t", strings.NewReader(`{"password":"s3cr3t"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer abc")
+		req.Header.Set("Referer", "https://bob:pw@example.com/form?password=s3cr3t#top")
+		req.Header.Set("User-Agent", "test/1.0")
+		return req
+	}
+
+	for _, tc := range []struct {
+		format LogFormat
+		expect string
+	}{
+		{CommonLog, `192.0.2.1 - - [01/Mar/2024:12:30:00 +0000] "POST /login?password=MASKED&user=alice HTTP/1.1" 200 19` + "\n"},
+		{CombinedLog, `192.0.2.1 - - [01/Mar/2024:12:30:00 +0000] "POST /login?password=MASKED&user=alice HTTP/1.1" 200 19 "https://example.com/form?password=MASKED" "test/1.0"` + "\n"},
+		{JSONLog, `{"time":"2024-03-01T12:30:00.000Z","remote_addr":"192.0.2.1","method":"POST","uri":"/login?password=MASKED&user=alice","proto":"HTTP/1.1","status":200,"bytes":19,"duration_ms":1.5,` +
+			`"referer":"https://example.com/form?password=MASKED","user_agent":"test/1.0",` +
+			`"headers":{"Authorization":["[REDACTED]"],"Content-Type":["application/json"],"Referer":["https://example.com/form?password=MASKED"],"User-Agent":["test/1.0"]},` +
+			`"request_body":{"password":"MASKED"},"response_body":{"token":"MASKED"}}` + "\n"},
+	} {
+		calls = 0
+		var logs bytes.Buffer
+		newTestMasker(t).AccessLog(&logs, tc.format)(handler).ServeHTTP(httptest.NewRecorder(), newRequest())
+		if logs.String() != tc.expect {
+			t.Errorf("expect %v == %v", logs.String(), tc.expect)
+		}
+	}
+}
+
+func TestMaskHeader(t *testing.T) {
+	h := http.Header{"Cookie": {"a=b", "c=d"}, "Accept": {"*/*"}}
+	masked := MaskHeader(h)
+	if got := masked.Values("Cookie"); len(got) != 2 || got[0] != "[REDACTED]" {
+		t.Errorf("expect cookies to be redacted, got %v", got)
+	}
+	if masked.Get("Accept") != "*/*" || h.Get("Cookie") != "a=b" {
+		t.Errorf("expect other headers and h to be kept, got %v, %v", masked, h)
+	}
+}
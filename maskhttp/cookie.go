@@ -0,0 +1,87 @@
+package maskhttp
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+// cookieValues mask the values of cookies, keeping enough of session ids to
+// correlate requests: a fifth of their characters, at most 4, e.g.
+// "abcd****************" for "abcdefghijklmnopqrst", so short values are not mostly disclosed.
+var cookieValues = [...]maskers.Strategy{
+	maskers.Partial(0, 0, maskers.Format{}),
+	maskers.Partial(1, 0, maskers.Format{}),
+	maskers.Partial(2, 0, maskers.Format{}),
+	maskers.Partial(3, 0, maskers.Format{}),
+	maskers.Partial(4, 0, maskers.Format{}),
+}
+
+func maskCookieValue(v string) string {
+	keep := min(utf8.RuneCountInString(v)/5, len(cookieValues)-1)
+	masked, err := cookieValues[keep].Mask(reflect.ValueOf(v))
+	if err != nil {
+		return redacted
+	}
+	return masked.String()
+}
+
+// MaskCookies returns copies of cookies with their values masked partially,
+// e.g. those of http.Request.Cookies or http.Response.Cookies. Names and
+// attributes like Path, Expires or HttpOnly are kept.
+func MaskCookies(cookies []*http.Cookie) []*http.Cookie {
+	out := make([]*http.Cookie, len(cookies))
+	for i, c := range cookies {
+		masked := *c
+		masked.Value = maskCookieValue(c.Value)
+		// Raw holds the unparsed header including the value
+		masked.Raw = ""
+		out[i] = &masked
+	}
+	return out
+}
+
+// MaskCookieHeader masks the values of a Cookie request header partially,
+// e.g. "session=ab**********; theme=****" for "session=abcdefghijkl; theme=dark".
+func MaskCookieHeader(header string) string {
+	pairs := strings.Split(header, ";")
+	for i, pair := range pairs {
+		pairs[i] = maskCookiePair(pair)
+	}
+	return strings.Join(pairs, ";")
+}
+
+// MaskSetCookie masks the value of a Set-Cookie response header partially,
+// keeping its name and attributes, so proxies can log the cookies they pass on:
+//
+//	for i, v := range resp.Header.Values("Set-Cookie") {
+//		log.Printf("set-cookie[%d]: %s", i, maskhttp.MaskSetCookie(v))
+//	}
+func MaskSetCookie(header string) string {
+	pair, attrs, ok := strings.Cut(header, ";")
+	if !ok {
+		return maskCookiePair(pair)
+	}
+	return maskCookiePair(pair) + ";" + attrs
+}
+
+// maskCookiePair masks the value of "name=value", keeping quotes around it.
+func maskCookiePair(pair string) string {
+	name, value, ok := strings.Cut(pair, "=")
+	if !ok {
+		return pair
+	}
+	trimmed := strings.TrimSpace(value)
+	quoted := len(trimmed) >= 2 && trimmed[0] == '"' && trimmed[len(trimmed)-1] == '"'
+	if quoted {
+		trimmed = trimmed[1 : len(trimmed)-1]
+	}
+	masked := maskCookieValue(trimmed)
+	if quoted {
+		masked = `"` + masked + `"`
+	}
+	return name + "=" + masked
+}
@@ -0,0 +1,51 @@
+package maskhttp
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMaskCookies(t *testing.T) {
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	cookies := []*http.Cookie{
+		{Name: "session", Value: "abcdefghijkl", Path: "/", Expires: expires, HttpOnly: true, Raw: "session=abcdefghijkl; Path=/"},
+		{Name: "theme", Value: "dark"},
+	}
+	out := MaskCookies(cookies)
+	if out[0].Value != "ab**********" || out[0].Path != "/" || !out[0].Expires.Equal(expires) || !out[0].HttpOnly || out[0].Raw != "" {
+		t.Errorf("expect %+v to be masked keeping its attributes", out[0])
+	}
+	if out[1].Value != "****" {
+		t.Errorf("expect %v == %v", out[1].Value, "****")
+	}
+	if cookies[0].Value != "abcdefghijkl" {
+		t.Errorf("expect %v to be unchanged", cookies[0])
+	}
+}
+
+func TestMaskCookieHeader(t *testing.T) {
+	for in, expect := range map[string]string{
+		"session=abcdefghijkl; theme=dark": "session=ab**********; theme=****",
+		`id="abcdefgh"`:                    `id="a*******"`,
+		"flag":                             "flag",
+		"session=abcdefghijklmnopqrst":     "session=abcd****************",
+		"session=abcde":                    "session=a****",
+	} {
+		if got := MaskCookieHeader(in); got != expect {
+			t.Errorf("expect %v == %v", got, expect)
+		}
+	}
+}
+
+func TestMaskSetCookie(t *testing.T) {
+	for in, expect := range map[string]string{
+		"session=abcdefghijkl; Path=/; Secure; HttpOnly; SameSite=Lax": "session=ab**********; Path=/; Secure; HttpOnly; SameSite=Lax",
+		"session=abcdefghijkl": "session=ab**********",
+		"session=; Max-Age=0":  "session=; Max-Age=0",
+	} {
+		if got := MaskSetCookie(in); got != expect {
+			t.Errorf("expect %v == %v", got, expect)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// Package maskhttp captures the bodies of HTTP requests and responses masked
+// for logging, so access logs and debugging middleware never write personal
+// data or credentials sent by clients:
+//
+//	m := maskhttp.New(maskhttp.WithOptions(policyOpts...))
+//	handler = m.Middleware(func(e maskhttp.Exchange) {
+//		slog.Info("request", "path", e.Request.URL.Path, "status", e.Status,
+//			"request_body", e.RequestBody, "response_body", e.ResponseBody)
+//	})(handler)
+//
+// JSON, form and multipart form bodies are decoded and masked using the given
+// options, usually path strategies or a policy. The contents of uploaded files are
+// replaced by their size and hash. Bodies of other types, bodies exceeding the
+// maximum size and bodies which cannot be decoded are omitted.
+package maskhttp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/internal/jsonnumber"
+)
+
+// DefaultMaxBodySize is the size of the largest body captured by default.
+const DefaultMaxBodySize = 64 << 10
+
+// now is replaced by tests.
+var now = time.Now
+
+// Option configures a Masker.
+type Option func(*Masker)
+
+// WithOptions masks bodies using opts.
+func WithOptions(opts ...mask.Option) Option {
+	return func(m *Masker) {
+		m.opts = append(m.opts, opts...)
+	}
+}
+
+// WithMaxBodySize captures bodies up to n bytes; larger ones are omitted.
+func WithMaxBodySize(n int) Option {
+	return func(m *Masker) {
+		m.maxBodySize = n
+	}
+}
+
+// Masker captures and masks bodies. It is safe for concurrent use.
+type Masker struct {
+	opts        []mask.Option
+	maxBodySize int
+}
+
+// New creates a Masker.
+func New(opts ...Option) *Masker {
+	m := &Masker{maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Exchange describes a request served by a handler.
+type Exchange struct {
+	Request *http.Request
+	Status  int
+	// RequestBody and ResponseBody hold the masked bodies, or a note why they were omitted.
+	// They are empty for requests and responses without body.
+	RequestBody, ResponseBody []byte
+	// ResponseSize is the number of bytes written by the handler.
+	ResponseSize int64
+	// Time is the time the request has been received.
+	Time     time.Time
+	Duration time.Duration
+}
+
+// Middleware returns a middleware passing the exchanges of all requests to logf
+// once they have been served.
+func (m *Masker) Middleware(logf func(Exchange)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := now()
+			req := m.CaptureRequest(r)
+			rec := &recorder{ResponseWriter: w, body: m.NewBody()}
+			next.ServeHTTP(rec, r)
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			logf(Exchange{
+				Request:      r,
+				Status:       status,
+				RequestBody:  m.Mask(r.Header.Get("Content-Type"), req),
+				ResponseBody: m.Mask(w.Header().Get("Content-Type"), rec.body),
+				ResponseSize: rec.body.Size(),
+				Time:         start,
+				Duration:     now().Sub(start),
+			})
+		})
+	}
+}
+
+// Body captures a body up to the maximum size of its Masker.
+// Body is an io.Writer, e.g. for ResponseWriter wrappers of frameworks.
+type Body struct {
+	buf  bytes.Buffer
+	max  int
+	size int64
+}
+
+// NewBody creates a Body capturing up to the maximum body size of m.
+func (m *Masker) NewBody() *Body {
+	return &Body{max: m.maxBodySize}
+}
+
+// Write captures p; it never fails.
+func (b *Body) Write(p []byte) (int, error) {
+	if rest := b.max - b.buf.Len(); rest > 0 && b.size == int64(b.buf.Len()) {
+		if len(p) <= rest {
+			b.buf.Write(p)
+		}
+	}
+	b.size += int64(len(p))
+	return len(p), nil
+}
+
+// Size returns the number of bytes written to b, including those not captured.
+func (b *Body) Size() int64 {
+	return b.size
+}
+
+func (b *Body) truncated() bool {
+	return b.size != int64(b.buf.Len())
+}
+
+// CaptureRequest captures the body of r. r.Body is replaced by a reader
+// returning the whole body, so handlers are not affected.
+func (m *Masker) CaptureRequest(r *http.Request) *Body {
+	b := m.NewBody()
+	if r.Body == nil || r.Body == http.NoBody {
+		return b
+	}
+	head, err := io.ReadAll(io.LimitReader(r.Body, int64(m.maxBodySize)+1))
+	b.Write(head)
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(head), errReader{err}, r.Body), r.Body}
+	return b
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// errReader returns the error reading the captured part of a body, if any, to the handler.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
+
+// Mask masks body of the given content type. It returns nil for empty bodies
+// and a note for bodies which cannot be masked, e.g. "[1024 bytes omitted]".
+func (m *Masker) Mask(contentType string, body *Body) []byte {
+	if body.size == 0 {
+		return nil
+	}
+	if body.truncated() {
+		return omitted(body)
+	}
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	var (
+		masked []byte
+		err    error
+	)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		masked, err = m.maskJSON(body.buf.Bytes())
+	case mediaType == "application/x-www-form-urlencoded":
+		masked, err = m.maskForm(body.buf.String())
+	case mediaType == "multipart/form-data":
+		masked, err = m.maskMultipart(body.buf.Bytes(), params["boundary"])
+	default:
+		return omitted(body)
+	}
+	if err != nil {
+		return omitted(body)
+	}
+	return masked
+}
+
+func omitted(body *Body) []byte {
+	return []byte(fmt.Sprintf("[%d bytes omitted]", body.size))
+}
+
+func (m *Masker) maskJSON(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("trailing data")
+	}
+	masked, err := mask.Mask(doc, m.opts...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonnumber.Masked(masked))
+}
+
+// maskForm masks forms like a JSON object of the form values,
+// i.e. fields are located by paths like "password".
+func (m *Masker) maskForm(s string) ([]byte, error) {
+	values, err := url.ParseQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	masked, err := m.maskValues(values)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(masked.Encode()), nil
+}
+
+func (m *Masker) maskValues(values url.Values) (url.Values, error) {
+	doc := make(map[string]interface{}, len(values))
+	for k, vs := range values {
+		if len(vs) == 1 {
+			doc[k] = vs[0]
+			continue
+		}
+		list := make([]interface{}, len(vs))
+		for i, v := range vs {
+			list[i] = v
+		}
+		doc[k] = list
+	}
+	masked, err := mask.Mask(doc, m.opts...)
+	if err != nil {
+		return nil, err
+	}
+	out := make(url.Values, len(masked))
+	for k, v := range masked {
+		if list, ok := v.([]interface{}); ok {
+			for _, item := range list {
+				out.Add(k, fmt.Sprint(item))
+			}
+			continue
+		}
+		out.Set(k, fmt.Sprint(v))
+	}
+	return out, nil
+}
+
+// maskMultipart masks the fields of multipart forms like forms; the contents of
+// files are replaced by their size and SHA-256 hash, e.g. "[1024 bytes, sha256:9f86d0...]",
+// so uploads can be told apart without logging them. The parts are written in
+// their original order, delimited by the original boundary.
+func (m *Masker) maskMultipart(b []byte, boundary string) ([]byte, error) {
+	if boundary == "" {
+		return nil, errors.New("missing boundary")
+	}
+	type part struct {
+		header      textproto.MIMEHeader
+		name, value string
+		file        bool
+	}
+	var (
+		parts  []part
+		values = url.Values{}
+	)
+	r := multipart.NewReader(bytes.NewReader(b), boundary)
+	for {
+		p, err := r.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(p)
+		if err != nil {
+			return nil, err
+		}
+		pt := part{header: p.Header, name: p.FormName(), file: p.FileName() != ""}
+		if pt.file {
+			pt.value = fmt.Sprintf("[%d bytes, sha256:%x]", len(content), sha256.Sum256(content))
+		} else {
+			values.Add(pt.name, string(content))
+		}
+		parts = append(parts, pt)
+		p.Close()
+	}
+	masked, err := m.maskValues(values)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	w := multipart.NewWriter(&out)
+	if err := w.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+	for _, p := range parts {
+		if !p.file {
+			// fields of the same name are masked in order
+			p.value = masked.Get(p.name)
+			masked[p.name] = masked[p.name][min(1, len(masked[p.name])):]
+		}
+		pw, err := w.CreatePart(p.header)
+		if err != nil {
+			return nil, err
+		}
+		io.WriteString(pw, p.value)
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// recorder records the status and body written by a handler.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   *Body
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 && status >= http.StatusOK {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.body.Write(p[:n])
+	return n, err
+}
+
+func (r *recorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap allows http.ResponseController to access the wrapped ResponseWriter.
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,124 @@
+package maskhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+)
+
+func newTestMasker(t *testing.T, opts ...Option) *Masker {
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(append([]Option{WithOptions(
+		mask.WithPathStrategy("password", redact),
+		mask.WithPathStrategy("**.token", redact),
+	)}, opts...)...)
+}
+
+func TestMiddleware(t *testing.T) {
+	var got Exchange
+	m := newTestMasker(t)
+	handler := m.Middleware(func(e Exchange) { got = e })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"user":"alice","password":"s3cr3t"}` {
+			t.Errorf("expect the handler to read the whole body, got %q", body)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"session":{"token":"tok_123","ttl":3600}}`)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"user":"alice","password":"s3cr3t"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Body.String() != `{"session":{"token":"tok_123","ttl":3600}}` {
+		t.Errorf("expect the response not to be modified, got %q", rec.Body.String())
+	}
+	if got.Status != http.StatusCreated {
+		t.Errorf("expect %v == %v", got.Status, http.StatusCreated)
+	}
+	if s := string(got.RequestBody); s != `{"password":"MASKED","user":"alice"}` {
+		t.Errorf("expect %v == %v", s, `{"password":"MASKED","user":"alice"}`)
+	}
+	if s := string(got.ResponseBody); s != `{"session":{"token":"MASKED","ttl":3600}}` {
+		t.Errorf("expect %v == %v", s, `{"session":{"token":"MASKED","ttl":3600}}`)
+	}
+}
+
+func TestMask(t *testing.T) {
+	m := newTestMasker(t, WithMaxBodySize(40))
+	for _, tc := range []struct {
+		contentType string
+		body        string
+		expect      string
+	}{
+		{"application/json", "", ""},
+		{"application/problem+json", `{"token":"x"}`, `{"token":"MASKED"}`},
+		{"application/json", `{"password":1234}`, `{"password":"MASKED"}`},
+		{"application/x-www-form-urlencoded", "user=alice&password=s3cr3t&tag=a&tag=b", "password=MASKED&tag=a&tag=b&user=alice"},
+		{"application/json", `{"password":`, "[12 bytes omitted]"},
+		{"application/json", `{} {}`, "[5 bytes omitted]"},
+		{"text/plain", "password=s3cr3t", "[15 bytes omitted]"},
+		{"application/json", `{"password":"` + strings.Repeat("x", 40) + `"}`, "[55 bytes omitted]"},
+	} {
+		b := m.NewBody()
+		// Write in chunks like handlers do.
+		for i := 0; i < len(tc.body); i += 10 {
+			b.Write([]byte(tc.body[i:min(i+10, len(tc.body))]))
+		}
+		if got := string(m.Mask(tc.contentType, b)); got != tc.expect {
+			t.Errorf("%s %s: expect %v == %v", tc.contentType, tc.body, got, tc.expect)
+		}
+	}
+}
+
+func TestCaptureRequest(t *testing.T) {
+	m := New(WithMaxBodySize(4))
+	body := strings.Repeat("x", 10)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	b := m.CaptureRequest(req)
+	if b.Size() != 5 {
+		t.Errorf("expect %v == %v", b.Size(), 5)
+	}
+	read, _ := io.ReadAll(req.Body)
+	if string(read) != body {
+		t.Errorf("expect %v == %v", string(read), body)
+	}
+}
+
+func TestMaskMultipart(t *testing.T) {
+	m := newTestMasker(t)
+	body := "--b\r\n" +
+		"Content-Disposition: form-data; name=\"user\"\r\n\r\nalice\r\n" +
+		"--b\r\n" +
+		"Content-Disposition: form-data; name=\"password\"\r\n\r\ns3cr3t\r\n" +
+		"--b\r\n" +
+		"Content-Disposition: form-data; name=\"avatar\"; filename=\"me.png\"\r\n" +
+		"Content-Type: image/png\r\n\r\ntest\r\n" +
+		"--b--\r\n"
+	expect := "--b\r\n" +
+		"Content-Disposition: form-data; name=\"user\"\r\n\r\nalice\r\n" +
+		"--b\r\n" +
+		"Content-Disposition: form-data; name=\"password\"\r\n\r\nMASKED\r\n" +
+		"--b\r\n" +
+		"Content-Disposition: form-data; name=\"avatar\"; filename=\"me.png\"\r\n" +
+		"Content-Type: image/png\r\n\r\n" +
+		"[4 bytes, sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08]\r\n" +
+		"--b--\r\n"
+	b := m.NewBody()
+	b.Write([]byte(body))
+	if got := string(m.Mask("multipart/form-data; boundary=b", b)); got != expect {
+		t.Errorf("expect %q == %q", got, expect)
+	}
+	if got := string(m.Mask("multipart/form-data", b)); got != "[234 bytes omitted]" {
+		t.Errorf("expect %v == %v", got, "[234 bytes omitted]")
+	}
+}
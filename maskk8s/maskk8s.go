@@ -0,0 +1,149 @@
+// Package maskk8s masks Kubernetes objects, so operators and controllers can log
+// resources safely:
+//
+//	log.Info("reconciling", "secret", maskk8s.Must(secret))
+//
+// Objects are copied by their DeepCopyObject method. In the copy, the values of Secrets,
+// the values of ConfigMap keys named like secrets and the values of environment variables
+// named like secrets in pod specs are redacted, see mask.WithSecretNames, as well as the
+// kubectl.kubernetes.io/last-applied-configuration annotation, which repeats all of them.
+// References to secrets, like valueFrom of environment variables, are kept.
+package maskk8s
+
+import (
+	mask "github.com/doejon/go-mask"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// LastAppliedConfigAnnotation holds the last configuration applied by kubectl,
+// including the data of Secrets and the environment of pods.
+const LastAppliedConfigAnnotation = corev1.LastAppliedConfigAnnotation
+
+// Mask returns a masked deep copy of obj. Options, e.g. path strategies, are applied
+// to the copy afterwards; values are copied by their DeepCopy methods then, see mask.WithCloneMethods.
+func Mask[T runtime.Object](obj T, opts ...mask.Option) (T, error) {
+	c, ok := obj.DeepCopyObject().(T)
+	if !ok {
+		// a nil object
+		return c, nil
+	}
+	maskObject(c)
+	if len(opts) == 0 {
+		return c, nil
+	}
+	return mask.Mask(c, append([]mask.Option{mask.WithCloneMethods()}, opts...)...)
+}
+
+// Must masks obj like Mask and panics on any errors.
+func Must[T runtime.Object](obj T, opts ...mask.Option) T {
+	c, err := Mask(obj, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// maskObject masks obj in place.
+func maskObject(obj runtime.Object) {
+	if m, err := meta.Accessor(obj); err == nil {
+		if annotations := m.GetAnnotations(); annotations[LastAppliedConfigAnnotation] != "" {
+			annotations[LastAppliedConfigAnnotation] = placeholder()
+		}
+	}
+	switch o := obj.(type) {
+	case *corev1.Secret:
+		maskSecret(o)
+	case *corev1.SecretList:
+		for i := range o.Items {
+			maskObject(&o.Items[i])
+		}
+	case *corev1.ConfigMap:
+		maskConfigMap(o)
+	case *corev1.ConfigMapList:
+		for i := range o.Items {
+			maskObject(&o.Items[i])
+		}
+	case *corev1.Pod:
+		maskPodSpec(&o.Spec)
+	case *corev1.PodList:
+		for i := range o.Items {
+			maskObject(&o.Items[i])
+		}
+	case *corev1.PodTemplate:
+		maskPodSpec(&o.Template.Spec)
+	case *corev1.ReplicationController:
+		if o.Spec.Template != nil {
+			maskPodSpec(&o.Spec.Template.Spec)
+		}
+	case *appsv1.Deployment:
+		maskPodSpec(&o.Spec.Template.Spec)
+	case *appsv1.DeploymentList:
+		for i := range o.Items {
+			maskObject(&o.Items[i])
+		}
+	case *appsv1.StatefulSet:
+		maskPodSpec(&o.Spec.Template.Spec)
+	case *appsv1.DaemonSet:
+		maskPodSpec(&o.Spec.Template.Spec)
+	case *appsv1.ReplicaSet:
+		maskPodSpec(&o.Spec.Template.Spec)
+	case *batchv1.Job:
+		maskPodSpec(&o.Spec.Template.Spec)
+	case *batchv1.CronJob:
+		maskPodSpec(&o.Spec.JobTemplate.Spec.Template.Spec)
+	}
+}
+
+func maskSecret(s *corev1.Secret) {
+	for k := range s.Data {
+		s.Data[k] = []byte(placeholder())
+	}
+	for k := range s.StringData {
+		s.StringData[k] = placeholder()
+	}
+}
+
+func maskConfigMap(c *corev1.ConfigMap) {
+	for k, v := range c.Data {
+		c.Data[k] = maskNamed(k, v)
+	}
+	for k, v := range c.BinaryData {
+		if masked := maskNamed(k, string(v)); masked != string(v) {
+			c.BinaryData[k] = []byte(masked)
+		}
+	}
+}
+
+func maskPodSpec(spec *corev1.PodSpec) {
+	for i := range spec.InitContainers {
+		maskEnv(spec.InitContainers[i].Env)
+	}
+	for i := range spec.Containers {
+		maskEnv(spec.Containers[i].Env)
+	}
+	for i := range spec.EphemeralContainers {
+		maskEnv(spec.EphemeralContainers[i].Env)
+	}
+}
+
+func maskEnv(env []corev1.EnvVar) {
+	for i, e := range env {
+		if e.Value != "" {
+			env[i].Value = maskNamed(e.Name, e.Value)
+		}
+	}
+}
+
+// maskNamed masks value if name looks like the name of a secret.
+func maskNamed(name, value string) string {
+	masked := mask.MaskEnviron([]string{name + "=" + value})
+	return masked[0][len(name)+1:]
+}
+
+func placeholder() string {
+	return mask.Placeholder[string]()
+}
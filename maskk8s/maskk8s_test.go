@@ -0,0 +1,88 @@
+package maskk8s
+
+import (
+	"reflect"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaskSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "db",
+			Annotations: map[string]string{LastAppliedConfigAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`, "owner": "team-a"},
+		},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+		StringData: map[string]string{"user": "ada"},
+	}
+	out := Must(secret)
+	expect := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "db",
+			Annotations: map[string]string{LastAppliedConfigAnnotation: "MASKED", "owner": "team-a"},
+		},
+		Data:       map[string][]byte{"password": []byte("MASKED")},
+		StringData: map[string]string{"user": "MASKED"},
+	}
+	if !reflect.DeepEqual(out, expect) {
+		t.Errorf("expect %+v == %+v", out, expect)
+	}
+	if string(secret.Data["password"]) != "hunter2" || secret.Annotations["owner"] != "team-a" {
+		t.Errorf("expect %+v to be unchanged", secret)
+	}
+
+	var nilSecret *corev1.Secret
+	if out := Must(nilSecret); out != nil {
+		t.Errorf("expect %v == nil", out)
+	}
+}
+
+func TestMaskConfigMap(t *testing.T) {
+	cm := &corev1.ConfigMap{Data: map[string]string{"LOG_LEVEL": "debug", "API_TOKEN": "t0k3n"}}
+	out := Must(cm)
+	if expect := map[string]string{"LOG_LEVEL": "debug", "API_TOKEN": "MASKED"}; !reflect.DeepEqual(out.Data, expect) {
+		t.Errorf("expect %v == %v", out.Data, expect)
+	}
+}
+
+func TestMaskDeployment(t *testing.T) {
+	ref := &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{Key: "password"}}
+	d := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name: "api",
+			Env: []corev1.EnvVar{
+				{Name: "DB_PASSWORD", Value: "hunter2"},
+				{Name: "DB_HOST", Value: "db"},
+				{Name: "API_KEY", ValueFrom: ref},
+			},
+			Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}},
+		}},
+	}}}}
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := Must(d, mask.WithPathStrategy("Spec.Template.Spec.Containers[*].Name", redact))
+
+	c := out.Spec.Template.Spec.Containers[0]
+	expect := []corev1.EnvVar{
+		{Name: "DB_PASSWORD", Value: "MASKED"},
+		{Name: "DB_HOST", Value: "db"},
+		{Name: "API_KEY", ValueFrom: ref},
+	}
+	if !reflect.DeepEqual(c.Env, expect) || c.Name != "MASKED" {
+		t.Errorf("expect %+v == %+v", c, expect)
+	}
+	// quantities keep their unexported state by DeepCopy
+	if cpu := c.Resources.Limits[corev1.ResourceCPU]; cpu.String() != "500m" {
+		t.Errorf("expect %v == 500m", cpu.String())
+	}
+	if d.Spec.Template.Spec.Containers[0].Env[0].Value != "hunter2" {
+		t.Errorf("expect %+v to be unchanged", d)
+	}
+}
@@ -0,0 +1,68 @@
+package maskobject
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type dir string
+
+const tempPrefix = ".maskobject-"
+
+// Dir returns a bucket keeping objects as files in the directory root; keys are
+// slash separated paths relative to root. Objects are written atomically.
+func Dir(root string) Bucket {
+	return dir(root)
+}
+
+func (d dir) List(ctx context.Context, prefix string, fn func(key string) error) error {
+	return filepath.WalkDir(string(d), func(name string, e fs.DirEntry, err error) error {
+		// temporary files are objects being written
+		if err != nil || e.IsDir() || strings.HasPrefix(e.Name(), tempPrefix) {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(string(d), name)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			return fn(key)
+		}
+		return nil
+	})
+}
+
+func (d dir) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(d.path(key))
+}
+
+func (d dir) Put(_ context.Context, key string, r io.Reader) error {
+	name := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+func (d dir) path(key string) string {
+	return filepath.Join(string(d), filepath.FromSlash(key))
+}
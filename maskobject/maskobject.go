@@ -0,0 +1,249 @@
+// Package maskobject scrubs objects in object storage, e.g. to minimize personal data
+// kept in cold storage by data retention workflows:
+//
+//	opts, err := policy.Options("archive")
+//	s := maskobject.New(maskaws.S3Bucket(client, "raw"), maskaws.S3Bucket(client, "archive"),
+//		maskobject.WithMaskOptions(opts...), maskobject.WithConcurrency(8))
+//	result, err := s.Scrub(ctx, "exports/2024/")
+//
+// JSON, NDJSON and CSV objects are recognized by their extension and streamed, so objects
+// of any size can be scrubbed. CSV rows are masked as a list holding a single object from
+// column names to values, i.e. columns are located by paths like "[*].email", like the mask
+// command does. All objects are masked within a single mask.Session. Objects of other
+// formats are skipped rather than copied unmasked.
+//
+// Buckets of S3 and GCS are provided by the modules maskaws and maskgcs; Dir uses a directory.
+package maskobject
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskpipe"
+)
+
+// Bucket reads and writes objects by key. Implementations must be safe for concurrent use.
+type Bucket interface {
+	// List calls fn with the key of every object starting with prefix.
+	List(ctx context.Context, prefix string, fn func(key string) error) error
+	// Get opens the object key for reading.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Put writes the object key, reading its content from r until io.EOF. If reading
+	// fails, the object must not be written.
+	Put(ctx context.Context, key string, r io.Reader) error
+}
+
+// formats maps the extensions of objects to their format.
+var formats = map[string]string{
+	".json":   "json",
+	".ndjson": "json",
+	".jsonl":  "json",
+	".csv":    "csv",
+}
+
+// Scrubber copies objects from one bucket to another, masking them, see New.
+type Scrubber struct {
+	src, dst    Bucket
+	opts        []mask.Option
+	session     *mask.Session
+	concurrency int
+	rename      func(key string) string
+}
+
+// Result lists the objects processed by Scrub.
+type Result struct {
+	// Scrubbed is the number of objects masked and written.
+	Scrubbed int
+	// Skipped lists the keys of objects of unknown formats, sorted.
+	Skipped []string
+}
+
+// Option configures a Scrubber.
+type Option func(*Scrubber)
+
+// WithMaskOptions masks objects using opts, e.g. the options of a policy.
+func WithMaskOptions(opts ...mask.Option) Option {
+	return func(s *Scrubber) {
+		s.opts = append(s.opts, opts...)
+	}
+}
+
+// WithSession masks objects within session rather than a session of their own.
+func WithSession(session *mask.Session) Option {
+	return func(s *Scrubber) {
+		s.session = session
+	}
+}
+
+// WithConcurrency scrubs up to n objects at once; it defaults to 4.
+func WithConcurrency(n int) Option {
+	return func(s *Scrubber) {
+		if n > 0 {
+			s.concurrency = n
+		}
+	}
+}
+
+// WithRename writes objects to the key returned by rename rather than their own,
+// e.g. to move them to another prefix.
+func WithRename(rename func(key string) string) Option {
+	return func(s *Scrubber) {
+		s.rename = rename
+	}
+}
+
+// New returns a Scrubber reading objects from src and writing them to dst.
+// src and dst may be the same bucket to scrub objects in place.
+func New(src, dst Bucket, opts ...Option) *Scrubber {
+	s := &Scrubber{src: src, dst: dst, concurrency: 4}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.session == nil {
+		s.session = mask.NewSession()
+	}
+	return s
+}
+
+// Scrub masks all objects starting with prefix. It stops at the first object which
+// cannot be scrubbed; objects scrubbed concurrently are completed.
+func (s *Scrubber) Scrub(ctx context.Context, prefix string) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		result Result
+		first  error
+		wg     sync.WaitGroup
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if first == nil {
+			first = err
+			cancel()
+		}
+	}
+	workers := make(chan struct{}, s.concurrency)
+	err := s.src.List(ctx, prefix, func(key string) error {
+		format, ok := formats[strings.ToLower(path.Ext(key))]
+		if !ok {
+			mu.Lock()
+			result.Skipped = append(result.Skipped, key)
+			mu.Unlock()
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case workers <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+			if err := s.scrub(ctx, key, format); err != nil {
+				fail(fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			mu.Lock()
+			result.Scrubbed++
+			mu.Unlock()
+		}()
+		return nil
+	})
+	wg.Wait()
+	if first != nil {
+		return &result, first
+	}
+	if err != nil {
+		return &result, err
+	}
+	sort.Strings(result.Skipped)
+	return &result, nil
+}
+
+// scrub masks the object key of format.
+func (s *Scrubber) scrub(ctx context.Context, key, format string) error {
+	rc, err := s.src.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	dst := key
+	if s.rename != nil {
+		dst = s.rename(key)
+	}
+	pr, pw := io.Pipe()
+	masked := make(chan error, 1)
+	go func() {
+		err := s.mask(ctx, format, rc, pw)
+		pw.CloseWithError(err)
+		masked <- err
+	}()
+	err = s.dst.Put(ctx, dst, pr)
+	// unblocks masking if Put returned early
+	pr.Close()
+	if err := <-masked; err != nil && !errors.Is(err, io.ErrClosedPipe) {
+		return err
+	}
+	return err
+}
+
+// mask masks the object of format read from r and writes it to w.
+func (s *Scrubber) mask(ctx context.Context, format string, r io.Reader, w io.Writer) error {
+	if format == "json" {
+		p := maskpipe.New(maskpipe.WithMaskOptions(s.opts...), maskpipe.WithSession(s.session), maskpipe.WithWorkers(1))
+		return p.Copy(ctx, maskpipe.NewJSONDecoder(r), maskpipe.NewJSONEncoder(w))
+	}
+
+	cr := csv.NewReader(r)
+	cw := csv.NewWriter(w)
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	opts := append(s.opts[:len(s.opts):len(s.opts)], mask.WithSession(s.session))
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		row := make(map[string]interface{}, len(header))
+		for i, col := range header {
+			row[col] = record[i]
+		}
+		masked, err := mask.Mask([]interface{}{row}, opts...)
+		if err != nil {
+			return err
+		}
+		row, _ = masked[0].(map[string]interface{})
+		for i, col := range header {
+			// omitted columns are empty
+			v, _ := row[col].(string)
+			record[i] = v
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
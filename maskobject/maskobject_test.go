@@ -0,0 +1,126 @@
+package maskobject
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskers"
+)
+
+func writeObjects(t *testing.T, objects map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for key, content := range objects {
+		name := filepath.Join(root, filepath.FromSlash(key))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func readObject(t *testing.T, root, key string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestScrub(t *testing.T) {
+	src := writeObjects(t, map[string]string{
+		"2024/users.json":    `{"users":[{"email":"ada@example.com","age":36}]}`,
+		"2024/events.ndjson": `{"email":"ada@example.com"}` + "\n" + `{"email":"bob@example.com"}` + "\n",
+		"2024/orders.CSV":    "id,email\n1,bob@example.com\n2,ada@example.com\n",
+		"2024/image.png":     "\x89PNG",
+		"2023/old.json":      `{"email":"eve@example.com"}`,
+	})
+	dst := t.TempDir()
+	s := New(Dir(src), Dir(dst), WithConcurrency(2), WithRename(func(key string) string {
+		return "scrubbed/" + key
+	}), WithMaskOptions(mask.WithPathStrategy("**.email", maskers.Sequence("user%d@example.com"))))
+	result, err := s.Scrub(context.Background(), "2024/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Scrubbed != 3 || len(result.Skipped) != 1 || result.Skipped[0] != "2024/image.png" {
+		t.Errorf("expect 3 objects scrubbed and the image skipped, got %+v", result)
+	}
+
+	// equal values are masked alike across objects
+	users := readObject(t, dst, "scrubbed/2024/users.json")
+	events := readObject(t, dst, "scrubbed/2024/events.ndjson")
+	orders := readObject(t, dst, "scrubbed/2024/orders.CSV")
+	ada, _, _ := strings.Cut(strings.TrimPrefix(users, `{"users":[{"age":36,"email":"`), `"`)
+	if !strings.HasPrefix(ada, "user") || !strings.Contains(events, ada) || !strings.Contains(orders, "2,"+ada+"\n") {
+		t.Errorf("expect %s to be masked consistently in %s, %s and %s", ada, users, events, orders)
+	}
+	for _, email := range []string{"ada@", "bob@"} {
+		if strings.Contains(users+events+orders, email) {
+			t.Errorf("expect %s, %s and %s to be masked", users, events, orders)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "scrubbed", "2023")); !os.IsNotExist(err) {
+		t.Errorf("expect objects outside the prefix to be ignored: %v", err)
+	}
+}
+
+func TestScrubInPlace(t *testing.T) {
+	root := writeObjects(t, map[string]string{
+		"a.json": `{"email":"ada@example.com"}`,
+		"b.json": `{"email":`,
+	})
+	s := New(Dir(root), Dir(root), WithMaskOptions(mask.WithPathStrategy("email", mask.Omit())))
+	if _, err := s.Scrub(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if out := readObject(t, root, "a.json"); out != "{}\n" {
+		t.Errorf("expect %q == %q", out, "{}\n")
+	}
+
+	// invalid objects are kept as they are
+	_, err := s.Scrub(context.Background(), "b")
+	if err == nil || !strings.HasPrefix(err.Error(), "b.json: ") {
+		t.Errorf("expect %v to fail for b.json", err)
+	}
+	if out := readObject(t, root, "b.json"); out != `{"email":` {
+		t.Errorf("expect %q to be kept", out)
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil || len(entries) != 2 {
+		t.Errorf("expect temporary files to be removed, got %v (%v)", entries, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Scrub(ctx, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v to be %v", err, context.Canceled)
+	}
+}
+
+func TestScrubNumbers(t *testing.T) {
+	root := writeObjects(t, map[string]string{
+		"users.json": `{"users":[{"age":36,"id":1}]}`,
+	})
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(Dir(root), Dir(root), WithMaskOptions(mask.WithPathStrategy("users[*].age", redact)))
+	if _, err := s.Scrub(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"users":[{"age":"MASKED","id":1}]}` + "\n"
+	if out := readObject(t, root, "users.json"); out != expect {
+		t.Errorf("expect %q == %q", out, expect)
+	}
+}
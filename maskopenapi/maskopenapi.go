@@ -0,0 +1,328 @@
+// Package maskopenapi derives masking policies from OpenAPI 3 specifications,
+// so HTTP handlers can redact request and response bodies using the markers
+// of the spec rather than duplicating them in Go code.
+//
+// Schemas and properties are marked sensitive using extensions:
+//
+//	properties:
+//	  email:
+//	    type: string
+//	    x-sensitive: true
+//	  phone:
+//	    type: string
+//	    x-mask: partial=0:4
+//
+// x-sensitive values are redacted, x-mask names a strategy like a struct tag.
+package maskopenapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	mask "github.com/doejon/go-mask"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SensitiveExtension marks schemas whose values are redacted.
+	SensitiveExtension = "x-sensitive"
+	// MaskExtension names the strategy masking the values of a schema.
+	MaskExtension = "x-mask"
+)
+
+// ErrInvalidSpec is returned for specifications which cannot be read.
+var ErrInvalidSpec = errors.New("invalid OpenAPI spec")
+
+// Spec holds the policies of all operations of an OpenAPI specification.
+type Spec struct {
+	operations []*Operation
+}
+
+// Operation holds the policies masking the bodies of a single operation.
+type Operation struct {
+	Method string
+	// Path is the path template of the operation, e.g. /users/{id}.
+	Path string
+	// Request masks request bodies.
+	Request *mask.Policy
+	// Responses masks response bodies by status code, e.g. "200", "2XX" or "default".
+	Responses map[string]*mask.Policy
+
+	segments []string
+}
+
+// Load reads an OpenAPI 3 specification in YAML or JSON.
+// It fails if a x-mask extension does not name a valid strategy.
+func Load(r io.Reader) (*Spec, error) {
+	var doc map[string]interface{}
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSpec, err)
+	}
+	paths, _ := doc["paths"].(map[string]interface{})
+	if paths == nil {
+		return nil, fmt.Errorf("%w: missing paths", ErrInvalidSpec)
+	}
+	s := &Spec{}
+	for path, item := range paths {
+		item, _ := resolve(doc, item).(map[string]interface{})
+		for method, op := range item {
+			method = strings.ToUpper(method)
+			if !isMethod(method) {
+				continue
+			}
+			o, err := newOperation(doc, method, path, op)
+			if err != nil {
+				return nil, err
+			}
+			s.operations = append(s.operations, o)
+		}
+	}
+	// Prefer literal segments over parameters, e.g. /users/me over /users/{id}.
+	sort.Slice(s.operations, func(i, j int) bool {
+		a, b := s.operations[i], s.operations[j]
+		if a.Path != b.Path {
+			return templateLess(a.segments, b.segments)
+		}
+		return a.Method < b.Method
+	})
+	return s, nil
+}
+
+func isMethod(m string) bool {
+	switch m {
+	case http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+		http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func newOperation(doc map[string]interface{}, method, path string, op interface{}) (*Operation, error) {
+	o := &Operation{
+		Method:    method,
+		Path:      path,
+		Responses: make(map[string]*mask.Policy),
+		segments:  strings.Split(strings.Trim(path, "/"), "/"),
+	}
+	spec, _ := resolve(doc, op).(map[string]interface{})
+	var err error
+	if o.Request, err = bodyPolicy(doc, spec["requestBody"]); err != nil {
+		return nil, fmt.Errorf("%s %s: request: %w", method, path, err)
+	}
+	responses, _ := resolve(doc, spec["responses"]).(map[string]interface{})
+	for status, response := range responses {
+		if o.Responses[status], err = bodyPolicy(doc, response); err != nil {
+			return nil, fmt.Errorf("%s %s: response %s: %w", method, path, status, err)
+		}
+	}
+	return o, nil
+}
+
+// Operations returns all operations of s.
+func (s *Spec) Operations() []*Operation {
+	return s.operations
+}
+
+// Operation returns the operation handling requests with the given method to path,
+// e.g. GET /users/42 for GET /users/{id}.
+func (s *Spec) Operation(method, path string) (*Operation, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, o := range s.operations {
+		if o.Method == method && o.match(segments) {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+func (o *Operation) match(segments []string) bool {
+	if len(segments) != len(o.segments) {
+		return false
+	}
+	for i, t := range o.segments {
+		if !isParam(t) && t != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func templateLess(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if pa, pb := isParam(a[i]), isParam(b[i]); pa != pb {
+			return pb
+		}
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// Response returns the policy of responses with the given status code,
+// falling back to its range, e.g. 2XX, and the default response.
+// Responses without policy yield an empty one.
+func (o *Operation) Response(status int) *mask.Policy {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		if p, ok := o.Responses[key]; ok {
+			return p
+		}
+	}
+	return &mask.Policy{}
+}
+
+// bodyPolicy derives the policy of a request body or response from the schemas of its JSON content.
+func bodyPolicy(doc map[string]interface{}, body interface{}) (*mask.Policy, error) {
+	p := &mask.Policy{}
+	b, _ := resolve(doc, body).(map[string]interface{})
+	content, _ := b["content"].(map[string]interface{})
+	mediaTypes := make([]string, 0, len(content))
+	for mt := range content {
+		mediaTypes = append(mediaTypes, mt)
+	}
+	sort.Strings(mediaTypes)
+	seen := make(map[string]bool)
+	for _, mt := range mediaTypes {
+		if !strings.Contains(mt, "json") {
+			continue
+		}
+		media, _ := content[mt].(map[string]interface{})
+		w := &walker{doc: doc, refs: make(map[string]int)}
+		if err := w.walk(media["schema"], ""); err != nil {
+			return nil, err
+		}
+		for _, r := range w.rules {
+			if !seen[r.Path] {
+				seen[r.Path] = true
+				p.Rules = append(p.Rules, r)
+			}
+		}
+	}
+	// bodies without sensitive properties yield an empty policy
+	if len(p.Rules) == 0 {
+		return p, nil
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+type walker struct {
+	doc   map[string]interface{}
+	rules []mask.PolicyRule
+	// refs tracks the schemas being walked: 1 while walking, 2 while walking recursions.
+	refs map[string]int
+}
+
+// walk collects the rules of schema located at path.
+func (w *walker) walk(schema interface{}, path string) error {
+	s, _ := schema.(map[string]interface{})
+	if s == nil {
+		return nil
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		state := w.refs[ref]
+		if state == 2 {
+			return nil
+		}
+		w.refs[ref] = state + 1
+		defer func() { w.refs[ref] = state }()
+		if state == 1 {
+			// The schema is recursive: mask its values at any depth.
+			path = join(path, "**")
+		}
+		return w.walk(resolve(w.doc, s), path)
+	}
+
+	if strategy, ok := strategyOf(s); ok {
+		if path == "" {
+			path = "**"
+		}
+		w.rules = append(w.rules, mask.PolicyRule{Path: path, Strategy: strategy})
+		return nil
+	}
+
+	for _, key := range []string{"allOf", "oneOf", "anyOf"} {
+		all, _ := s[key].([]interface{})
+		for _, sub := range all {
+			if err := w.walk(sub, path); err != nil {
+				return err
+			}
+		}
+	}
+	if props, ok := s["properties"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := w.walk(props[name], join(path, name)); err != nil {
+				return err
+			}
+		}
+	}
+	if items, ok := s["items"]; ok {
+		if err := w.walk(items, path+"[*]"); err != nil {
+			return err
+		}
+	}
+	if additional, ok := s["additionalProperties"]; ok {
+		if err := w.walk(additional, join(path, "*")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// strategyOf returns the strategy of a schema marked sensitive.
+func strategyOf(s map[string]interface{}) (string, bool) {
+	if strategy, ok := s[MaskExtension].(string); ok {
+		return strategy, true
+	}
+	if sensitive, _ := s[SensitiveExtension].(bool); sensitive {
+		return "redact", true
+	}
+	return "", false
+}
+
+// join appends name to path, quoting names which are not plain identifiers.
+func join(path, name string) string {
+	if name != "*" && name != "**" && strings.ContainsAny(name, `.[]"`) {
+		return path + "[" + strconv.Quote(name) + "]"
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// resolve follows the local reference of v, e.g. #/components/schemas/User, if any.
+func resolve(doc map[string]interface{}, v interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		m, _ := v.(map[string]interface{})
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return v
+		}
+		var cur interface{} = doc
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+			obj, _ := cur.(map[string]interface{})
+			cur = obj[token]
+		}
+		v = cur
+	}
+	return v
+}
@@ -0,0 +1,149 @@
+package maskopenapi
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info: {title: users, version: "1"}
+paths:
+  /users/{id}:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/User"}
+        default:
+          $ref: "#/components/responses/Error"
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              allOf:
+                - $ref: "#/components/schemas/User"
+                - properties:
+                    password: {type: string, x-sensitive: true}
+      responses:
+        "204": {description: updated}
+  /users/me:
+    get:
+      responses:
+        "2XX":
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: "#/components/schemas/User"}
+components:
+  responses:
+    Error:
+      content:
+        application/problem+json:
+          schema:
+            properties:
+              detail: {type: string, x-mask: "partial=0:0"}
+  schemas:
+    User:
+      properties:
+        name: {type: string}
+        email: {type: string, x-mask: "partial=1:1"}
+        labels:
+          type: object
+          additionalProperties: {type: string, x-sensitive: true}
+        "e.mail": {type: string, x-sensitive: true}
+        manager: {$ref: "#/components/schemas/User"}
+`
+
+func TestLoad(t *testing.T) {
+	spec, err := Load(strings.NewReader(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(spec.Operations()); n != 3 {
+		t.Errorf("expect %v == %v", n, 3)
+	}
+
+	op, ok := spec.Operation("GET", "/users/42")
+	if !ok || op.Path != "/users/{id}" {
+		t.Fatalf("expect GET /users/42 to match /users/{id}, got %v", op)
+	}
+	expect := []mask.PolicyRule{
+		{Path: `["e.mail"]`, Strategy: "redact"},
+		{Path: "email", Strategy: "partial=1:1"},
+		{Path: "labels.*", Strategy: "redact"},
+		{Path: `manager.**["e.mail"]`, Strategy: "redact"},
+		{Path: "manager.**.email", Strategy: "partial=1:1"},
+		{Path: "manager.**.labels.*", Strategy: "redact"},
+	}
+	if got := op.Response(200).Rules; !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect %v == %v", got, expect)
+	}
+	if got := op.Response(500).Rules; len(got) != 1 || got[0].Path != "detail" {
+		t.Errorf("expect the default response to mask detail, got %v", got)
+	}
+
+	op, _ = spec.Operation("PUT", "/users/42")
+	if got := op.Request.Rules; len(got) != 7 || got[6].Path != "password" {
+		t.Errorf("expect the request to mask the user and its password, got %v", got)
+	}
+	if got := op.Response(204).Rules; len(got) != 0 {
+		t.Errorf("expect responses without content to mask nothing, got %v", got)
+	}
+
+	op, _ = spec.Operation("GET", "/users/me")
+	if op.Path != "/users/me" {
+		t.Errorf("expect %v == %v", op.Path, "/users/me")
+	}
+	if got := op.Response(201).Rules; len(got) != 6 || got[1].Path != "[*].email" {
+		t.Errorf("expect items of the array to be masked, got %v", got)
+	}
+	if _, ok := spec.Operation("DELETE", "/users/42"); ok {
+		t.Errorf("expect unknown operations not to match")
+	}
+}
+
+func TestLoadMask(t *testing.T) {
+	spec, err := Load(strings.NewReader(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, _ := spec.Operation("GET", "/users/42")
+	opts, err := op.Response(200).Options("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := map[string]interface{}{
+		"name":    "Alice",
+		"email":   "alice@example.com",
+		"manager": map[string]interface{}{"name": "Bob", "manager": map[string]interface{}{"email": "carol@example.com"}},
+	}
+	masked, err := mask.Mask(user, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if masked["email"] != "a***************m" || masked["name"] != "Alice" {
+		t.Errorf("expect the email to be masked, got %v", masked)
+	}
+	nested := masked["manager"].(map[string]interface{})["manager"].(map[string]interface{})
+	if nested["email"] != "c***************m" {
+		t.Errorf("expect recursive values to be masked, got %v", nested)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(strings.NewReader("openapi: 3.0.3\n")); !errors.Is(err, ErrInvalidSpec) {
+		t.Errorf("expect %v == %v", err, ErrInvalidSpec)
+	}
+	invalid := strings.Replace(testSpec, "partial=1:1", "nope", 1)
+	if _, err := Load(strings.NewReader(invalid)); !errors.Is(err, mask.ErrInvalidPolicy) {
+		t.Errorf("expect %v == %v", err, mask.ErrInvalidPolicy)
+	}
+}
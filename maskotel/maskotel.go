@@ -0,0 +1,60 @@
+// Package maskotel exports the metrics and traces of masking using OpenTelemetry:
+//
+//	sink, err := maskotel.NewSink(otel.Meter("github.com/doejon/go-mask"))
+//	masked, err := mask.Mask(v, mask.WithMetrics(sink))
+//
+// It records the counter mask.calls with the attributes op and result (ok or error),
+// the histogram mask.call.duration in seconds with the attribute op and the counter
+// mask.values.masked with the attributes op and strategy. NewTracer traces calls, see Tracer.
+package maskotel
+
+import (
+	"context"
+
+	mask "github.com/doejon/go-mask"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Sink is a mask.MetricsSink recording OpenTelemetry metrics.
+type Sink struct {
+	calls    metric.Int64Counter
+	duration metric.Float64Histogram
+	masked   metric.Int64Counter
+}
+
+// NewSink creates the instruments of a Sink using meter.
+func NewSink(meter metric.Meter) (*Sink, error) {
+	s := &Sink{}
+	var err error
+	if s.calls, err = meter.Int64Counter("mask.calls",
+		metric.WithDescription("Number of values masked or encoded, by operation and result.")); err != nil {
+		return nil, err
+	}
+	if s.duration, err = meter.Float64Histogram("mask.call.duration", metric.WithUnit("s"),
+		metric.WithDescription("Duration of masking or encoding a value, by operation.")); err != nil {
+		return nil, err
+	}
+	if s.masked, err = meter.Int64Counter("mask.values.masked",
+		metric.WithDescription("Number of values masked, by operation and strategy.")); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ObserveCall implements mask.MetricsSink.
+func (s *Sink) ObserveCall(m mask.CallMetrics) {
+	ctx := context.Background()
+	result := "ok"
+	if m.Err != nil {
+		result = "error"
+	}
+	op := attribute.String("op", m.Op)
+	s.calls.Add(ctx, 1, metric.WithAttributes(op, attribute.String("result", result)))
+	s.duration.Record(ctx, m.Duration.Seconds(), metric.WithAttributes(op))
+	for strategy, n := range m.Masked {
+		s.masked.Add(ctx, int64(n), metric.WithAttributes(op, attribute.String("strategy", strategy)))
+	}
+}
+
+var _ mask.MetricsSink = (*Sink)(nil)
@@ -0,0 +1,70 @@
+package maskotel
+
+import (
+	"context"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type customer struct {
+	Email string `mask:"redact"`
+	Name  string `mask:"redact"`
+}
+
+func TestSink(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	sink, err := NewSink(provider.Meter("test"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := mask.Mask(customer{"ada@example.com", "Ada"}, mask.WithMetrics(sink)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := mask.Mask(make(chan int), mask.WithMetrics(sink)); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	sums := map[string]int64{}
+	var durations uint64
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			for _, dp := range data.DataPoints {
+				sums[m.Name+attrs(dp.Attributes)] += dp.Value
+			}
+		case metricdata.Histogram[float64]:
+			for _, dp := range data.DataPoints {
+				durations += dp.Count
+			}
+		}
+	}
+	for name, expect := range map[string]int64{
+		"mask.calls op=mask result=ok":               1,
+		"mask.calls op=mask result=error":            1,
+		"mask.values.masked op=mask strategy=redact": 2,
+	} {
+		if sums[name] != expect {
+			t.Errorf("expect %v == %v in %v", name, expect, sums)
+		}
+	}
+	if durations != 2 {
+		t.Errorf("expect 2 durations, got %v", durations)
+	}
+}
+
+func attrs(set attribute.Set) string {
+	var s string
+	for _, kv := range set.ToSlice() {
+		s += " " + string(kv.Key) + "=" + kv.Value.AsString()
+	}
+	return s
+}
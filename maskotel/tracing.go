@@ -0,0 +1,56 @@
+package maskotel
+
+import (
+	"context"
+	"reflect"
+
+	mask "github.com/doejon/go-mask"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer is a mask.Tracer starting OpenTelemetry spans as children of the span of a context:
+//
+//	masked, err := mask.Mask(v, mask.WithTracer(maskotel.NewTracer(ctx, tracer)))
+//
+// Spans are named mask.Mask or mask.JSON and carry the attributes mask.type and mask.nodes,
+// the number of values visited. Errors are recorded on the span.
+type Tracer struct {
+	ctx    context.Context
+	tracer trace.Tracer
+}
+
+// NewTracer creates a Tracer starting spans using tracer as children of the span of ctx.
+func NewTracer(ctx context.Context, tracer trace.Tracer) *Tracer {
+	return &Tracer{ctx: ctx, tracer: tracer}
+}
+
+// Start implements mask.Tracer.
+func (t *Tracer) Start(op string, typ reflect.Type) mask.Span {
+	name := "mask.Mask"
+	if op == "json" {
+		name = "mask.JSON"
+	}
+	typeName := "<nil>"
+	if typ != nil {
+		typeName = typ.String()
+	}
+	_, span := t.tracer.Start(t.ctx, name, trace.WithAttributes(attribute.String("mask.type", typeName)))
+	return spanEnder{span}
+}
+
+type spanEnder struct {
+	span trace.Span
+}
+
+func (s spanEnder) End(nodes int, err error) {
+	s.span.SetAttributes(attribute.Int("mask.nodes", nodes))
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+var _ mask.Tracer = (*Tracer)(nil)
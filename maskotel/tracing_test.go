@@ -0,0 +1,47 @@
+package maskotel
+
+import (
+	"context"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+	ctx, parent := tracer.Start(context.Background(), "request")
+
+	opt := mask.WithTracer(NewTracer(ctx, tracer))
+	if _, err := mask.Mask(customer{"ada@example.com", "Ada"}, opt); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := mask.Mask(make(chan int), opt); err == nil {
+		t.Fatalf("expected an error")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expect 3 spans, got %v", len(spans))
+	}
+	ok, failed := spans[0], spans[1]
+	if ok.Name() != "mask.Mask" || ok.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expect %v to be a mask.Mask child of the request span", ok.Name())
+	}
+	attrs := attribute.NewSet(ok.Attributes()...)
+	if v, _ := attrs.Value("mask.type"); v.AsString() != "maskotel.customer" {
+		t.Errorf("expect %v == maskotel.customer", v.AsString())
+	}
+	if v, _ := attrs.Value("mask.nodes"); v.AsInt64() != 3 {
+		t.Errorf("expect %v == 3", v.AsInt64())
+	}
+	if failed.Status().Code != codes.Error || len(failed.Events()) != 1 {
+		t.Errorf("expect %v to record the error", failed.Status())
+	}
+}
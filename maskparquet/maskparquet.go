@@ -0,0 +1,115 @@
+// Package maskparquet rewrites Parquet files masking selected columns,
+// e.g. to produce privacy-safe copies of data lake tables.
+package maskparquet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/parquet-go/parquet-go"
+)
+
+var (
+	// ErrUnknownColumn is returned if a column rule names a column missing from the schema.
+	ErrUnknownColumn = errors.New("unknown column")
+	// ErrUnsupportedColumn is returned if a column rule names a column which does not hold
+	// strings or bytes, or if a strategy changes the length of a fixed length column.
+	ErrUnsupportedColumn = errors.New("unsupported column")
+)
+
+// batchSize is the number of rows read and written at once.
+const batchSize = 1024
+
+// Mask copies the Parquet file of the given size read from r to w, masking the
+// values of the columns named in columnRules using their strategy. Nested columns
+// are named by their path separated by dots, e.g. "user.email".
+// Only string and byte array columns can be masked; strategies receive strings.
+// The schema and key/value metadata of the file are kept.
+func Mask(r io.ReaderAt, size int64, w io.Writer, columnRules map[string]mask.Strategy) error {
+	f, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return err
+	}
+	schema := f.Schema()
+
+	columns := make(map[int]mask.Strategy, len(columnRules))
+	for name, strategy := range columnRules {
+		leaf, ok := schema.Lookup(strings.Split(name, ".")...)
+		if !ok {
+			return fmt.Errorf("%w %q", ErrUnknownColumn, name)
+		}
+		switch leaf.Node.Type().Kind() {
+		case parquet.ByteArray, parquet.FixedLenByteArray:
+		default:
+			return fmt.Errorf("%w %q: %v", ErrUnsupportedColumn, name, leaf.Node.Type())
+		}
+		columns[leaf.ColumnIndex] = strategy
+	}
+
+	opts := []parquet.WriterOption{schema}
+	for _, kv := range f.Metadata().KeyValueMetadata {
+		opts = append(opts, parquet.KeyValueMetadata(kv.Key, kv.Value))
+	}
+	pw := parquet.NewWriter(w, opts...)
+	pr := parquet.NewReader(f)
+	defer pr.Close()
+
+	rows := make([]parquet.Row, batchSize)
+	for {
+		n, err := pr.ReadRows(rows)
+		for _, row := range rows[:n] {
+			if err := maskRow(row, columns, schema.Columns()); err != nil {
+				return err
+			}
+		}
+		if _, werr := pw.WriteRows(rows[:n]); werr != nil {
+			return werr
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	return pw.Close()
+}
+
+func maskRow(row parquet.Row, columns map[int]mask.Strategy, names [][]string) error {
+	for i, v := range row {
+		strategy, ok := columns[v.Column()]
+		if !ok || v.IsNull() {
+			continue
+		}
+		masked, err := maskValue(strategy, string(v.ByteArray()))
+		if err != nil {
+			return fmt.Errorf("column %q: %w", strings.Join(names[v.Column()], "."), err)
+		}
+		mv := parquet.ByteArrayValue([]byte(masked))
+		if v.Kind() == parquet.FixedLenByteArray {
+			if len(masked) != len(v.ByteArray()) {
+				return fmt.Errorf("%w %q: masked value has length %d, expected %d",
+					ErrUnsupportedColumn, strings.Join(names[v.Column()], "."), len(masked), len(v.ByteArray()))
+			}
+			mv = parquet.FixedLenByteArrayValue([]byte(masked))
+		}
+		row[i] = mv.Level(v.RepetitionLevel(), v.DefinitionLevel(), v.Column())
+	}
+	return nil
+}
+
+// maskValue masks s using strategy. Strategies returning other kinds than
+// strings, e.g. a placeholder, are formatted.
+func maskValue(strategy mask.Strategy, s string) (string, error) {
+	masked, err := strategy.Mask(reflect.ValueOf(s))
+	if err != nil {
+		return "", err
+	}
+	if masked.Kind() == reflect.String {
+		return masked.String(), nil
+	}
+	return fmt.Sprint(masked.Interface()), nil
+}
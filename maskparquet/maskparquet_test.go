@@ -0,0 +1,94 @@
+package maskparquet
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskers"
+	"github.com/parquet-go/parquet-go"
+)
+
+type testAddress struct {
+	City   string `parquet:"city"`
+	Street string `parquet:"street"`
+}
+
+type testRow struct {
+	ID      int64       `parquet:"id"`
+	Email   string      `parquet:"email"`
+	Phone   *string     `parquet:"phone,optional"`
+	Tags    []string    `parquet:"tags,list"`
+	Address testAddress `parquet:"address"`
+}
+
+func writeTestFile(t *testing.T, rows []testRow) *bytes.Reader {
+	var buf bytes.Buffer
+	w := parquet.NewGenericWriter[testRow](&buf, parquet.KeyValueMetadata("owner", "crm"))
+	if _, err := w.Write(rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestMask(t *testing.T) {
+	phone := "5551234"
+	in := writeTestFile(t, []testRow{
+		{ID: 1, Email: "alice@example.com", Phone: &phone, Tags: []string{"vip", "eu"}, Address: testAddress{City: "Berlin", Street: "Main St 1"}},
+		{ID: 2, Email: "bob@example.com", Address: testAddress{City: "Paris", Street: "Rue 2"}},
+	})
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial := maskers.Partial(0, 2, maskers.Format{})
+
+	var out bytes.Buffer
+	err = Mask(in, in.Size(), &out, map[string]mask.Strategy{
+		"email":             redact,
+		"phone":             partial,
+		"tags.list.element": partial,
+		"address.street":    redact,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner, _ := f.Lookup("owner"); owner != "crm" {
+		t.Errorf("expect %v == %v", owner, "crm")
+	}
+	got := make([]testRow, 2)
+	r := parquet.NewGenericReader[testRow](f)
+	if n, _ := r.Read(got); n != 2 {
+		t.Fatalf("expect %v == %v", n, 2)
+	}
+	maskedPhone := "*****34"
+	expect := []testRow{
+		{ID: 1, Email: mask.DefaultPlaceholder, Phone: &maskedPhone, Tags: []string{"*ip", "**"}, Address: testAddress{City: "Berlin", Street: mask.DefaultPlaceholder}},
+		{ID: 2, Email: mask.DefaultPlaceholder, Tags: []string{}, Address: testAddress{City: "Paris", Street: mask.DefaultPlaceholder}},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect %+v == %+v", got, expect)
+	}
+}
+
+func TestMaskErrors(t *testing.T) {
+	in := writeTestFile(t, []testRow{{ID: 1}})
+	redact, _ := mask.ParseStrategy("redact")
+	var out bytes.Buffer
+	if err := Mask(in, in.Size(), &out, map[string]mask.Strategy{"mail": redact}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expect %v == %v", err, ErrUnknownColumn)
+	}
+	if err := Mask(in, in.Size(), &out, map[string]mask.Strategy{"id": redact}); !errors.Is(err, ErrUnsupportedColumn) {
+		t.Errorf("expect %v == %v", err, ErrUnsupportedColumn)
+	}
+}
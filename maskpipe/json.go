@@ -0,0 +1,44 @@
+package maskpipe
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/doejon/go-mask/internal/jsonnumber"
+)
+
+type jsonDecoder struct {
+	dec *json.Decoder
+}
+
+// NewJSONDecoder reads JSON values from r, e.g. the lines of NDJSON files.
+// Numbers are decoded as json.Number, so they are written unchanged.
+func NewJSONDecoder(r io.Reader) Decoder {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return jsonDecoder{dec: dec}
+}
+
+func (d jsonDecoder) Decode() (interface{}, error) {
+	var v interface{}
+	if err := d.dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type jsonEncoder struct {
+	enc *json.Encoder
+}
+
+// NewJSONEncoder writes records to w as NDJSON, one JSON value per line.
+// Numbers masked by strategies yielding no number, e.g. redact, are written as strings.
+func NewJSONEncoder(w io.Writer) Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return jsonEncoder{enc: enc}
+}
+
+func (e jsonEncoder) Encode(v interface{}) error {
+	return e.enc.Encode(jsonnumber.Masked(v))
+}
@@ -0,0 +1,251 @@
+// Package maskpipe masks streams of records, the building block of jobs copying
+// production data to staging anonymized:
+//
+//	policy, err := mask.LoadPolicy(f)
+//	opts, err := policy.Options("staging")
+//	p := maskpipe.New(maskpipe.WithMaskOptions(opts...), maskpipe.WithProgress(10000, report))
+//	err = p.Copy(ctx, maskpipe.NewJSONDecoder(dump), maskpipe.NewJSONEncoder(out))
+//
+// Records are masked concurrently and written in the order they were read. All records
+// of a pipeline are masked within a single mask.Session, so equal values are replaced
+// by equal surrogates and join keys between records are preserved. Reading is throttled
+// to the pace of writing: at most the buffer size of records are masked ahead.
+package maskpipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	mask "github.com/doejon/go-mask"
+)
+
+// Pipeline masks records consistently, see New.
+type Pipeline struct {
+	opts     []mask.Option
+	session  *mask.Session
+	workers  int
+	buffer   int
+	every    int64
+	progress func(Progress)
+}
+
+// Progress reports the records processed by a pipeline so far.
+type Progress struct {
+	// Read is the number of records read, Written the number of masked records written.
+	// Records read but not written yet are being masked.
+	Read, Written int64
+	Elapsed       time.Duration
+}
+
+// Option configures a Pipeline.
+type Option func(*Pipeline)
+
+// WithMaskOptions masks records using opts, e.g. the options of a policy.
+func WithMaskOptions(opts ...mask.Option) Option {
+	return func(p *Pipeline) {
+		p.opts = append(p.opts, opts...)
+	}
+}
+
+// WithSession masks records within session rather than a session of their own,
+// e.g. to keep join keys consistent across the pipelines copying related tables.
+func WithSession(session *mask.Session) Option {
+	return func(p *Pipeline) {
+		p.session = session
+	}
+}
+
+// WithWorkers masks up to n records concurrently; it defaults to GOMAXPROCS.
+func WithWorkers(n int) Option {
+	return func(p *Pipeline) {
+		if n > 0 {
+			p.workers = n
+		}
+	}
+}
+
+// WithBuffer reads at most n records ahead of the records written; it defaults to 64.
+func WithBuffer(n int) Option {
+	return func(p *Pipeline) {
+		if n > 0 {
+			p.buffer = n
+		}
+	}
+}
+
+// WithProgress calls report after every n records written and once all records are written.
+func WithProgress(n int, report func(Progress)) Option {
+	return func(p *Pipeline) {
+		if n > 0 {
+			p.every = int64(n)
+			p.progress = report
+		}
+	}
+}
+
+// New returns a pipeline configured by opts.
+func New(opts ...Option) *Pipeline {
+	p := &Pipeline{workers: runtime.GOMAXPROCS(0), buffer: 64}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.session == nil {
+		p.session = mask.NewSession()
+	}
+	return p
+}
+
+// Session returns the session records are masked within.
+func (p *Pipeline) Session() *mask.Session {
+	return p.session
+}
+
+// Stream masks the records received from in and sends them to out in the same order,
+// until in is closed, masking a record fails or ctx is done. out is not closed.
+func Stream[T any](ctx context.Context, p *Pipeline, in <-chan T, out chan<- T) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	opts := append(p.opts[:len(p.opts):len(p.opts)], mask.WithSession(p.session))
+
+	type result struct {
+		v   T
+		err error
+	}
+	// queue holds the results of the records read in order; its capacity throttles reading
+	queue := make(chan chan result, p.buffer)
+	workers := make(chan struct{}, p.workers)
+	var read int64
+	go func() {
+		defer close(queue)
+		for {
+			var v T
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok = <-in:
+				if !ok {
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case workers <- struct{}{}:
+			}
+			c := make(chan result, 1)
+			go func() {
+				defer func() { <-workers }()
+				masked, err := mask.Mask(v, opts...)
+				c <- result{masked, err}
+			}()
+			atomic.AddInt64(&read, 1)
+			select {
+			case <-ctx.Done():
+				return
+			case queue <- c:
+			}
+		}
+	}()
+
+	start := time.Now()
+	var written int64
+	report := func() {
+		p.progress(Progress{Read: atomic.LoadInt64(&read), Written: written, Elapsed: time.Since(start)})
+	}
+	for c := range queue {
+		var r result
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r = <-c:
+		}
+		if r.err != nil {
+			return fmt.Errorf("record %d: %w", written+1, r.err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- r.v:
+		}
+		written++
+		if p.progress != nil && written%p.every == 0 {
+			report()
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.progress != nil && written%p.every != 0 {
+		report()
+	}
+	return nil
+}
+
+// Decoder reads records, e.g. from a dump; Decode returns io.EOF after the last record.
+type Decoder interface {
+	Decode() (interface{}, error)
+}
+
+// Encoder writes records.
+type Encoder interface {
+	Encode(v interface{}) error
+}
+
+// Copy masks the records read from dec and writes them to enc, see Stream.
+func (p *Pipeline) Copy(ctx context.Context, dec Decoder, enc Encoder) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	in := make(chan interface{})
+	decoded := make(chan error, 1)
+	go func() {
+		defer close(in)
+		for {
+			v, err := dec.Decode()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = nil
+				}
+				decoded <- err
+				return
+			}
+			select {
+			case <-ctx.Done():
+				decoded <- nil
+				return
+			case in <- v:
+			}
+		}
+	}()
+
+	out := make(chan interface{})
+	streamed := make(chan error, 1)
+	go func() {
+		streamed <- Stream(ctx, p, in, out)
+		close(out)
+	}()
+
+	var err error
+	for v := range out {
+		if err != nil {
+			continue
+		}
+		if err = enc.Encode(v); err != nil {
+			// stops streaming, which closes out
+			cancel()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := <-streamed; err != nil {
+		return err
+	}
+	return <-decoded
+}
@@ -0,0 +1,148 @@
+package maskpipe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskers"
+)
+
+type testUser struct {
+	ID    int
+	Email string `mask:"sequence=user-%d"`
+}
+
+func TestStream(t *testing.T) {
+	var reports []Progress
+	p := New(WithWorkers(4), WithBuffer(8), WithProgress(40, func(pr Progress) {
+		reports = append(reports, pr)
+	}))
+	in := make(chan testUser)
+	out := make(chan testUser)
+	go func() {
+		defer close(in)
+		for i := 0; i < 100; i++ {
+			in <- testUser{ID: i, Email: fmt.Sprintf("user%d@example.com", i%10)}
+		}
+	}()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Stream(context.Background(), p, in, out)
+		close(out)
+	}()
+	surrogates := map[int]string{}
+	i := 0
+	for u := range out {
+		if u.ID != i {
+			t.Fatalf("expect %d == %d", u.ID, i)
+		}
+		// equal values are masked alike throughout the session
+		if s, ok := surrogates[i%10]; ok && s != u.Email || !strings.HasPrefix(u.Email, "user-") {
+			t.Errorf("expect %v == %v", u.Email, s)
+		}
+		surrogates[i%10] = u.Email
+		i++
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if i != 100 || p.Session().Len() != 10 {
+		t.Errorf("expect %d == 100 records masked using %d == 10 surrogates", i, p.Session().Len())
+	}
+	if len(reports) != 3 || reports[0].Written != 40 || reports[2].Written != 100 || reports[2].Read != 100 {
+		t.Errorf("expect progress after 40, 80 and 100 records, got %+v", reports)
+	}
+}
+
+func TestStreamBackpressure(t *testing.T) {
+	p := New(WithWorkers(2), WithBuffer(4))
+	in := make(chan int, 100)
+	for i := 0; i < 100; i++ {
+		in <- i
+	}
+	close(in)
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Stream(ctx, p, in, make(chan int))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	// nobody receives the masked records: the queue, the workers and the record
+	// waiting to be sent hold all records read
+	if read := 100 - len(in); read > 4+2+2 {
+		t.Errorf("expect %d records read to be limited", read)
+	}
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v to be %v", err, context.Canceled)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	src := `{"id":1,"email":"ada@example.com","score":1.50}
+{"id":2,"email":"bob@example.com","tags":["a"]}
+{"id":3,"email":"ada@example.com"}
+`
+	var dst bytes.Buffer
+	p := New(WithMaskOptions(mask.WithPathStrategy("email", maskers.Sequence("user-%d"))))
+	if err := p.Copy(context.Background(), NewJSONDecoder(strings.NewReader(src)), NewJSONEncoder(&dst)); err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"email":"user-1","id":1,"score":1.50}
+{"email":"user-2","id":2,"tags":["a"]}
+{"email":"user-1","id":3}
+`
+	if dst.String() != expect {
+		t.Errorf("expect %s == %s", dst.String(), expect)
+	}
+
+	dst.Reset()
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = New(WithMaskOptions(mask.WithPathStrategy("score", redact))).Copy(context.Background(), NewJSONDecoder(strings.NewReader(src)), NewJSONEncoder(&dst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = `{"email":"ada@example.com","id":1,"score":"MASKED"}
+{"email":"bob@example.com","id":2,"tags":["a"]}
+{"email":"ada@example.com","id":3}
+`
+	if dst.String() != expect {
+		t.Errorf("expect %s == %s", dst.String(), expect)
+	}
+
+	err = p.Copy(context.Background(), NewJSONDecoder(strings.NewReader(`{"id":1} {`)), NewJSONEncoder(&dst))
+	if err == nil {
+		t.Error("expect invalid JSON to fail")
+	}
+
+	p = New(WithMaskOptions(mask.WithPathStrategy("email", maskers.Func("fail", func(v reflect.Value) (reflect.Value, error) {
+		return reflect.Value{}, errTest
+	}))))
+	err = p.Copy(context.Background(), NewJSONDecoder(strings.NewReader(src)), NewJSONEncoder(&dst))
+	if !errors.Is(err, errTest) || !strings.HasPrefix(err.Error(), "record 1:") {
+		t.Errorf("expect %v to be %v", err, errTest)
+	}
+
+	err = New().Copy(context.Background(), NewJSONDecoder(strings.NewReader(src)), failingEncoder{})
+	if !errors.Is(err, errTest) {
+		t.Errorf("expect %v to be %v", err, errTest)
+	}
+}
+
+var errTest = errors.New("test")
+
+type failingEncoder struct{}
+
+func (failingEncoder) Encode(interface{}) error {
+	return errTest
+}
@@ -0,0 +1,61 @@
+// Package maskprom exports the metrics of masking to Prometheus:
+//
+//	sink, err := maskprom.NewSink(prometheus.DefaultRegisterer)
+//	masked, err := mask.Mask(v, mask.WithMetrics(sink))
+//
+// It registers the counter mask_calls_total labeled by op and result (ok or error),
+// the histogram mask_call_duration_seconds labeled by op and the counter
+// mask_values_masked_total labeled by op and strategy.
+package maskprom
+
+import (
+	mask "github.com/doejon/go-mask"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Sink is a mask.MetricsSink updating Prometheus metrics.
+type Sink struct {
+	calls    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	masked   *prometheus.CounterVec
+}
+
+// NewSink creates the metrics of a Sink and registers them with reg.
+func NewSink(reg prometheus.Registerer) (*Sink, error) {
+	s := &Sink{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mask_calls_total",
+			Help: "Number of values masked or encoded, by operation and result.",
+		}, []string{"op", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mask_call_duration_seconds",
+			Help:    "Duration of masking or encoding a value, by operation.",
+			Buckets: prometheus.ExponentialBuckets(1e-6, 4, 10),
+		}, []string{"op"}),
+		masked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mask_values_masked_total",
+			Help: "Number of values masked, by operation and strategy.",
+		}, []string{"op", "strategy"}),
+	}
+	for _, c := range []prometheus.Collector{s.calls, s.duration, s.masked} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// ObserveCall implements mask.MetricsSink.
+func (s *Sink) ObserveCall(m mask.CallMetrics) {
+	result := "ok"
+	if m.Err != nil {
+		result = "error"
+	}
+	s.calls.WithLabelValues(m.Op, result).Inc()
+	s.duration.WithLabelValues(m.Op).Observe(m.Duration.Seconds())
+	for strategy, n := range m.Masked {
+		s.masked.WithLabelValues(m.Op, strategy).Add(float64(n))
+	}
+}
+
+var _ mask.MetricsSink = (*Sink)(nil)
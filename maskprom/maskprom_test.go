@@ -0,0 +1,53 @@
+package maskprom
+
+import (
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type customer struct {
+	Email string `mask:"redact"`
+	Name  string `mask:"redact"`
+}
+
+func TestSink(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	sink, err := NewSink(reg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := mask.Mask(customer{"ada@example.com", "Ada"}, mask.WithMetrics(sink)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := mask.JSON(customer{}, mask.WithMetrics(sink)).MarshalJSON(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := mask.Mask(make(chan int), mask.WithMetrics(sink)); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	for _, tc := range []struct {
+		c      prometheus.Collector
+		expect float64
+	}{
+		{sink.calls.WithLabelValues("mask", "ok"), 1},
+		{sink.calls.WithLabelValues("mask", "error"), 1},
+		{sink.calls.WithLabelValues("json", "ok"), 1},
+		{sink.masked.WithLabelValues("mask", "redact"), 2},
+		{sink.masked.WithLabelValues("json", "redact"), 2},
+	} {
+		if got := testutil.ToFloat64(tc.c); got != tc.expect {
+			t.Errorf("expect %v == %v", got, tc.expect)
+		}
+	}
+	if n := testutil.CollectAndCount(sink.duration); n != 2 {
+		t.Errorf("expect durations of 2 ops, got %v", n)
+	}
+
+	if _, err := NewSink(reg); err == nil {
+		t.Errorf("expected an error registering the metrics twice")
+	}
+}
@@ -0,0 +1,186 @@
+// Package maskterraform masks the sensitive values of Terraform state and plan files,
+// e.g. before attaching a plan to a ticket:
+//
+//	terraform show -json plan.out > plan.json
+//	err := maskterraform.Mask(planJSON, w)
+//
+// Values are sensitive if Terraform marks them as such: attributes listed in the
+// sensitive_attributes of state resources, values flagged by the sensitive_values,
+// before_sensitive and after_sensitive of plans, outputs with "sensitive": true and
+// variables declared sensitive. They are replaced by the placeholder for strings.
+package maskterraform
+
+import (
+	"encoding/json"
+	"io"
+
+	mask "github.com/doejon/go-mask"
+)
+
+// Mask copies the Terraform state or plan JSON read from r to w, masking its sensitive
+// values. It handles state files (format version 4) as well as the output of terraform show -json
+// for states and plans. The document is written indented by two spaces with object keys sorted.
+func Mask(r io.Reader, w io.Writer) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+	maskDocument(doc)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func maskDocument(doc map[string]interface{}) {
+	// state files
+	maskOutputs(object(doc["outputs"]))
+	for _, r := range array(doc["resources"]) {
+		for _, instance := range array(object(r)["instances"]) {
+			maskStateInstance(object(instance))
+		}
+	}
+
+	// terraform show -json
+	for _, values := range []map[string]interface{}{
+		object(doc["values"]),
+		object(doc["planned_values"]),
+		object(object(doc["prior_state"])["values"]),
+	} {
+		maskOutputs(object(values["outputs"]))
+		maskModule(object(values["root_module"]))
+	}
+	for _, key := range []string{"resource_changes", "resource_drift"} {
+		for _, rc := range array(doc[key]) {
+			maskChange(object(object(rc)["change"]))
+		}
+	}
+	for _, oc := range object(doc["output_changes"]) {
+		maskChange(object(oc))
+	}
+	variables := object(doc["variables"])
+	for name, v := range object(object(object(doc["configuration"])["root_module"])["variables"]) {
+		if sensitive, _ := object(v)["sensitive"].(bool); sensitive {
+			if variable := object(variables[name]); variable != nil {
+				variable["value"] = placeholder()
+			}
+		}
+	}
+}
+
+// maskStateInstance masks the attributes of a resource instance of a state file
+// located by its sensitive_attributes: paths of steps like {"type": "get_attr", "value": "password"}.
+func maskStateInstance(instance map[string]interface{}) {
+	for _, path := range array(instance["sensitive_attributes"]) {
+		steps := array(path)
+		v := instance["attributes"]
+		for i, step := range steps {
+			key := object(step)["value"]
+			if index, ok := object(key)["value"]; ok {
+				// index steps hold typed values like {"value": 0, "type": "number"}
+				key = index
+			}
+			var ok bool
+			if v, ok = descend(v, key, i == len(steps)-1); !ok {
+				break
+			}
+		}
+	}
+}
+
+// descend returns the element key of the object or array v, replacing it by the placeholder if last is set.
+func descend(v, key interface{}, last bool) (interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		k, _ := key.(string)
+		child, ok := v[k]
+		if ok && last {
+			v[k] = placeholder()
+		}
+		return child, ok
+	case []interface{}:
+		n, _ := key.(json.Number)
+		i, err := n.Int64()
+		if err != nil || i < 0 || int(i) >= len(v) {
+			return nil, false
+		}
+		child := v[i]
+		if last {
+			v[i] = placeholder()
+		}
+		return child, true
+	}
+	return nil, false
+}
+
+// maskModule masks the resources of a module of terraform show -json and its child modules.
+func maskModule(module map[string]interface{}) {
+	for _, r := range array(module["resources"]) {
+		resource := object(r)
+		resource["values"] = maskMarked(resource["values"], resource["sensitive_values"])
+	}
+	for _, child := range array(module["child_modules"]) {
+		maskModule(object(child))
+	}
+}
+
+// maskChange masks the values before and after a change of a plan.
+func maskChange(change map[string]interface{}) {
+	if change == nil {
+		return
+	}
+	change["before"] = maskMarked(change["before"], change["before_sensitive"])
+	change["after"] = maskMarked(change["after"], change["after_sensitive"])
+}
+
+// maskOutputs masks the values of outputs marked sensitive.
+func maskOutputs(outputs map[string]interface{}) {
+	for _, o := range outputs {
+		output := object(o)
+		if sensitive, _ := output["sensitive"].(bool); sensitive {
+			output["value"] = placeholder()
+		}
+	}
+}
+
+// maskMarked masks the parts of v marked true in marks, which mirrors the structure of v.
+func maskMarked(v, marks interface{}) interface{} {
+	switch m := marks.(type) {
+	case bool:
+		if m && v != nil {
+			return placeholder()
+		}
+	case map[string]interface{}:
+		if obj, ok := v.(map[string]interface{}); ok {
+			for k, mark := range m {
+				if value, ok := obj[k]; ok {
+					obj[k] = maskMarked(value, mark)
+				}
+			}
+		}
+	case []interface{}:
+		if arr, ok := v.([]interface{}); ok {
+			for i, mark := range m {
+				if i < len(arr) {
+					arr[i] = maskMarked(arr[i], mark)
+				}
+			}
+		}
+	}
+	return v
+}
+
+func object(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func array(v interface{}) []interface{} {
+	a, _ := v.([]interface{})
+	return a
+}
+
+func placeholder() string {
+	return mask.Placeholder[string]()
+}
@@ -0,0 +1,123 @@
+package maskterraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func maskJSON(t *testing.T, in string) interface{} {
+	t.Helper()
+	var out bytes.Buffer
+	if err := Mask(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var doc interface{}
+	if err := json.Unmarshal([]byte(s), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestMaskState(t *testing.T) {
+	state := `{
+  "version": 4,
+  "outputs": {
+    "db_password": {"value": "hunter2", "type": "string", "sensitive": true},
+    "db_host": {"value": "db.internal", "type": "string"}
+  },
+  "resources": [{
+    "type": "aws_db_instance",
+    "instances": [{
+      "attributes": {"username": "admin", "password": "hunter2", "tags": ["a", "b"], "port": 5432},
+      "sensitive_attributes": [
+        [{"type": "get_attr", "value": "password"}],
+        [{"type": "get_attr", "value": "tags"}, {"type": "index", "value": {"value": 1, "type": "number"}}],
+        [{"type": "get_attr", "value": "missing"}]
+      ]
+    }]
+  }]
+}`
+	expect := decode(t, `{
+  "version": 4,
+  "outputs": {
+    "db_password": {"value": "MASKED", "type": "string", "sensitive": true},
+    "db_host": {"value": "db.internal", "type": "string"}
+  },
+  "resources": [{
+    "type": "aws_db_instance",
+    "instances": [{
+      "attributes": {"username": "admin", "password": "MASKED", "tags": ["a", "MASKED"], "port": 5432},
+      "sensitive_attributes": [
+        [{"type": "get_attr", "value": "password"}],
+        [{"type": "get_attr", "value": "tags"}, {"type": "index", "value": {"value": 1, "type": "number"}}],
+        [{"type": "get_attr", "value": "missing"}]
+      ]
+    }]
+  }]
+}`)
+	if out := maskJSON(t, state); !reflect.DeepEqual(out, expect) {
+		t.Errorf("expect %v == %v", out, expect)
+	}
+}
+
+func TestMaskPlan(t *testing.T) {
+	plan := `{
+  "variables": {"db_password": {"value": "hunter2"}, "region": {"value": "eu-west-1"}},
+  "configuration": {"root_module": {"variables": {"db_password": {"sensitive": true}, "region": {}}}},
+  "planned_values": {
+    "outputs": {"token": {"sensitive": true, "value": "t0k3n"}},
+    "root_module": {
+      "resources": [{"values": {"password": "hunter2", "name": "db"}, "sensitive_values": {"password": true}}],
+      "child_modules": [{"resources": [{"values": {"settings": {"key": "k", "size": 1}}, "sensitive_values": {"settings": {"key": true}}}]}]
+    }
+  },
+  "resource_changes": [{
+    "change": {
+      "before": {"password": "old", "name": "db"},
+      "after": {"password": "new", "name": "db"},
+      "before_sensitive": {"password": true},
+      "after_sensitive": {"password": true}
+    }
+  }],
+  "output_changes": {"token": {"before": null, "after": "t0k3n", "before_sensitive": false, "after_sensitive": true}}
+}`
+	expect := decode(t, `{
+  "variables": {"db_password": {"value": "MASKED"}, "region": {"value": "eu-west-1"}},
+  "configuration": {"root_module": {"variables": {"db_password": {"sensitive": true}, "region": {}}}},
+  "planned_values": {
+    "outputs": {"token": {"sensitive": true, "value": "MASKED"}},
+    "root_module": {
+      "resources": [{"values": {"password": "MASKED", "name": "db"}, "sensitive_values": {"password": true}}],
+      "child_modules": [{"resources": [{"values": {"settings": {"key": "MASKED", "size": 1}}, "sensitive_values": {"settings": {"key": true}}}]}]
+    }
+  },
+  "resource_changes": [{
+    "change": {
+      "before": {"password": "MASKED", "name": "db"},
+      "after": {"password": "MASKED", "name": "db"},
+      "before_sensitive": {"password": true},
+      "after_sensitive": {"password": true}
+    }
+  }],
+  "output_changes": {"token": {"before": null, "after": "MASKED", "before_sensitive": false, "after_sensitive": true}}
+}`)
+	if out := maskJSON(t, plan); !reflect.DeepEqual(out, expect) {
+		t.Errorf("expect %v == %v", out, expect)
+	}
+
+	if err := Mask(strings.NewReader("{"), &bytes.Buffer{}); err == nil {
+		t.Errorf("expected an error")
+	}
+}
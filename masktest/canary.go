@@ -0,0 +1,142 @@
+package masktest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+)
+
+// sensitiveNames are parts of field names considered sensitive by AssertCanaries,
+// compared ignoring case, underscores and dashes.
+var sensitiveNames = []string{
+	"password", "passwd", "secret", "token", "apikey", "privatekey", "credential",
+	"ssn", "creditcard", "cardnumber", "cvv", "iban", "email", "phone",
+}
+
+// canary is a sentinel string filled into a field by AssertCanaries.
+type canary struct {
+	path      string
+	sensitive bool
+}
+
+// AssertCanaries fills a value of type T with canaries, masks it using opts and
+// fails t for every canary surviving in a sensitive field. Every string is filled
+// with a canary of its own; pointers, slices and maps with string keys are filled
+// with a single element. Fields are sensitive if they are tagged, if their name
+// looks sensitive, e.g. Password, APIKey or Email, or if they are part of a sensitive field.
+//
+// This catches fields a policy forgot:
+//
+//	masktest.AssertCanaries[SignupRequest](t, policyOpts...)
+//
+// A canary survives if it is kept as a whole; strategies keeping parts of a value
+// like partial=1:1 mask it. The masked value is returned.
+func AssertCanaries[T any](t testing.TB, opts ...mask.Option) T {
+	t.Helper()
+	var x T
+	f := &filler{canaries: map[string]canary{}, filling: map[reflect.Type]bool{}}
+	v := reflect.ValueOf(&x).Elem()
+	root := v.Type()
+	for root.Kind() == reflect.Ptr {
+		root = root.Elem()
+	}
+	f.fill(v, root.Name(), false)
+
+	out, err := mask.Mask(x, opts...)
+	if err != nil {
+		t.Fatalf("mask failed: %v", err)
+	}
+	walk(t, out, func(path string, v reflect.Value) {
+		if v.Kind() != reflect.String {
+			return
+		}
+		for _, name := range canariesIn(v.String()) {
+			if c, ok := f.canaries[name]; ok && c.sensitive {
+				t.Errorf("%s of sensitive %s is not masked", name, c.path)
+			}
+		}
+	})
+	return out
+}
+
+const canaryPrefix = "CANARY"
+
+// canariesIn returns the canaries contained in s.
+func canariesIn(s string) []string {
+	var found []string
+	for {
+		i := strings.Index(s, canaryPrefix)
+		if i < 0 || len(s) < i+len(canaryPrefix)+6 {
+			return found
+		}
+		found = append(found, s[i:i+len(canaryPrefix)+6])
+		s = s[i+len(canaryPrefix)+6:]
+	}
+}
+
+// filler fills values with canaries.
+type filler struct {
+	canaries map[string]canary
+	// filling holds the types being filled, which are not filled again to end recursion.
+	filling map[reflect.Type]bool
+}
+
+func (f *filler) fill(v reflect.Value, path string, sensitive bool) {
+	switch v.Kind() {
+	case reflect.String:
+		name := fmt.Sprintf("%s%06d", canaryPrefix, len(f.canaries))
+		f.canaries[name] = canary{path: path, sensitive: sensitive}
+		v.SetString(name)
+	case reflect.Ptr:
+		if f.filling[v.Type().Elem()] {
+			return
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		f.fill(v.Elem(), path, sensitive)
+	case reflect.Slice:
+		if f.filling[v.Type().Elem()] {
+			return
+		}
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		f.fill(v.Index(0), path+"[0]", sensitive)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			f.fill(v.Index(i), fmt.Sprintf("%s[%d]", path, i), sensitive)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || f.filling[v.Type().Elem()] {
+			return
+		}
+		v.Set(reflect.MakeMap(v.Type()))
+		key := reflect.New(v.Type().Key()).Elem()
+		key.SetString("key")
+		elem := reflect.New(v.Type().Elem()).Elem()
+		f.fill(elem, path+`["key"]`, sensitive)
+		v.SetMapIndex(key, elem)
+	case reflect.Struct:
+		t := v.Type()
+		f.filling[t] = true
+		defer delete(f.filling, t)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			_, tagged := field.Tag.Lookup("mask")
+			f.fill(v.Field(i), path+"."+field.Name, sensitive || tagged || isSensitiveName(field.Name))
+		}
+	}
+}
+
+func isSensitiveName(name string) bool {
+	name = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	for _, s := range sensitiveNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package masktest
+
+import (
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+)
+
+type signup struct {
+	Login    string
+	Password string
+	APIKey   *string
+	Emails   []string
+	Profile  *signupProfile
+	Card     signupCard `mask:"redact"`
+}
+
+type signupProfile struct {
+	DisplayName string
+	Phone       string `mask:"partial=0:2"`
+	Attributes  map[string]string
+	Referrer    *signupProfile
+}
+
+type signupCard struct {
+	Number string
+	Holder string
+}
+
+func TestAssertCanaries(t *testing.T) {
+	failures := record(func(t testing.TB) {
+		AssertCanaries[signup](t)
+	})
+	expect := []string{
+		"signup.Password is not masked",
+		"signup.APIKey is not masked",
+		"signup.Emails[0] is not masked",
+	}
+	if len(failures) != len(expect) {
+		t.Fatalf("expect %v failures, got %v", len(expect), failures)
+	}
+	for i, e := range expect {
+		if !strings.Contains(failures[i], e) {
+			t.Errorf("expect %v to contain %v", failures[i], e)
+		}
+	}
+
+	failures = record(func(t testing.TB) {
+		masked := AssertCanaries[*signup](t, mask.WithDenyFields("password", "apikey", "emails"))
+		if masked.Login == "" || masked.Profile.DisplayName == "" || masked.Profile.Attributes["key"] == "" {
+			t.Errorf("expect insensitive fields to be filled, got %+v", masked)
+		}
+	})
+	if len(failures) != 0 {
+		t.Errorf("expected no failures, got %v", failures)
+	}
+}
@@ -0,0 +1,74 @@
+package masktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+)
+
+var update = flag.Bool("masktest.update", false, "update golden files of masktest.Golden")
+
+// Golden masks v using opts, encodes it as indented JSON with map keys sorted and
+// compares it to the golden file testdata/<name>.golden, failing t on any difference.
+// This locks in the redaction of API payloads: a change of tags, strategies or types
+// shows up as a diff of the golden file in review.
+//
+// Golden files are written by running the tests with -masktest.update:
+//
+//	go test ./... -masktest.update
+func Golden(t testing.TB, name string, v interface{}, opts ...mask.Option) {
+	t.Helper()
+	opts = append(opts[:len(opts):len(opts)], mask.WithSortedMaps())
+	got, err := json.MarshalIndent(mask.JSON(v, opts...), "", "  ")
+	if err != nil {
+		t.Fatalf("mask failed: %v", err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("update golden file: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("update golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden file %s does not exist, run the test with -masktest.update to create it", path)
+	} else if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("masked %s differs from %s, run the test with -masktest.update to accept it:\n%s", name, path, diff(string(want), string(got)))
+	}
+}
+
+// diff returns the lines of want and got starting at the first line they differ in.
+func diff(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	i := 0
+	for i < len(w) && i < len(g) && w[i] == g[i] {
+		i++
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ line %d\n", i+1)
+	for _, l := range w[i:min(len(w), i+5)] {
+		b.WriteString("- " + l + "\n")
+	}
+	for _, l := range g[i:min(len(g), i+5)] {
+		b.WriteString("+ " + l + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package masktest
+
+import (
+	"strings"
+	"testing"
+)
+
+type payload struct {
+	User     customer
+	Metadata map[string]interface{}
+}
+
+func newPayload() payload {
+	return payload{
+		User:     customer{Name: "Ada", Code: "A-1234", Note: "vip", Tags: map[string]string{"tier": "gold", "region": "eu"}},
+		Metadata: map[string]interface{}{"z": 1, "a": []string{"x"}},
+	}
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, "payload", newPayload())
+	if *update {
+		return
+	}
+
+	changed := newPayload()
+	changed.User.Note = "regular"
+	failures := record(func(t testing.TB) {
+		Golden(t, "payload", changed)
+	})
+	if len(failures) != 1 || !strings.Contains(failures[0], `-     "Note": "vip",`) || !strings.Contains(failures[0], `+     "Note": "regular",`) {
+		t.Errorf("expect the difference to be reported, got %v", failures)
+	}
+
+	failures = record(func(t testing.TB) {
+		Golden(t, "missing", changed)
+	})
+	if len(failures) != 1 || !strings.Contains(failures[0], "does not exist") {
+		t.Errorf("expect the missing golden file to be reported, got %v", failures)
+	}
+}
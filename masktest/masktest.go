@@ -0,0 +1,227 @@
+// Package masktest checks the invariants of masking for values of your own types,
+// e.g. in property-based tests:
+//
+//	func TestCustomerMasking(t *testing.T) {
+//	  f := func(c Customer) bool {
+//	    masktest.AssertInvariant(t, c)
+//	    return !t.Failed()
+//	  }
+//	  if err := quick.Check(f, nil); err != nil {
+//	    t.Error(err)
+//	  }
+//	}
+package masktest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+)
+
+// AssertInvariant masks x using opts and fails t unless
+//
+//   - x is left unchanged, including its unexported fields,
+//   - the masked value has the dynamic type of x,
+//   - the masked value shares no pointer, map or slice with x and
+//   - every non-zero value masked by a strategy, e.g. referenced by a tag, differs from the original.
+//
+// Values which a strategy keeps as they are, like short strings masked partially, are
+// thus reported. Values copied by reference using mask.WithSkipUnsupported or custom
+// copiers returning their input must not be pointers, maps or slices.
+// The masked value is returned for further assertions.
+func AssertInvariant[T any](t testing.TB, x T, opts ...mask.Option) T {
+	t.Helper()
+	before := fingerprint(reflect.ValueOf(x))
+	findings, err := mask.Scan(x, opts...)
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	out, err := mask.Mask(x, opts...)
+	if err != nil {
+		t.Fatalf("mask failed: %v", err)
+	}
+
+	if after := fingerprint(reflect.ValueOf(x)); after != before {
+		t.Errorf("masking changed the original value:\n%s\nto\n%s", before, after)
+	}
+	if in, o := reflect.TypeOf(x), reflect.TypeOf(out); in != o {
+		t.Errorf("masked value is of type %v, expected %v", o, in)
+	}
+
+	inRefs := map[uintptr]string{}
+	inValues := map[string]reflect.Value{}
+	walk(t, x, func(path string, v reflect.Value) {
+		if p, ok := ref(v); ok {
+			inRefs[p] = path
+		}
+		inValues[path] = v
+	})
+	outValues := map[string]reflect.Value{}
+	walk(t, out, func(path string, v reflect.Value) {
+		if p, ok := ref(v); ok {
+			if in, shared := inRefs[p]; shared {
+				t.Errorf("%s shares its %v with %s of the original value", path, v.Kind(), in)
+			}
+		}
+		outValues[path] = v
+	})
+
+	for _, f := range findings {
+		switch f.Source {
+		case mask.SourceTag, mask.SourcePath, mask.SourceType, mask.SourceField, mask.SourceDetector:
+		default:
+			continue
+		}
+		in, o := inValues[f.Path], outValues[f.Path]
+		if !in.IsValid() || !o.IsValid() || in.IsZero() || !in.CanInterface() || !o.CanInterface() {
+			continue
+		}
+		if reflect.DeepEqual(in.Interface(), o.Interface()) {
+			t.Errorf("%s is not masked by %s", f.Path, f.Strategy)
+		}
+	}
+	return out
+}
+
+func walk(t testing.TB, x interface{}, visit func(path string, v reflect.Value)) {
+	t.Helper()
+	err := mask.Walk(x, func(path string, v reflect.Value) error {
+		visit(path, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk failed: %v", err)
+	}
+}
+
+// ref returns the address of the data referenced by v, if any.
+func ref(v reflect.Value) (uintptr, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		return v.Pointer(), !v.IsNil() && v.Type().Elem().Size() > 0
+	case reflect.Slice:
+		return v.Pointer(), v.Cap() > 0 && v.Type().Elem().Size() > 0
+	}
+	return 0, false
+}
+
+// fingerprint formats v and everything reachable from it, including unexported fields.
+// References already formatted are formatted by their number, so cycles terminate.
+func fingerprint(v reflect.Value) string {
+	type key struct {
+		ptr uintptr
+		len int
+		typ reflect.Type
+	}
+	var b strings.Builder
+	seen := map[key]int{}
+	var format func(v reflect.Value)
+	reference := func(v reflect.Value) bool {
+		k := key{ptr: v.Pointer(), typ: v.Type()}
+		if v.Kind() == reflect.Slice {
+			k.len = v.Len()
+		}
+		if n, ok := seen[k]; ok {
+			fmt.Fprintf(&b, "@%d", n)
+			return true
+		}
+		seen[k] = len(seen)
+		return false
+	}
+	format = func(v reflect.Value) {
+		if !v.IsValid() {
+			b.WriteString("<nil>")
+			return
+		}
+		switch v.Kind() {
+		case reflect.Ptr:
+			if v.IsNil() {
+				b.WriteString("nil")
+				return
+			}
+			if v.Type().Elem().Size() > 0 && reference(v) {
+				return
+			}
+			b.WriteString("&")
+			format(v.Elem())
+		case reflect.Interface:
+			if v.IsNil() {
+				b.WriteString("nil")
+				return
+			}
+			fmt.Fprintf(&b, "(%v)", v.Elem().Type())
+			format(v.Elem())
+		case reflect.Struct:
+			b.WriteString("{")
+			for i := 0; i < v.NumField(); i++ {
+				fmt.Fprintf(&b, "%s:", v.Type().Field(i).Name)
+				format(v.Field(i))
+				b.WriteString(" ")
+			}
+			b.WriteString("}")
+		case reflect.Slice, reflect.Array:
+			if v.Kind() == reflect.Slice {
+				if v.IsNil() {
+					b.WriteString("nil")
+					return
+				}
+				if v.Len() > 0 && reference(v) {
+					return
+				}
+			}
+			b.WriteString("[")
+			for i := 0; i < v.Len(); i++ {
+				format(v.Index(i))
+				b.WriteString(" ")
+			}
+			b.WriteString("]")
+		case reflect.Map:
+			if v.IsNil() {
+				b.WriteString("nil")
+				return
+			}
+			if reference(v) {
+				return
+			}
+			// format entries in the order of their keys, so references are numbered consistently
+			type entry struct {
+				name  string
+				value reflect.Value
+			}
+			var entries []entry
+			for iter := v.MapRange(); iter.Next(); {
+				entries = append(entries, entry{fingerprint(iter.Key()), iter.Value()})
+			}
+			sort.Slice(entries, func(i, j int) bool {
+				return entries[i].name < entries[j].name
+			})
+			b.WriteString("map[")
+			for _, e := range entries {
+				b.WriteString(e.name + ":")
+				format(e.value)
+				b.WriteString(" ")
+			}
+			b.WriteString("]")
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			fmt.Fprintf(&b, "%v(%#x)", v.Kind(), v.Pointer())
+		case reflect.String:
+			fmt.Fprintf(&b, "%q", v.String())
+		case reflect.Bool:
+			fmt.Fprint(&b, v.Bool())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fmt.Fprint(&b, v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			fmt.Fprint(&b, v.Uint())
+		case reflect.Float32, reflect.Float64:
+			fmt.Fprint(&b, v.Float())
+		case reflect.Complex64, reflect.Complex128:
+			fmt.Fprint(&b, v.Complex())
+		}
+	}
+	format(v)
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package masktest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskers"
+)
+
+// recorder records the failures reported to it.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	panic(r)
+}
+
+// record runs f, recovering from Fatalf.
+func record(f func(t testing.TB)) []string {
+	r := &recorder{}
+	func() {
+		defer func() {
+			if p := recover(); p != nil && p != r {
+				panic(p)
+			}
+		}()
+		f(r)
+	}()
+	return r.failures
+}
+
+type customer struct {
+	Name    string `mask:"redact"`
+	Code    string `mask:"partial=1:1"`
+	Note    string
+	Tags    map[string]string
+	Friends []*customer
+	secret  string
+}
+
+func TestAssertInvariant(t *testing.T) {
+	c := &customer{Name: "Ada", Code: "A-1234", Tags: map[string]string{"tier": "gold"}, secret: "s"}
+	c.Friends = []*customer{c, {Name: "Bob"}}
+
+	var masked *customer
+	failures := record(func(t testing.TB) {
+		masked = AssertInvariant(t, c)
+	})
+	if len(failures) > 0 {
+		t.Errorf("expected no failures, got %v", failures)
+	}
+	if masked.Name != "MASKED" || masked.Friends[0] != masked {
+		t.Errorf("expect the masked value to be returned, got %+v", masked)
+	}
+
+	keep := maskers.Func("keep", func(v reflect.Value) (reflect.Value, error) {
+		return v, nil
+	})
+	failures = record(func(t testing.TB) {
+		AssertInvariant(t, customer{Note: "n"}, mask.WithPathStrategy("Note", keep))
+	})
+	if len(failures) != 1 || !strings.Contains(failures[0], "customer.Note is not masked by keep") {
+		t.Errorf("expect the unmasked code to be reported, got %v", failures)
+	}
+
+	shared := mask.NewCopier()
+	shared.Register(map[string]string{}, func(x interface{}, deep func(interface{}) (interface{}, error)) (interface{}, error) {
+		return x, nil
+	})
+	failures = record(func(t testing.TB) {
+		AssertInvariant(t, customer{Tags: map[string]string{"a": "b"}}, mask.WithCopier(shared))
+	})
+	if len(failures) != 1 || !strings.Contains(failures[0], "customer.Tags shares its map with customer.Tags") {
+		t.Errorf("expect the shared map to be reported, got %v", failures)
+	}
+
+	failures = record(func(t testing.TB) {
+		AssertInvariant(t, make(chan int))
+	})
+	if len(failures) != 1 || !strings.Contains(failures[0], "mask failed") {
+		t.Errorf("expect the failure to mask to be reported, got %v", failures)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	m := map[string]interface{}{"b": 2, "a": []int{1}}
+	m["self"] = m
+	s := []interface{}{nil}
+	s[0] = s
+	c := &customer{secret: "s"}
+	c.Friends = []*customer{c}
+	for _, x := range []interface{}{m, s, c} {
+		if a, b := fingerprint(reflect.ValueOf(x)), fingerprint(reflect.ValueOf(x)); a != b {
+			t.Errorf("expect %s == %s", a, b)
+		}
+	}
+	before := fingerprint(reflect.ValueOf(c))
+	c.secret = "changed"
+	if after := fingerprint(reflect.ValueOf(c)); after == before {
+		t.Errorf("expect unexported fields to change the fingerprint %s", after)
+	}
+}
@@ -0,0 +1,14 @@
+// Command maskvet runs the maskvet analyzer.
+//
+//	go install github.com/doejon/go-mask/maskvet/cmd/maskvet@latest
+//	go vet -vettool=$(which maskvet) ./...
+package main
+
+import (
+	"github.com/doejon/go-mask/maskvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(maskvet.Analyzer)
+}
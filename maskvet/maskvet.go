@@ -0,0 +1,241 @@
+// Package maskvet defines an analyzer checking the usage of go-mask:
+//
+//   - MaskXXX methods with a signature go-mask cannot call
+//   - struct fields with sensitive looking names (password, secret, ...)
+//     which are neither tagged with `mask:"..."` nor of a type implementing MaskXXX
+//   - structs holding sensitive fields passed to log or log/slog without being masked
+//
+// The analyzer can be run standalone using cmd/maskvet or as part of go vet:
+//
+//	go vet -vettool=$(which maskvet) ./...
+package maskvet
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const maskPkg = "github.com/doejon/go-mask"
+
+// Analyzer reports misuse of go-mask.
+var Analyzer = &analysis.Analyzer{
+	Name:     "maskvet",
+	Doc:      "check MaskXXX signatures, untagged sensitive fields and unmasked structs passed to loggers",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// SensitiveNames are matched case-insensitively against field names,
+// ignoring underscores and dashes.
+var SensitiveNames = []string{
+	"password", "passwd", "secret", "token", "apikey", "privatekey",
+	"ssn", "creditcard", "cardnumber", "cvv", "iban", "pin",
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	filter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.StructType)(nil),
+		(*ast.CallExpr)(nil),
+	}
+	insp.Preorder(filter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			checkMaskMethod(pass, n)
+		case *ast.StructType:
+			checkSensitiveFields(pass, n)
+		case *ast.CallExpr:
+			checkLoggerCall(pass, n)
+		}
+	})
+	return nil, nil
+}
+
+func checkMaskMethod(pass *analysis.Pass, fn *ast.FuncDecl) {
+	if fn.Recv == nil || fn.Name.Name != "MaskXXX" {
+		return
+	}
+	obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func)
+	if !ok {
+		return
+	}
+	sig := obj.Type().(*types.Signature)
+	recv := sig.Recv().Type()
+	ptr, isPtr := recv.(*types.Pointer)
+	base := recv
+	if isPtr {
+		base = ptr.Elem()
+	}
+
+	if sig.Params().Len() != 0 {
+		pass.Reportf(fn.Name.Pos(), "MaskXXX must not take any arguments")
+		return
+	}
+	switch sig.Results().Len() {
+	case 0:
+		if !isPtr {
+			pass.Reportf(fn.Name.Pos(), "MaskXXX without result needs a pointer receiver, otherwise it masks a copy")
+		}
+	case 1:
+		if res := sig.Results().At(0).Type(); !types.Identical(res, base) {
+			pass.Reportf(fn.Name.Pos(), "MaskXXX must return its receiver type %s, got %s", types.TypeString(base, types.RelativeTo(pass.Pkg)), types.TypeString(res, types.RelativeTo(pass.Pkg)))
+		}
+	default:
+		pass.Reportf(fn.Name.Pos(), "MaskXXX must return at most 1 value, got %d", sig.Results().Len())
+	}
+}
+
+func checkSensitiveFields(pass *analysis.Pass, st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		if hasMaskTag(field) {
+			continue
+		}
+		if tv, ok := pass.TypesInfo.Types[field.Type]; ok && hasMaskMethod(tv.Type) {
+			continue
+		}
+		for _, name := range field.Names {
+			if name.IsExported() && isSensitiveName(name.Name) {
+				pass.Reportf(name.Pos(), "field %s looks sensitive but is not masked; add a `mask:\"...\"` tag", name.Name)
+			}
+		}
+	}
+}
+
+func hasMaskTag(field *ast.Field) bool {
+	if field.Tag == nil {
+		return false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return false
+	}
+	_, ok := reflect.StructTag(tag).Lookup("mask")
+	return ok
+}
+
+func isSensitiveName(name string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	for _, s := range SensitiveNames {
+		if strings.Contains(normalized, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMaskMethod reports whether t (or *t) declares a MaskXXX method.
+func hasMaskMethod(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	for _, typ := range []types.Type{t, types.NewPointer(t)} {
+		if obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, "MaskXXX"); obj != nil {
+			if _, ok := obj.(*types.Func); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsSensitive reports whether values of type t hold anything go-mask would mask.
+func containsSensitive(t types.Type, seen map[types.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if hasMaskMethod(t) {
+		return true
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		return containsSensitive(u.Elem(), seen)
+	case *types.Slice:
+		return containsSensitive(u.Elem(), seen)
+	case *types.Array:
+		return containsSensitive(u.Elem(), seen)
+	case *types.Map:
+		return containsSensitive(u.Elem(), seen)
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			f := u.Field(i)
+			if !f.Exported() {
+				continue
+			}
+			if _, ok := reflect.StructTag(u.Tag(i)).Lookup("mask"); ok || isSensitiveName(f.Name()) {
+				return true
+			}
+			if containsSensitive(f.Type(), seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loggers maps logging packages to the names of their logging functions and methods.
+var loggers = map[string][]string{
+	"log":      {"Print", "Printf", "Println", "Fatal", "Fatalf", "Fatalln", "Panic", "Panicf", "Panicln"},
+	"log/slog": {"Debug", "Info", "Warn", "Error", "DebugContext", "InfoContext", "WarnContext", "ErrorContext", "Log", "Any", "With"},
+}
+
+func checkLoggerCall(pass *analysis.Pass, call *ast.CallExpr) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return
+	}
+	names, ok := loggers[fn.Pkg().Path()]
+	if !ok || !contains(names, fn.Name()) {
+		return
+	}
+	for _, arg := range call.Args {
+		if isMaskCall(pass, arg) {
+			continue
+		}
+		tv, ok := pass.TypesInfo.Types[arg]
+		if !ok || tv.Type == nil {
+			continue
+		}
+		if _, isStruct := derefUnderlying(tv.Type).(*types.Struct); !isStruct {
+			continue
+		}
+		if containsSensitive(tv.Type, map[types.Type]bool{}) {
+			pass.Reportf(arg.Pos(), "%s holds sensitive fields and is logged without masking; use mask.Must", types.TypeString(tv.Type, types.RelativeTo(pass.Pkg)))
+		}
+	}
+}
+
+func derefUnderlying(t types.Type) types.Type {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		return p.Elem().Underlying()
+	}
+	return t.Underlying()
+}
+
+// isMaskCall reports whether e is a call to a function of the mask package.
+func isMaskCall(pass *analysis.Pass, e ast.Expr) bool {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == maskPkg
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package maskvet_test
+
+import (
+	"testing"
+
+	"github.com/doejon/go-mask/maskvet"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), maskvet.Analyzer, "a")
+}
@@ -0,0 +1,57 @@
+package a
+
+import (
+	"log"
+	"log/slog"
+
+	mask "github.com/doejon/go-mask"
+)
+
+type Email string
+
+func (e Email) MaskXXX() Email { return "MASKED" }
+
+type Phone string
+
+func (p *Phone) MaskXXX() { *p = "MASKED" }
+
+type BadArgs string
+
+func (b BadArgs) MaskXXX(n int) BadArgs { return b } // want `MaskXXX must not take any arguments`
+
+type BadResult string
+
+func (b BadResult) MaskXXX() string { return "" } // want `MaskXXX must return its receiver type BadResult, got string`
+
+type BadValue string
+
+func (b BadValue) MaskXXX() {} // want `MaskXXX without result needs a pointer receiver`
+
+type BadMany string
+
+func (b BadMany) MaskXXX() (BadMany, error) { return b, nil } // want `MaskXXX must return at most 1 value, got 2`
+
+type User struct {
+	Name        string `mask:"name"`
+	Password    string // want `field Password looks sensitive but is not masked`
+	API_Token   string // want `field API_Token looks sensitive but is not masked`
+	SecretEmail Email
+	password    string
+	Age         int
+}
+
+type Public struct {
+	Name string
+	Age  int
+}
+
+func logging(u User, p Public) {
+	log.Println(u)       // want `User holds sensitive fields and is logged without masking`
+	log.Printf("%v", &u) // want `\*User holds sensitive fields and is logged without masking`
+	log.Println(mask.Must(u))
+	log.Println(p)
+	slog.Info("user", "user", u)           // want `User holds sensitive fields and is logged without masking`
+	slog.Default().Info("user", "user", u) // want `User holds sensitive fields and is logged without masking`
+	slog.Info("user", "user", mask.Must(u))
+	_ = u.password
+}
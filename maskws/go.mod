@@ -0,0 +1,13 @@
+module github.com/doejon/go-mask/maskws
+
+go 1.22.2
+
+require (
+	github.com/doejon/go-mask v0.0.0
+	github.com/gorilla/websocket v1.5.3
+	nhooyr.io/websocket v1.8.17
+)
+
+require gopkg.in/yaml.v3 v3.0.1 // indirect
+
+replace github.com/doejon/go-mask => ..
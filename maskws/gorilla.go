@@ -0,0 +1,56 @@
+package maskws
+
+import (
+	"encoding/json"
+
+	gorilla "github.com/gorilla/websocket"
+)
+
+// GorillaConn is a gorilla/websocket connection mirroring its data frames.
+// Only frames read and written by ReadMessage, WriteMessage, ReadJSON and WriteJSON
+// are mirrored, not those of NextReader and NextWriter.
+type GorillaConn struct {
+	*gorilla.Conn
+	i *Interceptor
+}
+
+// Gorilla wraps c, mirroring its frames.
+func (i *Interceptor) Gorilla(c *gorilla.Conn) *GorillaConn {
+	return &GorillaConn{Conn: c, i: i}
+}
+
+// ReadMessage reads a message and mirrors it.
+func (c *GorillaConn) ReadMessage() (int, []byte, error) {
+	typ, p, err := c.Conn.ReadMessage()
+	if err == nil {
+		c.i.frame(Incoming, typ == gorilla.BinaryMessage, p)
+	}
+	return typ, p, err
+}
+
+// WriteMessage writes a message and mirrors data messages.
+func (c *GorillaConn) WriteMessage(typ int, data []byte) error {
+	err := c.Conn.WriteMessage(typ, data)
+	if err == nil && (typ == gorilla.TextMessage || typ == gorilla.BinaryMessage) {
+		c.i.frame(Outgoing, typ == gorilla.BinaryMessage, data)
+	}
+	return err
+}
+
+// ReadJSON reads a message, mirrors it and decodes it into v.
+func (c *GorillaConn) ReadJSON(v interface{}) error {
+	_, p, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(p, v)
+}
+
+// WriteJSON encodes v as a text message, writes and mirrors it.
+func (c *GorillaConn) WriteJSON(v interface{}) error {
+	p, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(gorilla.TextMessage, p)
+}
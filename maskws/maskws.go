@@ -0,0 +1,83 @@
+// Package maskws mirrors the frames of WebSocket connections masked, e.g. to debugging
+// or session replay systems, without sending them personal data or credentials:
+//
+//	i := maskws.New(func(f maskws.Frame) {
+//		slog.Debug("frame", "direction", f.Direction, "data", f.Data)
+//	}, maskhttp.WithOptions(policyOpts...))
+//	conn := i.Gorilla(gorillaConn) // or i.Nhooyr(nhooyrConn)
+//
+// JSON text frames are decoded and masked like JSON bodies by maskhttp; other frames
+// and frames exceeding the maximum size are omitted. The frames sent and received by
+// the connection are never modified.
+package maskws
+
+import (
+	"time"
+
+	"github.com/doejon/go-mask/maskhttp"
+)
+
+// now is replaced by tests.
+var now = time.Now
+
+// Direction is the direction of a frame.
+type Direction int
+
+const (
+	// Incoming frames have been read from the connection.
+	Incoming Direction = iota
+	// Outgoing frames have been written to the connection.
+	Outgoing
+)
+
+func (d Direction) String() string {
+	if d == Outgoing {
+		return "outgoing"
+	}
+	return "incoming"
+}
+
+// Frame is a masked data frame.
+type Frame struct {
+	Direction Direction
+	// Binary is set for binary frames, otherwise the frame is a text frame.
+	Binary bool
+	// Data holds the masked JSON of the frame, or a note why it was omitted,
+	// e.g. "[1024 bytes omitted]". It is empty for empty frames.
+	Data []byte
+	// Size is the size of the original frame.
+	Size int
+	// Time is the time the frame has been read or written.
+	Time time.Time
+}
+
+// Interceptor masks the frames of connections and passes them to a mirror.
+// It is safe for concurrent use.
+type Interceptor struct {
+	m      *maskhttp.Masker
+	mirror func(Frame)
+}
+
+// New creates an Interceptor passing masked frames to mirror. Frames are masked
+// using the options of a maskhttp.Masker, e.g. maskhttp.WithOptions and
+// maskhttp.WithMaxBodySize. mirror is called by the goroutine reading or writing
+// a frame once it has been read or written successfully.
+func New(mirror func(Frame), opts ...maskhttp.Option) *Interceptor {
+	return &Interceptor{m: maskhttp.New(opts...), mirror: mirror}
+}
+
+func (i *Interceptor) frame(d Direction, binary bool, data []byte) {
+	contentType := "application/json"
+	if binary {
+		contentType = "application/octet-stream"
+	}
+	b := i.m.NewBody()
+	b.Write(data)
+	i.mirror(Frame{
+		Direction: d,
+		Binary:    binary,
+		Data:      i.m.Mask(contentType, b),
+		Size:      len(data),
+		Time:      now(),
+	})
+}
@@ -0,0 +1,99 @@
+package maskws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	mask "github.com/doejon/go-mask"
+	"github.com/doejon/go-mask/maskhttp"
+	gorilla "github.com/gorilla/websocket"
+	"nhooyr.io/websocket"
+)
+
+type testMirror struct {
+	mu     sync.Mutex
+	frames []Frame
+}
+
+func (m *testMirror) mirror(f Frame) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.frames = append(m.frames, f)
+}
+
+func (m *testMirror) data() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data := make([]string, len(m.frames))
+	for i, f := range m.frames {
+		data[i] = f.Direction.String() + " " + string(f.Data)
+	}
+	return data
+}
+
+func newTestInterceptor(t *testing.T, m *testMirror) *Interceptor {
+	redact, err := mask.ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(m.mirror, maskhttp.WithOptions(mask.WithPathStrategy("**.token", redact)))
+}
+
+func TestInterceptor(t *testing.T) {
+	var server, client testMirror
+	serverInterceptor := newTestInterceptor(t, &server)
+	upgrader := gorilla.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn := serverInterceptor.Gorilla(c)
+		defer conn.Close()
+		for {
+			var msg map[string]interface{}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if err := conn.WriteJSON(map[string]interface{}{"echo": msg}); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := newTestInterceptor(t, &client).Nhooyr(c)
+	if err := conn.Write(ctx, websocket.MessageText, []byte(`{"token":"s3cr3t"}`)); err != nil {
+		t.Fatal(err)
+	}
+	_, p, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != `{"echo":{"token":"s3cr3t"}}` {
+		t.Errorf("expect frames not to be modified, got %s", p)
+	}
+	if err := conn.Write(ctx, websocket.MessageBinary, []byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close(websocket.StatusNormalClosure, "")
+
+	expect := []string{`outgoing {"token":"MASKED"}`, `incoming {"echo":{"token":"MASKED"}}`, "outgoing [3 bytes omitted]"}
+	if got := client.data(); strings.Join(got, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("expect %q == %q", got, expect)
+	}
+	expect = []string{`incoming {"token":"MASKED"}`, `outgoing {"echo":{"token":"MASKED"}}`}
+	if got := server.data(); len(got) < 2 || strings.Join(got[:2], "\n") != strings.Join(expect, "\n") {
+		t.Errorf("expect %q to start with %q", got, expect)
+	}
+}
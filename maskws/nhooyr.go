@@ -0,0 +1,38 @@
+package maskws
+
+import (
+	"context"
+
+	"nhooyr.io/websocket"
+)
+
+// NhooyrConn is a nhooyr.io/websocket connection mirroring its frames.
+// Only frames read and written by Read and Write are mirrored, not those
+// of Reader and Writer, e.g. used by wsjson.
+type NhooyrConn struct {
+	*websocket.Conn
+	i *Interceptor
+}
+
+// Nhooyr wraps c, mirroring its frames.
+func (i *Interceptor) Nhooyr(c *websocket.Conn) *NhooyrConn {
+	return &NhooyrConn{Conn: c, i: i}
+}
+
+// Read reads a message and mirrors it.
+func (c *NhooyrConn) Read(ctx context.Context) (websocket.MessageType, []byte, error) {
+	typ, p, err := c.Conn.Read(ctx)
+	if err == nil {
+		c.i.frame(Incoming, typ == websocket.MessageBinary, p)
+	}
+	return typ, p, err
+}
+
+// Write writes a message and mirrors it.
+func (c *NhooyrConn) Write(ctx context.Context, typ websocket.MessageType, p []byte) error {
+	err := c.Conn.Write(ctx, typ, p)
+	if err == nil {
+		c.i.frame(Outgoing, typ == websocket.MessageBinary, p)
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package mask
+
+import "time"
+
+// CallMetrics describes a single call to Mask or a JSON encoding, see WithMetrics.
+type CallMetrics struct {
+	// Op is "mask" for calls to Mask and the functions based on it,
+	// "json" for encodings using JSON.
+	Op       string
+	Duration time.Duration
+	// Masked counts the values masked by the name of the strategy masking them, or MaskXXX.
+	Masked map[string]int
+	// Err is the error returned by the call, if any.
+	Err error
+}
+
+// MetricsSink receives the metrics of calls, e.g. to export them to Prometheus or
+// OpenTelemetry using the adapters of the maskprom and maskotel modules.
+// It is called concurrently by concurrent calls.
+type MetricsSink interface {
+	ObserveCall(m CallMetrics)
+}
+
+// WithMetrics reports the duration, the values masked and the error of every call to sink,
+// to monitor the cost and coverage of masking in production. Metrics are only collected
+// using WithMetrics.
+func WithMetrics(sink MetricsSink) Option {
+	return func(o *options) {
+		o.metrics = sink
+	}
+}
+
+// observe reports the call op, started at start and failing with *err, to the metrics sink.
+func (s *state) observe(op string, start time.Time, err *error) {
+	s.opts.metrics.ObserveCall(CallMetrics{
+		Op:       op,
+		Duration: time.Since(start),
+		Masked:   s.maskedBy,
+		Err:      *err,
+	})
+}
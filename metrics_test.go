@@ -0,0 +1,54 @@
+package mask
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type testMetricsSink struct {
+	mu    sync.Mutex
+	calls []CallMetrics
+}
+
+func (s *testMetricsSink) ObserveCall(m CallMetrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, m)
+}
+
+func TestWithMetrics(t *testing.T) {
+	sink := &testMetricsSink{}
+	val := struct {
+		Email  string `mask:"redact"`
+		Phone  string `mask:"redact"`
+		Secret TestString
+		Name   string
+	}{"ada@example.com", "555", "s", "Ada"}
+
+	if _, err := Mask(val, WithMetrics(sink)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := json.Marshal(JSON(val, WithMetrics(sink))); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := Mask(make(chan int), WithMetrics(sink)); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if len(sink.calls) != 3 {
+		t.Fatalf("expect 3 calls, got %v", sink.calls)
+	}
+	masked := map[string]int{"redact": 2, "MaskXXX": 1}
+	for i, op := range []string{"mask", "json"} {
+		c := sink.calls[i]
+		if c.Op != op || c.Duration < 0 || c.Err != nil || !reflect.DeepEqual(c.Masked, masked) {
+			t.Errorf("expect %+v == %v call masking %v", c, op, masked)
+		}
+	}
+	if c := sink.calls[2]; !errors.Is(c.Err, ErrUnsupportedKind) || len(c.Masked) != 0 {
+		t.Errorf("expect %+v to report %v", c, ErrUnsupportedKind)
+	}
+}
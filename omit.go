@@ -0,0 +1,41 @@
+package mask
+
+import "reflect"
+
+// omitStrategy drops values from masked copies, see Omit.
+type omitStrategy struct{}
+
+func (omitStrategy) Name() string {
+	return "omit"
+}
+
+func (omitStrategy) Mask(v reflect.Value) (reflect.Value, error) {
+	return reflect.Zero(v.Type()), nil
+}
+
+// Omit returns the strategy of the tags `mask:"omit"` and `mask:"-"`, for values which
+// should not even appear redacted. Struct fields and slice elements are replaced by their
+// zero value; map entries are removed from the masked copy. JSON leaves out omitted struct
+// fields and map entries altogether:
+//
+//	mask.WithPathStrategy("**.internal_notes", mask.Omit())
+func Omit() Strategy {
+	return omitStrategy{}
+}
+
+func isOmit(strategy Strategy) bool {
+	_, ok := strategy.(omitStrategy)
+	return ok
+}
+
+// omits reports whether v, located by the current path, is omitted. tag is the
+// strategy of the struct tag of v, if any, which takes precedence like in _field.
+// Detectors are not consulted, their omitted values are encoded as zero values.
+func (s *state) omits(tag Strategy, v reflect.Value) bool {
+	omitted := isOmit(tag)
+	if tag == nil {
+		omitted = isOmit(s.pathStrategy()) || isOmit(s.typeStrategy(v)) || isOmit(s.fieldStrategy())
+	}
+	// hooks are only asked about omitted values, as they are asked again when encoding v
+	return omitted && s.hook(v).Action == HookContinue
+}
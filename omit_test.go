@@ -0,0 +1,54 @@
+package mask
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestOmit(t *testing.T) {
+	type account struct {
+		User     string
+		Password string  `mask:"-"`
+		Notes    *string `mask:"omit"`
+		Meta     map[string]interface{}
+		Tags     []string
+	}
+	notes := "internal"
+	val := account{
+		User:     "ada",
+		Password: "s3cr3t",
+		Notes:    &notes,
+		Meta:     map[string]interface{}{"debug": "trace", "region": "eu", "nested": map[string]string{"debug": "x", "ok": "y"}},
+		Tags:     []string{"a", "b"},
+	}
+	opts := []Option{WithPathStrategy("**.debug", Omit()), WithPathStrategy("Tags[0]", Omit())}
+	masked, err := Mask(val, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := account{
+		User: "ada",
+		Meta: map[string]interface{}{"region": "eu", "nested": map[string]string{"ok": "y"}},
+		Tags: []string{"", "b"},
+	}
+	if !reflect.DeepEqual(masked, expect) {
+		t.Errorf("expect %+v == %+v", masked, expect)
+	}
+
+	b, err := json.Marshal(JSON(val, opts...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); s != `{"User":"ada","Meta":{"nested":{"ok":"y"},"region":"eu"},"Tags":["","b"]}` {
+		t.Errorf("expect %v == %v", s, `{"User":"ada","Meta":{"nested":{"ok":"y"},"region":"eu"},"Tags":["","b"]}`)
+	}
+
+	cloned, err := Clone(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cloned.Password != "s3cr3t" || len(cloned.Meta) != 3 {
+		t.Errorf("expect clones to keep omitted values, got %+v", cloned)
+	}
+}
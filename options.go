@@ -0,0 +1,217 @@
+package mask
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+// Option configures a single call to Mask.
+type Option func(*options)
+
+type unsupportedMode int
+
+const (
+	failUnsupported unsupportedMode = iota
+	keepUnsupported
+	zeroUnsupported
+)
+
+type options struct {
+	unsupported    unsupportedMode
+	collectErrors  bool
+	maxDepth       int
+	maxElements    int
+	truncateLimits bool
+	// maxStringLen and maxSliceLen truncate values, see WithMaxStringLen and WithMaxSliceLen.
+	maxStringLen int
+	maxSliceLen  int
+	truncate     Strategy
+
+	preserveAliasing bool
+	preserveCapacity bool
+	pureMaskers      bool
+	skipEmbedded     bool
+
+	keyStrategies map[reflect.Type]Strategy
+	keyCollision  KeyCollision
+	sortMaps      bool
+
+	pathStrategies []pathStrategy
+	typeStrategies map[reflect.Type]Strategy
+	// interfaceStrategies mask values implementing interfaces, see WithMaskTypes.
+	interfaceStrategies []interfaceStrategy
+	detectors           []detectorRule
+	fields              fieldRules
+	hooks               []Hook
+	// transform replaces hooks and all other means of masking, see Transform.
+	transform func(Path, interface{}) (interface{}, bool)
+	// clone disables masking entirely, see Clone.
+	clone bool
+
+	session *Session
+	copier  *Copier
+
+	report  *Report
+	metrics MetricsSink
+	tracer  Tracer
+	// sampling is the fraction of calls running detectors, see WithSampling.
+	sampling *float64
+	// ctx aborts masking once done, see MaskCtx.
+	ctx context.Context
+	// partial returns partial results along with collected errors, see WithPartialResult.
+	partial bool
+	// marshalerCopies copies structs with unexported fields by marshaling, see WithMarshalerCopies.
+	marshalerCopies bool
+	// cloneMethods copies structs by their Clone or DeepCopy method, see WithCloneMethods.
+	cloneMethods bool
+	// tagKeys and tagAliases replace the mask tag, see WithTagKeys and WithTagAlias.
+	tagKeys    []string
+	tagAliases []tagAlias
+	// ruleSets resolves tags referencing the rule sets of a policy, see Policy.Options.
+	ruleSets *ruleSetResolver
+	// clearance fails masking fields classified above it, see WithClearance.
+	clearance *Classification
+	// reveal reverses strategies instead of applying them, see Unmask.
+	reveal *revealer
+	// watermark is added to the root object of JSON documents, see WithWatermark.
+	watermark *Watermark
+	// err is set by options which failed to be applied, e.g. because of an invalid path.
+	err error
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.sample()
+	return o
+}
+
+// WithSkipUnsupported copies channels, functions and unsafe pointers by reference
+// instead of failing. The copy shares them with the original value,
+// which allows masking structs holding callbacks or done-channels e.g. for logging.
+func WithSkipUnsupported() Option {
+	return func(o *options) {
+		o.unsupported = keepUnsupported
+	}
+}
+
+// WithZeroUnsupported replaces channels, functions and unsafe pointers
+// with their zero value (nil) instead of failing.
+func WithZeroUnsupported() Option {
+	return func(o *options) {
+		o.unsupported = zeroUnsupported
+	}
+}
+
+// isUnsupported reports whether values of kind k cannot be deep copied.
+func isUnsupported(k reflect.Kind) bool {
+	return k == reflect.Chan || k == reflect.Func || k == reflect.UnsafePointer
+}
+
+// WithCollectErrors keeps masking after a value failed to be masked.
+// Instead of aborting on the first problem, Mask returns a *MaskError
+// listing the path of every value which failed.
+func WithCollectErrors() Option {
+	return func(o *options) {
+		o.collectErrors = true
+	}
+}
+
+// WithMaxDepth limits how deeply nested values are traversed.
+// Every struct field, slice, array or map element counts as one level.
+// Masking fails with ErrMaxDepth for values nested deeper than n
+// unless WithTruncateLimits is used.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithMaxElements limits the total number of slice, array and map elements copied.
+// Masking fails with ErrMaxElements once more than n elements have been visited
+// unless WithTruncateLimits is used.
+func WithMaxElements(n int) Option {
+	return func(o *options) {
+		o.maxElements = n
+	}
+}
+
+// WithMaxStringLen cuts strings after n characters, marked by an ellipsis, and byte
+// slices after n bytes in the masked copy, keeping logs small; see maskers.Truncate.
+// Values masked by strategies and map keys are kept as they are.
+func WithMaxStringLen(n int) Option {
+	return func(o *options) {
+		o.maxStringLen = n
+		o.truncate = maskers.Truncate(n)
+	}
+}
+
+// WithMaxSliceLen keeps only the first n elements of slices in the masked copy,
+// other than byte slices, see WithMaxStringLen.
+func WithMaxSliceLen(n int) Option {
+	return func(o *options) {
+		o.maxSliceLen = n
+	}
+}
+
+// WithTruncateLimits truncates values exceeding WithMaxDepth or WithMaxElements
+// instead of failing: values nested too deeply are replaced by their zero value,
+// slices and maps only keep the elements copied before reaching the limit.
+func WithTruncateLimits() Option {
+	return func(o *options) {
+		o.truncateLimits = true
+	}
+}
+
+// WithPreserveAliasing shares copies of maps and slices referenced multiple times,
+// like copies of pointers are shared: if two fields reference the same map, their
+// copies reference the same copied map as well. Slices are considered the same
+// if they share type, length and underlying array start.
+// By default every reference gets an independent copy.
+func WithPreserveAliasing() Option {
+	return func(o *options) {
+		o.preserveAliasing = true
+	}
+}
+
+// WithPreserveCapacity creates copies of slices with the capacity of the original.
+// Elements between length and capacity are not copied.
+func WithPreserveCapacity() Option {
+	return func(o *options) {
+		o.preserveCapacity = true
+	}
+}
+
+// WithSortedMaps processes map entries in sorted key order instead of Go's
+// random map iteration order. This makes everything depending on the order
+// reproducible, e.g. renamed keys using KeyCollisionSuffix or the order of
+// errors collected using WithCollectErrors.
+func WithSortedMaps() Option {
+	return func(o *options) {
+		o.sortMaps = true
+	}
+}
+
+// WithSkipEmbeddedMaskers does not apply MaskXXX methods of embedded fields; their
+// fields are still masked by tags, strategies and the MaskXXX methods of their types.
+// By default, an embedded field is masked by the MaskXXX method of its type like any
+// other field, see Masker.
+func WithSkipEmbeddedMaskers() Option {
+	return func(o *options) {
+		o.skipEmbedded = true
+	}
+}
+
+// WithPureMaskers only accepts MaskXXX methods returning the masked value and
+// fails with ErrMutatingMasker for types masking themselves in place, i.e.
+// implementing Masker. In-place maskers are only ever called on a copy of the
+// original value; this mode rules out maskers relying on shared state entirely.
+func WithPureMaskers() Option {
+	return func(o *options) {
+		o.pureMaskers = true
+	}
+}
@@ -0,0 +1,55 @@
+package mask
+
+import (
+	"testing"
+)
+
+type testCallbacks struct {
+	Name     TestString
+	Done     chan struct{}
+	Callback func() string
+	Any      interface{}
+}
+
+func TestSkipUnsupported(t *testing.T) {
+	val := &testCallbacks{
+		Name:     "secret",
+		Done:     make(chan struct{}),
+		Callback: func() string { return "called" },
+		Any:      func() {},
+	}
+
+	if _, err := Mask(val); err == nil {
+		t.Errorf("expected an error without WithSkipUnsupported")
+	}
+
+	masked := Must(val, WithSkipUnsupported())
+	if masked.Name != "MASKED" {
+		t.Errorf("expect %v == MASKED", masked.Name)
+	}
+	if masked.Done != val.Done {
+		t.Errorf("expect channel to be copied by reference")
+	}
+	if masked.Callback == nil || masked.Callback() != "called" {
+		t.Errorf("expect callback to be copied by reference")
+	}
+	if masked.Any == nil {
+		t.Errorf("expect func in interface to be copied by reference")
+	}
+
+	masked = Must(val, WithZeroUnsupported())
+	if masked.Name != "MASKED" {
+		t.Errorf("expect %v == MASKED", masked.Name)
+	}
+	if masked.Done != nil || masked.Callback != nil {
+		t.Errorf("expect channel and callback to be nil")
+	}
+	if val.Done == nil || val.Callback == nil {
+		t.Errorf("expect original to stay untouched")
+	}
+
+	m := Must(map[string]func(){"a": func() {}}, WithZeroUnsupported())
+	if fn, ok := m["a"]; !ok || fn != nil {
+		t.Errorf("expect map entry to be kept and nil")
+	}
+}
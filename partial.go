@@ -0,0 +1,52 @@
+package mask
+
+import "reflect"
+
+// UnsupportedValue replaces channels, functions and unsafe pointers held by interfaces
+// using WithPartialResult. It formats as (unsupported chan), also as JSON.
+type UnsupportedValue struct {
+	Kind reflect.Kind
+}
+
+func (u UnsupportedValue) String() string {
+	return "(unsupported " + u.Kind.String() + ")"
+}
+
+// MarshalText encodes u like String.
+func (u UnsupportedValue) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// WithPartialResult masks as much as possible rather than losing the whole value,
+// e.g. a log entry, to a single problem. Like using WithCollectErrors, values which
+// fail to be masked are replaced by their zero value and Mask returns a *MaskError
+// listing them; but Mask returns the masked value along with it. Channels, functions
+// and unsafe pointers held by interfaces, e.g. in a map[string]any, are replaced
+// by an UnsupportedValue telling their kind.
+//
+// JSON encodes values which fail to be masked as null, unsupported values as their
+// UnsupportedValue, and reports no error.
+func WithPartialResult() Option {
+	return func(o *options) {
+		o.collectErrors = true
+		o.partial = true
+	}
+}
+
+// unsupported handles the channel, function or unsafe pointer v which cannot be copied.
+func (s *state) unsupported(v reflect.Value, err error) (interface{}, error) {
+	out, err := s.fail(v.Type(), err)
+	if err != nil || !s.opts.partial {
+		return out, err
+	}
+	return UnsupportedValue{Kind: v.Kind()}, nil
+}
+
+// held returns the copy item as value to be held by a slot of type t. An UnsupportedValue
+// cannot replace a value held by a slot of its original type, which is left zero instead.
+func held(item interface{}, t reflect.Type) reflect.Value {
+	if u, ok := item.(UnsupportedValue); ok && !reflect.TypeOf(u).AssignableTo(t) {
+		return reflect.Zero(t)
+	}
+	return reflect.ValueOf(item)
+}
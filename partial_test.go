@@ -0,0 +1,62 @@
+package mask
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWithPartialResult(t *testing.T) {
+	type entry struct {
+		Message  string
+		Password string `mask:"redact"`
+		Done     chan struct{}
+		Fields   map[string]interface{}
+		Hooks    []func()
+	}
+	val := entry{
+		Message:  "failed",
+		Password: "secret",
+		Done:     make(chan struct{}),
+		Fields:   map[string]interface{}{"user": "ada", "callback": func() {}},
+		Hooks:    []func(){func() {}},
+	}
+
+	out, err := Mask(val, WithPartialResult())
+	var maskErr *MaskError
+	if !errors.As(err, &maskErr) || len(maskErr.Errors) != 3 || !errors.Is(err, ErrUnsupportedKind) {
+		t.Fatalf("expect %v to list 3 unsupported values", err)
+	}
+	expect := entry{
+		Message:  "failed",
+		Password: "MASKED",
+		Fields:   map[string]interface{}{"user": "ada", "callback": UnsupportedValue{Kind: reflect.Func}},
+		Hooks:    []func(){nil},
+	}
+	if !reflect.DeepEqual(out, expect) {
+		t.Errorf("expect %+v == %+v", out, expect)
+	}
+
+	// without partial results, the value is lost
+	if out, err := Mask(val, WithCollectErrors()); err == nil || out.Message != "" {
+		t.Errorf("expect %+v to be lost, got %v", out, err)
+	}
+	if out, err := Mask(make(chan int), WithPartialResult()); err == nil || out != nil {
+		t.Errorf("expect %v == nil, got %v", out, err)
+	}
+}
+
+func TestWithPartialResultJSON(t *testing.T) {
+	val := map[string]interface{}{"user": "ada", "done": make(chan int)}
+	b, err := json.Marshal(JSON(val, WithPartialResult(), WithSortedMaps()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expect := `{"done":"(unsupported chan)","user":"ada"}`; string(b) != expect {
+		t.Errorf("expect %s == %s", b, expect)
+	}
+	if b, err := json.Marshal(UnsupportedValue{Kind: reflect.Func}); err != nil || string(b) != `"(unsupported func)"` {
+		t.Errorf("expect %s == \"(unsupported func)\", got %v", b, err)
+	}
+}
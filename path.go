@@ -0,0 +1,76 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// segment is a single step from a value to one of its children:
+// a struct field, a slice or array index or a map key.
+type segment struct {
+	field string
+	index int
+	key   interface{}
+}
+
+func (s segment) String() string {
+	switch {
+	case s.field != "":
+		return "." + s.field
+	case s.key != nil:
+		if k := reflect.ValueOf(s.key); k.Kind() == reflect.String {
+			return "[" + strconv.Quote(k.String()) + "]"
+		}
+		return fmt.Sprintf("[%v]", s.key)
+	}
+	return "[" + strconv.Itoa(s.index) + "]"
+}
+
+func (s *state) pushField(name string) {
+	s.visits++
+	s.path = append(s.path, segment{field: name})
+}
+
+func (s *state) pushIndex(i int) {
+	s.visits++
+	s.path = append(s.path, segment{index: i})
+}
+
+func (s *state) pushKey(k interface{}) {
+	s.visits++
+	s.path = append(s.path, segment{key: k})
+}
+
+func (s *state) pop() {
+	s.path = s.path[:len(s.path)-1]
+}
+
+// popN removes the last n segments of the path.
+func (s *state) popN(n int) {
+	s.path = s.path[:len(s.path)-n]
+}
+
+// currentPath formats the path to the value currently visited,
+// e.g. Order.Items[3].Card.Number.
+func (s *state) currentPath() string {
+	var b strings.Builder
+	b.WriteString(s.root)
+	for _, seg := range s.path {
+		b.WriteString(seg.String())
+	}
+	return strings.TrimPrefix(b.String(), ".")
+}
+
+// rootName names the root of all paths after the type of x, e.g. Order for an *Order.
+func rootName(x interface{}) string {
+	t := reflect.TypeOf(x)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	return t.Name()
+}
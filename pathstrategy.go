@@ -0,0 +1,171 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathPattern matches the path of a value relative to the masked root,
+// see WithPathStrategy for its syntax.
+type pathPattern []patternSegment
+
+type patternSegment struct {
+	// name matches a struct field or a map key formatting to it.
+	name string
+	// any matches a single segment, deep any number of segments.
+	any, deep bool
+}
+
+type pathStrategy struct {
+	pattern  pathPattern
+	strategy Strategy
+}
+
+// WithPathStrategy masks all values located by pattern using strategy, regardless
+// of their type. This allows masking values without tags, e.g. decoded JSON.
+//
+// Patterns are relative to the masked value and consist of field names or map keys,
+// separated by dots, and indices or keys in brackets. * matches any single
+// field, key or index, ** any number of them:
+//
+//	Items[*].Card.Number
+//	users[0]["e-mail"]
+//	**.password
+//
+// Struct tags take precedence over path strategies. An invalid pattern fails
+// Mask with ErrInvalidPath.
+func WithPathStrategy(pattern string, strategy Strategy) Option {
+	p, err := parsePathPattern(pattern)
+	return func(o *options) {
+		if err != nil {
+			o.err = err
+			return
+		}
+		o.pathStrategies = append(o.pathStrategies, pathStrategy{pattern: p, strategy: strategy})
+	}
+}
+
+func parsePathPattern(pattern string) (pathPattern, error) {
+	var p pathPattern
+	rest := pattern
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			if len(p) == 0 {
+				return nil, fmt.Errorf("%w %q: unexpected leading dot", ErrInvalidPath, pattern)
+			}
+			rest = rest[1:]
+			name := rest
+			if i := strings.IndexAny(rest, ".["); i >= 0 {
+				name = rest[:i]
+			}
+			if name == "" {
+				return nil, fmt.Errorf("%w %q: empty name", ErrInvalidPath, pattern)
+			}
+			p = append(p, namePattern(name))
+			rest = rest[len(name):]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if strings.HasPrefix(rest, `["`) {
+				quoted, err := strconv.QuotedPrefix(rest[1:])
+				if err != nil {
+					return nil, fmt.Errorf("%w %q: %v", ErrInvalidPath, pattern, err)
+				}
+				end = 1 + len(quoted)
+				if end >= len(rest) || rest[end] != ']' {
+					return nil, fmt.Errorf("%w %q: missing ]", ErrInvalidPath, pattern)
+				}
+				key, _ := strconv.Unquote(quoted)
+				p = append(p, patternSegment{name: key})
+				rest = rest[end+1:]
+				continue
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("%w %q: missing ]", ErrInvalidPath, pattern)
+			}
+			p = append(p, namePattern(rest[1:end]))
+			rest = rest[end+1:]
+		default:
+			if len(p) != 0 {
+				return nil, fmt.Errorf("%w %q: unexpected %q", ErrInvalidPath, pattern, rest)
+			}
+			name := rest
+			if i := strings.IndexAny(rest, ".["); i >= 0 {
+				name = rest[:i]
+			}
+			p = append(p, namePattern(name))
+			rest = rest[len(name):]
+		}
+	}
+	if len(p) == 0 {
+		return nil, fmt.Errorf("%w %q: empty pattern", ErrInvalidPath, pattern)
+	}
+	return p, nil
+}
+
+func namePattern(name string) patternSegment {
+	switch name {
+	case "*":
+		return patternSegment{any: true}
+	case "**":
+		return patternSegment{deep: true}
+	}
+	return patternSegment{name: name}
+}
+
+func (p pathPattern) match(path []segment) bool {
+	if len(p) == 0 {
+		return len(path) == 0
+	}
+	if p[0].deep {
+		for i := 0; i <= len(path); i++ {
+			if p[1:].match(path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 || !p[0].matchSegment(path[0]) {
+		return false
+	}
+	return p[1:].match(path[1:])
+}
+
+func (p patternSegment) matchSegment(s segment) bool {
+	switch {
+	case p.any:
+		return true
+	case s.field != "":
+		return p.name == s.field
+	case s.key != nil:
+		return p.name == fmt.Sprint(s.key)
+	}
+	return p.name == strconv.Itoa(s.index)
+}
+
+// pathStrategy returns the strategy of the first path strategy matching the current path, if any.
+// Map keys are never matched: their path is the one of their value.
+func (s *state) pathStrategy() Strategy {
+	if s.inKey || len(s.path) == 0 {
+		return nil
+	}
+	for _, ps := range s.opts.pathStrategies {
+		if ps.pattern.match(s.path) {
+			return ps.strategy
+		}
+	}
+	return nil
+}
+
+// _path masks x using strategy, which matched the current path or value.
+func _path(x interface{}, strategy Strategy, s *state) (interface{}, error) {
+	v := reflect.ValueOf(x)
+	masked, err := s.applyStrategy(strategy, v)
+	if err != nil {
+		return s.fail(v.Type(), err)
+	}
+	s.masked(strategy.Name())
+	return masked.Interface(), nil
+}
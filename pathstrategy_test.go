@@ -0,0 +1,109 @@
+package mask
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+func TestPathStrategy(t *testing.T) {
+	val := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"email": "ada@example.com", "age": 36.0},
+			map[string]interface{}{"email": "charles@example.com", "age": 79.0},
+		},
+		"email": "admin@example.com",
+	}
+	redact, err := ParseStrategy("redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial := maskers.Partial(1, 0, maskers.Format{})
+
+	masked := Must(val, WithPathStrategy(`users[*]["email"]`, partial), WithPathStrategy("users[1].age", redact))
+	users := masked["users"].([]interface{})
+	if email := users[0].(map[string]interface{})["email"]; email != "a**************" {
+		t.Errorf("expect %v == a**************", email)
+	}
+	if age := users[1].(map[string]interface{})["age"]; age != 0.0 {
+		t.Errorf("expect %v == 0", age)
+	}
+	if age := users[0].(map[string]interface{})["age"]; age != 36.0 {
+		t.Errorf("expect %v == 36", age)
+	}
+	if masked["email"] != "admin@example.com" {
+		t.Errorf("expect %v to stay untouched", masked["email"])
+	}
+	if _, ok := users[0].(map[string]interface{})["email"]; !ok {
+		t.Errorf("expect map keys not to be masked")
+	}
+
+	masked = Must(val, WithPathStrategy("**.email", redact))
+	users = masked["users"].([]interface{})
+	if masked["email"] != "MASKED" || users[1].(map[string]interface{})["email"] != "MASKED" {
+		t.Errorf("expect all emails to be masked, got %v", masked)
+	}
+	if val["email"] != "admin@example.com" {
+		t.Errorf("expect the original to stay untouched")
+	}
+}
+
+func TestPathStrategyStruct(t *testing.T) {
+	val := testPerson{Name: "Ada Lovelace", Email: "ada@example.com"}
+	masked, report, err := MaskWithReport(val, WithPathStrategy("Email", maskers.Partial(1, 0, maskers.Format{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if masked.Email != "a**************" {
+		t.Errorf("expect %v == a**************", masked.Email)
+	}
+	if counts := report.CountByStrategy(); counts["partial"] != 1 {
+		t.Errorf("expect path strategies to be reported, got %v", report.Entries)
+	}
+
+	findings, err := Scan(val, WithPathStrategy("Email", maskers.Partial(1, 0, maskers.Format{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, f := range findings {
+		found = found || f.Path == "testPerson.Email" && f.Source == SourcePath
+	}
+	if !found {
+		t.Errorf("expect Email to be found, got %v", findings)
+	}
+}
+
+func TestPathPattern(t *testing.T) {
+	for _, pattern := range []string{"", ".a", "a..b", "a[0", `a["b]`, "a[0]b"} {
+		if _, err := parsePathPattern(pattern); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("expect %q to be invalid, got %v", pattern, err)
+		}
+	}
+	if _, err := Mask(testPerson{}, WithPathStrategy("a[", nil)); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expect %v to be ErrInvalidPath", err)
+	}
+
+	path := []segment{{field: "Items"}, {index: 3}, {key: "card"}, {field: "Number"}}
+	for pattern, expect := range map[string]bool{
+		"Items[3].card.Number":     true,
+		`Items[*]["card"].Number`:  true,
+		"**.Number":                true,
+		"**":                       true,
+		"Items.**.Number":          true,
+		"*.*.*.*":                  true,
+		"Items[2].card.Number":     false,
+		"Items[3].card":            false,
+		"**.card":                  false,
+		"Items[3].card.Number.foo": false,
+	} {
+		p, err := parsePathPattern(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.match(path) != expect {
+			t.Errorf("expect %q matching %v", pattern, expect)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+// DefaultPlaceholder replaces strings redacted by the "redact" strategy
+// unless a different placeholder has been registered.
+const DefaultPlaceholder = "MASKED"
+
+var placeholders = newRegistry(map[reflect.Type]reflect.Value{
+	reflect.TypeOf(""): reflect.ValueOf(DefaultPlaceholder),
+})
+
+// RegisterPlaceholder sets the value replacing redacted values of type T,
+// e.g. "[REDACTED]" for strings, -1 for ints or a fixed time.Time.
+// Placeholders registered for predeclared types (string, int, float64, ...)
+// apply to all types of the same kind without a placeholder of their own,
+// e.g. the string placeholder to a `type Email string`.
+// Values without placeholder are replaced by their zero value.
+// It is safe to register placeholders while masking.
+func RegisterPlaceholder[T any](placeholder T) {
+	placeholders.store(reflect.TypeOf((*T)(nil)).Elem(), reflect.ValueOf(placeholder))
+}
+
+// Placeholder returns the placeholder for redacted values of type T.
+// Use it in MaskXXX implementations rather than hardcoding a replacement:
+//
+//	func (e Email) MaskXXX() Email {
+//	  return mask.Placeholder[Email]()
+//	}
+func Placeholder[T any]() T {
+	var t T
+	p, _ := placeholderFor(reflect.TypeOf(&t).Elem()).Interface().(T)
+	return p
+}
+
+// placeholderFor returns the placeholder for values of type t.
+func placeholderFor(t reflect.Type) reflect.Value {
+	if p, ok := placeholders.load(t); ok {
+		return p
+	}
+	if basic, ok := basicTypes[t.Kind()]; ok {
+		if p, ok := placeholders.load(basic); ok {
+			return p.Convert(t)
+		}
+	}
+	return reflect.Zero(t)
+}
+
+var basicTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:       reflect.TypeOf(false),
+	reflect.Int:        reflect.TypeOf(int(0)),
+	reflect.Int8:       reflect.TypeOf(int8(0)),
+	reflect.Int16:      reflect.TypeOf(int16(0)),
+	reflect.Int32:      reflect.TypeOf(int32(0)),
+	reflect.Int64:      reflect.TypeOf(int64(0)),
+	reflect.Uint:       reflect.TypeOf(uint(0)),
+	reflect.Uint8:      reflect.TypeOf(uint8(0)),
+	reflect.Uint16:     reflect.TypeOf(uint16(0)),
+	reflect.Uint32:     reflect.TypeOf(uint32(0)),
+	reflect.Uint64:     reflect.TypeOf(uint64(0)),
+	reflect.Uintptr:    reflect.TypeOf(uintptr(0)),
+	reflect.Float32:    reflect.TypeOf(float32(0)),
+	reflect.Float64:    reflect.TypeOf(float64(0)),
+	reflect.Complex64:  reflect.TypeOf(complex64(0)),
+	reflect.Complex128: reflect.TypeOf(complex128(0)),
+	reflect.String:     reflect.TypeOf(""),
+}
+
+// redactStrategy replaces values by their registered placeholder,
+// or by the placeholder given as tag argument, e.g. `mask:"redact=***"`.
+func redactStrategy(arg string) (Strategy, error) {
+	if arg == "" {
+		return maskers.Func("redact", func(v reflect.Value) (reflect.Value, error) {
+			return placeholderFor(v.Type()), nil
+		}), nil
+	}
+	return maskers.Func("redact", func(v reflect.Value) (reflect.Value, error) {
+		return parsePlaceholder(arg, v.Type())
+	}), nil
+}
+
+// parsePlaceholder parses the placeholder s given in a tag as a value of type t.
+func parsePlaceholder(s string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	var err error
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, t.Bits())
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		u, err = strconv.ParseUint(s, 10, t.Bits())
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, t.Bits())
+		v.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: placeholder %q cannot be used for %v", ErrInvalidTag, s, t)
+	}
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: placeholder %q cannot be used for %v: %v", ErrInvalidTag, s, t, err)
+	}
+	return v, nil
+}
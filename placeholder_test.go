@@ -0,0 +1,65 @@
+package mask
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testPlaceholderString string
+
+func (t testPlaceholderString) MaskXXX() testPlaceholderString {
+	return Placeholder[testPlaceholderString]()
+}
+
+type testPlaceholderLevel int16
+
+func TestRedact(t *testing.T) {
+	type S struct {
+		Name     string    `mask:"redact"`
+		Custom   string    `mask:"redact=***"`
+		Number   int       `mask:"redact=-1"`
+		Ratio    float64   `mask:"redact"`
+		Time     time.Time `mask:"redact"`
+		Level    testPlaceholderLevel
+		LevelTag testPlaceholderLevel `mask:"redact"`
+		Other    testPlaceholderString
+	}
+	RegisterPlaceholder[testPlaceholderString]("[REDACTED]")
+	RegisterPlaceholder[int16](-1)
+
+	masked := Must(S{
+		Name:     "name",
+		Custom:   "custom",
+		Number:   42,
+		Ratio:    0.5,
+		Time:     time.Now(),
+		Level:    3,
+		LevelTag: 3,
+		Other:    "other",
+	})
+	expected := S{
+		Name:     DefaultPlaceholder,
+		Custom:   "***",
+		Number:   -1,
+		Level:    3,
+		LevelTag: -1,
+		Other:    "[REDACTED]",
+	}
+	if masked != expected {
+		t.Errorf("expect %v == %v", masked, expected)
+	}
+
+	type Invalid struct {
+		Time time.Time `mask:"redact=now"`
+	}
+	if _, err := Mask(Invalid{}); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expect %v to be ErrInvalidTag", err)
+	}
+	type InvalidNumber struct {
+		N uint8 `mask:"redact=-1"`
+	}
+	if _, err := Mask(InvalidNumber{}); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expect %v to be ErrInvalidTag", err)
+	}
+}
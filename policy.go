@@ -0,0 +1,190 @@
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/doejon/go-mask/detect"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is a declarative set of masking rules. Policies are usually loaded from
+// files using LoadPolicy, so masking rules live in configuration reviewed by
+// security rather than being scattered across the code:
+//
+//	version: "2024-06"
+//	rules:
+//	  - path: "**.email"
+//	    strategy: partial=1:1
+//	  - path: "**.notes"
+//	    strategy: redact
+//	    audiences: [analytics]
+//	detectors:
+//	  - name: iban
+//	    pattern: '\b[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}\b'
+//	    strategy: redact
+type Policy struct {
+	// Version identifies the policy, e.g. in watermarks and DiffPolicies.
+	Version   string           `json:"version,omitempty" yaml:"version,omitempty"`
+	Rules     []PolicyRule     `json:"rules,omitempty" yaml:"rules,omitempty"`
+	Detectors []PolicyDetector `json:"detectors,omitempty" yaml:"detectors,omitempty"`
+	// RuleSets defines named rule sets referenced by rules and struct tags as
+	// "ruleset=<name>", see RegisterRuleSet.
+	RuleSets map[string]*Policy `json:"rulesets,omitempty" yaml:"rulesets,omitempty"`
+	// Clearances maps audiences to the classification they may receive, e.g.
+	// "analytics: internal", see WithClearance. Audiences without clearance
+	// receive fields of any classification.
+	Clearances map[string]string `json:"clearances,omitempty" yaml:"clearances,omitempty"`
+}
+
+// PolicyRule masks the values located by Path, see WithPathStrategy.
+type PolicyRule struct {
+	Path string `json:"path" yaml:"path"`
+	// Strategy is referenced like in struct tags, e.g. "partial=1:1".
+	Strategy string `json:"strategy" yaml:"strategy"`
+	// Audiences restricts the rule to the given audiences; it applies to all of them if empty.
+	Audiences []string `json:"audiences,omitempty" yaml:"audiences,omitempty"`
+}
+
+// PolicyDetector masks all strings matching Pattern regardless of their location,
+// e.g. to catch credentials in free text fields.
+type PolicyDetector struct {
+	Name string `json:"name" yaml:"name"`
+	// Pattern is a regular expression in the syntax of package regexp.
+	Pattern string `json:"pattern" yaml:"pattern"`
+	// Strategy is referenced like in struct tags, e.g. "redact".
+	Strategy string `json:"strategy" yaml:"strategy"`
+	// Audiences restricts the detector to the given audiences; it applies to all of them if empty.
+	Audiences []string `json:"audiences,omitempty" yaml:"audiences,omitempty"`
+}
+
+// ErrInvalidPolicy is returned for policies which cannot be decoded or applied.
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// LoadPolicy decodes and validates a YAML or JSON policy document.
+// Unknown fields are rejected to catch typos in rules, empty documents
+// are rejected as they would silently mask nothing.
+func LoadPolicy(r io.Reader) (*Policy, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var p Policy
+	if err := dec.Decode(&p); errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: empty document", ErrInvalidPolicy)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks all paths, patterns and strategies of p. Policies without
+// rules, rule sets, detectors or clearances are rejected as they would mask nothing.
+func (p *Policy) Validate() error {
+	if p.empty() {
+		return fmt.Errorf("%w: no rules, rule sets, detectors or clearances", ErrInvalidPolicy)
+	}
+	_, err := p.options("", true)
+	return err
+}
+
+func (p *Policy) empty() bool {
+	return p == nil || len(p.Rules) == 0 && len(p.RuleSets) == 0 && len(p.Detectors) == 0 && len(p.Clearances) == 0
+}
+
+// Options returns the options applying the rules and detectors of p for audience.
+// An empty audience applies only rules and detectors without audiences. Rules and
+// struct tags referencing the rule sets of p resolve them before registered ones.
+func (p *Policy) Options(audience string) ([]Option, error) {
+	return p.options(audience, false)
+}
+
+func (p *Policy) options(audience string, all bool) ([]Option, error) {
+	return p.optionsWithin(audience, all, nil)
+}
+
+// optionsWithin returns the options of p, resolving rule sets not defined by p using
+// parent, i.e. the rule sets of the policy defining p as a rule set.
+func (p *Policy) optionsWithin(audience string, all bool, parent *ruleSetResolver) ([]Option, error) {
+	// rules and tags resolve the rule sets of p, which are not registered
+	resolver := &ruleSetResolver{sets: p.RuleSets, parent: parent}
+	for name, set := range p.RuleSets {
+		// empty rule sets decode to nil
+		if set.empty() {
+			return nil, fmt.Errorf("rule set %s: %w: no rules, rule sets, detectors or clearances", name, ErrInvalidPolicy)
+		}
+		if _, err := set.optionsWithin("", true, resolver); err != nil {
+			return nil, fmt.Errorf("rule set %s: %w", name, err)
+		}
+	}
+	var opts []Option
+	if len(p.RuleSets) > 0 || parent != nil {
+		opts = append(opts, func(o *options) {
+			o.ruleSets = resolver
+		})
+	}
+	for a, name := range p.Clearances {
+		c, err := ParseClassification(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: clearance of %s: %w", ErrInvalidPolicy, a, err)
+		}
+		if a == audience {
+			opts = append(opts, WithClearance(c))
+		}
+	}
+	for i, r := range p.Rules {
+		if !all && !forAudience(r.Audiences, audience) {
+			continue
+		}
+		if r.Path == "" || r.Strategy == "" {
+			return nil, fmt.Errorf("%w: rule %d: path and strategy are required", ErrInvalidPolicy, i+1)
+		}
+		strategy, err := resolver.parse(r.Strategy)
+		if err != nil {
+			return nil, fmt.Errorf("%w: rule %d: %w", ErrInvalidPolicy, i+1, err)
+		}
+		pattern, err := parsePathPattern(r.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: rule %d: %w", ErrInvalidPolicy, i+1, err)
+		}
+		opts = append(opts, func(o *options) {
+			o.pathStrategies = append(o.pathStrategies, pathStrategy{pattern: pattern, strategy: strategy})
+		})
+	}
+	for i, d := range p.Detectors {
+		if !all && !forAudience(d.Audiences, audience) {
+			continue
+		}
+		if d.Pattern == "" || d.Strategy == "" {
+			return nil, fmt.Errorf("%w: detector %d: pattern and strategy are required", ErrInvalidPolicy, i+1)
+		}
+		strategy, err := resolver.parse(d.Strategy)
+		if err != nil {
+			return nil, fmt.Errorf("%w: detector %d: %w", ErrInvalidPolicy, i+1, err)
+		}
+		re, err := regexp.Compile(d.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: detector %d: %w", ErrInvalidPolicy, i+1, err)
+		}
+		rule := detectorRule{detector: detect.Regexp(d.Name, re), strategy: strategy, whole: true}
+		opts = append(opts, func(o *options) {
+			o.detectors = append(o.detectors, rule)
+		})
+	}
+	return opts, nil
+}
+
+func forAudience(audiences []string, audience string) bool {
+	if len(audiences) == 0 {
+		return true
+	}
+	for _, a := range audiences {
+		if a == audience {
+			return true
+		}
+	}
+	return false
+}
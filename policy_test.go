@@ -0,0 +1,87 @@
+package mask
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `
+rules:
+  - path: "**.email"
+    strategy: partial=1:0
+  - path: "**.notes"
+    strategy: redact
+    audiences: [analytics]
+detectors:
+  - name: token
+    pattern: 'tok_[a-z0-9]+'
+    strategy: redact
+`
+
+func TestLoadPolicy(t *testing.T) {
+	p, err := LoadPolicy(strings.NewReader(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Rules) != 2 || len(p.Detectors) != 1 || p.Rules[1].Audiences[0] != "analytics" {
+		t.Fatalf("expect policy to be decoded, got %+v", p)
+	}
+
+	val := map[string]interface{}{
+		"email": "ada@example.com",
+		"notes": "call back",
+		"meta":  map[string]string{"auth": "Bearer tok_abc123"},
+	}
+	opts, err := p.Options("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	masked := Must(val, opts...)
+	if masked["email"] != "a**************" || masked["notes"] != "call back" {
+		t.Errorf("expect only rules for all audiences to be applied, got %v", masked)
+	}
+	if auth := masked["meta"].(map[string]string)["auth"]; auth != "MASKED" {
+		t.Errorf("expect %v to be detected", auth)
+	}
+
+	opts, err = p.Options("analytics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if masked := Must(val, opts...); masked["notes"] != "MASKED" {
+		t.Errorf("expect %v == MASKED", masked["notes"])
+	}
+}
+
+func TestLoadPolicyJSON(t *testing.T) {
+	p, err := LoadPolicy(strings.NewReader(`{"rules": [{"path": "Email", "strategy": "redact"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := p.Options("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if masked := Must(testPerson{Email: "ada@example.com"}, opts...); masked.Email != "MASKED" {
+		t.Errorf("expect %v == MASKED", masked.Email)
+	}
+}
+
+func TestLoadPolicyInvalid(t *testing.T) {
+	for _, policy := range []string{
+		"rules: [{path: email, strategy: does-not-exist}]",
+		"rules: [{path: '.email', strategy: redact}]",
+		"detectors: [{name: x, pattern: '(', strategy: redact}]",
+		"rules: [{path: email, stratgy: redact}]",
+		"rules: email",
+		"",
+		"# no rules yet\n",
+		"{}",
+		"version: v1\nrules: []\n",
+	} {
+		if _, err := LoadPolicy(strings.NewReader(policy)); !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("expect %v to be ErrInvalidPolicy for %q", err, policy)
+		}
+	}
+}
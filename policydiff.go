@@ -0,0 +1,253 @@
+package mask
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PolicyDiff lists the changes between two policies, see DiffPolicies.
+type PolicyDiff struct {
+	// From and To are the versions of the policies compared.
+	From, To string
+	Changes  []PolicyChange
+}
+
+// PolicyChange describes a rule, detector, rule set or clearance which was added,
+// removed or changed.
+type PolicyChange struct {
+	// Kind is "added", "removed" or "changed".
+	Kind string
+	// Item locates the change, e.g. `rule "**.email"` or `clearance "analytics"`.
+	Item string
+	// From and To describe the item before and after the change; From is empty
+	// for added items, To for removed ones.
+	From, To string
+	// Reduces is set for changes possibly masking less than before, e.g. removed
+	// rules or rules changing their strategy to one not known to be as strong.
+	Reduces bool
+}
+
+func (c PolicyChange) String() string {
+	switch c.Kind {
+	case "added":
+		return fmt.Sprintf("+ %s: %s", c.Item, c.To)
+	case "removed":
+		return fmt.Sprintf("- %s: %s", c.Item, c.From)
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", c.Item, c.From, c.To)
+}
+
+// DiffPolicies compares the policies a and b, e.g. the policy of the last release
+// and the one about to be released, so changes of masking coverage can be reviewed:
+//
+//	diff := mask.DiffPolicies(released, proposed)
+//	if diff.ReducesCoverage() {
+//		log.Fatalf("policy %s masks less than %s:\n%v", diff.To, diff.From, diff)
+//	}
+//
+// Rules are identified by their path and audiences, detectors by their name and rule
+// sets by their name. Changing the audiences of a rule removes it and adds a new one.
+func DiffPolicies(a, b *Policy) *PolicyDiff {
+	d := &PolicyDiff{From: a.Version, To: b.Version}
+	d.diff("rule", policyRules(a), policyRules(b), atLeastAsStrong)
+	d.diff("detector", policyDetectors(a), policyDetectors(b), func(from, to string) bool {
+		// detectors are described by their strategy and pattern
+		fromStrategy, fromPattern, _ := strings.Cut(from, " /")
+		toStrategy, toPattern, _ := strings.Cut(to, " /")
+		return fromPattern == toPattern && atLeastAsStrong(fromStrategy, toStrategy)
+	})
+	for _, name := range unionKeys(a.RuleSets, b.RuleSets) {
+		item := fmt.Sprintf("rule set %q", name)
+		from, inA := a.RuleSets[name]
+		to, inB := b.RuleSets[name]
+		// empty rule sets decode to nil and are rejected by Validate, but may be diffed
+		if from == nil {
+			from = &Policy{}
+		}
+		if to == nil {
+			to = &Policy{}
+		}
+		switch {
+		case !inA:
+			d.Changes = append(d.Changes, PolicyChange{Kind: "added", Item: item, To: describeRuleSet(to)})
+		case !inB:
+			d.Changes = append(d.Changes, PolicyChange{Kind: "removed", Item: item, From: describeRuleSet(from), Reduces: true})
+		default:
+			for _, c := range DiffPolicies(from, to).Changes {
+				c.Item = item + " " + c.Item
+				d.Changes = append(d.Changes, c)
+			}
+		}
+	}
+	for _, audience := range unionKeys(a.Clearances, b.Clearances) {
+		item := fmt.Sprintf("clearance %q", audience)
+		from, inA := a.Clearances[audience]
+		to, inB := b.Clearances[audience]
+		switch {
+		case !inA:
+			d.Changes = append(d.Changes, PolicyChange{Kind: "added", Item: item, To: to})
+		case !inB:
+			d.Changes = append(d.Changes, PolicyChange{Kind: "removed", Item: item, From: from, Reduces: true})
+		case !strings.EqualFold(from, to):
+			fc, _ := ParseClassification(from)
+			tc, _ := ParseClassification(to)
+			d.Changes = append(d.Changes, PolicyChange{Kind: "changed", Item: item, From: from, To: to, Reduces: tc > fc})
+		}
+	}
+	return d
+}
+
+// diff adds the changes between the items a and b, described by their key.
+// Changes reduce coverage unless stronger reports the new description to mask at
+// least as much as the previous one.
+func (d *PolicyDiff) diff(kind string, a, b map[string]string, stronger func(from, to string) bool) {
+	for _, key := range unionKeys(a, b) {
+		item := fmt.Sprintf("%s %s", kind, key)
+		from, inA := a[key]
+		to, inB := b[key]
+		switch {
+		case !inA:
+			d.Changes = append(d.Changes, PolicyChange{Kind: "added", Item: item, To: to})
+		case !inB:
+			d.Changes = append(d.Changes, PolicyChange{Kind: "removed", Item: item, From: from, Reduces: true})
+		case from != to:
+			d.Changes = append(d.Changes, PolicyChange{Kind: "changed", Item: item, From: from, To: to, Reduces: !joinedStronger(from, to, stronger)})
+		}
+	}
+}
+
+// joinedStronger applies stronger to the descriptions of items sharing a key pairwise,
+// see joinDescription.
+func joinedStronger(from, to string, stronger func(from, to string) bool) bool {
+	froms, tos := strings.Split(from, "; "), strings.Split(to, "; ")
+	if len(froms) != len(tos) {
+		return false
+	}
+	for i := range froms {
+		if !stronger(froms[i], tos[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// atLeastAsStrong reports whether the strategy to is known to mask at least as much as
+// the strategy from: omitting beats any strategy, redacting any but omitting, and partial
+// masks revealing no more characters than before beat partial masks. Any other change
+// may reveal more, e.g. changing redact to trim.
+func atLeastAsStrong(from, to string) bool {
+	if from == to {
+		return true
+	}
+	switch to {
+	case "omit", "-":
+		return true
+	case "redact":
+		return from != "omit" && from != "-"
+	}
+	fromStart, fromEnd, ok := partialArgs(from)
+	if !ok {
+		return false
+	}
+	toStart, toEnd, ok := partialArgs(to)
+	return ok && toStart <= fromStart && toEnd <= fromEnd
+}
+
+// partialArgs returns the numbers of characters kept by the partial strategy.
+func partialArgs(strategy string) (start, end int, ok bool) {
+	name, arg, _ := strings.Cut(strategy, "=")
+	if name != "partial" {
+		return 0, 0, false
+	}
+	if arg == "" {
+		return 1, 1, true
+	}
+	s, e, ok := strings.Cut(arg, ":")
+	start, err1 := strconv.Atoi(s)
+	end, err2 := strconv.Atoi(e)
+	return start, end, ok && err1 == nil && err2 == nil
+}
+
+// Empty reports whether the policies compared are equivalent.
+func (d *PolicyDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// ReducesCoverage reports whether any change masks fewer values than before,
+// e.g. to fail CI pipelines unless such changes are approved.
+func (d *PolicyDiff) ReducesCoverage() bool {
+	for _, c := range d.Changes {
+		if c.Reduces {
+			return true
+		}
+	}
+	return false
+}
+
+// String lists the changes, one per line.
+func (d *PolicyDiff) String() string {
+	lines := make([]string, len(d.Changes))
+	for i, c := range d.Changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// policyRules describes the strategies of the rules of p by their path and audiences.
+func policyRules(p *Policy) map[string]string {
+	rules := make(map[string]string, len(p.Rules))
+	for _, r := range p.Rules {
+		key := fmt.Sprintf("%q", r.Path)
+		if len(r.Audiences) > 0 {
+			audiences := append([]string(nil), r.Audiences...)
+			sort.Strings(audiences)
+			key += fmt.Sprintf(" for %s", strings.Join(audiences, ", "))
+		}
+		rules[key] = joinDescription(rules[key], r.Strategy)
+	}
+	return rules
+}
+
+// policyDetectors describes the patterns and strategies of the detectors of p by their name and audiences.
+func policyDetectors(p *Policy) map[string]string {
+	detectors := make(map[string]string, len(p.Detectors))
+	for _, d := range p.Detectors {
+		key := fmt.Sprintf("%q", d.Name)
+		if len(d.Audiences) > 0 {
+			audiences := append([]string(nil), d.Audiences...)
+			sort.Strings(audiences)
+			key += fmt.Sprintf(" for %s", strings.Join(audiences, ", "))
+		}
+		detectors[key] = joinDescription(detectors[key], fmt.Sprintf("%s /%s/", d.Strategy, d.Pattern))
+	}
+	return detectors
+}
+
+// joinDescription joins the descriptions of items sharing a key.
+func joinDescription(desc, item string) string {
+	if desc == "" {
+		return item
+	}
+	return desc + "; " + item
+}
+
+func describeRuleSet(p *Policy) string {
+	return fmt.Sprintf("%d rule(s), %d detector(s)", len(p.Rules), len(p.Detectors))
+}
+
+// unionKeys returns the keys of a and b, sorted.
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package mask
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func loadTestPolicy(t *testing.T, doc string) *Policy {
+	t.Helper()
+	p, err := LoadPolicy(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDiffPolicies(t *testing.T) {
+	a := loadTestPolicy(t, `
+version: "1"
+rules:
+  - path: "**.email"
+    strategy: partial=1:1
+  - path: "**.notes"
+    strategy: redact
+    audiences: [support, analytics]
+detectors:
+  - name: token
+    pattern: 'tok_[a-z0-9]+'
+    strategy: redact
+clearances:
+  analytics: internal
+`)
+	b := loadTestPolicy(t, `
+version: "2"
+rules:
+  - path: "**.email"
+    strategy: partial=1:0
+  - path: "**.notes"
+    strategy: redact
+    audiences: [analytics, support]
+  - path: "**.phone"
+    strategy: redact
+clearances:
+  analytics: confidential
+`)
+	d := DiffPolicies(a, b)
+	expect := strings.Join([]string{
+		`~ rule "**.email": partial=1:1 -> partial=1:0`,
+		`+ rule "**.phone": redact`,
+		`- detector "token": redact /tok_[a-z0-9]+/`,
+		`~ clearance "analytics": internal -> confidential`,
+	}, "\n")
+	if d.String() != expect {
+		t.Errorf("expect %s == %s", d, expect)
+	}
+	if d.From != "1" || d.To != "2" || !d.ReducesCoverage() {
+		t.Errorf("expect %+v to reduce coverage from 1 to 2", d)
+	}
+
+	d = DiffPolicies(b, a)
+	if !d.ReducesCoverage() {
+		t.Errorf("expect %v to reduce coverage", d)
+	}
+	if d = DiffPolicies(a, a); !d.Empty() || d.ReducesCoverage() {
+		t.Errorf("expect %v to be empty", d)
+	}
+}
+
+func TestDiffPoliciesStrategies(t *testing.T) {
+	for _, tc := range []struct {
+		from, to string
+		reduces  bool
+	}{
+		{"redact", "trim", true},
+		{"redact", "partial=0:100", true},
+		{"redact", "hash", true},
+		{"omit", "redact", true},
+		{"partial=1:1", "partial=2:0", true},
+		{"partial", "partial=0:4", true},
+		{"partial=1:1", "partial=1:0", false},
+		{"partial", "partial=0:0", false},
+		{"trim", "redact", false},
+		{"redact", "omit", false},
+		{"hash", "-", false},
+	} {
+		policy := func(strategy string) *Policy {
+			return loadTestPolicy(t, fmt.Sprintf(`
+rules:
+  - path: "**.email"
+    strategy: %q
+detectors:
+  - name: token
+    pattern: 'tok_[a-z0-9]+'
+    strategy: %q
+`, strategy, strategy))
+		}
+		d := DiffPolicies(policy(tc.from), policy(tc.to))
+		if len(d.Changes) != 2 || d.Changes[0].Reduces != tc.reduces || d.Changes[1].Reduces != tc.reduces {
+			t.Errorf("expect %s -> %s to reduce coverage: %v, got %+v", tc.from, tc.to, tc.reduces, d.Changes)
+		}
+	}
+
+	a := loadTestPolicy(t, "detectors: [{name: token, pattern: 'tok_[a-z0-9]+', strategy: redact}]")
+	b := loadTestPolicy(t, "detectors: [{name: token, pattern: 'tok_[a-z]+', strategy: omit}]")
+	if d := DiffPolicies(a, b); !d.ReducesCoverage() {
+		t.Errorf("expect changing the pattern of %v to reduce coverage", d)
+	}
+}
+
+func TestDiffPoliciesRuleSets(t *testing.T) {
+	a := loadTestPolicy(t, `
+rulesets:
+  pci:
+    rules:
+      - path: "**.pan"
+        strategy: partial=0:4
+      - path: "**.cvv"
+        strategy: omit
+  legacy:
+    rules:
+      - path: "**.ssn"
+        strategy: redact
+`)
+	b := loadTestPolicy(t, `
+rulesets:
+  pci:
+    rules:
+      - path: "**.pan"
+        strategy: partial=0:4
+  gdpr:
+    rules:
+      - path: "**.email"
+        strategy: redact
+`)
+	d := DiffPolicies(a, b)
+	expect := strings.Join([]string{
+		`+ rule set "gdpr": 1 rule(s), 0 detector(s)`,
+		`- rule set "legacy": 1 rule(s), 0 detector(s)`,
+		`- rule set "pci" rule "**.cvv": omit`,
+	}, "\n")
+	if d.String() != expect {
+		t.Errorf("expect %s == %s", d, expect)
+	}
+	if !d.ReducesCoverage() {
+		t.Errorf("expect %v to reduce coverage", d)
+	}
+}
@@ -0,0 +1,105 @@
+package mask
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PolicySource reads the current policy document, e.g. from a file or a config service.
+type PolicySource func() ([]byte, error)
+
+// PolicyFile reads the policy document from the file name.
+func PolicyFile(name string) PolicySource {
+	return func() ([]byte, error) {
+		return os.ReadFile(name)
+	}
+}
+
+// PolicyStore holds the current policy of a long running service and replaces it
+// when its source changes, so new rules are picked up without a deploy.
+// Policies are swapped atomically: options obtained from the store keep
+// applying the policy they were created from, even if it is replaced meanwhile.
+type PolicyStore struct {
+	source  PolicySource
+	current atomic.Pointer[policySnapshot]
+	// mu serializes reloads.
+	mu sync.Mutex
+}
+
+type policySnapshot struct {
+	policy *Policy
+	raw    []byte
+	// options caches the options per audience.
+	options sync.Map
+}
+
+// NewPolicyStore loads the policy from source, failing if it cannot be read or is invalid.
+func NewPolicyStore(source PolicySource) (*PolicyStore, error) {
+	s := &PolicyStore{source: source}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Policy returns the current policy. It must not be modified.
+func (s *PolicyStore) Policy() *Policy {
+	return s.current.Load().policy
+}
+
+// Options returns the options applying the current policy for audience, see Policy.Options.
+func (s *PolicyStore) Options(audience string) []Option {
+	snapshot := s.current.Load()
+	if opts, ok := snapshot.options.Load(audience); ok {
+		return opts.([]Option)
+	}
+	// the policy has been validated on load, so this cannot fail
+	opts, _ := snapshot.policy.Options(audience)
+	snapshot.options.Store(audience, opts)
+	return opts
+}
+
+// Reload reads the policy from the source and replaces the current one if it changed.
+// An empty or invalid policy, e.g. a file read while being written, is rejected and
+// the current one is kept. As a document truncated between two rules is still valid,
+// sources should be replaced atomically, e.g. by renaming a temporary file.
+// Reload reports whether the policy was replaced.
+func (s *PolicyStore) Reload() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	raw, err := s.source()
+	if err != nil {
+		return false, err
+	}
+	if current := s.current.Load(); current != nil && bytes.Equal(current.raw, raw) {
+		return false, nil
+	}
+	p, err := LoadPolicy(bytes.NewReader(raw))
+	if err != nil {
+		return false, err
+	}
+	s.current.Store(&policySnapshot{policy: p, raw: raw})
+	return true, nil
+}
+
+// Watch reloads the policy every interval until ctx is done.
+// Failed reloads are passed to onError, if not nil, and keep the current policy.
+func (s *PolicyStore) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
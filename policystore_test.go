@@ -0,0 +1,151 @@
+package mask
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPolicyStore(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "policy.yaml")
+	write := func(policy string) {
+		if err := os.WriteFile(name, []byte(policy), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("rules: [{path: Email, strategy: redact}]")
+
+	store, err := NewPolicyStore(PolicyFile(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	val := testPerson{Name: "Ada", Email: "ada@example.com"}
+	before := store.Options("")
+	if masked := Must(val, before...); masked.Email != "MASKED" {
+		t.Errorf("expect %v == MASKED", masked.Email)
+	}
+
+	if changed, err := store.Reload(); changed || err != nil {
+		t.Errorf("expect an unchanged policy not to be replaced, got %v, %v", changed, err)
+	}
+
+	write("rules: [{path: Email, strategy: partial=1:0}]")
+	if changed, err := store.Reload(); !changed || err != nil {
+		t.Fatalf("expect the policy to be replaced, got %v, %v", changed, err)
+	}
+	if masked := Must(val, store.Options("")...); masked.Email != "a**************" {
+		t.Errorf("expect %v == a**************", masked.Email)
+	}
+	if masked := Must(val, before...); masked.Email != "MASKED" {
+		t.Errorf("expect options obtained before to keep their policy, got %v", masked.Email)
+	}
+
+	write("rules: [{path: Email, strategy: does-not-exist}]")
+	if _, err := store.Reload(); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("expect %v to be ErrInvalidPolicy", err)
+	}
+	if store.Policy().Rules[0].Strategy != "partial=1:0" {
+		t.Errorf("expect an invalid policy to be rejected, got %+v", store.Policy())
+	}
+}
+
+func TestPolicyStoreTruncated(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "policy.yaml")
+	policy := "rules:\n  - path: Email\n    strategy: redact\n  - path: Name\n    strategy: redact\n"
+	if err := os.WriteFile(name, []byte(policy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewPolicyStore(PolicyFile(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := store.Options("")
+
+	for _, truncated := range []string{"", "\n", "rules:\n", "rules:\n  - path: Email\n", "rules:\n  - path: Email\n    strat"} {
+		if err := os.WriteFile(name, []byte(truncated), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if changed, err := store.Reload(); changed || !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("expect %q to be rejected, got %v, %v", truncated, changed, err)
+		}
+		if len(store.Policy().Rules) != 2 {
+			t.Errorf("expect the last good policy to be kept for %q, got %+v", truncated, store.Policy())
+		}
+		val := testPerson{Name: "Ada", Email: "ada@example.com"}
+		if masked := Must(val, store.Options("")...); masked.Email != "MASKED" {
+			t.Errorf("expect %v == MASKED for %q", masked.Email, truncated)
+		}
+		if masked := Must(val, opts...); masked.Email != "MASKED" {
+			t.Errorf("expect %v == MASKED", masked.Email)
+		}
+	}
+}
+
+func TestPolicyStoreRuleSets(t *testing.T) {
+	type account struct {
+		Contact map[string]string `mask:"ruleset=test-store"`
+	}
+	policy := func(strategy string) string {
+		return "rulesets:\n  test-store:\n    rules: [{path: \"**.email\", strategy: \"" + strategy + "\"}]\n"
+	}
+	name := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(name, []byte(policy("partial=1:0")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewPolicyStore(PolicyFile(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	val := account{Contact: map[string]string{"email": "ada@example.com"}}
+	before := store.Options("")
+
+	if err := os.WriteFile(name, []byte(policy("redact")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := store.Reload(); !changed || err != nil {
+		t.Fatalf("expect the policy to be replaced, got %v, %v", changed, err)
+	}
+	if masked := Must(val, before...); masked.Contact["email"] != "a**************" {
+		t.Errorf("expect options obtained before to keep their rule set, got %v", masked.Contact["email"])
+	}
+	if masked := Must(val, store.Options("")...); masked.Contact["email"] != "MASKED" {
+		t.Errorf("expect %v == MASKED", masked.Contact["email"])
+	}
+	if _, err := Mask(val); !errors.Is(err, ErrUnknownRuleSet) {
+		t.Errorf("expect the rule sets of the store not to be registered, got %v", err)
+	}
+}
+
+func TestPolicyStoreWatch(t *testing.T) {
+	policies := make(chan string, 1)
+	current := "rules: [{path: Email, strategy: redact}]"
+	store, err := NewPolicyStore(func() ([]byte, error) {
+		select {
+		case current = <-policies:
+		default:
+		}
+		return []byte(current), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		store.Watch(ctx, time.Millisecond, nil)
+		close(done)
+	}()
+	policies <- "rules: [{path: Name, strategy: redact}]"
+	for deadline := time.Now().Add(time.Second); store.Policy().Rules[0].Path != "Name"; {
+		if time.Now().After(deadline) {
+			t.Fatal("expect the policy to be reloaded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,69 @@
+package mask
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooled limits the number of entries of maps kept by pooled states and
+// maxPooledBuf the size of pooled JSON buffers, so a single large value does
+// not pin its memory.
+const (
+	maxPooled    = 4096
+	maxPooledBuf = 64 << 10
+)
+
+var (
+	statePool  sync.Pool
+	bufWriters = sync.Pool{New: func() interface{} { return &bufWriter{} }}
+	// ptrsHint is the number of pointers recently copied by a single call,
+	// used to size the maps of new states.
+	ptrsHint atomic.Int64
+)
+
+// newState returns a state for a call on x using opts, reusing released ones.
+func newState(x interface{}, opts []Option) *state {
+	s, ok := statePool.Get().(*state)
+	if !ok {
+		s = &state{ptrs: make(map[uintptr]interface{}, ptrsHint.Load())}
+	}
+	s.opts = newOptions(opts)
+	s.root = rootName(x)
+	return s
+}
+
+// release resets s and returns it to the pool. s must not be used afterwards;
+// errors collected have been handed out and are not reused.
+func (s *state) release() {
+	n := len(s.ptrs)
+	if n > maxPooled || len(s.visiting) > maxPooled {
+		return
+	}
+	if n > 0 {
+		ptrsHint.Store(int64(n))
+	}
+	clear(s.ptrs)
+	clear(s.visiting)
+	clear(s.path[:cap(s.path)])
+	*s = state{
+		ptrs:     s.ptrs,
+		visiting: s.visiting,
+		path:     s.path[:0],
+	}
+	statePool.Put(s)
+}
+
+func newBufWriter() *bufWriter {
+	return bufWriters.Get().(*bufWriter)
+}
+
+// release resets w and returns it to the pool.
+func (w *bufWriter) release() {
+	if w.buf.Cap() > maxPooledBuf {
+		return
+	}
+	w.buf.Reset()
+	w.objects = w.objects[:0]
+	w.counts = w.counts[:0]
+	bufWriters.Put(w)
+}
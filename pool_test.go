@@ -0,0 +1,80 @@
+package mask
+
+import "testing"
+
+type testLogEntry struct {
+	Message string
+	User    *testLogUser
+	Tags    map[string]string
+	Related []*testLogUser
+}
+
+type testLogUser struct {
+	ID    int
+	Email string `mask:"redact"`
+	Name  string `mask:"partial=1:0"`
+}
+
+func newTestLogEntry() testLogEntry {
+	user := &testLogUser{ID: 1, Email: "ada@example.com", Name: "Ada"}
+	entry := testLogEntry{Message: "login", User: user, Tags: map[string]string{"region": "eu"}}
+	for i := 0; i < 20; i++ {
+		entry.Related = append(entry.Related, &testLogUser{ID: i, Email: "user@example.com", Name: "User"}, user)
+	}
+	return entry
+}
+
+func TestStateReuse(t *testing.T) {
+	entry := newTestLogEntry()
+	for i := 0; i < 3; i++ {
+		masked, err := Mask(entry)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if masked.User.Email != "MASKED" || masked.Related[1] != masked.User || masked.User == entry.User {
+			t.Errorf("expect pointers to be copied once, got %v", masked.Related[1])
+		}
+		if _, err := Mask(struct{ C chan int }{}); err == nil {
+			t.Errorf("expected an error")
+		}
+	}
+	if _, err := Mask(struct{ C chan int }{}, WithCollectErrors()); err == nil {
+		t.Errorf("expected an error")
+	}
+	// errors collected by a released state are not reused
+	_, err := Mask(struct{ C, D chan int }{}, WithCollectErrors())
+	merr, ok := err.(*MaskError)
+	if !ok || len(merr.Errors) != 2 {
+		t.Errorf("expect 2 errors, got %v", err)
+	}
+}
+
+func BenchmarkMask(b *testing.B) {
+	entry := newTestLogEntry()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Mask(entry); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMaskJSON(b *testing.B) {
+	entry := newTestLogEntry()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := JSON(entry).MarshalJSON(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	entry := newTestLogEntry()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Scan(entry); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
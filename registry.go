@@ -0,0 +1,48 @@
+package mask
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// registry is a map safe for concurrent use, which is read on every masked value
+// and rarely written. Writes copy the map, so reads never block.
+// The zero value is an empty registry.
+type registry[K comparable, V any] struct {
+	mu sync.Mutex
+	m  atomic.Pointer[map[K]V]
+}
+
+func newRegistry[K comparable, V any](m map[K]V) *registry[K, V] {
+	r := &registry[K, V]{}
+	r.m.Store(&m)
+	return r
+}
+
+func (r *registry[K, V]) load(k K) (V, bool) {
+	var v V
+	m := r.m.Load()
+	if m == nil {
+		return v, false
+	}
+	v, ok := (*m)[k]
+	return v, ok
+}
+
+func (r *registry[K, V]) store(k K, v V) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := make(map[K]V)
+	if m := r.m.Load(); m != nil {
+		for k, v := range *m {
+			next[k] = v
+		}
+	}
+	next[k] = v
+	r.m.Store(&next)
+}
+
+func (r *registry[K, V]) empty() bool {
+	m := r.m.Load()
+	return m == nil || len(*m) == 0
+}
@@ -0,0 +1,54 @@
+package mask
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type concurrentID string
+
+type concurrentRecord struct {
+	ID      concurrentID `mask:"redact"`
+	Name    string       `mask:"name"`
+	Country string
+	Note    string `mask:"redact,if=concurrent"`
+}
+
+func TestConcurrentRegistration(t *testing.T) {
+	RegisterCondition("concurrent", func(interface{}) bool { return true })
+	copier := NewCopier()
+	val := concurrentRecord{ID: "1", Name: "Ada Lovelace", Country: "UK", Note: "n"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			RegisterStrategy(fmt.Sprintf("concurrent-%d", i), redactStrategy)
+			RegisterPlaceholder(concurrentID("***"))
+			RegisterCondition(fmt.Sprintf("concurrent-%d", i), func(interface{}) bool { return true })
+			copier.Register(concurrentRecord{}, func(x interface{}, deep func(interface{}) (interface{}, error)) (interface{}, error) {
+				return x, nil
+			})
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := Mask(val); err != nil {
+					t.Error(err)
+				}
+				if _, err := Mask(val, WithCopier(copier)); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	masked, err := Mask(val)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if masked.ID != "***" || masked.Note != "MASKED" {
+		t.Errorf("expect registrations to be applied, got %v", masked)
+	}
+}
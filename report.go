@@ -0,0 +1,60 @@
+package mask
+
+// Report lists the values masked by MaskWithReport.
+// It never contains any original or masked values.
+type Report struct {
+	Entries []ReportEntry
+}
+
+// ReportEntry describes a single masked value.
+type ReportEntry struct {
+	// Path locates the value, e.g. Order.Items[3].Card.Number.
+	Path string
+	// Strategy is the name of the strategy applied, or MaskXXX.
+	Strategy string
+}
+
+// Count returns the number of masked values.
+func (r Report) Count() int {
+	return len(r.Entries)
+}
+
+// CountByStrategy returns the number of masked values per strategy.
+func (r Report) CountByStrategy() map[string]int {
+	counts := make(map[string]int)
+	for _, e := range r.Entries {
+		counts[e.Strategy]++
+	}
+	return counts
+}
+
+// MaskWithReport masks x like Mask and additionally reports every value masked,
+// e.g. to provide compliance evidence of what was redacted.
+func MaskWithReport[T any](x T, opts ...Option) (T, Report, error) {
+	var r Report
+	out, err := Mask(x, append(opts[:len(opts):len(opts)], withReport(&r))...)
+	return out, r, err
+}
+
+func withReport(r *Report) Option {
+	return func(o *options) {
+		o.report = r
+	}
+}
+
+// masked records the value currently visited as masked using strategy.
+func (s *state) masked(strategy string) {
+	if s.opts.metrics != nil {
+		if s.maskedBy == nil {
+			s.maskedBy = make(map[string]int)
+		}
+		s.maskedBy[strategy]++
+	}
+	if s.opts.report == nil {
+		return
+	}
+	s.opts.report.Entries = append(s.opts.report.Entries, ReportEntry{
+		Path:     s.currentPath(),
+		Strategy: strategy,
+	})
+}
@@ -0,0 +1,48 @@
+package mask
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaskWithReport(t *testing.T) {
+	val := newTestStruct()
+	masked, report, err := MaskWithReport(val, WithSortedMaps())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if masked.Value != "MASKED" {
+		t.Errorf("expect %v == MASKED", masked.Value)
+	}
+
+	expected := []ReportEntry{
+		{"testStruct.S1", "MaskXXX"},
+		{"testStruct.S2", "MaskXXX"},
+		{"testStruct.I1", "MaskXXX"},
+		{"testStruct.I2", "MaskXXX"},
+		{"testStruct.Mp", "MaskXXX"},
+		{"testStruct.Sl", "MaskXXX"},
+		{"testStruct.Strct1", "MaskXXX"},
+		{"testStruct.Strct2", "MaskXXX"},
+		{"testStruct", "MaskXXX"},
+	}
+	if !reflect.DeepEqual(report.Entries, expected) {
+		t.Errorf("expect %v == %v", report.Entries, expected)
+	}
+	if report.Count() != len(expected) {
+		t.Errorf("expect %v == %v", report.Count(), len(expected))
+	}
+
+	type S struct {
+		Name  string `mask:"name"`
+		Email string `mask:"redact"`
+		Keep  string
+	}
+	_, report, err = MaskWithReport(S{"Ada Lovelace", "ada@example.com", "keep"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if counts := report.CountByStrategy(); counts["name"] != 1 || counts["redact"] != 1 || len(counts) != 2 {
+		t.Errorf("expect one name and one redact, got %v", counts)
+	}
+}
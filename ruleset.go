@@ -0,0 +1,153 @@
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+// ErrUnknownRuleSet is returned for tags and policies referencing an unregistered rule set.
+var ErrUnknownRuleSet = errors.New("unknown rule set")
+
+// ruleSets holds the rule sets registered by RegisterRuleSet.
+var ruleSets = newRegistry(map[string]*Policy{})
+
+// RegisterRuleSet makes the rules and detectors of set available to struct tags and
+// policies under the given name, so compliance presets are maintained centrally:
+//
+//	mask.RegisterRuleSet("pci", &mask.Policy{Rules: []mask.PolicyRule{
+//		{Path: "**.pan", Strategy: "partial=0:4"},
+//		{Path: "**.cvv", Strategy: "omit"},
+//	}})
+//
+//	type Order struct {
+//		Payment Payment `mask:"ruleset=pci"`
+//	}
+//
+// Paths of the rules are relative to the value masked by the rule set, which is masked
+// by the rule set alone, i.e. without the options of the call. Rules and detectors
+// restricted to audiences are not applied. Registering a name twice
+// replaces the previous rule set. It is safe to register rule sets while masking.
+func RegisterRuleSet(name string, set *Policy) error {
+	if err := set.Validate(); err != nil {
+		return fmt.Errorf("rule set %s: %w", name, err)
+	}
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	ruleSets.store(name, set)
+	// tags resolved so far reference the previous rule set
+	tagStrategies.Range(func(tag, _ interface{}) bool {
+		tagStrategies.Delete(tag)
+		return true
+	})
+	return nil
+}
+
+// RegisterRuleSets registers the rule sets defined by p, see RegisterRuleSet. The options
+// of p resolve tags referencing its rule sets without registering them, see Policy.Options.
+func (p *Policy) RegisterRuleSets() error {
+	names := make([]string, 0, len(p.RuleSets))
+	for name := range p.RuleSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := RegisterRuleSet(name, p.RuleSets[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleSetFactory resolves `mask:"ruleset=name"` tags.
+func ruleSetFactory(name string) (Strategy, error) {
+	set, ok := ruleSets.load(name)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownRuleSet, name)
+	}
+	return ruleSetStrategy(name, set, nil), nil
+}
+
+// ruleSetResolver resolves strategies referencing the rule sets of a policy before
+// registered ones, so the options of a policy keep applying its rule sets, even if
+// rule sets of the same name are registered or other policies loaded meanwhile.
+type ruleSetResolver struct {
+	sets map[string]*Policy
+	// parent resolves the rule sets of the policy defining these rule sets, so rule
+	// sets of a policy may reference each other.
+	parent *ruleSetResolver
+	// tags caches the strategies of the tags resolved so far, like tagStrategies.
+	tags sync.Map
+}
+
+// parse resolves tag like ParseStrategy, preferring the rule sets of r.
+func (r *ruleSetResolver) parse(tag string) (Strategy, error) {
+	if strings.Contains(tag, "|") {
+		parts := strings.Split(tag, "|")
+		pipeline := make([]Strategy, len(parts))
+		for i, part := range parts {
+			strategy, err := r.parse(part)
+			if err != nil {
+				return nil, err
+			}
+			pipeline[i] = strategy
+		}
+		return maskers.Compose(pipeline...), nil
+	}
+	if name, ok := strings.CutPrefix(tag, "ruleset="); ok {
+		for resolver := r; resolver != nil; resolver = resolver.parent {
+			if set, ok := resolver.sets[name]; ok {
+				return ruleSetStrategy(name, set, resolver), nil
+			}
+		}
+	}
+	return ParseStrategy(tag)
+}
+
+// fromTag resolves the strategy of a struct tag like strategyFromTag, preferring the
+// rule sets of r. A nil resolver resolves registered rule sets only.
+func (r *ruleSetResolver) fromTag(tag string) (Strategy, error) {
+	if r == nil || !strings.Contains(tag, "ruleset=") {
+		return strategyFromTag(tag)
+	}
+	if strategy, ok := r.tags.Load(tag); ok {
+		return strategy.(Strategy), nil
+	}
+	strategy, err := r.parse(tag)
+	if err != nil {
+		return nil, err
+	}
+	cached, _ := r.tags.LoadOrStore(tag, strategy)
+	return cached.(Strategy), nil
+}
+
+// ruleSetStrategy masks values using the rules and detectors of set. Rules and tags
+// resolve rule sets not defined by set using parent, i.e. the resolver defining set.
+func ruleSetStrategy(name string, set *Policy, parent *ruleSetResolver) Strategy {
+	var (
+		once sync.Once
+		opts []Option
+		err  error
+	)
+	return maskers.Func("ruleset="+name, func(v reflect.Value) (reflect.Value, error) {
+		once.Do(func() {
+			opts, err = set.optionsWithin("", false, parent)
+		})
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		masked, err := Mask(v.Interface(), opts...)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if masked == nil {
+			return reflect.Zero(v.Type()), nil
+		}
+		return reflect.ValueOf(masked), nil
+	})
+}
@@ -0,0 +1,163 @@
+package mask
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegisterRuleSet(t *testing.T) {
+	type payment struct {
+		PAN string
+		CVV string
+	}
+	type order struct {
+		ID      string
+		Payment payment `mask:"ruleset=test-pci"`
+		Backup  payment
+	}
+	err := RegisterRuleSet("test-pci", &Policy{Rules: []PolicyRule{
+		{Path: "**.PAN", Strategy: "partial=0:4"},
+		{Path: "**.CVV", Strategy: "omit"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	val := order{ID: "o-1", Payment: payment{PAN: "4111111111111111", CVV: "123"}, Backup: payment{PAN: "5500", CVV: "456"}}
+	masked := Must(val)
+	expect := order{ID: "o-1", Payment: payment{PAN: "************1111"}, Backup: payment{PAN: "5500", CVV: "456"}}
+	if masked != expect {
+		t.Errorf("expect %+v == %+v", masked, expect)
+	}
+
+	// re-registering replaces the rule set used by tags
+	if err := RegisterRuleSet("test-pci", &Policy{Rules: []PolicyRule{{Path: "**.PAN", Strategy: "redact"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if masked := Must(val); masked.Payment.PAN != "MASKED" || masked.Payment.CVV != "123" {
+		t.Errorf("expect %+v to be masked by the new rule set", masked.Payment)
+	}
+
+	if err := RegisterRuleSet("test-invalid", &Policy{Rules: []PolicyRule{{Path: "**.PAN", Strategy: "unknown"}}}); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("expect %v to be %v", err, ErrUnknownStrategy)
+	}
+	type unknown struct {
+		Payment payment `mask:"ruleset=test-unknown"`
+	}
+	if _, err := Mask(unknown{}); !errors.Is(err, ErrUnknownRuleSet) {
+		t.Errorf("expect %v to be %v", err, ErrUnknownRuleSet)
+	}
+}
+
+func TestPolicyRuleSets(t *testing.T) {
+	p, err := LoadPolicy(strings.NewReader(`
+rulesets:
+  test-gdpr:
+    rules:
+      - path: "**.email"
+        strategy: partial=1:0
+      - path: "**.birthday"
+        strategy: date=year
+rules:
+  - path: "customer"
+    strategy: ruleset=test-gdpr
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := p.Options("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	val := map[string]interface{}{
+		"customer": map[string]string{"email": "ada@example.com", "birthday": "1990-05-17"},
+		"email":    "ops@example.com",
+	}
+	masked := Must(val, opts...)
+	customer := masked["customer"].(map[string]string)
+	if customer["email"] != "a**************" || customer["birthday"] != "1990-01-01" || masked["email"] != "ops@example.com" {
+		t.Errorf("expect only the customer to be masked by the rule set, got %v", masked)
+	}
+
+	if err := p.RegisterRuleSets(); err != nil {
+		t.Fatal(err)
+	}
+	type customerRecord struct {
+		Contact map[string]string `mask:"ruleset=test-gdpr"`
+	}
+	if masked := Must(customerRecord{Contact: map[string]string{"email": "ada@example.com"}}); masked.Contact["email"] != "a**************" {
+		t.Errorf("expect %v to be masked by the registered rule set", masked.Contact)
+	}
+
+	_, err = LoadPolicy(strings.NewReader(`
+rulesets:
+  broken:
+    rules:
+      - path: "**.email"
+        strategy: unknown
+`))
+	if !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("expect %v to be %v", err, ErrInvalidPolicy)
+	}
+}
+
+func TestPolicyRuleSetsEmpty(t *testing.T) {
+	const doc = `
+rulesets:
+  pci:
+rules:
+  - path: "payment"
+    strategy: ruleset=pci
+`
+	if _, err := LoadPolicy(strings.NewReader(doc)); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("expect %v to be %v", err, ErrInvalidPolicy)
+	}
+	if err := RegisterRuleSet("test-empty", nil); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("expect %v to be %v", err, ErrInvalidPolicy)
+	}
+	released := &Policy{RuleSets: map[string]*Policy{"pci": {Rules: []PolicyRule{{Path: "**.cvv", Strategy: "omit"}}}}}
+	proposed := &Policy{RuleSets: map[string]*Policy{"pci": nil}}
+	if diff := DiffPolicies(released, proposed); !diff.ReducesCoverage() {
+		t.Errorf("expect %v to reduce coverage", diff)
+	}
+}
+
+func TestPolicyRuleSetsNested(t *testing.T) {
+	p, err := LoadPolicy(strings.NewReader(`
+rulesets:
+  test-pci:
+    rules:
+      - path: "**.cvv"
+        strategy: omit
+  test-strict:
+    rules:
+      - path: "payment"
+        strategy: ruleset=test-pci
+      - path: "**.email"
+        strategy: partial=1:0
+rules:
+  - path: "order"
+    strategy: ruleset=test-strict
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := p.Options("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	val := map[string]map[string]interface{}{
+		"order": {
+			"email":   "ada@example.com",
+			"payment": map[string]string{"cvv": "123", "pan": "4111"},
+		},
+	}
+	masked := Must(val, opts...)
+	order := masked["order"]
+	if order["email"] != "a**************" {
+		t.Errorf("expect %v == %v", order["email"], "a**************")
+	}
+	if payment := order["payment"].(map[string]string); payment["cvv"] != "" || payment["pan"] != "4111" {
+		t.Errorf("expect only the cvv of %v to be omitted", payment)
+	}
+}
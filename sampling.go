@@ -0,0 +1,28 @@
+package mask
+
+import "math/rand/v2"
+
+// WithSampling runs detectors only on the given fraction of calls, between 0 and 1,
+// chosen at random. Tags, path, type and field strategies and MaskXXX methods still mask
+// every call; only the scanning of strings by detectors is skipped. Use it for logging
+// at high rates, where scanning every message is too costly and finding sensitive data
+// in a sample suffices, e.g. to report it using MaskWithReport or WithMetrics:
+//
+//	mask.Must(entry, mask.WithDetector(detect.Email(), nil), mask.WithSampling(0.01))
+//
+// Rates of 1 or more scan every call, rates of 0 or less none.
+func WithSampling(rate float64) Option {
+	return func(o *options) {
+		o.sampling = &rate
+	}
+}
+
+// sample drops the detectors of o unless the call is sampled.
+func (o *options) sample() {
+	if o.sampling == nil || len(o.detectors) == 0 {
+		return
+	}
+	if rate := *o.sampling; rate < 1 && rand.Float64() >= rate {
+		o.detectors = nil
+	}
+}
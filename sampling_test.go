@@ -0,0 +1,38 @@
+package mask
+
+import (
+	"testing"
+
+	"github.com/doejon/go-mask/detect"
+)
+
+func TestWithSampling(t *testing.T) {
+	type entry struct {
+		Message  string
+		Password string `mask:"redact"`
+	}
+	val := entry{"mail ada@example.com", "secret"}
+	count := func(rate float64) int {
+		detected := 0
+		for i := 0; i < 1000; i++ {
+			out := Must(val, WithDetector(detect.Email(), nil), WithSampling(rate))
+			if out.Password != "MASKED" {
+				t.Fatalf("expect %v == MASKED", out.Password)
+			}
+			if out.Message != val.Message {
+				detected++
+			}
+		}
+		return detected
+	}
+
+	if n := count(1); n != 1000 {
+		t.Errorf("expect %v == 1000", n)
+	}
+	if n := count(0); n != 0 {
+		t.Errorf("expect %v == 0", n)
+	}
+	if n := count(0.5); n < 350 || n > 650 {
+		t.Errorf("expect %v around 500", n)
+	}
+}
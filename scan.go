@@ -0,0 +1,221 @@
+package mask
+
+import (
+	"reflect"
+)
+
+// FindingSource tells why a value would be masked.
+type FindingSource string
+
+const (
+	// SourceTag marks values masked by a strategy referenced in a struct tag.
+	SourceTag FindingSource = "tag"
+	// SourceMethod marks values masked by their MaskXXX method.
+	SourceMethod FindingSource = "method"
+	// SourceMapKey marks map keys masked using WithMapKeyMasking.
+	SourceMapKey FindingSource = "map-key"
+	// SourcePath marks values masked using WithPathStrategy.
+	SourcePath FindingSource = "path"
+	// SourceType marks values masked because of their type, see WithMaskTypes.
+	SourceType FindingSource = "type"
+	// SourceField marks values masked because of their field name, see WithDenyFields.
+	SourceField FindingSource = "field"
+	// SourceHook marks values masked by a hook, see WithHook.
+	SourceHook FindingSource = "hook"
+	// SourceDetector marks values masked because of their content, see WithDetector.
+	SourceDetector FindingSource = "detector"
+)
+
+// Finding describes a value which would be masked.
+type Finding struct {
+	// Path locates the value, e.g. Order.Items[3].Card.Number.
+	Path string
+	// Type is the type of the value.
+	Type reflect.Type
+	// Strategy is the name of the strategy masking the value, or MaskXXX.
+	Strategy string
+	Source   FindingSource
+	// Detectors are the names of the detectors which found sensitive data in the value, for SourceDetector.
+	Detectors []string
+}
+
+// Scan walks x and reports every value which would be masked, without
+// copying or masking anything: no MaskXXX method and no strategy is called.
+// Use it e.g. in CI to assert sensitive fields are covered by masking rules.
+// Scan fails on anything masking would fail on as well, like invalid tags.
+func Scan(x interface{}, opts ...Option) ([]Finding, error) {
+	s := newState(x, opts)
+	defer s.release()
+	if s.opts.err != nil {
+		return nil, s.opts.err
+	}
+	var findings []Finding
+	err := s.scan(reflect.ValueOf(x), &findings)
+	return findings, err
+}
+
+func (s *state) found(findings *[]Finding, t reflect.Type, strategy string, source FindingSource) {
+	*findings = append(*findings, Finding{
+		Path:     s.currentPath(),
+		Type:     t,
+		Strategy: strategy,
+		Source:   source,
+	})
+}
+
+func (s *state) scan(v reflect.Value, findings *[]Finding) error {
+	if !v.IsValid() || s.exceedsDepth() {
+		return nil
+	}
+	promotes := s.promotes()
+	hook := s.hook(v)
+	switch hook.Action {
+	case HookReplace, HookMask:
+		s.found(findings, v.Type(), hook.strategyName(), SourceHook)
+		return nil
+	}
+	if v.Kind() != reflect.Interface && hook.Action == HookContinue {
+		if strategy := s.pathStrategy(); strategy != nil {
+			s.found(findings, v.Type(), strategy.Name(), SourcePath)
+			return nil
+		}
+		if strategy := s.typeStrategy(v); strategy != nil {
+			s.found(findings, v.Type(), strategy.Name(), SourceType)
+			return nil
+		}
+		if strategy := s.fieldStrategy(); strategy != nil {
+			s.found(findings, v.Type(), strategy.Name(), SourceField)
+			return nil
+		}
+		if strategy, detectors := s.detect(v); strategy != nil {
+			s.found(findings, v.Type(), strategy.Name(), SourceDetector)
+			(*findings)[len(*findings)-1].Detectors = detectors
+			return nil
+		}
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || s.ptrs[v.Pointer()] != nil {
+			return nil
+		}
+		s.ptrs[v.Pointer()] = true
+		return s.scan(v.Elem(), findings)
+	case reflect.Interface:
+		return s.scan(v.Elem(), findings)
+	case reflect.Struct:
+		if err := s.scanStruct(v, findings); err != nil {
+			return err
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && s.scanned(v) {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			s.pushIndex(i)
+			err := s.scan(v.Index(i), findings)
+			s.pop()
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if s.scanned(v) {
+			return nil
+		}
+		keyStrategy := s.opts.keyStrategies[v.Type().Key()]
+		for _, e := range mapEntries(v, s.opts.sortMaps) {
+			s.pushKey(e.key.Interface())
+			if keyStrategy != nil {
+				s.found(findings, v.Type().Key(), keyStrategy.Name(), SourceMapKey)
+			}
+			s.inKey = true
+			err := s.scan(e.key, findings)
+			s.inKey = false
+			if err == nil {
+				err = s.scan(e.value, findings)
+			}
+			s.pop()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, ok, err := maskMethod(v.Type())
+	ok = ok && promotes
+	if err == nil && ok && s.opts.pureMaskers && masksInPlace(v.Type()) {
+		err = ErrMutatingMasker
+	}
+	if err != nil {
+		_, err = s.fail(v.Type(), err)
+		return err
+	}
+	if ok && hook.Action != HookKeep {
+		s.found(findings, v.Type(), maskFnName, SourceMethod)
+	}
+	return nil
+}
+
+func (s *state) scanStruct(v reflect.Value, findings *[]Finding) error {
+	t := v.Type()
+	if _, ok := s.copier().typeCopier(t); ok {
+		// copied as a whole, see Copier.Register and typeCopiers
+		return nil
+	}
+	if s.flat(t) {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		s.pushStructField(f)
+		err := s.scanField(f, v, i, findings)
+		s.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *state) scanField(f reflect.StructField, parent reflect.Value, i int, findings *[]Finding) error {
+	v := parent.Field(i)
+	strategy, err := s.tagStrategy(f, parent)
+	if err != nil {
+		_, err = s.fail(f.Type, err)
+		return err
+	}
+	if strategy == nil {
+		return s.scan(v, findings)
+	}
+	switch hook := s.hook(v); hook.Action {
+	case HookReplace, HookMask:
+		s.found(findings, f.Type, hook.strategyName(), SourceHook)
+		return nil
+	case HookKeep:
+		s.decide(hook, func() {
+			err = s.scan(v, findings)
+		})
+		return err
+	}
+	s.found(findings, f.Type, strategy.Name(), SourceTag)
+	return nil
+}
+
+// scanned reports whether the map or slice v has been scanned before and marks it as scanned.
+func (s *state) scanned(v reflect.Value) bool {
+	if v.Len() == 0 {
+		return false
+	}
+	k := newRefKey(v)
+	if _, ok := s.visiting[k]; ok {
+		return true
+	}
+	if s.visiting == nil {
+		s.visiting = make(map[refKey]interface{})
+	}
+	s.visiting[k] = nil
+	return false
+}
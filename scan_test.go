@@ -0,0 +1,72 @@
+package mask
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+func TestScan(t *testing.T) {
+	val := newTestStruct()
+	val.CustomInterface = &testPerson{Name: "Ada Lovelace"}
+	orig := newTestStruct()
+	orig.CustomInterface = &testPerson{Name: "Ada Lovelace"}
+
+	findings, err := Scan(val)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(val, orig) {
+		t.Errorf("expect scan not to modify %v", val)
+	}
+
+	var paths []string
+	for _, f := range findings {
+		paths = append(paths, f.Path+":"+f.Strategy+":"+string(f.Source))
+	}
+	expected := []string{
+		"testStruct.S1:MaskXXX:method",
+		"testStruct.S2:MaskXXX:method",
+		"testStruct.I1:MaskXXX:method",
+		"testStruct.I2:MaskXXX:method",
+		"testStruct.Mp:MaskXXX:method",
+		"testStruct.Sl:MaskXXX:method",
+		"testStruct.Strct1:MaskXXX:method",
+		"testStruct.Strct2:MaskXXX:method",
+		"testStruct.CustomInterface.Name:name:tag",
+		"testStruct.CustomInterface.Nickname:name:tag",
+		"testStruct.CustomInterface.Spouse:name:tag",
+		"testStruct:MaskXXX:method",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expect %v == %v", paths, expected)
+	}
+	if findings[0].Type != reflect.TypeOf(TestString("")) {
+		t.Errorf("expect %v == TestString", findings[0].Type)
+	}
+}
+
+func TestScanMapKeysAndCycles(t *testing.T) {
+	m := map[testEmail]interface{}{"ada@example.com": TestString("secret")}
+	m["self"] = m
+
+	findings, err := Scan(m, WithMapKeyMasking[testEmail](maskers.Partial(1, 1, maskers.Format{})), WithSortedMaps())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(findings) != 3 {
+		t.Fatalf("expect 3 findings, got %v", findings)
+	}
+	if findings[0].Source != SourceMapKey || findings[1].Source != SourceMethod || findings[1].Path != `["ada@example.com"]` {
+		t.Errorf("unexpected findings %v", findings)
+	}
+}
+
+func TestScanInvalidTag(t *testing.T) {
+	_, err := Scan(testOrder{})
+	if !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("expect %v to be ErrUnknownStrategy", err)
+	}
+}
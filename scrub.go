@@ -0,0 +1,74 @@
+package mask
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// minSecretLen is the minimum length of values of a session overwritten by Scrub;
+// shorter ones would match all over a binary blob.
+const minSecretLen = 4
+
+// Scrub overwrites sensitive data in data, a binary blob like a heap dump, core dump or
+// support bundle, in place and returns the number of matches overwritten:
+//
+//   - the matches of the detectors of opts, see WithDetector and Policy, and
+//   - every occurrence of the strings masked within the session of opts, see WithSession,
+//     which are at least 4 bytes long, e.g. the tokens replaced by a tokenizing strategy.
+//
+// Matches are overwritten by '*', keeping the length and thus the layout of data.
+// Strategies are not applied; other options are ignored.
+func Scrub(data []byte, opts ...Option) (int, error) {
+	o := newOptions(opts)
+	if o.err != nil {
+		return 0, o.err
+	}
+	n := 0
+	if len(o.detectors) > 0 {
+		// detectors find matches in a copy, so overwriting data does not affect them
+		str := string(data)
+		for _, rule := range o.detectors {
+			for _, m := range rule.detector.Find(str) {
+				overwrite(data[m.Start:m.End])
+				n++
+			}
+		}
+	}
+	if o.session != nil {
+		for _, secret := range o.session.secrets() {
+			for i := 0; ; {
+				j := bytes.Index(data[i:], secret)
+				if j < 0 {
+					break
+				}
+				overwrite(data[i+j : i+j+len(secret)])
+				n++
+				i += j + len(secret)
+			}
+		}
+	}
+	return n, nil
+}
+
+func overwrite(b []byte) {
+	for i := range b {
+		b[i] = '*'
+	}
+}
+
+// secrets returns the original strings masked within the session which are at least minSecretLen long.
+func (s *Session) secrets() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var secrets [][]byte
+	seen := make(map[string]bool)
+	for k := range s.surrogates {
+		v := reflect.ValueOf(k.value)
+		if v.Kind() != reflect.String || v.Len() < minSecretLen || seen[v.String()] {
+			continue
+		}
+		seen[v.String()] = true
+		secrets = append(secrets, []byte(v.String()))
+	}
+	return secrets
+}
@@ -0,0 +1,29 @@
+package mask
+
+import (
+	"testing"
+
+	"github.com/doejon/go-mask/detect"
+)
+
+func TestScrub(t *testing.T) {
+	session := NewSession()
+	token := mustParseStrategy(t, "redact")
+	_ = Must(struct {
+		Token string
+		PIN   string
+	}{"tok_4f9a8c", "123"}, WithSession(session), WithPathStrategy("Token", token), WithPathStrategy("PIN", token))
+
+	data := []byte("\x00\x01tok_4f9a8c\x00ada@example.com\xff123\x00tok_4f9a8c")
+	n, err := Scrub(data, WithDetector(detect.Email(), nil), WithSession(session))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expect := "\x00\x01**********\x00***************\xff123\x00**********"; string(data) != expect || n != 3 {
+		t.Errorf("expect %q == %q and %v == 3", data, expect, n)
+	}
+
+	if _, err := Scrub(data, WithPathStrategy("[", token)); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,80 @@
+package mask
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// SecretPlaceholder is printed and encoded in place of secrets.
+const SecretPlaceholder = "[REDACTED]"
+
+// Secret holds a credential like a password or an API key. Unlike PII, a secret
+// is never printed or encoded: String, GoString, MarshalJSON, MarshalText and
+// LogValue all return SecretPlaceholder, which prevents leaks even without calling Mask.
+// Mask replaces the secret with the zero value of T.
+//
+//	type Config struct {
+//	  APIKey mask.Secret[string] `json:"api_key"`
+//	}
+//
+// Use Reveal to access the secret.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret wraps v.
+func NewSecret[T any](v T) Secret[T] {
+	return Secret[T]{value: v}
+}
+
+// Reveal returns the secret.
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+// MaskXXX replaces the secret by the zero value of T.
+func (s Secret[T]) MaskXXX() Secret[T] {
+	return Secret[T]{}
+}
+
+func (s Secret[T]) String() string {
+	return SecretPlaceholder
+}
+
+func (s Secret[T]) GoString() string {
+	return fmt.Sprintf("mask.Secret[%v]{%s}", reflect.TypeOf((*T)(nil)).Elem(), SecretPlaceholder)
+}
+
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(SecretPlaceholder)
+}
+
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(SecretPlaceholder), nil
+}
+
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(SecretPlaceholder)
+}
+
+// UnmarshalJSON decodes the secret, e.g. from a configuration file.
+func (s *Secret[T]) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &s.value)
+}
+
+// UnmarshalText decodes the secret, e.g. from an environment variable.
+// T needs to be a string kind or implement encoding.TextUnmarshaler.
+func (s *Secret[T]) UnmarshalText(b []byte) error {
+	if u, ok := any(&s.value).(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText(b)
+	}
+	v := reflect.ValueOf(&s.value).Elem()
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("mask: unable to decode text into a secret %v", v.Type())
+	}
+	v.SetString(string(b))
+	return nil
+}
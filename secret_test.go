@@ -0,0 +1,74 @@
+package mask
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type testConfig struct {
+	User   string
+	APIKey Secret[string] `json:"api_key"`
+	Pin    *Secret[int]
+}
+
+func TestSecret(t *testing.T) {
+	pin := NewSecret(1234)
+	val := testConfig{User: "ada", APIKey: NewSecret("sk_live_123"), Pin: &pin}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		if s := fmt.Sprintf(format, val); strings.Contains(s, "sk_live_123") || strings.Contains(s, "1234") {
+			t.Errorf("expect %q not to leak the secret", s)
+		}
+	}
+	if s := fmt.Sprintf("%v", val.APIKey); s != SecretPlaceholder {
+		t.Errorf("expect %v == %v", s, SecretPlaceholder)
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"User":"ada","api_key":"[REDACTED]","Pin":"[REDACTED]"}` {
+		t.Errorf("expect %s not to leak the secret", b)
+	}
+	if b, _ := json.Marshal(map[Secret[string]]int{val.APIKey: 1}); string(b) != `{"[REDACTED]":1}` {
+		t.Errorf("expect %s not to leak the secret", b)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("config", "key", val.APIKey)
+	if strings.Contains(buf.String(), "sk_live_123") {
+		t.Errorf("expect %q not to leak the secret", buf.String())
+	}
+
+	masked := Must(val)
+	if masked.APIKey.Reveal() != "" || masked.Pin.Reveal() != 0 {
+		t.Errorf("expect %v, %v to be masked", masked.APIKey.Reveal(), masked.Pin.Reveal())
+	}
+	if val.APIKey.Reveal() != "sk_live_123" || val.Pin.Reveal() != 1234 {
+		t.Errorf("expect the original to stay untouched")
+	}
+}
+
+func TestSecretUnmarshal(t *testing.T) {
+	var decoded testConfig
+	if err := json.Unmarshal([]byte(`{"api_key":"sk_live_123","Pin":1234}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.APIKey.Reveal() != "sk_live_123" || decoded.Pin.Reveal() != 1234 {
+		t.Errorf("expect %v, %v to be decoded", decoded.APIKey.Reveal(), decoded.Pin.Reveal())
+	}
+
+	var s Secret[string]
+	if err := s.UnmarshalText([]byte("hunter2")); err != nil || s.Reveal() != "hunter2" {
+		t.Errorf("expect %v == hunter2 (%v)", s.Reveal(), err)
+	}
+	var i Secret[int]
+	if err := i.UnmarshalText([]byte("42")); err == nil {
+		t.Errorf("expect decoding text into an int secret to fail")
+	}
+}
@@ -0,0 +1,111 @@
+package mask
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Session makes masking consistent across multiple calls to Mask: within a session,
+// a strategy always replaces equal values by the same surrogate, even if the strategy
+// is randomized, like encryption. This preserves join keys in masked datasets,
+// e.g. the same email address results in the same masked address throughout an export.
+//
+// Only values masked by strategies are covered, not MaskXXX methods.
+// A Session is safe for concurrent use.
+type Session struct {
+	mu         sync.Mutex
+	surrogates map[sessionKey]reflect.Value
+}
+
+type sessionKey struct {
+	// strategy is the strategy itself if it is comparable, its name otherwise.
+	strategy interface{}
+	value    interface{}
+}
+
+// NewSession returns an empty session.
+func NewSession() *Session {
+	return &Session{surrogates: make(map[sessionKey]reflect.Value)}
+}
+
+// WithSession masks values consistently with all other calls using session.
+func WithSession(session *Session) Option {
+	return func(o *options) {
+		o.session = session
+	}
+}
+
+// Len returns the number of distinct values masked within the session.
+func (s *Session) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.surrogates)
+}
+
+// Apply masks the single value x using strategy within the session, e.g. the values of
+// database columns or CSV fields masked one by one. Nil values are returned unchanged.
+// A nil session masks x on its own, like Mask without session does.
+func (s *Session) Apply(strategy Strategy, x interface{}) (interface{}, error) {
+	v := reflect.ValueOf(x)
+	if !v.IsValid() {
+		return x, nil
+	}
+	apply := applyStrategy
+	if s != nil {
+		apply = s.apply
+	}
+	masked, err := apply(strategy, v)
+	if err != nil {
+		return nil, err
+	}
+	return masked.Interface(), nil
+}
+
+// apply masks v using strategy, reusing the surrogate of an equal value masked before.
+// Values which are not comparable are masked as usual.
+func (s *Session) apply(strategy Strategy, v reflect.Value) (reflect.Value, error) {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface || !v.Comparable() {
+		return applyStrategy(strategy, v)
+	}
+	k := sessionKey{strategy: strategy, value: v.Interface()}
+	if !reflect.TypeOf(strategy).Comparable() {
+		k.strategy = strategy.Name()
+	}
+
+	s.mu.Lock()
+	surrogate, ok := s.surrogates[k]
+	s.mu.Unlock()
+	if ok {
+		return surrogate, nil
+	}
+	// the strategy is applied without holding the lock, as strategies masking detected
+	// matches apply further strategies within the session
+	surrogate, err := applyStrategy(strategy, v)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// concurrent calls masking the same value agree on the first surrogate stored
+	if stored, ok := s.surrogates[k]; ok {
+		return stored, nil
+	}
+	s.surrogates[k] = surrogate
+	return surrogate, nil
+}
+
+// applyStrategy masks v using strategy, consistently within the session, if any.
+func (s *state) applyStrategy(strategy Strategy, v reflect.Value) (reflect.Value, error) {
+	if s.opts.reveal != nil {
+		return s.opts.reveal.reveal(strategy, v)
+	}
+	if isOmit(strategy) {
+		// nil pointers are omitted as well, and omitted values are not part of sessions
+		s.omittedAt = s.visits
+		return reflect.Zero(v.Type()), nil
+	}
+	if s.opts.session == nil {
+		return applyStrategy(strategy, v)
+	}
+	return s.opts.session.apply(strategy, v)
+}
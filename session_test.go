@@ -0,0 +1,85 @@
+package mask
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/doejon/go-mask/detect"
+	"github.com/doejon/go-mask/maskers"
+)
+
+type testCustomer struct {
+	Email   string `mask:"sequence=user-%04d@example.com"`
+	Referer string `mask:"sequence=user-%04d@example.com"`
+}
+
+func TestSession(t *testing.T) {
+	session := NewSession()
+	a := Must(testCustomer{Email: "ada@example.com"}, WithSession(session))
+	b := Must(testCustomer{Email: "bob@example.com", Referer: "ada@example.com"}, WithSession(session))
+	c := Must(testCustomer{Email: "ada@example.com"}, WithSession(session))
+
+	if a.Email != b.Referer || a.Email != c.Email {
+		t.Errorf("expect %v == %v == %v", a.Email, b.Referer, c.Email)
+	}
+	if a.Email == b.Email {
+		t.Errorf("expect %v != %v", a.Email, b.Email)
+	}
+	if session.Len() != 3 {
+		t.Errorf("expect %d == 3 distinct values (including the empty referer)", session.Len())
+	}
+
+	if d := Must(testCustomer{Email: "ada@example.com"}); d.Email == a.Email {
+		t.Errorf("expect masking without session to create a new surrogate, got %v", d.Email)
+	}
+}
+
+func TestSessionApply(t *testing.T) {
+	session := NewSession()
+	strategy := maskers.Sequence("user-%d")
+	a, err := session.Apply(strategy, "ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := Must(testCustomer{Email: "ada@example.com"}, WithSession(session))
+	if c, _ := session.Apply(strategy, "ada@example.com"); a != c || a == b.Email {
+		t.Errorf("expect %v == %v != %v", a, c, b.Email)
+	}
+	if out, err := session.Apply(strategy, nil); out != nil || err != nil {
+		t.Errorf("expect %v == nil (%v)", out, err)
+	}
+}
+
+func TestSessionPathStrategy(t *testing.T) {
+	session := NewSession()
+	block, err := aes.NewCipher([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val := map[string]string{"a": "ada@example.com", "b": "ada@example.com"}
+	opts := []Option{WithSession(session), WithPathStrategy("*", maskers.Encrypt(aead))}
+
+	masked := Must(val, opts...)
+	again := Must(val, opts...)
+	if masked["a"] != masked["b"] || masked["a"] != again["a"] || masked["a"] == val["a"] {
+		t.Errorf("expect equal values to be encrypted equally within a session, got %v and %v", masked, again)
+	}
+}
+
+func TestSessionDetector(t *testing.T) {
+	session := NewSession()
+	opts := []Option{WithSession(session), WithDetector(detect.Email(), maskers.Sequence("user-%d@example.com"))}
+	val := map[string]string{"a": "mail me at john@example.com", "b": "or john@example.com"}
+	masked := Must(val, opts...)
+	if masked["a"] != "mail me at user-1@example.com" || masked["b"] != "or user-1@example.com" {
+		t.Errorf("expect detected emails to be masked consistently within the session, got %v", masked)
+	}
+	if masked := Must(map[string]string{"a": "mail me at john@example.com"}, WithSession(NewSession()), WithDetector(detect.Email(), nil)); masked["a"] == "mail me at john@example.com" {
+		t.Errorf("expect %v to be masked", masked["a"])
+	}
+}
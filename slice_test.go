@@ -0,0 +1,42 @@
+package mask
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNilSlice(t *testing.T) {
+	val := struct {
+		Nil   []string
+		Empty []string
+	}{nil, []string{}}
+
+	masked := Must(val)
+	if masked.Nil != nil {
+		t.Errorf("expect %v to be nil", masked.Nil)
+	}
+	if masked.Empty == nil {
+		t.Errorf("expect %v not to be nil", masked.Empty)
+	}
+
+	a, _ := json.Marshal(val)
+	b, _ := json.Marshal(masked)
+	if string(a) != string(b) {
+		t.Errorf("expect %s == %s", b, a)
+	}
+}
+
+func TestPreserveCapacity(t *testing.T) {
+	val := make([]TestString, 2, 10)
+
+	if masked := Must(val); cap(masked) != 2 {
+		t.Errorf("expect %v == 2", cap(masked))
+	}
+	masked := Must(val, WithPreserveCapacity())
+	if len(masked) != 2 || cap(masked) != 10 {
+		t.Errorf("expect len %v == 2 and cap %v == 10", len(masked), cap(masked))
+	}
+	if masked[0] != "MASKED" {
+		t.Errorf("expect %v == MASKED", masked[0])
+	}
+}
@@ -0,0 +1,88 @@
+package mask
+
+import (
+	"cmp"
+	"reflect"
+	"sort"
+)
+
+// mapEntry is a single key value pair of a map.
+type mapEntry struct {
+	key   reflect.Value
+	value reflect.Value
+}
+
+// mapEntries returns the entries of the map v, sorted by key if requested.
+func mapEntries(v reflect.Value, sorted bool) []mapEntry {
+	entries := make([]mapEntry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entries = append(entries, mapEntry{key: iter.Key(), value: iter.Value()})
+	}
+	if sorted {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return compareKeys(entries[i].key, entries[j].key) < 0
+		})
+	}
+	return entries
+}
+
+// compareKeys orders map keys: numbers, strings and booleans by their value,
+// pointers and channels by their address, arrays and structs element by element
+// and interfaces by their dynamic type first, nil interfaces first.
+func compareKeys(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		if c := cmp.Compare(real(a.Complex()), real(b.Complex())); c != 0 {
+			return c
+		}
+		return cmp.Compare(imag(a.Complex()), imag(b.Complex()))
+	case reflect.Bool:
+		switch {
+		case a.Bool() == b.Bool():
+			return 0
+		case a.Bool():
+			return 1
+		}
+		return -1
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Chan:
+		return cmp.Compare(a.Pointer(), b.Pointer())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if c := compareKeys(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if c := compareKeys(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Interface:
+		switch {
+		case a.IsNil() && b.IsNil():
+			return 0
+		case a.IsNil():
+			return -1
+		case b.IsNil():
+			return 1
+		}
+		ea, eb := a.Elem(), b.Elem()
+		if ea.Type() != eb.Type() {
+			return cmp.Compare(ea.Type().String(), eb.Type().String())
+		}
+		return compareKeys(ea, eb)
+	}
+	return 0
+}
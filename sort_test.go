@@ -0,0 +1,70 @@
+package mask
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSortedMaps(t *testing.T) {
+	val := map[TestString]int{"c": 3, "a": 1, "b": 2}
+
+	for i := 0; i < 10; i++ {
+		masked := Must(val, WithSortedMaps(), WithKeyCollision(KeyCollisionSuffix))
+		expected := map[TestString]int{"MASKED": 1, "MASKED#2": 2, "MASKED#3": 3}
+		if !reflect.DeepEqual(masked, expected) {
+			t.Fatalf("expect %v == %v", masked, expected)
+		}
+	}
+
+	funcs := map[string]interface{}{"z": func() {}, "a": func() {}, "m": func() {}}
+	_, err := Mask(funcs, WithSortedMaps(), WithCollectErrors())
+	var maskErr *MaskError
+	if !errors.As(err, &maskErr) {
+		t.Fatalf("expected a *MaskError, got %v", err)
+	}
+	var paths []string
+	for _, e := range maskErr.Errors {
+		paths = append(paths, e.Path)
+	}
+	if strings.Join(paths, ",") != `["a"],["m"],["z"]` {
+		t.Errorf("expect errors in key order, got %v", paths)
+	}
+}
+
+func TestCompareKeys(t *testing.T) {
+	type pair struct {
+		A int
+		B string
+	}
+	tests := []struct {
+		a, b     interface{}
+		expected int
+	}{
+		{1, 2, -1},
+		{uint8(2), uint8(1), 1},
+		{"a", "a", 0},
+		{1.5, -1.5, 1},
+		{false, true, -1},
+		{pair{1, "b"}, pair{1, "a"}, 1},
+		{[2]int{1, 2}, [2]int{1, 3}, -1},
+	}
+	for _, test := range tests {
+		if c := compareKeys(reflect.ValueOf(test.a), reflect.ValueOf(test.b)); c != test.expected {
+			t.Errorf("expect compare(%v, %v) = %v == %v", test.a, test.b, c, test.expected)
+		}
+	}
+
+	var keys []interface{} = []interface{}{"b", nil, 1, "a"}
+	v := reflect.ValueOf(&keys).Elem()
+	if c := compareKeys(v.Index(1), v.Index(0)); c != -1 {
+		t.Errorf("expect nil interfaces first, got %v", c)
+	}
+	if c := compareKeys(v.Index(2), v.Index(0)); c != -1 {
+		t.Errorf("expect int < string by type name, got %v", c)
+	}
+	if c := compareKeys(v.Index(3), v.Index(0)); c != -1 {
+		t.Errorf("expect a < b, got %v", c)
+	}
+}
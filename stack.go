@@ -0,0 +1,50 @@
+package mask
+
+import (
+	"bytes"
+
+	"github.com/doejon/go-mask/detect"
+)
+
+// stackDetectors find secrets in stack traces unless MaskStack is passed detectors.
+var stackDetectors = []Detector{detect.Email(), detect.CardNumber(), detect.IBAN(), detect.Entropy()}
+
+// MaskStack scrubs a Go stack trace, e.g. of a panic or from runtime/debug.Stack, or a goroutine
+// dump of pprof before it is shipped to crash reporting. The argument values of every frame,
+// which may hold pointers into or words of secrets, are replaced by "...":
+//
+//	main.login({0xc000012345, 0x8}, 0x1)  ->  main.login(...)
+//
+// Secrets found by detectors in the remaining text, e.g. in the panic message, are replaced by
+// the placeholder for strings. Without detectors, emails, card numbers, IBANs and random looking
+// tokens are found. Function names and file locations are kept.
+func MaskStack(stack []byte, detectors ...Detector) []byte {
+	if len(detectors) == 0 {
+		detectors = stackDetectors
+	}
+	opts := make([]Option, len(detectors))
+	for i, d := range detectors {
+		opts[i] = WithDetector(d, nil)
+	}
+	lines := bytes.Split(stack, []byte("\n"))
+	out := make([][]byte, len(lines))
+	for i, line := range lines {
+		switch {
+		case i+1 < len(lines) && bytes.HasPrefix(lines[i+1], []byte("\t")) && !bytes.HasPrefix(line, []byte("\t")):
+			// a frame, followed by its file; arguments never contain parentheses
+			if bytes.HasSuffix(line, []byte(")")) {
+				if args := bytes.LastIndexByte(line, '('); args >= 0 {
+					line = append(line[:args:args], "(...)"...)
+				}
+			}
+		case bytes.HasPrefix(line, []byte("\t")), bytes.HasPrefix(line, []byte("#")), bytes.HasPrefix(line, []byte("goroutine ")):
+			// files and addresses of frames, goroutine headers
+		default:
+			if masked, err := Mask(string(line), opts...); err == nil {
+				line = []byte(masked)
+			}
+		}
+		out[i] = line
+	}
+	return bytes.Join(out, []byte("\n"))
+}
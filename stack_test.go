@@ -0,0 +1,46 @@
+package mask
+
+import (
+	"bytes"
+	"runtime/debug"
+
+	"github.com/doejon/go-mask/detect"
+	"testing"
+)
+
+func TestMaskStack(t *testing.T) {
+	stack := []byte(`panic: login failed for ada@example.com
+
+goroutine 1 [running]:
+main.(*Service).login(0xc000010018, {0xc000012345, 0x8}, {0x4b2e1f, ...})
+	/src/main.go:12 +0x1d
+main.main()
+	/src/main.go:20 +0x25
+created by main.start in goroutine 1
+	/src/main.go:30 +0x4d
+`)
+	expect := `panic: login failed for MASKED
+
+goroutine 1 [running]:
+main.(*Service).login(...)
+	/src/main.go:12 +0x1d
+main.main(...)
+	/src/main.go:20 +0x25
+created by main.start in goroutine 1
+	/src/main.go:30 +0x4d
+`
+	if out := MaskStack(stack); string(out) != expect {
+		t.Errorf("expect %s == %s", out, expect)
+	}
+	if out := MaskStack(stack, detect.Dictionary("names", "login")); !bytes.Contains(out, []byte("MASKED failed for ada@example.com")) {
+		t.Errorf("expect %s to mask login only", out)
+	}
+}
+
+func TestMaskStackRuntime(t *testing.T) {
+	stack := debug.Stack()
+	out := MaskStack(stack)
+	if len(bytes.Split(out, []byte("\n"))) != len(bytes.Split(stack, []byte("\n"))) || bytes.Contains(out, []byte("MASKED")) {
+		t.Errorf("expect %s to keep the frames of %s", out, stack)
+	}
+}
@@ -0,0 +1,274 @@
+package mask
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+// Strategy masks a single value; see package maskers for implementations.
+type Strategy = maskers.Strategy
+
+// StrategyFactory creates a strategy from the argument given in a struct tag,
+// e.g. "256" for `mask:"truncate=256"`. arg is empty if none was given.
+type StrategyFactory func(arg string) (Strategy, error)
+
+const tagName = "mask"
+
+var (
+	// strategiesMu guards strategies and keeps tagStrategies consistent with them:
+	// strategies are resolved holding a read lock, registered holding the write lock.
+	strategiesMu sync.RWMutex
+	strategies   map[string]StrategyFactory
+)
+
+func init() {
+	strategies = map[string]StrategyFactory{
+		"redact":  redactStrategy,
+		"omit":    omitFactory,
+		"ruleset": ruleSetFactory,
+		"-":       omitFactory,
+		"name": func(arg string) (Strategy, error) {
+			return maskers.Name(nil), nil
+		},
+		"date": func(arg string) (Strategy, error) {
+			p, err := maskers.ParseDatePrecision(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidTag, err)
+			}
+			return maskers.Date(p), nil
+		},
+		"agerange": func(arg string) (Strategy, error) {
+			width := 10
+			if arg != "" {
+				var err error
+				if width, err = strconv.Atoi(arg); err != nil || width < 1 {
+					return nil, fmt.Errorf("%w: invalid age range width %q", ErrInvalidTag, arg)
+				}
+			}
+			return maskers.AgeRange(width), nil
+		},
+		"partial": func(arg string) (Strategy, error) {
+			keepStart, keepEnd := 1, 1
+			if arg != "" {
+				start, end, ok := strings.Cut(arg, ":")
+				var err1, err2 error
+				keepStart, err1 = strconv.Atoi(start)
+				keepEnd, err2 = strconv.Atoi(end)
+				if !ok || err1 != nil || err2 != nil {
+					return nil, fmt.Errorf("%w: invalid partial mask %q, expected <keepStart>:<keepEnd>", ErrInvalidTag, arg)
+				}
+			}
+			return maskers.Partial(keepStart, keepEnd, maskers.Format{}), nil
+		},
+		"round": func(arg string) (Strategy, error) {
+			bucket, err := parseNumberArg("round", arg)
+			if err != nil {
+				return nil, err
+			}
+			return maskers.Round(bucket), nil
+		},
+		"jitter": func(arg string) (Strategy, error) {
+			fraction, err := parseNumberArg("jitter", arg)
+			if err != nil {
+				return nil, err
+			}
+			return maskers.Jitter(fraction), nil
+		},
+		"laplace": func(arg string) (Strategy, error) {
+			args, err := parseNumberArgs("laplace", arg, "<sensitivity>:<epsilon>")
+			if err != nil {
+				return nil, err
+			}
+			return maskers.Laplace(args[0], args[1]), nil
+		},
+		"gaussian": func(arg string) (Strategy, error) {
+			args, err := parseNumberArgs("gaussian", arg, "<sensitivity>:<epsilon>:<delta>")
+			if err != nil || args[2] >= 1 {
+				return nil, fmt.Errorf("%w: invalid gaussian argument %q, expected <sensitivity>:<epsilon>:<delta> with a delta below 1", ErrInvalidTag, arg)
+			}
+			return maskers.Gaussian(args[0], args[1], args[2]), nil
+		},
+		"geo": func(arg string) (Strategy, error) {
+			if arg == "" {
+				return maskers.Geo(1000), nil
+			}
+			radius, err := parseNumberArg("geo", arg)
+			if err != nil {
+				return nil, err
+			}
+			return maskers.Geo(radius), nil
+		},
+		"trim": func(string) (Strategy, error) {
+			return maskers.Trim(), nil
+		},
+		"hash": func(string) (Strategy, error) {
+			return maskers.Hash(nil), nil
+		},
+		"truncate": func(arg string) (Strategy, error) {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%w: invalid truncate length %q", ErrInvalidTag, arg)
+			}
+			return maskers.Truncate(n), nil
+		},
+		"sequence": func(arg string) (Strategy, error) {
+			if arg == "" {
+				arg = "MASKED-%d"
+			}
+			return maskers.Sequence(arg), nil
+		},
+	}
+}
+
+// parseNumberArg parses the positive number argument of the strategy name.
+func parseNumberArg(name, arg string) (float64, error) {
+	x, err := strconv.ParseFloat(arg, 64)
+	if err != nil || x <= 0 || math.IsInf(x, 0) {
+		return 0, fmt.Errorf("%w: invalid %s argument %q, expected a positive number", ErrInvalidTag, name, arg)
+	}
+	return x, nil
+}
+
+// parseNumberArgs parses the colon separated positive number arguments of the
+// strategy name, formatted like usage, e.g. "<sensitivity>:<epsilon>".
+func parseNumberArgs(name, arg, usage string) ([]float64, error) {
+	parts := strings.Split(arg, ":")
+	args := make([]float64, len(parts))
+	for i, part := range parts {
+		x, err := strconv.ParseFloat(part, 64)
+		if err != nil || x <= 0 || math.IsInf(x, 0) || len(parts) != strings.Count(usage, ":")+1 {
+			return nil, fmt.Errorf("%w: invalid %s argument %q, expected %s", ErrInvalidTag, name, arg, usage)
+		}
+		args[i] = x
+	}
+	return args, nil
+}
+
+func omitFactory(string) (Strategy, error) {
+	return Omit(), nil
+}
+
+// RegisterStrategy makes a strategy available to struct tags under the given name.
+// Registering a name twice replaces the previous factory; this is how built-in
+// strategies can be configured, e.g. to use a secret key for "name":
+//
+//	mask.RegisterStrategy("name", func(string) (mask.Strategy, error) {
+//	  return maskers.Name(key), nil
+//	})
+//
+// It is safe to register strategies while masking.
+func RegisterStrategy(name string, factory StrategyFactory) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[name] = factory
+	tagStrategies.Range(func(tag, _ interface{}) bool {
+		tagStrategies.Delete(tag)
+		return true
+	})
+}
+
+// ParseStrategy resolves a strategy referenced as in a struct tag, e.g. "partial=2:2"
+// or a pipeline like "trim|hash|truncate=12".
+// This allows referencing strategies from configuration, see WithPathStrategy.
+func ParseStrategy(tag string) (Strategy, error) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	return parseStrategy(tag)
+}
+
+// parseStrategy resolves tag, holding strategiesMu. Strategies separated by "|"
+// are applied in order, see maskers.Compose.
+func parseStrategy(tag string) (Strategy, error) {
+	if strings.Contains(tag, "|") {
+		parts := strings.Split(tag, "|")
+		pipeline := make([]Strategy, len(parts))
+		for i, part := range parts {
+			strategy, err := parseStrategy(part)
+			if err != nil {
+				return nil, err
+			}
+			pipeline[i] = strategy
+		}
+		return maskers.Compose(pipeline...), nil
+	}
+	name, arg, _ := strings.Cut(tag, "=")
+	factory, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownStrategy, name)
+	}
+	return factory(arg)
+}
+
+// tagStrategies caches the strategies of all tags resolved so far, so every tag
+// is backed by a single strategy instance, e.g. keeping the state of Session
+// and the counter of "sequence" across fields.
+var tagStrategies sync.Map
+
+// strategyFromTag resolves the strategy referenced by a `mask:"name[=arg]"` tag.
+// A nil strategy is returned for an empty tag.
+func strategyFromTag(tag string) (Strategy, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	if strategy, ok := tagStrategies.Load(tag); ok {
+		return strategy.(Strategy), nil
+	}
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	strategy, err := parseStrategy(tag)
+	if err != nil {
+		return nil, err
+	}
+	// concurrent calls resolving the same tag agree on the first strategy stored
+	cached, _ := tagStrategies.LoadOrStore(tag, strategy)
+	return cached.(Strategy), nil
+}
+
+// applyStrategy masks v using s. Nil pointers and interfaces are kept as they are,
+// non-nil ones are masked by their element and returned as a new pointer.
+// Wrappers like sql.NullString are masked by the value they hold, see RegisterWrapper.
+func applyStrategy(s Strategy, v reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, nil
+		}
+		inner, err := applyStrategy(s, v.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		dc := reflect.New(v.Type().Elem())
+		dc.Elem().Set(inner)
+		return dc, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		return applyStrategy(s, v.Elem())
+	case reflect.Struct:
+		if w, ok := unwrap(v); ok {
+			return applyWrapped(s, v, w)
+		}
+	}
+
+	out, err := s.Mask(v)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if !out.IsValid() {
+		return reflect.Zero(v.Type()), nil
+	}
+	if out.Type() != v.Type() {
+		if !out.Type().ConvertibleTo(v.Type()) {
+			return reflect.Value{}, fmt.Errorf("%w: strategy %s returned %v which cannot be converted to %v", ErrIncompatibleStrategy, s.Name(), out.Type(), v.Type())
+		}
+		out = out.Convert(v.Type())
+	}
+	return out, nil
+}
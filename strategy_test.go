@@ -0,0 +1,209 @@
+package mask
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+type personName string
+
+type testPerson struct {
+	Name     string      `mask:"name"`
+	Nickname *personName `mask:"name"`
+	Spouse   *personName `mask:"name"`
+	Email    string
+}
+
+func TestStrategyTag(t *testing.T) {
+	nick := personName("Ada Lovelace")
+	val := &testPerson{
+		Name:     "Ada Lovelace",
+		Nickname: &nick,
+		Email:    "ada@example.com",
+	}
+	masked := Must(val)
+
+	if val.Name != "Ada Lovelace" || *val.Nickname != "Ada Lovelace" {
+		t.Errorf("expect original to stay untouched, got %v", val)
+	}
+	if masked.Name == val.Name || len(strings.Fields(masked.Name)) != 2 {
+		t.Errorf("expect %v to be pseudonymized", masked.Name)
+	}
+	if masked.Nickname == val.Nickname {
+		t.Errorf("expect pointer to be copied")
+	}
+	if string(*masked.Nickname) != masked.Name {
+		t.Errorf("expect %v == %v", *masked.Nickname, masked.Name)
+	}
+	if masked.Spouse != nil {
+		t.Errorf("expect %v == nil", masked.Spouse)
+	}
+	if masked.Email != val.Email {
+		t.Errorf("expect %v == %v", masked.Email, val.Email)
+	}
+}
+
+func TestUnknownStrategyTag(t *testing.T) {
+	type S struct {
+		A string `mask:"does-not-exist"`
+	}
+	if _, err := Mask(S{A: "a"}); err == nil {
+		t.Errorf("expected an error for an unknown strategy")
+	}
+}
+
+func TestRegisterStrategy(t *testing.T) {
+	RegisterStrategy("test-upper", func(arg string) (Strategy, error) {
+		return maskers.StringFunc("test-upper", strings.ToUpper), nil
+	})
+	type S struct {
+		A string `mask:"test-upper"`
+		B int    `mask:"test-upper"`
+	}
+	masked, err := Mask(S{A: "a"})
+	if err == nil {
+		t.Errorf("expected an error for masking an int as string, got %v", masked)
+	}
+
+	type T struct {
+		A string `mask:"test-upper"`
+	}
+	res := Must(T{A: "a"})
+	if !reflect.DeepEqual(res, T{A: "A"}) {
+		t.Errorf("expect %v == A", res.A)
+	}
+}
+
+func TestDateStrategyTag(t *testing.T) {
+	type S struct {
+		Birthday  time.Time  `mask:"date=year"`
+		Joined    *time.Time `mask:"date=month"`
+		Formatted string     `mask:"date"`
+		Age       int        `mask:"agerange=5"`
+	}
+	joined := time.Date(2020, time.March, 4, 5, 6, 7, 0, time.UTC)
+	masked := Must(S{
+		Birthday:  time.Date(1987, time.May, 3, 0, 0, 0, 0, time.UTC),
+		Joined:    &joined,
+		Formatted: "1987-05-03",
+		Age:       37,
+	})
+
+	if !masked.Birthday.Equal(time.Date(1987, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expect %v to be generalized to its year", masked.Birthday)
+	}
+	if !masked.Joined.Equal(time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expect %v to be generalized to its month", masked.Joined)
+	}
+	if masked.Formatted != "1987-01-01" {
+		t.Errorf("expect %v == 1987-01-01", masked.Formatted)
+	}
+	if masked.Age != 35 {
+		t.Errorf("expect %v == 35", masked.Age)
+	}
+
+	type Invalid struct {
+		Birthday time.Time `mask:"date=week"`
+	}
+	if _, err := Mask(Invalid{}); err == nil {
+		t.Errorf("expected an error for an unknown precision")
+	}
+}
+
+func TestPartialStrategyTag(t *testing.T) {
+	type S struct {
+		Card  string `mask:"partial=0:4"`
+		Name  string `mask:"partial"`
+		Other string `mask:"partial=1"`
+	}
+	masked, err := Mask(S{Card: "4111111111111111", Name: "山田太郎"})
+	if err == nil {
+		t.Errorf("expected an error for an invalid partial mask, got %v", masked)
+	}
+
+	type T struct {
+		Card string `mask:"partial=0:4"`
+		Name string `mask:"partial"`
+	}
+	res := Must(T{Card: "4111111111111111", Name: "山田太郎"})
+	if res.Card != "************1111" {
+		t.Errorf("expect %v == ************1111", res.Card)
+	}
+	if res.Name != "山**郎" {
+		t.Errorf("expect %v == 山**郎", res.Name)
+	}
+}
+
+func TestNumberStrategyTags(t *testing.T) {
+	type order struct {
+		Total    float64 `mask:"round=10"`
+		Items    uint    `mask:"round=5"`
+		Discount int     `mask:"jitter=0.1"`
+	}
+	res := Must(order{Total: 123.45, Items: 13, Discount: 1000})
+	if res.Total != 120 || res.Items != 15 {
+		t.Errorf("expect %+v to be rounded", res)
+	}
+	if res.Discount < 900 || res.Discount > 1100 {
+		t.Errorf("expect %v to be within 900 and 1100", res.Discount)
+	}
+
+	type invalid struct {
+		Total float64 `mask:"round=-1"`
+	}
+	if _, err := Mask(invalid{}); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expect %v to be %v", err, ErrInvalidTag)
+	}
+}
+
+func TestNoiseStrategyTags(t *testing.T) {
+	type stats struct {
+		Visitors int     `mask:"laplace=1:0.5"`
+		Revenue  float64 `mask:"gaussian=100:0.5:1e-6"`
+	}
+	if _, err := Mask(stats{Visitors: 100, Revenue: 1e4}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	for _, tag := range []string{"laplace=1", "laplace=1:0", "laplace=1:x", "gaussian=1:0.5", "gaussian=1:0.5:1"} {
+		if _, err := ParseStrategy(tag); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("%s: expect %v to be %v", tag, err, ErrInvalidTag)
+		}
+	}
+}
+
+func TestGeoStrategyTag(t *testing.T) {
+	type location struct {
+		Lat, Lng float64
+	}
+	type checkin struct {
+		Location location `mask:"geo"`
+		Geohash  string   `mask:"geo=5000"`
+	}
+	res := Must(checkin{Location: location{Lat: 52.520008, Lng: 13.404954}, Geohash: "u33dc0cppj"})
+	expect := checkin{Location: location{Lat: 52.52, Lng: 13.4}, Geohash: "u33d"}
+	if res != expect {
+		t.Errorf("expect %+v == %+v", res, expect)
+	}
+}
+
+func TestStrategyPipelineTag(t *testing.T) {
+	type user struct {
+		Email string `mask:"trim|hash|truncate=12"`
+		Note  string `mask:"trim|partial=1:0,if=Email!="`
+	}
+	res := Must(user{Email: " ada@example.com\n", Note: " hello "})
+	expect := user{Email: "b5fc85e55755…", Note: "h****"}
+	if res != expect {
+		t.Errorf("expect %+v == %+v", res, expect)
+	}
+
+	if _, err := ParseStrategy("trim|unknown"); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("expect %v to be %v", err, ErrUnknownStrategy)
+	}
+}
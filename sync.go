@@ -0,0 +1,79 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var syncMapType = reflect.TypeOf(sync.Map{})
+
+// _zero returns the zero value of the type of x.
+func _zero(x interface{}, s *state) (interface{}, error) {
+	return reflect.Zero(reflect.TypeOf(x)).Interface(), nil
+}
+
+// _atomic copies atomic values by loading the current value and storing a
+// deep copy of it into a new instance.
+func _atomic(x interface{}, s *state) (interface{}, error) {
+	v := reflect.ValueOf(x)
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: must pass an atomic value; got %v", ErrKindMismatch, v.Kind())
+	}
+	src := reflect.New(v.Type())
+	src.Elem().Set(v)
+	load := src.MethodByName("Load")
+	if !load.IsValid() {
+		return nil, fmt.Errorf("%w: must pass an atomic value; got %v", ErrKindMismatch, v.Type())
+	}
+
+	dc := reflect.New(v.Type())
+	loaded := load.Call(nil)[0]
+	switch loaded.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if loaded.IsNil() {
+			return dc.Elem().Interface(), nil
+		}
+		item, err := _anything(loaded.Interface(), s)
+		if err != nil {
+			return nil, err
+		}
+		loaded = reflect.ValueOf(item)
+	}
+	dc.MethodByName("Store").Call([]reflect.Value{loaded})
+	return dc.Elem().Interface(), nil
+}
+
+// _syncMap copies a sync.Map including deep copies of its keys and values.
+func _syncMap(x interface{}, s *state) (interface{}, error) {
+	v := reflect.ValueOf(x)
+	if v.Type() != syncMapType {
+		return nil, fmt.Errorf("%w: must pass a value of type sync.Map; got %v", ErrKindMismatch, v.Type())
+	}
+	src := reflect.New(syncMapType)
+	src.Elem().Set(v)
+	dc := reflect.New(syncMapType)
+
+	m := dc.Interface().(*sync.Map)
+	var err error
+	src.Interface().(*sync.Map).Range(func(key, value any) bool {
+		var k, item interface{}
+		s.pushKey(key)
+		defer s.pop()
+		s.inKey = true
+		k, err = _anything(key, s)
+		s.inKey = false
+		if err != nil {
+			return false
+		}
+		if item, err = _anything(value, s); err != nil {
+			return false
+		}
+		m.Store(k, item)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dc.Elem().Interface(), nil
+}
@@ -0,0 +1,77 @@
+package mask
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type testSyncItem struct {
+	Secret TestString
+}
+
+type testSync struct {
+	Mu      sync.Mutex
+	RW      sync.RWMutex
+	Once    sync.Once
+	Map     sync.Map
+	Count   atomic.Int64
+	Flag    atomic.Bool
+	Value   atomic.Value
+	Pointer atomic.Pointer[testSyncItem]
+	Empty   atomic.Pointer[testSyncItem]
+}
+
+func TestSyncTypes(t *testing.T) {
+	val := &testSync{}
+	val.Mu.Lock()
+	defer val.Mu.Unlock()
+	val.RW.RLock()
+	defer val.RW.RUnlock()
+	val.Once.Do(func() {})
+	val.Map.Store("key", TestString("secret"))
+	val.Count.Store(42)
+	val.Flag.Store(true)
+	val.Value.Store(TestString("secret"))
+	item := &testSyncItem{Secret: "secret"}
+	val.Pointer.Store(item)
+
+	masked := Must(val)
+
+	if !masked.Mu.TryLock() {
+		t.Errorf("expect copied mutex to be unlocked")
+	}
+	if !masked.RW.TryLock() {
+		t.Errorf("expect copied rw mutex to be unlocked")
+	}
+	ran := false
+	masked.Once.Do(func() { ran = true })
+	if !ran {
+		t.Errorf("expect copied once to be reset")
+	}
+	if v, ok := masked.Map.Load("key"); !ok || v != TestString("MASKED") {
+		t.Errorf("expect map value %v == MASKED", v)
+	}
+	if v, _ := val.Map.Load("key"); v != TestString("secret") {
+		t.Errorf("expect original map value %v to stay untouched", v)
+	}
+	if masked.Count.Load() != 42 {
+		t.Errorf("expect %v == 42", masked.Count.Load())
+	}
+	if !masked.Flag.Load() {
+		t.Errorf("expect flag to be copied")
+	}
+	if v := masked.Value.Load(); v != TestString("MASKED") {
+		t.Errorf("expect %v == MASKED", v)
+	}
+	p := masked.Pointer.Load()
+	if p == nil || p == item {
+		t.Fatalf("expect %v to be a copy of %v", p, item)
+	}
+	if p.Secret != "MASKED" || item.Secret != "secret" {
+		t.Errorf("expect %v == MASKED and %v == secret", p.Secret, item.Secret)
+	}
+	if masked.Empty.Load() != nil {
+		t.Errorf("expect empty pointer to stay nil")
+	}
+}
@@ -0,0 +1,72 @@
+package mask
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagAlias masks fields tagged with key:"value" using the strategy of a mask tag, see WithTagAlias.
+type tagAlias struct {
+	key, value, tag string
+}
+
+// WithTagKeys reads strategies from the struct tags with the given keys instead of
+// mask, so models tagged for other libraries need not be retagged:
+//
+//	mask.WithTagKeys("mask", "sensitive", "pii")
+//
+// Fields are masked by the tag of the first key they are tagged with. Tags have
+// the syntax of mask tags, e.g. `pii:"partial=2:2"`.
+func WithTagKeys(keys ...string) Option {
+	return func(o *options) {
+		o.tagKeys = append(o.tagKeys, keys...)
+	}
+}
+
+// WithTagAlias masks fields tagged with key:"value" like fields tagged with mask:"tag",
+// honoring the conventions of other libraries:
+//
+//	mask.WithTagAlias("logger", "omit", "redact")
+//	mask.WithTagAlias("json", "-", "redact")
+//
+// A tag matches if it, or one of its comma-separated parts, equals value.
+// Tags read per WithTagKeys take precedence over aliases.
+func WithTagAlias(key, value, tag string) Option {
+	return func(o *options) {
+		o.tagAliases = append(o.tagAliases, tagAlias{key: key, value: value, tag: tag})
+	}
+}
+
+// tag returns the mask tag of the struct field f, see WithTagKeys and WithTagAlias.
+func (o *options) tag(f reflect.StructField) string {
+	if len(o.tagKeys) == 0 && len(o.tagAliases) == 0 {
+		return f.Tag.Get(tagName)
+	}
+	keys := o.tagKeys
+	if len(keys) == 0 {
+		keys = []string{tagName}
+	}
+	for _, key := range keys {
+		if tag, ok := f.Tag.Lookup(key); ok {
+			return tag
+		}
+	}
+	for _, a := range o.tagAliases {
+		if tag, ok := f.Tag.Lookup(a.key); ok && a.matches(tag) {
+			return a.tag
+		}
+	}
+	return ""
+}
+
+func (a tagAlias) matches(tag string) bool {
+	if tag == a.value {
+		return true
+	}
+	for _, part := range strings.Split(tag, ",") {
+		if part == a.value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package mask
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWithTagKeys(t *testing.T) {
+	type user struct {
+		Name  string `pii:"partial=1:0"`
+		Email string `sensitive:"redact" pii:"partial=1:0"`
+		Token string `mask:"redact"`
+		Note  string
+	}
+	val := user{Name: "Ada", Email: "ada@example.com", Token: "tok", Note: "hi"}
+
+	masked, err := Mask(val, WithTagKeys("sensitive", "pii"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := user{Name: "A**", Email: "MASKED", Token: "tok", Note: "hi"}
+	if masked != expect {
+		t.Errorf("expect %+v == %+v", masked, expect)
+	}
+
+	masked, err = Mask(val, WithTagKeys("mask", "pii"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = user{Name: "A**", Email: "a**************", Token: "MASKED", Note: "hi"}
+	if masked != expect {
+		t.Errorf("expect %+v == %+v", masked, expect)
+	}
+
+	b, err := json.Marshal(JSON(val, WithTagKeys("pii")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); s != `{"Name":"A**","Email":"a**************","Token":"tok","Note":"hi"}` {
+		t.Errorf("expect %v == %v", s, `{"Name":"A**","Email":"a**************","Token":"tok","Note":"hi"}`)
+	}
+}
+
+func TestWithTagAlias(t *testing.T) {
+	type request struct {
+		User     string
+		Password string `json:"-"`
+		APIKey   string `json:"api_key" logger:"omit,redacted"`
+		Session  string `json:"session" mask:"partial=2:0"`
+		Dash     string `json:"-,"`
+	}
+	val := request{User: "ada", Password: "s3cr3t", APIKey: "key", Session: "abcdef", Dash: "dash"}
+	masked, err := Mask(val, WithTagAlias("json", "-", "redact"), WithTagAlias("logger", "omit", "redact"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := request{User: "ada", Password: "MASKED", APIKey: "MASKED", Session: "ab****", Dash: "MASKED"}
+	if masked != expect {
+		t.Errorf("expect %+v == %+v", masked, expect)
+	}
+
+	if _, err := Mask(val, WithTagAlias("json", "-", "unknown")); err == nil {
+		t.Errorf("expect an error for an unknown strategy")
+	}
+}
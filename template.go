@@ -0,0 +1,68 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+// FuncMap returns template functions masking values inline, so rendered emails, admin
+// pages and reports redact values without preprocessing the data. It works with
+// text/template as well as html/template:
+//
+//	tmpl := template.New("receipt").Funcs(mask.FuncMap())
+//
+// The functions are
+//
+//	{{ mask .Customer }}            masks a value like Mask using opts
+//	{{ .Notes | mask "redact" }}    masks a value using a strategy referenced like in struct tags
+//	{{ .Email | maskEmail }}        a**@example.com, keeping the domain
+//	{{ .Card | maskPartial 0 4 }}   ************1111, like the strategy partial=0:4
+func FuncMap(opts ...Option) map[string]interface{} {
+	return map[string]interface{}{
+		"mask": func(args ...interface{}) (interface{}, error) {
+			switch len(args) {
+			case 1:
+				return Mask(args[0], opts...)
+			case 2:
+				tag, ok := args[0].(string)
+				if !ok {
+					return nil, fmt.Errorf("%w: expected a strategy, got %v", ErrInvalidTag, args[0])
+				}
+				strategy, err := ParseStrategy(tag)
+				if err != nil {
+					return nil, err
+				}
+				v := reflect.ValueOf(args[1])
+				if !v.IsValid() {
+					return nil, nil
+				}
+				masked, err := applyStrategy(strategy, v)
+				if err != nil {
+					return nil, err
+				}
+				return masked.Interface(), nil
+			}
+			return nil, fmt.Errorf("mask: expected a value and an optional strategy, got %d arguments", len(args))
+		},
+		"maskEmail":   maskEmail,
+		"maskPartial": maskPartial,
+	}
+}
+
+// maskEmail masks all but the first character of the local part of the email address s.
+// Strings which are no email address are masked partially keeping their first character.
+func maskEmail(s string) string {
+	local, domain, ok := strings.Cut(s, "@")
+	if !ok {
+		return maskPartial(1, 0, s)
+	}
+	return maskPartial(1, 0, local) + "@" + domain
+}
+
+func maskPartial(keepStart, keepEnd int, s string) string {
+	masked, _ := maskers.Partial(keepStart, keepEnd, maskers.Format{}).Mask(reflect.ValueOf(s))
+	return masked.String()
+}
@@ -0,0 +1,45 @@
+package mask
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFuncMap(t *testing.T) {
+	type customer struct {
+		Name     string
+		Password string `mask:"redact"`
+	}
+	data := struct {
+		Customer customer
+		Email    string
+		Card     string
+		Notes    string
+	}{customer{"Ada", "secret"}, "ada@example.com", "4111111111111111", "call <back>"}
+
+	const text = `{{ mask .Customer }} {{ .Email | maskEmail }} {{ .Card | maskPartial 0 4 }} {{ .Notes | mask "redact" }}`
+	var b strings.Builder
+	tmpl := template.Must(template.New("text").Funcs(FuncMap()).Parse(text))
+	if err := tmpl.Execute(&b, data); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expect := "{Ada MASKED} a**@example.com ************1111 MASKED"; b.String() != expect {
+		t.Errorf("expect %v == %v", b.String(), expect)
+	}
+
+	b.Reset()
+	html := htmltemplate.Must(htmltemplate.New("html").Funcs(FuncMap()).Parse(`<p>{{ .Notes | mask "partial=5:0" }}</p>`))
+	if err := html.Execute(&b, data); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expect := "<p>call ******</p>"; b.String() != expect {
+		t.Errorf("expect %v == %v", b.String(), expect)
+	}
+
+	tmpl = template.Must(template.New("invalid").Funcs(FuncMap()).Parse(`{{ .Notes | mask "unknown" }}`))
+	if err := tmpl.Execute(&b, data); err == nil {
+		t.Errorf("expected an error")
+	}
+}
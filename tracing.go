@@ -0,0 +1,36 @@
+package mask
+
+import "reflect"
+
+// Tracer starts a span for every call to Mask or a JSON encoding, see WithTracer.
+// It is called concurrently by concurrent calls.
+type Tracer interface {
+	// Start starts the span of the call op, "mask" or "json" like CallMetrics.Op,
+	// masking a value of type typ; typ is nil for a nil interface.
+	Start(op string, typ reflect.Type) Span
+}
+
+// Span is the span of a single call started by a Tracer.
+type Span interface {
+	// End ends the span, reporting the number of values visited and the error
+	// returned by the call, if any.
+	End(nodes int, err error)
+}
+
+// WithTracer traces every call using tracer, so slow masking of large payloads shows up
+// in traces rather than as unexplained latency. The maskotel module starts OpenTelemetry spans.
+func WithTracer(tracer Tracer) Option {
+	return func(o *options) {
+		o.tracer = tracer
+	}
+}
+
+// startSpan starts the span of the call op masking x.
+func (s *state) startSpan(op string, x interface{}) Span {
+	return s.opts.tracer.Start(op, reflect.TypeOf(x))
+}
+
+// endSpan ends span reporting *err; the root value is not counted as visit.
+func (s *state) endSpan(span Span, err *error) {
+	span.End(s.visits+1, *err)
+}
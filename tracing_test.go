@@ -0,0 +1,63 @@
+package mask
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testSpan struct {
+	op    string
+	typ   reflect.Type
+	nodes int
+	err   error
+	ended bool
+}
+
+func (s *testSpan) End(nodes int, err error) {
+	s.nodes, s.err, s.ended = nodes, err, true
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(op string, typ reflect.Type) Span {
+	s := &testSpan{op: op, typ: typ}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := &testTracer{}
+	type payload struct {
+		Email string `mask:"redact"`
+		Tags  []string
+	}
+	val := payload{"ada@example.com", []string{"a", "b"}}
+
+	if _, err := Mask(val, WithTracer(tracer)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := json.Marshal(JSON(val, WithTracer(tracer))); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := Mask(make(chan int), WithTracer(tracer)); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expect 3 spans, got %v", tracer.spans)
+	}
+	for i, op := range []string{"mask", "json"} {
+		// payload, Email, Tags and its two elements
+		s := tracer.spans[i]
+		if !s.ended || s.op != op || s.typ != reflect.TypeOf(val) || s.nodes != 5 || s.err != nil {
+			t.Errorf("expect %+v == %v span of 5 nodes", s, op)
+		}
+	}
+	if s := tracer.spans[2]; !s.ended || !errors.Is(s.err, ErrUnsupportedKind) {
+		t.Errorf("expect %+v to end with %v", s, ErrUnsupportedKind)
+	}
+}
@@ -0,0 +1,79 @@
+package mask
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Path locates a value within the value passed to Transform.
+type Path struct {
+	root     string
+	segments []segment
+}
+
+// String formats the path like in findings, e.g. Order.Items[3].Card.
+func (p Path) String() string {
+	s := state{root: p.root, path: p.segments}
+	return s.currentPath()
+}
+
+// Match reports whether the path matches pattern, which uses the syntax of
+// WithPathStrategy, e.g. Items[*].Card.Number. Invalid patterns match nothing.
+func (p Path) Match(pattern string) bool {
+	var pp pathPattern
+	if cached, ok := pathPatterns.Load(pattern); ok {
+		pp = cached.(pathPattern)
+	} else {
+		var err error
+		if pp, err = parsePathPattern(pattern); err != nil {
+			return false
+		}
+		pathPatterns.Store(pattern, pp)
+	}
+	return len(p.segments) > 0 && pp.match(p.segments)
+}
+
+// pathPatterns caches the patterns parsed by Path.Match.
+var pathPatterns sync.Map
+
+// Transform makes a deep copy of x like Mask, replacing every value for which fn
+// returns true by the value returned along with it. Values which are replaced are
+// not visited any further. fn is called for every value but pointers and interfaces,
+// which are followed to their elements:
+//
+//	trimmed, err := mask.Transform(form, func(p mask.Path, v interface{}) (interface{}, bool) {
+//	  s, ok := v.(string)
+//	  return strings.TrimSpace(s), ok
+//	})
+//
+// Replacements must be convertible to the type of the replaced value; nil replaces
+// it by its zero value. Nothing but fn changes the copy: tags, MaskXXX methods and
+// masking options are ignored, other options like WithMaxDepth or WithPreserveAliasing apply.
+// Masking is the transform applying them.
+func Transform[T any](x T, fn func(path Path, v interface{}) (interface{}, bool), opts ...Option) (T, error) {
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		o.transform = fn
+	})
+	return Mask(x, opts...)
+}
+
+// transformed returns the decision of the transform function about v,
+// which replaces it or keeps it unmasked.
+func (s *state) transformed(v reflect.Value) HookResult {
+	p := Path{root: s.root, segments: append([]segment(nil), s.path...)}
+	replacement, ok := s.opts.transform(p, v.Interface())
+	if !ok {
+		return HookResult{Action: HookKeep}
+	}
+	return HookResult{Action: HookReplace, Value: reflect.ValueOf(replacement)}
+}
+
+// Clone makes a deep copy of x like Mask, without masking anything: no MaskXXX
+// method, strategy, hook or detector is called. Options like WithMaxDepth or
+// WithSkipUnsupported apply, masking options are ignored.
+func Clone[T any](x T, opts ...Option) (T, error) {
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		o.clone = true
+	})
+	return Mask(x, opts...)
+}
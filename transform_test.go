@@ -0,0 +1,117 @@
+package mask
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type signupForm struct {
+	Name     string `mask:"redact"`
+	Email    *string
+	Comment  TestString
+	Tags     []string
+	Answers  map[testEmail]string
+	Password string
+}
+
+func TestTransform(t *testing.T) {
+	email := "  Ada@Example.com "
+	val := signupForm{
+		Name:     " Ada ",
+		Email:    &email,
+		Comment:  " hi ",
+		Tags:     []string{" a", "b "},
+		Answers:  map[testEmail]string{" q ": " yes "},
+		Password: "s3cr3t",
+	}
+	var paths []string
+	out, err := Transform(val, func(p Path, v interface{}) (interface{}, bool) {
+		paths = append(paths, p.String())
+		if p.Match("Password") {
+			return nil, true
+		}
+		s, ok := v.(string)
+		return strings.TrimSpace(s), ok
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	trimmed := "Ada@Example.com"
+	expected := signupForm{
+		Name:     "Ada",
+		Email:    &trimmed,
+		Comment:  " hi ",
+		Tags:     []string{"a", "b"},
+		Answers:  map[testEmail]string{" q ": "yes"},
+		Password: "",
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("expect %v == %v", out, expected)
+	}
+	if *val.Email != email || val.Tags[0] != " a" {
+		t.Errorf("expect original to be kept, got %v", val)
+	}
+	expectedPaths := []string{
+		"signupForm",
+		"signupForm.Name",
+		"signupForm.Email",
+		"signupForm.Comment",
+		"signupForm.Tags",
+		"signupForm.Tags[0]",
+		"signupForm.Tags[1]",
+		"signupForm.Answers",
+		`signupForm.Answers[" q "]`,
+		"signupForm.Password",
+	}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("expect %v == %v", paths, expectedPaths)
+	}
+}
+
+func TestTransformIncompatible(t *testing.T) {
+	_, err := Transform(struct{ N int }{1}, func(p Path, v interface{}) (interface{}, bool) {
+		return "x", p.Match("N")
+	})
+	if err == nil {
+		t.Errorf("expect replacing an int by a string to fail")
+	}
+}
+
+type countingMasker struct {
+	Value string
+}
+
+var maskCalls int
+
+func (c *countingMasker) MaskXXX() {
+	maskCalls++
+	c.Value = "MASKED"
+}
+
+func TestClone(t *testing.T) {
+	val := map[string]interface{}{
+		"person":  testPerson{Name: "Ada Lovelace", Email: "ada@example.com"},
+		"counted": &countingMasker{Value: "v"},
+		"list":    []TestString{"a"},
+	}
+	val["self"] = val
+	clone, err := Clone(val, WithPathStrategy("**", mustParseStrategy(t, "redact")), WithSortedMaps())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if maskCalls != 0 {
+		t.Errorf("expect MaskXXX not to be called, got %d calls", maskCalls)
+	}
+	for _, k := range []string{"person", "counted", "list"} {
+		if !reflect.DeepEqual(clone[k], val[k]) {
+			t.Errorf("expect %v == %v", clone[k], val[k])
+		}
+	}
+	if clone["counted"] == val["counted"] {
+		t.Errorf("expect pointers to be copied")
+	}
+	if reflect.ValueOf(clone["self"]).Pointer() != reflect.ValueOf(clone).Pointer() {
+		t.Errorf("expect cycles to be kept")
+	}
+}
@@ -0,0 +1,78 @@
+package mask
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testTruncated string
+
+func TestTruncateTag(t *testing.T) {
+	type upload struct {
+		Comment string `mask:"truncate=5"`
+		Blob    []byte `mask:"truncate=2"`
+	}
+	masked, err := Mask(upload{Comment: "Lorem ipsum", Blob: []byte("abc")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := upload{Comment: "Lorem…", Blob: []byte("ab")}
+	if !reflect.DeepEqual(masked, expect) {
+		t.Errorf("expect %+v == %+v", masked, expect)
+	}
+
+	type invalid struct {
+		Comment string `mask:"truncate=x"`
+	}
+	if _, err := Mask(invalid{}); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expect %v to be %v", err, ErrInvalidTag)
+	}
+}
+
+func TestWithMaxLen(t *testing.T) {
+	type event struct {
+		Name    testTruncated
+		Payload []byte
+		Items   []string
+		Attrs   map[string]string
+		Secret  string `mask:"redact"`
+	}
+	long := strings.Repeat("x", 20)
+	val := event{
+		Name:    testTruncated(long),
+		Payload: []byte(long),
+		Items:   []string{"a", long, "c"},
+		Attrs:   map[string]string{long: long},
+		Secret:  long,
+	}
+	opts := []Option{WithMaxStringLen(4), WithMaxSliceLen(2)}
+	masked, err := Mask(val, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := event{
+		Name:    "xxxx…",
+		Payload: []byte("xxxx"),
+		Items:   []string{"a", "xxxx…"},
+		Attrs:   map[string]string{long: "xxxx…"},
+		Secret:  "MASKED",
+	}
+	if !reflect.DeepEqual(masked, expect) {
+		t.Errorf("expect %+v == %+v", masked, expect)
+	}
+	if len(val.Items) != 3 || val.Items[1] != long {
+		t.Errorf("expect %v to be unchanged", val)
+	}
+
+	b, err := json.Marshal(JSON(val, opts...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectJSON, _ := json.Marshal(expect)
+	if string(b) != string(expectJSON) {
+		t.Errorf("expect %s == %s", b, expectJSON)
+	}
+}
@@ -0,0 +1,89 @@
+package mask
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// typeCopiers copy types which cannot be copied field by field,
+// mostly because they hold their state in unexported fields.
+// They take precedence over the kind based copiers; see Copier.Register for custom ones.
+var typeCopiers map[reflect.Type]copier
+
+func init() {
+	typeCopiers = map[reflect.Type]copier{
+		reflect.TypeOf(time.Time{}):           _immutable,
+		reflect.TypeOf((*time.Location)(nil)): _immutable,
+		reflect.TypeOf(netip.Addr{}):          _immutable,
+		reflect.TypeOf(netip.AddrPort{}):      _immutable,
+		reflect.TypeOf(netip.Prefix{}):        _immutable,
+		reflect.TypeOf(big.Int{}):             _bigInt,
+		reflect.TypeOf(big.Float{}):           _bigFloat,
+		reflect.TypeOf(big.Rat{}):             _bigRat,
+		reflect.TypeOf(url.Userinfo{}):        _userinfo,
+
+		// locks and one-shot primitives are reset in the copy:
+		// copying them in whatever state they currently have
+		// might hand out a mutex which stays locked forever.
+		reflect.TypeOf(sync.Mutex{}):     _zero,
+		reflect.TypeOf(sync.RWMutex{}):   _zero,
+		reflect.TypeOf(sync.Once{}):      _zero,
+		reflect.TypeOf(sync.WaitGroup{}): _zero,
+		syncMapType:                      _syncMap,
+
+		reflect.TypeOf(atomic.Bool{}):    _atomic,
+		reflect.TypeOf(atomic.Int32{}):   _atomic,
+		reflect.TypeOf(atomic.Int64{}):   _atomic,
+		reflect.TypeOf(atomic.Uint32{}):  _atomic,
+		reflect.TypeOf(atomic.Uint64{}):  _atomic,
+		reflect.TypeOf(atomic.Uintptr{}): _atomic,
+		reflect.TypeOf(atomic.Value{}):   _atomic,
+	}
+}
+
+// _immutable returns values which are never modified after their creation as they are.
+// This keeps e.g. the monotonic clock reading of a time.Time and shares time zones.
+func _immutable(x interface{}, s *state) (interface{}, error) {
+	return x, nil
+}
+
+func _bigInt(x interface{}, s *state) (interface{}, error) {
+	v, ok := x.(big.Int)
+	if !ok {
+		return nil, fmt.Errorf("%w: must pass a value of type big.Int; got %T", ErrKindMismatch, x)
+	}
+	return *new(big.Int).Set(&v), nil
+}
+
+func _bigFloat(x interface{}, s *state) (interface{}, error) {
+	v, ok := x.(big.Float)
+	if !ok {
+		return nil, fmt.Errorf("%w: must pass a value of type big.Float; got %T", ErrKindMismatch, x)
+	}
+	return *new(big.Float).Copy(&v), nil
+}
+
+func _bigRat(x interface{}, s *state) (interface{}, error) {
+	v, ok := x.(big.Rat)
+	if !ok {
+		return nil, fmt.Errorf("%w: must pass a value of type big.Rat; got %T", ErrKindMismatch, x)
+	}
+	return *new(big.Rat).Set(&v), nil
+}
+
+func _userinfo(x interface{}, s *state) (interface{}, error) {
+	v, ok := x.(url.Userinfo)
+	if !ok {
+		return nil, fmt.Errorf("%w: must pass a value of type url.Userinfo; got %T", ErrKindMismatch, x)
+	}
+	if password, ok := v.Password(); ok {
+		return *url.UserPassword(v.Username(), password), nil
+	}
+	return *url.User(v.Username()), nil
+}
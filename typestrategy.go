@@ -0,0 +1,51 @@
+package mask
+
+import "reflect"
+
+// WithMaskTypes masks all values of type T anywhere in the value using strategy,
+// e.g. auth tokens or database types holding personal data, without tagging
+// every field of that type. Pointers to T are masked by their element.
+//
+// If T is an interface type, all values implementing it are masked, e.g. using
+// WithMaskTypes[fmt.Stringer]. Values held by interfaces, like fields of type
+// interface{}, are masked by their dynamic type; the copy keeps the static type
+// of the interface. Strategies for concrete types take precedence over those for
+// interfaces, which are tried in the order given.
+//
+// Struct tags and path strategies take precedence over type strategies.
+// Map keys are not masked, see WithMapKeyMasking.
+func WithMaskTypes[T any](strategy Strategy) Option {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(o *options) {
+		if t.Kind() == reflect.Interface {
+			o.interfaceStrategies = append(o.interfaceStrategies, interfaceStrategy{t, strategy})
+			return
+		}
+		if o.typeStrategies == nil {
+			o.typeStrategies = make(map[reflect.Type]Strategy)
+		}
+		o.typeStrategies[t] = strategy
+	}
+}
+
+// interfaceStrategy masks all values implementing iface.
+type interfaceStrategy struct {
+	iface    reflect.Type
+	strategy Strategy
+}
+
+// typeStrategy returns the strategy masking all values of the type of v, if any.
+func (s *state) typeStrategy(v reflect.Value) Strategy {
+	if s.inKey || v.Kind() == reflect.Interface {
+		return nil
+	}
+	if strategy, ok := s.opts.typeStrategies[v.Type()]; ok {
+		return strategy
+	}
+	for _, is := range s.opts.interfaceStrategies {
+		if v.Type().Implements(is.iface) {
+			return is.strategy
+		}
+	}
+	return nil
+}
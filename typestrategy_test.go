@@ -0,0 +1,148 @@
+package mask
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+type authToken string
+
+type session struct {
+	Token    authToken
+	Previous *authToken
+	History  []authToken
+	ByToken  map[authToken]int
+	Meta     map[string]interface{}
+	Pinned   authToken `mask:"partial=2:0"`
+}
+
+func TestWithMaskTypes(t *testing.T) {
+	prev := authToken("tok_prev")
+	val := session{
+		Token:    "tok_1",
+		Previous: &prev,
+		History:  []authToken{"tok_0"},
+		ByToken:  map[authToken]int{"tok_1": 1},
+		Meta:     map[string]interface{}{"token": authToken("tok_2")},
+		Pinned:   "tok_3",
+	}
+	opts := []Option{WithMaskTypes[authToken](maskers.Partial(4, 0, maskers.Format{Char: 'x'}))}
+	masked, err := Mask(val, opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	maskedPrev := authToken("tok_xxxx")
+	expected := session{
+		Token:    "tok_x",
+		Previous: &maskedPrev,
+		History:  []authToken{"tok_x"},
+		ByToken:  map[authToken]int{"tok_1": 1},
+		Meta:     map[string]interface{}{"token": authToken("tok_x")},
+		Pinned:   "to***",
+	}
+	if !reflect.DeepEqual(masked, expected) {
+		t.Errorf("expect %v == %v", masked, expected)
+	}
+	if *val.Previous != "tok_prev" {
+		t.Errorf("expect original to be kept, got %v", *val.Previous)
+	}
+
+	findings, err := Scan(val, opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var sources []string
+	for _, f := range findings {
+		sources = append(sources, f.Path+":"+string(f.Source))
+	}
+	expectedSources := []string{
+		"session.Token:type",
+		"session.Previous:type",
+		"session.History[0]:type",
+		`session.Meta["token"]:type`,
+		"session.Pinned:tag",
+	}
+	if !reflect.DeepEqual(sources, expectedSources) {
+		t.Errorf("expect %v == %v", sources, expectedSources)
+	}
+}
+
+type accountID int
+
+func (a accountID) String() string {
+	return "acc_" + strconv.Itoa(int(a))
+}
+
+type ledgerEntry struct {
+	Account fmt.Stringer
+	Ref     interface{}
+	Note    fmt.Stringer
+	Values  []interface{}
+}
+
+func TestWithMaskTypesInterface(t *testing.T) {
+	val := ledgerEntry{
+		Account: accountID(42),
+		Ref:     authToken("tok_1"),
+		Note:    nil,
+		Values:  []interface{}{accountID(7), "plain", authToken("tok_2")},
+	}
+	opts := []Option{
+		WithMaskTypes[fmt.Stringer](maskers.Func("zero", func(v reflect.Value) (reflect.Value, error) {
+			return reflect.Zero(v.Type()), nil
+		})),
+		WithMaskTypes[authToken](maskers.Partial(4, 0, maskers.Format{Char: 'x'})),
+	}
+	masked, err := Mask(val, opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := ledgerEntry{
+		Account: accountID(0),
+		Ref:     authToken("tok_x"),
+		Values:  []interface{}{accountID(0), "plain", authToken("tok_x")},
+	}
+	if !reflect.DeepEqual(masked, expected) {
+		t.Errorf("expect %v == %v", masked, expected)
+	}
+
+	// the copy keeps the static type of interfaces
+	stringer, err := Mask[fmt.Stringer](accountID(1), opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stringer != accountID(0) {
+		t.Errorf("expect %v == acc_0", stringer)
+	}
+
+	b, err := json.Marshal(JSON(val, opts...))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expect := `{"Account":0,"Ref":"tok_x","Note":null,"Values":[0,"plain","tok_x"]}`; string(b) != expect {
+		t.Errorf("expect %s == %s", b, expect)
+	}
+
+	findings, err := Scan(val, opts...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var sources []string
+	for _, f := range findings {
+		sources = append(sources, f.Path+":"+f.Strategy)
+	}
+	expectedSources := []string{
+		"ledgerEntry.Account:zero",
+		"ledgerEntry.Ref:partial",
+		"ledgerEntry.Values[0]:zero",
+		"ledgerEntry.Values[2]:partial",
+	}
+	if !reflect.DeepEqual(sources, expectedSources) {
+		t.Errorf("expect %v == %v", sources, expectedSources)
+	}
+}
@@ -0,0 +1,54 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+// revealer reverses the strategies applied by Mask, see Unmask.
+type revealer struct {
+	// decrypt reverses encryption using the keys passed to Unmask, if any.
+	decrypt maskers.Reversible
+}
+
+// Unmask restores the original form of x, which was masked by Mask using opts, e.g. in
+// services authorized to re-identify records. Every value masked by a strategy of a struct
+// tag or of opts is restored by the strategy's Unmask method, see maskers.Reversible:
+//
+//	mask.RegisterStrategy("encrypt", func(string) (mask.Strategy, error) {
+//		return maskers.EncryptWithKeys(keys), nil
+//	})
+//
+//	original, err := mask.Unmask(masked, keys)
+//
+// Values encrypted by maskers.Encrypt or maskers.EncryptWithKeys are decrypted using keys;
+// if keys is nil, strategies restore values using their own secrets, e.g. the token store
+// of maskers.Tokenize. Unmask fails with ErrIrreversible if any value was masked irreversibly,
+// e.g. redacted, omitted or masked by a MaskXXX method. Detectors are not applied.
+func Unmask[T any](x T, keys maskers.KeyProvider, opts ...Option) (T, error) {
+	r := &revealer{}
+	if keys != nil {
+		r.decrypt = maskers.EncryptWithKeys(keys)
+	}
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		o.reveal = r
+		// masked values cannot be told apart by their content
+		o.detectors = nil
+		o.session = nil
+	})
+	return Mask(x, opts...)
+}
+
+// reveal restores v, which was masked by strategy.
+func (r *revealer) reveal(strategy Strategy, v reflect.Value) (reflect.Value, error) {
+	reversible, ok := strategy.(maskers.Reversible)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrIrreversible, strategy.Name())
+	}
+	if r.decrypt != nil && reversible.Name() == r.decrypt.Name() {
+		reversible = r.decrypt
+	}
+	return applyStrategy(maskers.Func(reversible.Name(), reversible.Unmask), v)
+}
@@ -0,0 +1,85 @@
+package mask
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+func TestUnmask(t *testing.T) {
+	keys, err := maskers.NewMemoryKeyProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := maskers.NewMemoryTokenStore()
+	RegisterStrategy("test-encrypt", func(string) (Strategy, error) {
+		return maskers.EncryptWithKeys(keys), nil
+	})
+	RegisterStrategy("test-tokenize", func(string) (Strategy, error) {
+		return maskers.Tokenize(tokens), nil
+	})
+	type account struct {
+		ID    int
+		Email string  `mask:"test-encrypt"`
+		Phone *string `mask:"test-tokenize"`
+		IBAN  []byte
+	}
+	phone := "+44 20 7946 0958"
+	val := []account{{ID: 1, Email: "ada@example.com", Phone: &phone, IBAN: []byte("GB82WEST12345698765432")}, {ID: 2}}
+	opts := []Option{WithPathStrategy("[*].IBAN", maskers.EncryptWithKeys(keys))}
+	masked := Must(val, opts...)
+	if masked[0].Email == val[0].Email || *masked[0].Phone == phone || string(masked[0].IBAN) == string(val[0].IBAN) {
+		t.Fatalf("expect %+v to be masked", masked[0])
+	}
+
+	// values encrypted before a rotation are decrypted by their key
+	if _, err := keys.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []maskers.KeyProvider{keys, nil} {
+		unmasked, err := Unmask(masked, k, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := unmasked[0]
+		if got.Email != val[0].Email || *got.Phone != phone || string(got.IBAN) != string(val[0].IBAN) || unmasked[1].ID != 2 {
+			t.Errorf("expect %+v == %+v", got, val[0])
+		}
+	}
+
+	other, err := maskers.NewMemoryKeyProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Unmask(masked, other, opts...); !errors.Is(err, maskers.ErrDecrypt) {
+		t.Errorf("expect %v to be %v", err, maskers.ErrDecrypt)
+	}
+}
+
+func TestUnmaskIrreversible(t *testing.T) {
+	type user struct {
+		Name  string `mask:"partial=1:0"`
+		Notes string `mask:"omit"`
+	}
+	_, err := Unmask(user{Name: "A**"}, nil)
+	if !errors.Is(err, ErrIrreversible) {
+		t.Errorf("expect %v to be %v", err, ErrIrreversible)
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "user.Name" {
+		t.Errorf("expect %v to locate user.Name", err)
+	}
+
+	if _, err := Unmask(TestString("MASKED"), nil); !errors.Is(err, ErrIrreversible) {
+		t.Errorf("expect %v to be %v", err, ErrIrreversible)
+	}
+
+	// unmasked values are kept
+	type plain struct {
+		ID int
+	}
+	if out, err := Unmask(plain{ID: 1}, nil); err != nil || out.ID != 1 {
+		t.Errorf("expect %+v to be kept: %v", out, err)
+	}
+}
@@ -0,0 +1,101 @@
+package mask
+
+import (
+	"errors"
+	"reflect"
+)
+
+// SkipChildren is returned by a Visitor to skip the fields and elements of the value visited.
+var SkipChildren = errors.New("skip children")
+
+// Visitor is called by Walk for every value. path locates the value like in findings,
+// e.g. Order.Items[3].Card. Returning SkipChildren skips the fields and elements of v,
+// any other error stops the walk.
+type Visitor func(path string, v reflect.Value) error
+
+// Walk visits x and all values reachable from it depth first, without copying or
+// masking anything. Pointers aside, values are visited in the order and at the paths
+// hooks of Mask are called for them, see WithHook. Walk does not share the traversal
+// of Mask though: it is safe for cycles by visiting every pointer, map and slice once,
+// whereas hooks are called for values shared by several pointers at each of them.
+// Interfaces are followed to their dynamic values, map entries are visited in key
+// order if WithSortedMaps is given. Unexported fields and map keys are not visited.
+//
+// WithMaxDepth limits the depth of the walk; values nested deeper are not visited.
+// Other options do not apply: unlike Mask, Walk calls no hooks, enforces no other
+// limits and visits the fields and elements of values masked as a whole by strategies.
+func Walk(x interface{}, visitor Visitor, opts ...Option) error {
+	s := newState(x, opts)
+	defer s.release()
+	if s.opts.err != nil {
+		return s.opts.err
+	}
+	err := s.walk(reflect.ValueOf(x), visitor)
+	if err == SkipChildren {
+		return nil
+	}
+	return err
+}
+
+func (s *state) walk(v reflect.Value, visitor Visitor) error {
+	if !v.IsValid() || s.exceedsDepth() {
+		return nil
+	}
+	if v.Kind() == reflect.Interface {
+		return s.walk(v.Elem(), visitor)
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			if s.ptrs[v.Pointer()] != nil {
+				return nil
+			}
+			s.ptrs[v.Pointer()] = true
+		}
+	case reflect.Slice, reflect.Map:
+		if s.scanned(v) {
+			return nil
+		}
+	}
+	if err := visitor(s.currentPath(), v); err == SkipChildren {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		return s.walk(v.Elem(), visitor)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			s.pushField(t.Field(i).Name)
+			err := s.walk(v.Field(i), visitor)
+			s.pop()
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			s.pushIndex(i)
+			err := s.walk(v.Index(i), visitor)
+			s.pop()
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, e := range mapEntries(v, s.opts.sortMaps) {
+			s.pushKey(e.key.Interface())
+			err := s.walk(e.value, visitor)
+			s.pop()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package mask
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type walkNode struct {
+	Name     string
+	Children []*walkNode
+	Parent   *walkNode
+	Meta     map[string]interface{}
+	secret   string
+}
+
+func TestWalk(t *testing.T) {
+	root := &walkNode{Name: "root", Meta: map[string]interface{}{"b": 2, "a": []int{1}}, secret: "s"}
+	child := &walkNode{Name: "child", Parent: root}
+	root.Children = []*walkNode{child, child}
+
+	var visited []string
+	err := Walk(root, func(path string, v reflect.Value) error {
+		visited = append(visited, path+":"+v.Kind().String())
+		return nil
+	}, WithSortedMaps())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{
+		"walkNode:ptr",
+		"walkNode:struct",
+		"walkNode.Name:string",
+		"walkNode.Children:slice",
+		"walkNode.Children[0]:ptr",
+		"walkNode.Children[0]:struct",
+		"walkNode.Children[0].Name:string",
+		"walkNode.Children[0].Children:slice",
+		"walkNode.Children[0].Meta:map",
+		"walkNode.Parent:ptr",
+		"walkNode.Meta:map",
+		`walkNode.Meta["a"]:slice`,
+		`walkNode.Meta["a"][0]:int`,
+		`walkNode.Meta["b"]:int`,
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expect %v == %v", visited, expected)
+	}
+}
+
+func TestWalkMaskPaths(t *testing.T) {
+	val := &walkNode{
+		Name:     "root",
+		Children: []*walkNode{{Name: "a"}, {Name: "b", Meta: map[string]interface{}{"n": 1}}},
+		Meta:     map[string]interface{}{"b": 2, "a": []int{1}, "c": &walkNode{Name: "c"}},
+		secret:   "s",
+	}
+	var walked, masked []string
+	err := Walk(val, func(path string, v reflect.Value) error {
+		// hooks are not called for pointers
+		if v.Kind() != reflect.Ptr {
+			walked = append(walked, path)
+		}
+		return nil
+	}, WithSortedMaps())
+	if err != nil {
+		t.Fatal(err)
+	}
+	Must(val, WithSortedMaps(), WithHook(func(path string, v reflect.Value) HookResult {
+		masked = append(masked, path)
+		return HookResult{}
+	}))
+	if !reflect.DeepEqual(walked, masked) {
+		t.Errorf("expect %v == %v", walked, masked)
+	}
+
+	// values shared by several pointers are walked once, but hooked at each of them
+	val.Children[1] = val.Children[0]
+	walked, masked = nil, nil
+	Walk(val, func(path string, v reflect.Value) error {
+		walked = append(walked, path)
+		return nil
+	})
+	Must(val, WithHook(func(path string, v reflect.Value) HookResult {
+		masked = append(masked, path)
+		return HookResult{}
+	}))
+	if slices.Contains(walked, "walkNode.Children[1]") || !slices.Contains(masked, "walkNode.Children[1]") {
+		t.Errorf("expect the shared child to be walked once, got %v and %v", walked, masked)
+	}
+}
+
+func TestWalkSkipAndStop(t *testing.T) {
+	val := walkNode{Name: "root", Children: []*walkNode{{Name: "child"}}}
+	var visited []string
+	err := Walk(val, func(path string, v reflect.Value) error {
+		visited = append(visited, path)
+		if v.Kind() == reflect.Slice {
+			return SkipChildren
+		}
+		return nil
+	})
+	if err != nil || len(visited) != 5 {
+		t.Errorf("expect children to be skipped, got %v, %v", visited, err)
+	}
+
+	stop := errors.New("stop")
+	err = Walk(val, func(path string, v reflect.Value) error {
+		if v.Kind() == reflect.String {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("expect %v == %v", err, stop)
+	}
+}
@@ -0,0 +1,106 @@
+package mask
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/doejon/go-mask/maskers"
+)
+
+// WatermarkProperty is the property of the root JSON object holding the watermark, see WithWatermark.
+const WatermarkProperty = "_watermark"
+
+// Watermark identifies the pipeline, policy version and audience which produced a masked
+// document, so leaked documents can be traced to their source. MAC authenticates them,
+// so watermarks cannot be forged without the key.
+type Watermark struct {
+	Pipeline string `json:"pipeline,omitempty"`
+	Policy   string `json:"policy,omitempty"`
+	Audience string `json:"audience,omitempty"`
+	MAC      string `json:"mac,omitempty"`
+}
+
+// sum returns the HMAC-SHA256 of the fields of w using key.
+func (w Watermark) sum(key []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	for _, field := range []string{w.Pipeline, w.Policy, w.Audience} {
+		// length prefixes keep fields from being shifted into each other
+		fmt.Fprintf(h, "%d:%s", len(field), field)
+	}
+	return h.Sum(nil)
+}
+
+// Sign returns w with its MAC computed using key.
+func (w Watermark) Sign(key []byte) Watermark {
+	w.MAC = hex.EncodeToString(w.sum(key))
+	return w
+}
+
+// Verify reports whether the MAC of w was computed using key.
+func (w Watermark) Verify(key []byte) bool {
+	mac, err := hex.DecodeString(w.MAC)
+	return err == nil && hmac.Equal(mac, w.sum(key))
+}
+
+// WithWatermark embeds w, signed using key, into masked documents: struct fields of
+// type Watermark are set to it and JSON adds it to the root object as WatermarkProperty,
+// unless the root struct holds a Watermark field:
+//
+//	mark := mask.Watermark{Pipeline: "export-orders", Policy: "v12", Audience: "analytics"}
+//	json.NewEncoder(w).Encode(mask.JSON(orders, mask.WithWatermark(key, mark)))
+//
+// Read watermarks of leaked documents using ReadWatermark.
+func WithWatermark(key []byte, w Watermark) Option {
+	signed := w.Sign(key)
+	mark := WithMaskTypes[Watermark](maskers.Func("watermark", func(reflect.Value) (reflect.Value, error) {
+		return reflect.ValueOf(signed), nil
+	}))
+	return func(o *options) {
+		mark(o)
+		o.watermark = &signed
+	}
+}
+
+// ReadWatermark reads the watermark of the JSON document doc, as written by WithWatermark.
+// Use Watermark.Verify to check its authenticity.
+func ReadWatermark(doc []byte) (Watermark, bool) {
+	var root struct {
+		Watermark *Watermark `json:"_watermark"`
+	}
+	if err := json.Unmarshal(doc, &root); err != nil || root.Watermark == nil {
+		return Watermark{}, false
+	}
+	return *root.Watermark, true
+}
+
+var watermarkType = reflect.TypeOf(Watermark{})
+
+// watermarked reports whether the JSON encoding of the struct type t holds a watermark field.
+func watermarked(t reflect.Type) bool {
+	for _, f := range jsonFieldsOf(t) {
+		ft := f.field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft == watermarkType {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeWatermark adds the watermark, if any, to the JSON object encoded currently if it is the root.
+func (e *jsonEncoder) encodeWatermark(t reflect.Type) error {
+	mark := e.s.opts.watermark
+	if mark == nil || len(e.s.path) > 0 || t.Kind() == reflect.Struct && watermarked(t) {
+		return nil
+	}
+	if err := e.w.str(WatermarkProperty); err != nil {
+		return err
+	}
+	return e.w.value(*mark)
+}
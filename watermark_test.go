@@ -0,0 +1,81 @@
+package mask
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWithWatermark(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	mark := Watermark{Pipeline: "export", Policy: "v12", Audience: "analytics"}
+	opt := WithWatermark(key, mark)
+
+	type record struct {
+		Email string `json:"email" mask:"redact"`
+	}
+	doc, err := json.Marshal(JSON(record{Email: "ada@example.com"}, opt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, ok := ReadWatermark(doc)
+	if !ok || !read.Verify(key) || read.Pipeline != "export" || read.Policy != "v12" || read.Audience != "analytics" {
+		t.Errorf("expect %s to hold a valid watermark, got %+v", doc, read)
+	}
+	if !strings.HasPrefix(string(doc), `{"email":"MASKED","_watermark":{`) {
+		t.Errorf("expect %s to be masked", doc)
+	}
+
+	// maps and documents masked by Mask
+	doc, err = json.Marshal(JSON(map[string]int{"a": 1}, opt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read, ok := ReadWatermark(doc); !ok || !read.Verify(key) {
+		t.Errorf("expect %s to hold a valid watermark", doc)
+	}
+	type document struct {
+		Records   []record  `json:"records"`
+		Watermark Watermark `json:"watermark"`
+	}
+	masked := Must(document{Records: []record{{Email: "ada@example.com"}}}, opt)
+	if !masked.Watermark.Verify(key) || masked.Watermark.Policy != "v12" {
+		t.Errorf("expect %+v to hold a valid watermark", masked.Watermark)
+	}
+	doc, err = json.Marshal(JSON(document{}, opt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(doc), WatermarkProperty) || !strings.Contains(string(doc), `"mac":"`+masked.Watermark.MAC) {
+		t.Errorf("expect %s to hold the watermark in its field only", doc)
+	}
+
+	// only root objects are watermarked
+	doc, err = json.Marshal(JSON([]record{{}}, opt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ReadWatermark(doc); ok || strings.Contains(string(doc), WatermarkProperty) {
+		t.Errorf("expect %s not to be watermarked", doc)
+	}
+}
+
+func TestWatermarkVerify(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	mark := Watermark{Pipeline: "export", Policy: "v1"}.Sign(key)
+	if !mark.Verify(key) {
+		t.Errorf("expect %+v to be valid", mark)
+	}
+	if mark.Verify([]byte("fedcba9876543210")) {
+		t.Errorf("expect %+v to be invalid using another key", mark)
+	}
+	forged := mark
+	forged.Audience = "support"
+	if forged.Verify(key) {
+		t.Errorf("expect %+v to be invalid", forged)
+	}
+	shifted := Watermark{Pipeline: "expor", Policy: "tv1", MAC: mark.MAC}
+	if shifted.Verify(key) {
+		t.Errorf("expect %+v to be invalid", shifted)
+	}
+}
@@ -0,0 +1,90 @@
+package mask
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// wrapper locates the value and validity flag of a struct type wrapping an optional value.
+type wrapper struct {
+	value []int
+	valid []int
+}
+
+// wrappers are keyed by wrapperKey, built-in ones cover the nullable types of database/sql.
+var wrappers = newRegistry(map[string]wrapper{
+	"database/sql.NullString":  {[]int{0}, []int{1}},
+	"database/sql.NullInt64":   {[]int{0}, []int{1}},
+	"database/sql.NullInt32":   {[]int{0}, []int{1}},
+	"database/sql.NullInt16":   {[]int{0}, []int{1}},
+	"database/sql.NullByte":    {[]int{0}, []int{1}},
+	"database/sql.NullFloat64": {[]int{0}, []int{1}},
+	"database/sql.NullBool":    {[]int{0}, []int{1}},
+	"database/sql.NullTime":    {[]int{0}, []int{1}},
+	"database/sql.Null":        {[]int{0}, []int{1}},
+})
+
+// wrapperKey identifies the struct type t, or the generic type t is an instance of.
+func wrapperKey(t reflect.Type) string {
+	name, _, _ := strings.Cut(t.Name(), "[")
+	return t.PkgPath() + "." + name
+}
+
+// RegisterWrapper makes strategies mask the value held by the struct type T rather than
+// T itself, like they do for sql.NullString and the other nullable types of database/sql.
+// value and valid name the fields holding the value and whether it is set; values
+// which are not set are kept, the flag is never changed:
+//
+//	mask.RegisterWrapper[null.String]("String", "Valid")
+//
+// Fields may be promoted from embedded structs. Registering an instance of a
+// generic type, e.g. Option[string], registers all of its instances.
+// It is safe to register wrappers while masking.
+func RegisterWrapper[T any](value, valid string) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: wrapper %v must be a struct", ErrKindMismatch, t)
+	}
+	vf, ok := t.FieldByName(value)
+	if !ok || vf.PkgPath != "" {
+		return fmt.Errorf("%w: wrapper %v has no exported field %q", ErrKindMismatch, t, value)
+	}
+	bf, ok := t.FieldByName(valid)
+	if !ok || bf.PkgPath != "" || bf.Type.Kind() != reflect.Bool {
+		return fmt.Errorf("%w: wrapper %v has no exported bool field %q", ErrKindMismatch, t, valid)
+	}
+	for _, index := range [][]int{vf.Index, bf.Index} {
+		for i := 1; i < len(index); i++ {
+			if t.FieldByIndex(index[:i]).Type.Kind() == reflect.Ptr {
+				return fmt.Errorf("%w: wrapper %v embeds a pointer holding its fields", ErrKindMismatch, t)
+			}
+		}
+	}
+	wrappers.store(wrapperKey(t), wrapper{vf.Index, bf.Index})
+	return nil
+}
+
+// unwrap returns the wrapper of the struct v, if its type is a registered wrapper.
+func unwrap(v reflect.Value) (wrapper, bool) {
+	if v.Type().Name() == "" {
+		return wrapper{}, false
+	}
+	return wrappers.load(wrapperKey(v.Type()))
+}
+
+// applyWrapped masks the value held by v, which is wrapped by w, using s.
+// Values which are not set are returned unchanged.
+func applyWrapped(s Strategy, v reflect.Value, w wrapper) (reflect.Value, error) {
+	if !v.FieldByIndex(w.valid).Bool() {
+		return v, nil
+	}
+	inner, err := applyStrategy(s, v.FieldByIndex(w.value))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	dc := reflect.New(v.Type()).Elem()
+	dc.Set(v)
+	dc.FieldByIndex(w.value).Set(inner)
+	return dc, nil
+}
@@ -0,0 +1,85 @@
+package mask
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testNullString struct {
+	sql.NullString
+}
+
+type testOption[T any] struct {
+	Value T
+	Set   bool
+}
+
+type testNullable struct {
+	Email   sql.NullString     `mask:"redact"`
+	Missing sql.NullString     `mask:"redact"`
+	Age     sql.NullInt64      `mask:"redact"`
+	Seen    sql.NullTime       `mask:"redact"`
+	Score   sql.Null[int32]    `mask:"redact"`
+	Phone   testNullString     `mask:"redact"`
+	Name    testOption[string] `mask:"redact"`
+	Nick    **string           `mask:"redact"`
+	Unset   **string           `mask:"redact"`
+}
+
+func TestWrappers(t *testing.T) {
+	if err := RegisterWrapper[testNullString]("String", "Valid"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := RegisterWrapper[testOption[int]]("Value", "Set"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	nick := "ada"
+	nickPtr := &nick
+	val := testNullable{
+		Email: sql.NullString{String: "ada@example.com", Valid: true},
+		Age:   sql.NullInt64{Int64: 36, Valid: true},
+		Seen:  sql.NullTime{Time: time.Unix(0, 0), Valid: true},
+		Score: sql.Null[int32]{V: 7, Valid: true},
+		Phone: testNullString{sql.NullString{String: "555-0100", Valid: true}},
+		Name:  testOption[string]{Value: "Ada", Set: true},
+		Nick:  &nickPtr,
+		Unset: new(*string),
+	}
+	masked, err := Mask(val)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := testNullable{
+		Email: sql.NullString{String: "MASKED", Valid: true},
+		Age:   sql.NullInt64{Valid: true},
+		Seen:  sql.NullTime{Valid: true},
+		Score: sql.Null[int32]{Valid: true},
+		Phone: testNullString{sql.NullString{String: "MASKED", Valid: true}},
+		Name:  testOption[string]{Value: "MASKED", Set: true},
+		Unset: new(*string),
+	}
+	maskedNick := "MASKED"
+	maskedNickPtr := &maskedNick
+	expected.Nick = &maskedNickPtr
+	if !reflect.DeepEqual(masked, expected) {
+		t.Errorf("expect %+v == %+v", masked, expected)
+	}
+	if val.Email.String != "ada@example.com" || **val.Nick != "ada" {
+		t.Errorf("expect the original to be kept, got %v", val)
+	}
+}
+
+func TestRegisterWrapperInvalid(t *testing.T) {
+	if err := RegisterWrapper[string]("V", "Valid"); !errors.Is(err, ErrKindMismatch) {
+		t.Errorf("expect %v == %v", err, ErrKindMismatch)
+	}
+	if err := RegisterWrapper[testOption[int]]("Missing", "Set"); !errors.Is(err, ErrKindMismatch) {
+		t.Errorf("expect %v == %v", err, ErrKindMismatch)
+	}
+	if err := RegisterWrapper[testOption[int]]("Set", "Value"); !errors.Is(err, ErrKindMismatch) {
+		t.Errorf("expect %v == %v", err, ErrKindMismatch)
+	}
+}